@@ -1,27 +1,57 @@
 package api
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"math"
+	"math/rand"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/noueii/nocs-log-generator/backend/pkg/formatter"
 	"github.com/noueii/nocs-log-generator/backend/pkg/generator"
 	"github.com/noueii/nocs-log-generator/backend/pkg/models"
+	"github.com/noueii/nocs-log-generator/backend/pkg/parser"
+	"github.com/noueii/nocs-log-generator/backend/pkg/scenarios"
+	"github.com/noueii/nocs-log-generator/backend/pkg/store"
 	"github.com/noueii/nocs-log-generator/backend/pkg/websocket"
 )
 
+// defaultMaxInlineLogSize caps the size of a log returned inline with a generate response
+const defaultMaxInlineLogSize = 5 * 1024 * 1024
+
+// DemoParser turns a demo file into a match
+type DemoParser interface {
+	ParseDemo(demoPath string) (*models.Match, error)
+}
+
 // Handler contains dependencies for API handlers
 type Handler struct {
-	generator *generator.MatchGenerator
-	wsManager *websocket.Manager
+	generator  *generator.MatchGenerator
+	store      *store.MatchStore
+	wsManager  *websocket.Manager
+	demoParser DemoParser
+	
+	maxInlineLogSize int // Largest log returned inline, in bytes
 }
 
 // NewHandler creates a new API handler instance
 func NewHandler() *Handler {
 	return &Handler{
-		generator: generator.NewMatchGenerator(),
+		generator:  generator.NewMatchGenerator(),
+		store:      store.NewMatchStore(),
+		demoParser: parser.NewDemoParser(),
+		
+		maxInlineLogSize: defaultMaxInlineLogSize,
 	}
 }
 
@@ -30,16 +60,46 @@ func (h *Handler) SetWebSocketManager(wsManager *websocket.Manager) {
 	h.wsManager = wsManager
 }
 
+// SetMaxInlineLogSize sets the largest log, in bytes, returned inline with a generate
+// response. Larger logs get a 413 pointing at the log endpoint.
+func (h *Handler) SetMaxInlineLogSize(size int) {
+	h.maxInlineLogSize = size
+}
+
 // RegisterRoutes sets up API routes
 func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
 	// Match generation endpoints
 	router.POST("/generate", h.GenerateMatch)
+	router.POST("/generate/stream", h.StreamMatch)
+	router.POST("/generate/series", h.GenerateSeries)
+	
+	// Simulation endpoints
+	router.POST("/simulate/round", h.SimulateRound)
+	router.POST("/simulate/engagement", h.SimulateEngagement)
+	
+	// Scenario endpoints
+	router.GET("/scenarios", h.ListScenarios)
+	router.POST("/scenarios/:name", h.GenerateScenario)
 	
 	// Configuration endpoints
 	router.GET("/config/templates", h.GetConfigTemplates)
 	router.GET("/config/maps", h.GetAvailableMaps)
+	router.GET("/schema/generate", h.GetGenerateSchema)
+	
+	// Match endpoints
+	router.GET("/matches/:id/log", h.GetMatchLog)
+	router.GET("/matches/:id/economy", h.GetMatchEconomy)
+	router.GET("/matches/:id/weapons", h.GetMatchWeapons)
+	router.GET("/matches/:id/timeline", h.GetMatchTimeline)
+	router.GET("/matches/:id/summary", h.GetMatchSummary)
+	router.GET("/matches/:id/scoreboard", h.GetMatchScoreboard)
+	router.POST("/matches/compare", h.CompareMatches)
+	router.POST("/matches/:id/regenerate", h.RegenerateMatch)
 	
-	// Demo parsing endpoints (placeholder)
+	// Formatting endpoints
+	router.POST("/format", h.FormatEvents)
+	
+	// Demo parsing endpoints
 	router.POST("/parse", h.ParseDemo)
 	
 	// Utility endpoints
@@ -51,32 +111,35 @@ func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
 func (h *Handler) GenerateMatch(c *gin.Context) {
 	var req models.GenerateRequest
 	
-	// Parse and validate request
-	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Printf("Invalid request: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request format: " + err.Error(),
-		})
-		return
-	}
-	
-	// Validate the request
-	if err := req.Validate(); err != nil {
-		log.Printf("Basic validation failed: %v", err)
-		c.JSON(http.StatusBadRequest, GenerateResponseError("Basic validation failed: "+err.Error()))
+	inline, ok := inlineFormat(c)
+	if !ok {
 		return
 	}
 	
-	// Additional validation
-	if err := ValidateGenerateRequest(&req); err != nil {
-		log.Printf("Request validation failed: %v", err)
-		c.JSON(http.StatusBadRequest, GenerateResponseError("Validation failed: "+err.Error()))
+	if !bindGenerateRequest(c, &req) {
 		return
 	}
 	
+	h.generateAndRespond(c, &req, inline)
+}
+
+// generateAndRespond generates a validated request, stores the match and writes the response
+func (h *Handler) generateAndRespond(c *gin.Context, req *models.GenerateRequest, inline string) {
 	// Sanitize team data
 	req.Teams = SanitizeTeamData(req.Teams)
 	
+	// Return a cached match for an identical seeded request
+	config := generator.BuildMatchConfig(req)
+	var digest string
+	if config.Seed != 0 {
+		digest = requestDigest(&config, req.Teams)
+		if cached, ok := h.store.GetByDigest(digest); ok {
+			log.Printf("Returning cached match %s for identical request", cached.ID)
+			h.writeGenerateResponse(c, cached, inline, true)
+			return
+		}
+	}
+	
 	// Broadcast generation start event if WebSocket is available
 	if h.wsManager != nil {
 		startEvent := websocket.GenerationStartEvent{
@@ -84,15 +147,20 @@ func (h *Handler) GenerateMatch(c *gin.Context) {
 			Teams:     []string{req.Teams[0].Name, req.Teams[1].Name},
 			Map:       req.Map,
 			Format:    req.Format,
-			MaxRounds: getMaxRoundsForFormat(req.Format),
+			MaxRounds: config.GetMaxRounds(),
 			StartedAt: time.Now().UTC(),
 		}
 		// We'll broadcast this after we have the match ID
 		_ = startEvent
 	}
 
-	// Generate the match using the real generator
-	match, err := h.generator.GenerateWithStreaming(&req, h.wsManager)
+	// Generate the match using the real generator. A nil manager is passed as a nil
+	// interface, so the generator does not try to broadcast through it.
+	var wsManager generator.WebSocketManager
+	if h.wsManager != nil {
+		wsManager = h.wsManager
+	}
+	match, err := h.generator.GenerateWithStreaming(req, wsManager)
 	if err != nil {
 		log.Printf("Match generation failed: %v", err)
 		
@@ -114,7 +182,7 @@ func (h *Handler) GenerateMatch(c *gin.Context) {
 		completionEvent := websocket.GenerationCompleteEvent{
 			MatchID:     match.ID,
 			TotalRounds: len(match.Rounds),
-			TotalEvents: match.TotalEvents,
+			TotalEvents: int(match.TotalEvents),
 			Duration:    match.Duration,
 			CompletedAt: time.Now().UTC(),
 			Success:     true,
@@ -122,16 +190,244 @@ func (h *Handler) GenerateMatch(c *gin.Context) {
 		h.wsManager.BroadcastMatchEvent(match.ID, websocket.EventTypeGenerationEnd, completionEvent)
 	}
 	
-	// Return successful response
+	h.store.PutWithDigest(match, digest)
+	
+	h.writeGenerateResponse(c, match, inline, false)
+}
+
+// bindGenerateRequest parses and validates a generate request, writing an error response on failure
+func bindGenerateRequest(c *gin.Context, req *models.GenerateRequest) bool {
+	// Parse and validate request
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Printf("Invalid request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format: " + err.Error(),
+		})
+		return false
+	}
+	
+	// Fill incomplete teams before validating team sizes
+	if req.Options.AutoRoster {
+		generator.FillRosters(req)
+	}
+	
+	// Validate the request
+	if err := req.Validate(); err != nil {
+		log.Printf("Basic validation failed: %v", err)
+		c.JSON(http.StatusBadRequest, GenerateResponseError("Basic validation failed: "+err.Error()))
+		return false
+	}
+	
+	// Additional validation
+	if err := ValidateGenerateRequest(req); err != nil {
+		log.Printf("Request validation failed: %v", err)
+		c.JSON(http.StatusBadRequest, GenerateResponseError("Validation failed: "+err.Error()))
+		return false
+	}
+	
+	return true
+}
+
+// GenerateSeries generates a best-of-N series over the requested maps, storing the
+// match of every map played, and returns the series result
+func (h *Handler) GenerateSeries(c *gin.Context) {
+	var req models.SeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GenerateResponseError("Invalid request format: "+err.Error()))
+		return
+	}
+	req.Teams = SanitizeTeamData(req.Teams)
+	
+	result, matches, err := h.generator.GenerateSeries(&req)
+	for _, match := range matches {
+		h.store.Put(match)
+	}
+	if err != nil {
+		// No result means the request was rejected before any map was played
+		if result == nil {
+			c.JSON(http.StatusBadRequest, GenerateResponseError("Validation failed: "+err.Error()))
+			return
+		}
+		log.Printf("Series generation failed: %v", err)
+		c.JSON(http.StatusInternalServerError, GenerateResponseError("Series generation failed: "+err.Error()))
+		return
+	}
+	
+	log.Printf("Successfully generated series %s: best of %d, %d maps played, winner %q",
+		result.ID, result.BestOf, len(result.Maps), result.Winner)
+	c.JSON(http.StatusOK, result)
+}
+
+// StreamMatch generates a match and streams its log with chunked transfer encoding,
+// flushing each round's lines as soon as the round has been played
+func (h *Handler) StreamMatch(c *gin.Context) {
+	var req models.GenerateRequest
+	
+	if !bindGenerateRequest(c, &req) {
+		return
+	}
+	req.Teams = SanitizeTeamData(req.Teams)
+	
+	engine, err := h.generator.NewEngine(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, GenerateResponseError("Match generation failed: "+err.Error()))
+		return
+	}
+	match := engine.Match()
+	logFormatter := formatter.NewLogFormatter(&match.Config)
+	
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Header("X-Match-ID", match.ID)
+	c.Status(http.StatusOK)
+	
+	// Multi-line events carry their own line breaks, which take the configured ending too
+	terminator := match.Config.LineTerminator()
+	writeLines := func(lines ...string) bool {
+		for _, line := range lines {
+			if terminator != "\n" {
+				line = strings.ReplaceAll(line, "\n", terminator)
+			}
+			if _, err := io.WriteString(c.Writer, line+terminator); err != nil {
+				return false
+			}
+		}
+		c.Writer.Flush()
+		return true
+	}
+	
+	if !writeLines(logFormatter.FormatLogHeader(match)) {
+		return
+	}
+	
+	for engine.HasNextRound() {
+		_, roundEvents, err := h.generator.PlayNextRound(engine)
+		if err != nil {
+			// Headers are already sent, so the missing footer marks the log as incomplete;
+			// the failed match is stored so its error can be looked up by the match ID
+			log.Printf("Streamed generation of match %s failed: %v", match.ID, err)
+			h.store.Put(match)
+			return
+		}
+		
+		// Timestamps are derived from the match clock at finalization; apply it early for streamed rounds
+		models.RepairEventTimestamps(roundEvents, match.StartTime, match.Config.TickRate)
+		if !writeLines(logFormatter.FormatEventLines(roundEvents)...) {
+			log.Printf("Client disconnected while streaming match %s", match.ID)
+			return
+		}
+	}
+	
+	writeLines(logFormatter.FormatLogFooter(match))
+	h.store.Put(match)
+}
+
+// ListScenarios returns the canned scenarios that can be generated
+func (h *Handler) ListScenarios(c *gin.Context) {
+	list := scenarios.List()
+	c.JSON(http.StatusOK, gin.H{
+		"scenarios": list,
+		"count":     len(list),
+	})
+}
+
+// GenerateScenario generates the match for a named scenario
+func (h *Handler) GenerateScenario(c *gin.Context) {
+	name := c.Param("name")
+	scenario, ok := scenarios.Get(name)
+	if !ok {
+		c.JSON(http.StatusNotFound, GenerateResponseError("Unknown scenario: "+name, "list scenarios with GET /api/v1/scenarios"))
+		return
+	}
+	
+	inline, ok := inlineFormat(c)
+	if !ok {
+		return
+	}
+	
+	h.generateAndRespond(c, &scenario.Request, inline)
+}
+
+// inlineFormat reads the inline query parameter, writing an error response for unknown formats
+func inlineFormat(c *gin.Context) (string, bool) {
+	inline := c.Query("inline")
+	if inline != "" && inline != "standard" && inline != "json" && inline != "raw" {
+		c.JSON(http.StatusBadRequest, GenerateResponseError("Invalid inline format: "+inline, "supported formats: standard, json, raw"))
+		return "", false
+	}
+	return inline, true
+}
+
+// writeGenerateResponse writes the response for a generated or cached match
+func (h *Handler) writeGenerateResponse(c *gin.Context, match *models.Match, inline string, cached bool) {
 	response := models.GenerateResponse{
-		MatchID: match.ID,
-		Status:  match.Status,
-		LogURL:  fmt.Sprintf("/api/v1/matches/%s/log", match.ID),
+		MatchID:      match.ID,
+		Status:       match.Status,
+		LogURL:       fmt.Sprintf("/api/v1/matches/%s/log", match.ID),
+		ConfigDigest: match.Config.Digest(),
+		StartTime:    match.StartTime,
+		Cached:       cached,
+	}
+	
+	// Include the formatted log directly when requested
+	if inline != "" {
+		inlineLog, size, err := formatInlineLog(match, inline)
+		if err != nil {
+			log.Printf("Failed to format inline log for match %s: %v", match.ID, err)
+			c.JSON(http.StatusInternalServerError, GenerateResponseError("Failed to format log: "+err.Error()))
+			return
+		}
+		if size > h.maxInlineLogSize {
+			c.JSON(http.StatusRequestEntityTooLarge, GenerateResponseError(
+				fmt.Sprintf("Log too large to inline (%d bytes, limit %d)", size, h.maxInlineLogSize),
+				"fetch the log from "+response.LogURL,
+			))
+			return
+		}
+		response.Log = inlineLog
 	}
 	
 	c.JSON(http.StatusOK, response)
 }
 
+// requestDigest identifies a generate request by its configuration and its teams as
+// canonical JSON, so profiles, starting money and loss streaks count too. An empty
+// digest disables caching for the request.
+func requestDigest(config *models.MatchConfig, teams []models.Team) string {
+	data, err := json.Marshal(teams)
+	if err != nil {
+		return ""
+	}
+	hash := sha256.New()
+	hash.Write([]byte(config.DigestWithSeed()))
+	hash.Write(data)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// formatInlineLog formats a match log for inclusion in a response and returns its size in bytes
+func formatInlineLog(match *models.Match, format string) (interface{}, int, error) {
+	switch format {
+	case "json":
+		httpLog, err := formatter.NewHTTPFormatter(&match.Config).FormatAsHTTPLog(match)
+		if err != nil {
+			return nil, 0, err
+		}
+		data, err := json.Marshal(httpLog)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to marshal log: %w", err)
+		}
+		return json.RawMessage(data), len(data), nil
+	case "raw":
+		data, err := formatter.FormatRawEvents(match.Events)
+		if err != nil {
+			return nil, 0, err
+		}
+		return json.RawMessage(data), len(data), nil
+	default:
+		logText := formatter.NewLogFormatter(&match.Config).FormatMatchToString(match)
+		return logText, len(logText), nil
+	}
+}
+
 // GetConfigTemplates returns predefined configuration templates
 func (h *Handler) GetConfigTemplates(c *gin.Context) {
 	templates := map[string]models.MatchConfig{
@@ -164,16 +460,14 @@ func (h *Handler) GetConfigTemplates(c *gin.Context) {
 
 // GetAvailableMaps returns the list of available CS2 maps
 func (h *Handler) GetAvailableMaps(c *gin.Context) {
-	maps := []map[string]interface{}{
-		{"name": "de_mirage", "display_name": "Mirage", "type": "defusal"},
-		{"name": "de_dust2", "display_name": "Dust II", "type": "defusal"},
-		{"name": "de_inferno", "display_name": "Inferno", "type": "defusal"},
-		{"name": "de_cache", "display_name": "Cache", "type": "defusal"},
-		{"name": "de_overpass", "display_name": "Overpass", "type": "defusal"},
-		{"name": "de_train", "display_name": "Train", "type": "defusal"},
-		{"name": "de_nuke", "display_name": "Nuke", "type": "defusal"},
-		{"name": "de_vertigo", "display_name": "Vertigo", "type": "defusal"},
-		{"name": "de_ancient", "display_name": "Ancient", "type": "defusal"},
+	maps := []map[string]interface{}{}
+	for _, info := range models.GetMaps() {
+		maps = append(maps, map[string]interface{}{
+			"name":         info.Name,
+			"display_name": info.DisplayName,
+			"type":         info.Type,
+			"bomb_sites":   info.BombSites,
+		})
 	}
 	
 	c.JSON(http.StatusOK, gin.H{
@@ -181,14 +475,449 @@ func (h *Handler) GetAvailableMaps(c *gin.Context) {
 	})
 }
 
-// ParseDemo handles demo parsing requests (placeholder)
-func (h *Handler) ParseDemo(c *gin.Context) {
-	// TODO: Implement demo parsing
-	c.JSON(http.StatusNotImplemented, gin.H{
-		"error": "Demo parsing not yet implemented",
+// SimulateRound predicts the outcome of a round for the given economy and skill matchup
+func (h *Handler) SimulateRound(c *gin.Context) {
+	var req models.SimulateRoundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GenerateResponseError("Invalid request format: "+err.Error()))
+		return
+	}
+	
+	if req.CT.AverageMoney < 0 || req.T.AverageMoney < 0 {
+		c.JSON(http.StatusBadRequest, GenerateResponseError("Validation failed: average money must be non-negative"))
+		return
+	}
+	if req.CT.Skill < 0 || req.CT.Skill > 1 || req.T.Skill < 0 || req.T.Skill > 1 {
+		c.JSON(http.StatusBadRequest, GenerateResponseError("Validation failed: skill must be between 0.0 and 1.0"))
+		return
+	}
+	
+	if req.CT.Name != "" && strings.EqualFold(req.CT.Name, req.T.Name) {
+		c.JSON(http.StatusBadRequest, GenerateResponseError("Validation failed: team names must be different"))
+		return
+	}
+	
+	config := models.DefaultMatchConfig()
+	if req.Map != "" {
+		config.Map = req.Map
+	}
+	
+	match, state := buildRoundPredictionState(config, req)
+	simulator := generator.NewRoundSimulator(rand.New(rand.NewSource(config.Seed)), models.NewEconomyManager(), &config)
+	ctWinProb, details := simulator.PredictRoundOutcome(match, state)
+	
+	c.JSON(http.StatusOK, gin.H{
+		"ct_win_probability": ctWinProb,
+		"t_win_probability":  1.0 - ctWinProb,
+		"details":            details,
+	})
+}
+
+// SimulateEngagement plays out a single fight between attackers and defenders and
+// returns its hurt and kill events, for tuning the combat model
+func (h *Handler) SimulateEngagement(c *gin.Context) {
+	var req models.SimulateEngagementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GenerateResponseError("Invalid request format: "+err.Error()))
+		return
+	}
+	
+	names := make(map[string]bool)
+	groups := []struct {
+		players []models.Player
+		side    string
+	}{{req.Attackers, "TERRORIST"}, {req.Defenders, "CT"}}
+	sides := make([][]*models.Player, len(groups))
+	for i, group := range groups {
+		for j := range group.players {
+			player := &group.players[j]
+			name := strings.ToLower(strings.TrimSpace(player.Name))
+			if name == "" || names[name] {
+				c.JSON(http.StatusBadRequest, GenerateResponseError("Validation failed: players need unique names"))
+				return
+			}
+			names[name] = true
+			
+			if player.Side = models.NormalizeSide(player.Side); player.Side == "" {
+				player.Side = group.side
+			}
+			if player.Profile == (models.PlayerProfile{}) {
+				player.Profile = models.DefaultPlayerProfile()
+			}
+			sides[i] = append(sides[i], player)
+		}
+	}
+	
+	seed := req.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	config := models.DefaultMatchConfig()
+	events := generator.NewEventGenerator(rand.New(rand.NewSource(seed)), &config).SimulateEngagement(sides[0], sides[1], seed)
+	
+	kills := make(map[string]bool)
+	for _, event := range events {
+		if kill, ok := event.(*models.KillEvent); ok {
+			kills[kill.Victim.Name] = true
+		}
+	}
+	alive := func(players []*models.Player) int {
+		count := 0
+		for _, player := range players {
+			if !kills[player.Name] {
+				count++
+			}
+		}
+		return count
+	}
+	
+	c.JSON(http.StatusOK, gin.H{
+		"seed":            seed,
+		"events":          events,
+		"attackers_alive": alive(sides[0]),
+		"defenders_alive": alive(sides[1]),
+	})
+}
+
+// buildRoundPredictionState creates a minimal match and state from round prediction input
+func buildRoundPredictionState(config models.MatchConfig, req models.SimulateRoundRequest) (*models.Match, *models.MatchState) {
+	inputs := []models.RoundTeamInput{req.CT, req.T}
+	sides := []string{"CT", "TERRORIST"}
+	defaultNames := []string{"Counter-Terrorists", "Terrorists"}
+	
+	teams := make([]models.Team, len(inputs))
+	state := &models.MatchState{
+		CurrentRound:  req.Round,
+		Scores:        make(map[string]int),
+		TeamEconomies: make(map[string]*models.TeamEconomy),
+		PlayerStates:  make(map[string]*models.PlayerState),
+	}
+	
+	for i, input := range inputs {
+		name := strings.TrimSpace(input.Name)
+		if name == "" {
+			name = defaultNames[i]
+		}
+		
+		teams[i] = models.Team{Name: name, Side: sides[i]}
+		for j := 0; j < 5; j++ {
+			player := models.Player{
+				Name:    fmt.Sprintf("%s_%d", name, j+1),
+				Team:    name,
+				Side:    sides[i],
+				Profile: models.DefaultPlayerProfile(),
+			}
+			player.Profile.AimSkill = input.Skill
+			teams[i].Players = append(teams[i].Players, player)
+		}
+		
+		state.TeamEconomies[name] = &models.TeamEconomy{
+			TotalMoney:   input.AverageMoney * len(teams[i].Players),
+			AverageMoney: input.AverageMoney,
+		}
+	}
+	
+	return models.NewMatch(config, teams), state
+}
+
+// GetMatchLog returns a stored match's log. With start_tick and/or end_tick only
+// the events in that inclusive tick range are returned, found through the tick index.
+func (h *Handler) GetMatchLog(c *gin.Context) {
+	match, ok := h.store.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, GenerateResponseError("Match not found: "+c.Param("id")))
+		return
+	}
+	
+	logFormatter := formatter.NewLogFormatter(&match.Config)
+	startParam, endParam := c.Query("start_tick"), c.Query("end_tick")
+	if startParam == "" && endParam == "" {
+		c.String(http.StatusOK, logFormatter.FormatMatchToString(match)+match.Config.LineTerminator())
+		return
+	}
+	
+	startTick, endTick := int64(0), int64(math.MaxInt64)
+	var err error
+	if startParam != "" {
+		if startTick, err = strconv.ParseInt(startParam, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, GenerateResponseError("Invalid start_tick: "+startParam))
+			return
+		}
+	}
+	if endParam != "" {
+		if endTick, err = strconv.ParseInt(endParam, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, GenerateResponseError("Invalid end_tick: "+endParam))
+			return
+		}
+	}
+	if endTick < startTick {
+		c.JSON(http.StatusBadRequest, GenerateResponseError(fmt.Sprintf("end_tick %d is before start_tick %d", endTick, startTick)))
+		return
+	}
+	
+	index, ok := h.store.EventIndex(match.ID)
+	if !ok {
+		c.JSON(http.StatusNotFound, GenerateResponseError("Match not found: "+c.Param("id")))
+		return
+	}
+	
+	var body strings.Builder
+	for _, line := range logFormatter.FormatEventLines(index.EventsInTickRange(startTick, endTick)) {
+		body.WriteString(line)
+		body.WriteString(match.Config.LineTerminator())
+	}
+	c.String(http.StatusOK, body.String())
+}
+
+// GetMatchEconomy returns a stored match's per-round team economy as JSON or CSV
+func (h *Handler) GetMatchEconomy(c *gin.Context) {
+	match, ok := h.store.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, GenerateResponseError("Match not found: "+c.Param("id")))
+		return
+	}
+	
+	httpFormatter := formatter.NewHTTPFormatter(&match.Config)
+	series := httpFormatter.FormatEconomyTimeseries(match)
+	
+	switch format := c.DefaultQuery("format", "json"); format {
+	case "json":
+		c.JSON(http.StatusOK, gin.H{
+			"match_id": match.ID,
+			"economy":  series,
+		})
+	case "csv":
+		data, err := httpFormatter.FormatEconomyTimeseriesCSV(series)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, GenerateResponseError("Failed to format economy: "+err.Error()))
+			return
+		}
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+	default:
+		c.JSON(http.StatusBadRequest, GenerateResponseError("Invalid format: "+format, "supported formats: json, csv"))
+	}
+}
+
+// GetMatchSummary returns a short narrative of a stored match, as JSON or plain text
+func (h *Handler) GetMatchSummary(c *gin.Context) {
+	match, ok := h.store.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, GenerateResponseError("Match not found: "+c.Param("id")))
+		return
+	}
+	
+	summary := formatter.NewHTTPFormatter(&match.Config).GenerateNarrative(match)
+	
+	switch format := c.DefaultQuery("format", "json"); format {
+	case "json":
+		c.JSON(http.StatusOK, gin.H{
+			"match_id": match.ID,
+			"summary":  summary,
+		})
+	case "text":
+		c.String(http.StatusOK, summary+"\n")
+	default:
+		c.JSON(http.StatusBadRequest, GenerateResponseError("Invalid format: "+format, "supported formats: json, text"))
+	}
+}
+
+// GetMatchScoreboard returns the scoreboard of a stored match: both teams' scores
+// and every player's kills, deaths, assists, ADR, rating and MVPs
+func (h *Handler) GetMatchScoreboard(c *gin.Context) {
+	match, ok := h.store.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, GenerateResponseError("Match not found: "+c.Param("id")))
+		return
+	}
+	
+	c.JSON(http.StatusOK, formatter.NewHTTPFormatter(&match.Config).FormatScoreboard(match))
+}
+
+// GetMatchWeapons returns per-weapon kill counts by round and hitgroup for a stored match
+func (h *Handler) GetMatchWeapons(c *gin.Context) {
+	match, ok := h.store.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, GenerateResponseError("Match not found: "+c.Param("id")))
+		return
+	}
+	
+	c.JSON(http.StatusOK, gin.H{
+		"match_id": match.ID,
+		"weapons":  formatter.NewHTTPFormatter(&match.Config).FormatWeaponStats(match),
+	})
+}
+
+// GetMatchTimeline returns each round's tick span and the ticks of its kills, plants and defuses
+func (h *Handler) GetMatchTimeline(c *gin.Context) {
+	match, ok := h.store.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, GenerateResponseError("Match not found: "+c.Param("id")))
+		return
+	}
+	
+	c.JSON(http.StatusOK, gin.H{
+		"match_id": match.ID,
+		"rounds":   formatter.NewHTTPFormatter(&match.Config).FormatTimeline(match),
 	})
 }
 
+// RegenerateMatch replays a stored match with configuration overrides and stores the
+// result as a new match. The seed is reused, so output-only settings such as verbose
+// logging keep the round outcomes.
+func (h *Handler) RegenerateMatch(c *gin.Context) {
+	inline, ok := inlineFormat(c)
+	if !ok {
+		return
+	}
+	
+	original, ok := h.store.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, GenerateResponseError("Match not found: "+c.Param("id")))
+		return
+	}
+	if original.Request == nil {
+		c.JSON(http.StatusConflict, GenerateResponseError("Match cannot be regenerated: "+original.ID, "only matches generated from a request can be replayed"))
+		return
+	}
+	
+	var req models.RegenerateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GenerateResponseError("Invalid request format: "+err.Error()))
+		return
+	}
+	
+	// Overrides are decoded into a deep copy, so maps and slices of the stored match's
+	// config are not modified in place
+	config := *original.Config.Clone()
+	if len(req.Config) > 0 {
+		if err := json.Unmarshal(req.Config, &config); err != nil {
+			c.JSON(http.StatusBadRequest, GenerateResponseError("Invalid config overrides: "+err.Error()))
+			return
+		}
+	}
+	
+	match, err := h.generator.Regenerate(original, config)
+	if err != nil {
+		log.Printf("Match regeneration failed: %v", err)
+		status := http.StatusInternalServerError
+		if match == nil {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, GenerateResponseError("Match regeneration failed: "+err.Error()))
+		return
+	}
+	
+	h.store.Put(match)
+	h.writeGenerateResponse(c, match, inline, false)
+}
+
+// CompareMatches diffs the aggregate stats of two stored matches
+func (h *Handler) CompareMatches(c *gin.Context) {
+	var req models.CompareRequest
+	
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GenerateResponseError("Invalid request format: "+err.Error()))
+		return
+	}
+	
+	matches := make([]*models.Match, 0, len(req.MatchIDs))
+	for _, id := range req.MatchIDs {
+		match, ok := h.store.Get(id)
+		if !ok {
+			c.JSON(http.StatusNotFound, GenerateResponseError("Match not found: "+id))
+			return
+		}
+		matches = append(matches, match)
+	}
+	
+	c.JSON(http.StatusOK, formatter.NewHTTPFormatter(&matches[0].Config).CompareMatches(matches[0], matches[1]))
+}
+
+// FormatEvents re-renders raw events in the requested format without regenerating a match
+func (h *Handler) FormatEvents(c *gin.Context) {
+	var req models.FormatRequest
+	
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GenerateResponseError("Invalid request format: "+err.Error()))
+		return
+	}
+	
+	events, err := formatter.ParseRawEvents(req.Events)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, GenerateResponseError("Invalid raw events: "+err.Error()))
+		return
+	}
+	
+	config := models.DefaultMatchConfig()
+	switch req.Format {
+	case "", "standard":
+		lines := formatter.NewLogFormatter(&config).FormatEventLines(events)
+		c.String(http.StatusOK, strings.Join(lines, "\n"))
+	case "json":
+		data, err := formatter.NewHTTPFormatter(&config).FormatEventsAsJSON(events)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, GenerateResponseError("Failed to format events: "+err.Error()))
+			return
+		}
+		c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+	case "csv":
+		data, err := formatter.NewLogFormatter(&config).FormatEventsAsCSV(events)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, GenerateResponseError("Failed to format events: "+err.Error()))
+			return
+		}
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+	default:
+		c.JSON(http.StatusBadRequest, GenerateResponseError("Invalid format: "+req.Format, "supported formats: standard, json, csv"))
+	}
+}
+
+// ParseDemo parses an uploaded demo and re-emits it through the HTTP log formatter,
+// so parsed matches come out exactly like generated ones
+func (h *Handler) ParseDemo(c *gin.Context) {
+	if output := c.DefaultQuery("output", "http_log"); output != "http_log" {
+		c.JSON(http.StatusBadRequest, GenerateResponseError("Invalid output: "+output, "supported outputs: http_log"))
+		return
+	}
+	
+	upload, err := c.FormFile("demo")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, GenerateResponseError("Missing demo file: "+err.Error()))
+		return
+	}
+	
+	demoFile, err := os.CreateTemp("", "demo-*.dem")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, GenerateResponseError("Failed to store demo: "+err.Error()))
+		return
+	}
+	demoPath := demoFile.Name()
+	demoFile.Close()
+	defer os.Remove(demoPath)
+	
+	if err := c.SaveUploadedFile(upload, demoPath); err != nil {
+		c.JSON(http.StatusInternalServerError, GenerateResponseError("Failed to store demo: "+err.Error()))
+		return
+	}
+	
+	match, err := h.demoParser.ParseDemo(demoPath)
+	if errors.Is(err, parser.ErrDemoParsingUnavailable) {
+		c.JSON(http.StatusNotImplemented, GenerateResponseError(err.Error()))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, GenerateResponseError("Failed to parse demo: "+err.Error()))
+		return
+	}
+	
+	response, err := formatter.NewHTTPFormatter(&match.Config).FormatAsHTTPLog(match)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, GenerateResponseError("Failed to format demo: "+err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
 // GetSampleRequest returns a sample generate request for testing
 func (h *Handler) GetSampleRequest(c *gin.Context) {
 	sample := GetSampleGenerateRequest()
@@ -284,18 +1013,6 @@ func (h *Handler) getMockRoundWinner(round int, teams []models.Team) string {
 	return teams[0].Name
 }
 
-// getMaxRoundsForFormat returns the maximum rounds for a given format
-func getMaxRoundsForFormat(format string) int {
-	switch format {
-	case "mr12":
-		return 24
-	case "mr15":
-		return 30
-	default:
-		return 24
-	}
-}
-
 func (h *Handler) getMockRoundEndReason(round int) string {
 	reasons := []string{"elimination", "bomb_defused", "bomb_exploded", "time"}
 	return reasons[round%len(reasons)]
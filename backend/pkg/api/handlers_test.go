@@ -0,0 +1,1168 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/noueii/nocs-log-generator/backend/pkg/formatter"
+	"github.com/noueii/nocs-log-generator/backend/pkg/models"
+)
+
+// flushRecorder records the body written before every flush
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushes []string
+}
+
+func (r *flushRecorder) Flush() {
+	r.flushes = append(r.flushes, r.Body.String())
+	r.ResponseRecorder.Flush()
+}
+
+func newTestRouter(h *Handler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h.RegisterRoutes(router.Group("/api/v1"))
+	return router
+}
+
+func TestHandler_StreamMatch(t *testing.T) {
+	h := NewHandler()
+	router := newTestRouter(h)
+
+	req := GetSampleGenerateRequest()
+	req.Options.MaxRounds = 16
+	req.Options.Seed = 1407
+	startTime := time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC)
+	req.Options.StartTime = &startTime
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+
+	recorder := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/generate/stream", bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(recorder, httpReq)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	// Header, one flush per round and the footer
+	if len(recorder.flushes) < 6 {
+		t.Fatalf("Expected at least 6 flushes, got %d", len(recorder.flushes))
+	}
+	first := recorder.flushes[0]
+	if !strings.Contains(first, "Log file started") || strings.Contains(first, "Log file closed") {
+		t.Errorf("Expected the first flush to hold only the log header, got %q", first)
+	}
+	for i := 1; i < len(recorder.flushes); i++ {
+		if len(recorder.flushes[i]) <= len(recorder.flushes[i-1]) {
+			t.Errorf("Expected flush %d to add output", i)
+		}
+		if i < len(recorder.flushes)-1 && strings.Contains(recorder.flushes[i], "Log file closed") {
+			t.Errorf("Expected the footer only after the last round, found it in flush %d", i)
+		}
+	}
+
+	matchID := recorder.Header().Get("X-Match-ID")
+	if _, ok := h.store.Get(matchID); !ok {
+		t.Fatalf("Expected streamed match %q to be stored", matchID)
+	}
+	if !strings.HasSuffix(recorder.Body.String(), "Log file closed\n") {
+		t.Error("Expected the stream to end with the log footer")
+	}
+
+	// The stream matches the log of the same request generated in one go
+	match, err := h.generator.Generate(&req)
+	if err != nil {
+		t.Fatalf("Failed to generate match: %v", err)
+	}
+	expected := formatter.NewLogFormatter(&match.Config).FormatMatch(match)
+	streamed := strings.Split(strings.TrimSuffix(recorder.Body.String(), "\n"), "\n")
+	if len(streamed) != len(expected) {
+		t.Fatalf("Expected %d streamed lines, got %d", len(expected), len(streamed))
+	}
+	for i := range expected {
+		if streamed[i] != expected[i] {
+			t.Fatalf("Expected streamed line %d to be %q, got %q", i, expected[i], streamed[i])
+		}
+	}
+}
+
+func TestHandler_GenerateMatchCachesIdenticalRequests(t *testing.T) {
+	router := newTestRouter(NewHandler())
+
+	generate := func(req models.GenerateRequest) models.GenerateResponse {
+		t.Helper()
+		body, err := json.Marshal(req)
+		if err != nil {
+			t.Fatalf("Failed to marshal request: %v", err)
+		}
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/generate", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httpReq)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+		}
+		var response models.GenerateResponse
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return response
+	}
+
+	req := GetSampleGenerateRequest()
+	req.Options.MaxRounds = 4
+	req.Options.Seed = 1389
+	first := generate(req)
+	if repeat := generate(req); !repeat.Cached || repeat.MatchID != first.MatchID {
+		t.Errorf("Expected the identical request to return cached match %s, got %s (cached %v)", first.MatchID, repeat.MatchID, repeat.Cached)
+	}
+
+	// Any team or player setting that affects generation is part of the request's identity
+	changes := map[string]func(req *models.GenerateRequest){
+		"profile":            func(req *models.GenerateRequest) { req.Teams[0].Players[0].Profile.AimSkill = 0.95 },
+		"starting money":     func(req *models.GenerateRequest) { req.Teams[1].Players[2].Economy.Money = 4000 },
+		"consecutive losses": func(req *models.GenerateRequest) { req.Teams[0].Economy.ConsecutiveLosses = 2 },
+	}
+	for name, change := range changes {
+		changed := GetSampleGenerateRequest()
+		changed.Options = req.Options
+		change(&changed)
+		if response := generate(changed); response.Cached || response.MatchID == first.MatchID {
+			t.Errorf("%s: expected a newly generated match, got cached match %s", name, response.MatchID)
+		}
+	}
+}
+
+func TestHandler_GenerateMatchInlineLog(t *testing.T) {
+	h := NewHandler()
+	router := newTestRouter(h)
+
+	req := GetSampleGenerateRequest()
+	req.Options.MaxRounds = 4
+	req.Options.Seed = 1388
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+	generate := func(inline string) *httptest.ResponseRecorder {
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/generate?inline="+inline, bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httpReq)
+		return recorder
+	}
+
+	for _, inline := range []string{"standard", "json", "raw"} {
+		recorder := generate(inline)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d: %s", inline, recorder.Code, recorder.Body.String())
+		}
+		var response struct {
+			MatchID string          `json:"match_id"`
+			Log     json.RawMessage `json:"log"`
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			t.Fatalf("%s: failed to decode response: %v", inline, err)
+		}
+		match, ok := h.store.Get(response.MatchID)
+		if !ok {
+			t.Fatalf("%s: match %s was not stored", inline, response.MatchID)
+		}
+
+		var expected []byte
+		switch inline {
+		case "standard":
+			expected, err = json.Marshal(formatter.NewLogFormatter(&match.Config).FormatMatchToString(match))
+		case "json":
+			var httpLog interface{}
+			if httpLog, err = formatter.NewHTTPFormatter(&match.Config).FormatAsHTTPLog(match); err == nil {
+				expected, err = json.Marshal(httpLog)
+			}
+		case "raw":
+			expected, err = formatter.FormatRawEvents(match.Events)
+		}
+		if err != nil {
+			t.Fatalf("%s: formatting the expected log failed: %v", inline, err)
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, expected); err != nil {
+			t.Fatalf("%s: compacting the expected log failed: %v", inline, err)
+		}
+		if !bytes.Equal(response.Log, compact.Bytes()) {
+			t.Errorf("%s: inline log does not match the %s formatter output", inline, inline)
+		}
+	}
+
+	if recorder := generate("xml"); recorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown inline format, got %d", recorder.Code)
+	}
+
+	// Logs over the limit are left for the log endpoint
+	h.SetMaxInlineLogSize(1024)
+	recorder := generate("standard")
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("Expected status 413 over the inline limit, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if !strings.Contains(recorder.Body.String(), "/log") {
+		t.Errorf("Expected the 413 response to point at the log endpoint, got %s", recorder.Body.String())
+	}
+}
+
+func TestHandler_StreamMatchCRLF(t *testing.T) {
+	router := newTestRouter(NewHandler())
+
+	req := GetSampleGenerateRequest()
+	req.Options.MaxRounds = 4
+	req.Options.LineEnding = "crlf"
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+
+	httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/generate/stream", bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httpReq)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	streamed := recorder.Body.String()
+	if !strings.HasSuffix(streamed, "Log file closed\r\n") {
+		t.Error("Expected the stream to end with a CRLF terminated footer")
+	}
+	if bare := strings.Count(streamed, "\n") - strings.Count(streamed, "\r\n"); bare != 0 {
+		t.Errorf("Expected every streamed line to end with CRLF, found %d bare LF endings", bare)
+	}
+}
+
+func TestHandler_StreamMatchInvalidRequest(t *testing.T) {
+	router := newTestRouter(NewHandler())
+
+	httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/generate/stream", strings.NewReader(`{"teams":[]}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httpReq)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", recorder.Code)
+	}
+}
+
+func TestHandler_FormatEvents(t *testing.T) {
+	router := newTestRouter(NewHandler())
+
+	player := &models.Player{Name: "Formatter", UserID: 1, SteamID: "STEAM_1:0:1", Side: "CT"}
+	events := []models.GameEvent{
+		&models.ItemPurchaseEvent{BaseEvent: models.NewBaseEvent("item_purchase", 64, 1), Player: player, Item: "ak47", Cost: 2700},
+	}
+	raw, err := formatter.FormatRawEvents(events)
+	if err != nil {
+		t.Fatalf("FormatRawEvents failed: %v", err)
+	}
+
+	testCases := []struct {
+		name        string
+		format      string
+		events      string
+		status      int
+		contentType string
+		contains    string
+	}{
+		{name: "standard", format: "standard", events: string(raw), status: http.StatusOK, contentType: "text/plain", contains: events[0].ToLogLine()},
+		{name: "json", format: "json", events: string(raw), status: http.StatusOK, contentType: "application/json", contains: `"type":"item_purchase"`},
+		{name: "csv", format: "csv", events: string(raw), status: http.StatusOK, contentType: "text/csv", contains: "timestamp,tick,round,type,log_line"},
+		{name: "unknown format", format: "xml", events: string(raw), status: http.StatusBadRequest, contains: "Invalid format"},
+		{name: "unknown event type", format: "standard", events: `[{"__type":"NukeEvent"}]`, status: http.StatusBadRequest, contains: "unknown event type"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body := fmt.Sprintf(`{"format":%q,"events":%s}`, tc.format, tc.events)
+			httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/format", strings.NewReader(body))
+			httpReq.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httpReq)
+
+			if recorder.Code != tc.status {
+				t.Fatalf("Expected status %d, got %d: %s", tc.status, recorder.Code, recorder.Body.String())
+			}
+			if tc.contentType != "" && !strings.HasPrefix(recorder.Header().Get("Content-Type"), tc.contentType) {
+				t.Errorf("Expected content type %s, got %s", tc.contentType, recorder.Header().Get("Content-Type"))
+			}
+			if !strings.Contains(recorder.Body.String(), tc.contains) {
+				t.Errorf("Expected body to contain %q, got %s", tc.contains, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_GetMatchLog(t *testing.T) {
+	h := NewHandler()
+	router := newTestRouter(h)
+
+	req := GetSampleGenerateRequest()
+	req.Options.MaxRounds = 16
+	match, err := h.generator.Generate(&req)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	h.store.Put(match)
+	logFormatter := formatter.NewLogFormatter(&match.Config)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/matches/"+match.ID+"/log", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if recorder.Body.String() != logFormatter.FormatMatchToString(match)+"\n" {
+		t.Error("Expected the full match log without a tick range")
+	}
+
+	// The indexed range matches a linear scan of the events
+	startTick, endTick := int64(5000), int64(20000)
+	var expected []string
+	for _, event := range match.Events {
+		if event.GetTick() >= startTick && event.GetTick() <= endTick {
+			expected = append(expected, logFormatter.FormatEventLines([]models.GameEvent{event})...)
+		}
+	}
+	if len(expected) == 0 {
+		t.Fatal("Expected the tick range to contain events")
+	}
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/matches/%s/log?start_tick=%d&end_tick=%d", match.ID, startTick, endTick), nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if lines := strings.Split(strings.TrimSuffix(recorder.Body.String(), "\n"), "\n"); !reflect.DeepEqual(lines, expected) {
+		t.Errorf("Expected %d lines in the tick range, got %d", len(expected), len(lines))
+	}
+
+	testCases := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{"invalid start", "/api/v1/matches/" + match.ID + "/log?start_tick=abc", http.StatusBadRequest},
+		{"reversed range", "/api/v1/matches/" + match.ID + "/log?start_tick=100&end_tick=10", http.StatusBadRequest},
+		{"unknown match", "/api/v1/matches/missing/log?start_tick=0", http.StatusNotFound},
+	}
+	for _, tc := range testCases {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if recorder.Code != tc.status {
+			t.Errorf("%s: expected status %d, got %d", tc.name, tc.status, recorder.Code)
+		}
+	}
+}
+
+func TestHandler_GetMatchEconomy(t *testing.T) {
+	h := NewHandler()
+	router := newTestRouter(h)
+
+	req := GetSampleGenerateRequest()
+	req.Options.MaxRounds = 16
+	match, err := h.generator.Generate(&req)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	h.store.Put(match)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/matches/"+match.ID+"/economy", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	var response struct {
+		MatchID string                   `json:"match_id"`
+		Economy []formatter.RoundEconomy `json:"economy"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	rounds := make(map[int]int)
+	for _, entry := range response.Economy {
+		rounds[entry.Round]++
+		if entry.BuyType == "" {
+			t.Errorf("Round %d %s: expected a buy type", entry.Round, entry.Team)
+		}
+	}
+	if len(rounds) != len(match.Rounds) {
+		t.Errorf("Expected the series to cover %d rounds, got %d", len(match.Rounds), len(rounds))
+	}
+	for round, teams := range rounds {
+		if teams != len(match.Teams) {
+			t.Errorf("Round %d: expected an entry per team, got %d", round, teams)
+		}
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/matches/"+match.ID+"/economy?format=csv", nil))
+	lines := strings.Split(strings.TrimSpace(recorder.Body.String()), "\n")
+	if len(lines) != len(response.Economy)+1 {
+		t.Errorf("Expected %d CSV lines, got %d", len(response.Economy)+1, len(lines))
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/matches/missing/economy", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown match, got %d", recorder.Code)
+	}
+}
+
+func TestHandler_GetMatchSummary(t *testing.T) {
+	h := NewHandler()
+	router := newTestRouter(h)
+
+	req := GetSampleGenerateRequest()
+	req.Options.MaxRounds = 16
+	match, err := h.generator.Generate(&req)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	h.store.Put(match)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/matches/"+match.ID+"/summary?format=text", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain") {
+		t.Errorf("Expected a plain text summary, got %s", recorder.Header().Get("Content-Type"))
+	}
+	text := strings.TrimSpace(recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/matches/"+match.ID+"/summary", nil))
+	var response struct {
+		MatchID string `json:"match_id"`
+		Summary string `json:"summary"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.MatchID != match.ID || response.Summary != text {
+		t.Errorf("Expected the JSON summary to match the text one, got %+v", response)
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/matches/"+match.ID+"/summary?format=xml", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown format, got %d", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/matches/missing/summary", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown match, got %d", recorder.Code)
+	}
+}
+
+func TestHandler_GetMatchScoreboard(t *testing.T) {
+	h := NewHandler()
+	router := newTestRouter(h)
+
+	req := GetSampleGenerateRequest()
+	req.Options.MaxRounds = 16
+	match, err := h.generator.Generate(&req)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	h.store.Put(match)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/matches/"+match.ID+"/scoreboard", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	var response struct {
+		Map   string `json:"map"`
+		Teams []struct {
+			Name    string                   `json:"name"`
+			Score   *int                     `json:"score"`
+			Players []map[string]interface{} `json:"players"`
+		} `json:"teams"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Map != match.Map || len(response.Teams) != 2 {
+		t.Fatalf("Expected both teams on %s, got %+v", match.Map, response)
+	}
+
+	players := 0
+	for _, team := range response.Teams {
+		if team.Score == nil || *team.Score != match.Scores[team.Name] {
+			t.Errorf("%s: expected score %d, got %v", team.Name, match.Scores[team.Name], team.Score)
+		}
+		adr, assists := 0.0, 0.0
+		for _, player := range team.Players {
+			players++
+			for _, field := range []string{"name", "kills", "deaths", "assists", "adr", "rating", "mvps"} {
+				if player[field] == nil {
+					t.Errorf("%s: expected a %s field on %v", team.Name, field, player["name"])
+				}
+			}
+			playerADR, _ := player["adr"].(float64)
+			playerAssists, _ := player["assists"].(float64)
+			adr += playerADR
+			assists += playerAssists
+		}
+		// Live rounds deal damage and credit assists, so both show on a generated match
+		if average := adr / float64(len(team.Players)); average < 30 || average > 150 {
+			t.Errorf("%s: expected an average ADR between 30 and 150, got %.1f", team.Name, average)
+		}
+		if assists == 0 {
+			t.Errorf("%s: expected assists on the scoreboard", team.Name)
+		}
+	}
+	if players != 10 {
+		t.Errorf("Expected all ten players on the scoreboard, got %d", players)
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/matches/missing/scoreboard", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown match, got %d", recorder.Code)
+	}
+}
+
+func TestHandler_GetMatchWeapons(t *testing.T) {
+	h := NewHandler()
+	router := newTestRouter(h)
+
+	req := GetSampleGenerateRequest()
+	req.Options.MaxRounds = 16
+	match, err := h.generator.Generate(&req)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	h.store.Put(match)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/matches/"+match.ID+"/weapons", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	var response struct {
+		MatchID string                          `json:"match_id"`
+		Weapons map[string]formatter.WeaponStat `json:"weapons"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	headKills := make(map[string]int)
+	for _, event := range match.Events {
+		if kill, ok := event.(*models.KillEvent); ok && kill.Hitgroup == 1 {
+			headKills[kill.Weapon]++
+		}
+	}
+
+	for weapon, stat := range response.Weapons {
+		if stat.Headshots != headKills[weapon] || stat.HitgroupDistribution[1] != stat.Headshots {
+			t.Errorf("%s: expected %d headshots, got %d (head hitgroup kills %d)", weapon, headKills[weapon], stat.Headshots, stat.HitgroupDistribution[1])
+		}
+		byRound, byHitgroup := 0, 0
+		for _, kills := range stat.KillsByRound {
+			byRound += kills
+		}
+		for _, kills := range stat.HitgroupDistribution {
+			byHitgroup += kills
+		}
+		if byRound != stat.Kills || byHitgroup != stat.Kills {
+			t.Errorf("%s: expected round and hitgroup breakdowns to sum to %d kills, got %d and %d", weapon, stat.Kills, byRound, byHitgroup)
+		}
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/matches/missing/weapons", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown match, got %d", recorder.Code)
+	}
+}
+
+func TestHandler_GetMatchTimeline(t *testing.T) {
+	h := NewHandler()
+	router := newTestRouter(h)
+
+	req := GetSampleGenerateRequest()
+	req.Options.MaxRounds = 16
+	match, err := h.generator.Generate(&req)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	h.store.Put(match)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/matches/"+match.ID+"/timeline", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	var response struct {
+		MatchID string                    `json:"match_id"`
+		Rounds  []formatter.RoundTimeline `json:"rounds"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Rounds) != len(match.Rounds) {
+		t.Fatalf("Expected %d rounds, got %d", len(match.Rounds), len(response.Rounds))
+	}
+
+	kills := 0
+	for _, round := range response.Rounds {
+		if round.StartTick <= 0 || round.EndTick < round.StartTick {
+			t.Errorf("Round %d: invalid tick span %d-%d", round.Round, round.StartTick, round.EndTick)
+		}
+		for _, event := range round.Events {
+			if event.Type == "player_death" {
+				kills++
+			}
+			if event.Tick < round.StartTick || event.Tick > round.EndTick {
+				t.Errorf("Round %d: %s at tick %d outside %d-%d", round.Round, event.Type, event.Tick, round.StartTick, round.EndTick)
+			}
+		}
+	}
+	expected := 0
+	for _, event := range match.Events {
+		if _, ok := event.(*models.KillEvent); ok {
+			expected++
+		}
+	}
+	if kills != expected || kills == 0 {
+		t.Errorf("Expected all %d kills in the timeline, got %d", expected, kills)
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/matches/missing/timeline", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown match, got %d", recorder.Code)
+	}
+}
+
+// fixtureDemoParser stands in for a demo decoder, returning a prepared match for any demo
+type fixtureDemoParser struct {
+	match    *models.Match
+	received []byte
+}
+
+func (p *fixtureDemoParser) ParseDemo(demoPath string) (*models.Match, error) {
+	data, err := os.ReadFile(demoPath)
+	if err != nil {
+		return nil, err
+	}
+	p.received = data
+	return p.match, nil
+}
+
+// newDemoUpload builds a multipart /parse request carrying demo as the uploaded file
+func newDemoUpload(t *testing.T, target string, demo []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("demo", "fixture.dem")
+	if err != nil {
+		t.Fatalf("CreateFormFile failed: %v", err)
+	}
+	part.Write(demo)
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, target, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestHandler_ParseDemo(t *testing.T) {
+	demo := []byte("HL2DEMO\x00fixture")
+
+	// Without a demo decoder the endpoint reports that parsing is unavailable
+	recorder := httptest.NewRecorder()
+	newTestRouter(NewHandler()).ServeHTTP(recorder, newDemoUpload(t, "/api/v1/parse?output=http_log", demo))
+	if recorder.Code != http.StatusNotImplemented {
+		t.Fatalf("Expected status 501 without a demo decoder, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	h := NewHandler()
+	req := GetSampleGenerateRequest()
+	req.Options.MaxRounds = 16
+	match, err := h.generator.Generate(&req)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	demoParser := &fixtureDemoParser{match: match}
+	h.demoParser = demoParser
+	router := newTestRouter(h)
+
+	expectedKills := 0
+	for _, event := range match.Events {
+		if _, ok := event.(*models.KillEvent); ok {
+			expectedKills++
+		}
+	}
+	if expectedKills == 0 {
+		t.Fatal("Expected the fixture match to contain kills")
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, newDemoUpload(t, "/api/v1/parse?output=http_log", demo))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if !bytes.Equal(demoParser.received, demo) {
+		t.Error("Expected the parser to receive the uploaded demo")
+	}
+
+	var response formatter.HTTPLogResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	kills := 0
+	for _, entry := range response.Events {
+		if entry.Type == "player_death" {
+			kills++
+		}
+	}
+	if kills != expectedKills {
+		t.Errorf("Expected %d kill events, got %d", expectedKills, kills)
+	}
+	if response.Statistics == nil || response.Statistics.TotalKills != expectedKills {
+		t.Errorf("Expected statistics to count %d kills", expectedKills)
+	}
+
+	// Parsed matches come out exactly like generated ones
+	generated, err := formatter.NewHTTPFormatter(&match.Config).FormatAsHTTPLog(match)
+	if err != nil {
+		t.Fatalf("FormatAsHTTPLog failed: %v", err)
+	}
+	expected, _ := json.Marshal(generated)
+	if strings.TrimSpace(recorder.Body.String()) != string(expected) {
+		t.Error("Expected the parsed demo output to match the formatter output for the same match")
+	}
+
+	testCases := []struct {
+		name string
+		req  *http.Request
+	}{
+		{"unsupported output", newDemoUpload(t, "/api/v1/parse?output=csv", demo)},
+		{"missing demo", httptest.NewRequest(http.MethodPost, "/api/v1/parse", nil)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, tc.req)
+			if recorder.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d: %s", recorder.Code, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_CompareMatches(t *testing.T) {
+	h := NewHandler()
+	router := newTestRouter(h)
+
+	// Same seed and rosters, only the players' skill differs
+	generateWithSkill := func(skill float64) *models.Match {
+		req := GetSampleGenerateRequest()
+		req.Options.Seed = 7
+		for i := range req.Teams {
+			for j := range req.Teams[i].Players {
+				req.Teams[i].Players[j].Profile.AimSkill = skill
+			}
+		}
+		match, err := h.generator.Generate(&req)
+		if err != nil {
+			t.Fatalf("Generate failed: %v", err)
+		}
+		match.ID = fmt.Sprintf("skill_%.1f", skill)
+		h.store.Put(match)
+		return match
+	}
+	lowSkill := generateWithSkill(0.2)
+	highSkill := generateWithSkill(0.9)
+
+	compare := func(ids ...string) *httptest.ResponseRecorder {
+		body, err := json.Marshal(models.CompareRequest{MatchIDs: ids})
+		if err != nil {
+			t.Fatalf("Failed to marshal request: %v", err)
+		}
+		recorder := httptest.NewRecorder()
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/matches/compare", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, httpReq)
+		return recorder
+	}
+
+	recorder := compare(lowSkill.ID, highSkill.ID)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var comparison formatter.MatchComparison
+	if err := json.Unmarshal(recorder.Body.Bytes(), &comparison); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if !reflect.DeepEqual(comparison.MatchIDs, []string{lowSkill.ID, highSkill.ID}) {
+		t.Errorf("Expected match IDs in request order, got %v", comparison.MatchIDs)
+	}
+	low, high := comparison.Stats[0], comparison.Stats[1]
+	if comparison.Diff.TotalKills != high.TotalKills-low.TotalKills || comparison.Diff.TotalRounds != high.TotalRounds-low.TotalRounds {
+		t.Errorf("Unexpected kill/round diff %+v for stats %d/%d kills", comparison.Diff, low.TotalKills, high.TotalKills)
+	}
+	for eventType, count := range high.EventTypes {
+		if comparison.Diff.EventTypes[eventType] != count-low.EventTypes[eventType] {
+			t.Errorf("Event type %s: expected diff %d, got %d", eventType, count-low.EventTypes[eventType], comparison.Diff.EventTypes[eventType])
+		}
+	}
+	for weapon, stat := range low.WeaponStats {
+		if comparison.Diff.WeaponKills[weapon] != high.WeaponStats[weapon].Kills-stat.Kills {
+			t.Errorf("Weapon %s: expected kill diff %d, got %d", weapon, high.WeaponStats[weapon].Kills-stat.Kills, comparison.Diff.WeaponKills[weapon])
+		}
+	}
+	if comparison.Scores[1][highSkill.Teams[0].Name] != highSkill.Scores[highSkill.Teams[0].Name] {
+		t.Errorf("Expected the second score to be the high skill match's, got %v", comparison.Scores[1])
+	}
+
+	if recorder := compare(lowSkill.ID, "missing"); recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown match, got %d", recorder.Code)
+	}
+	if recorder := compare(lowSkill.ID); recorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a single match ID, got %d", recorder.Code)
+	}
+}
+
+func TestHandler_RegenerateMatch(t *testing.T) {
+	h := NewHandler()
+	router := newTestRouter(h)
+
+	req := GetSampleGenerateRequest()
+	req.Options.MaxRounds = 16
+	original, err := h.generator.Generate(&req)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	h.store.Put(original)
+
+	regenerate := func(id, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/matches/"+id+"/regenerate", strings.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, httpReq)
+		return recorder
+	}
+
+	// Verbose output settings add log lines without changing what happens in a round
+	recorder := regenerate(original.ID, `{"config": {"verbose_logging": true, "server_cvars": true, "first_blood": true}}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var response models.GenerateResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	verbose, ok := h.store.Get(response.MatchID)
+	if !ok || response.MatchID == original.ID {
+		t.Fatalf("Expected the regenerated match to be stored under a new ID, got %q", response.MatchID)
+	}
+
+	if !verbose.Config.VerboseLogging || verbose.Config.Seed != original.Config.Seed {
+		t.Errorf("Expected verbose logging with seed %d, got verbose=%v seed %d", original.Config.Seed, verbose.Config.VerboseLogging, verbose.Config.Seed)
+	}
+	if len(verbose.Events) <= len(original.Events) {
+		t.Errorf("Expected verbose output to add events, got %d vs %d", len(verbose.Events), len(original.Events))
+	}
+	if !reflect.DeepEqual(verbose.Scores, original.Scores) {
+		t.Errorf("Expected the scoreline %v to be kept, got %v", original.Scores, verbose.Scores)
+	}
+	for i := range original.Rounds {
+		if verbose.Rounds[i].Winner != original.Rounds[i].Winner || verbose.Rounds[i].Reason != original.Rounds[i].Reason {
+			t.Errorf("Round %d: expected %s by %s, got %s by %s", i+1, original.Rounds[i].Winner, original.Rounds[i].Reason, verbose.Rounds[i].Winner, verbose.Rounds[i].Reason)
+		}
+	}
+
+	// Overrides of maps and slices leave the stored match's config untouched
+	req.Options.ExtraCvars = map[string]string{"sv_cheats": "0"}
+	req.Options.LossBonusLadder = []int{1400, 1900, 2400, 2900, 3400}
+	withCvars, err := h.generator.Generate(&req)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	h.store.Put(withCvars)
+	recorder = regenerate(withCvars.ID, `{"config": {"extra_cvars": {"sv_cheats": "1", "mp_autokick": "0"}, "loss_bonus_ladder": [1000, 1500]}}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if cvars := map[string]string{"sv_cheats": "0"}; !reflect.DeepEqual(withCvars.Config.ExtraCvars, cvars) {
+		t.Errorf("Expected the stored extra cvars to stay %v, got %v", cvars, withCvars.Config.ExtraCvars)
+	}
+	if ladder := []int{1400, 1900, 2400, 2900, 3400}; !reflect.DeepEqual(withCvars.Config.LossBonusLadder, ladder) {
+		t.Errorf("Expected the stored loss bonus ladder to stay %v, got %v", ladder, withCvars.Config.LossBonusLadder)
+	}
+
+	if recorder := regenerate("missing", `{}`); recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown match, got %d", recorder.Code)
+	}
+	if recorder := regenerate(original.ID, `{"config": {"format": "mr9"}}`); recorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid config override, got %d", recorder.Code)
+	}
+}
+
+func TestHandler_GenerateMatchFixedStartTime(t *testing.T) {
+	h := NewHandler()
+	router := newTestRouter(h)
+
+	// The start time has an offset, the log is written in UTC
+	startTime := time.Date(2025, 3, 14, 23, 30, 5, 0, time.FixedZone("EST", -5*3600))
+	logHeader := func(id string) string {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/matches/"+id+"/log", nil))
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+		}
+		header, _, _ := strings.Cut(recorder.Body.String(), `\n`)
+		return header
+	}
+
+	expectedHeader := `L 03/15/2025 - 04:30:05: Log file started (file "logs/L031525.log")`
+	var matches []*models.Match
+	for _, seed := range []int64{11, 12} {
+		req := GetSampleGenerateRequest()
+		req.Options.MaxRounds = 16
+		req.Options.Seed = seed
+		req.Options.StartTime = &startTime
+		match, err := h.generator.Generate(&req)
+		if err != nil {
+			t.Fatalf("Generate failed: %v", err)
+		}
+		h.store.Put(match)
+		matches = append(matches, match)
+
+		if header := logHeader(match.ID); !strings.HasPrefix(header, expectedHeader) {
+			t.Errorf("Seed %d: expected the header to start with %s, got %s", seed, expectedHeader, header)
+		}
+	}
+
+	// A regenerated match keeps the start time and reports it
+	recorder := httptest.NewRecorder()
+	httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/matches/"+matches[0].ID+"/regenerate", strings.NewReader(`{"config": {"first_blood": true}}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(recorder, httpReq)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var response models.GenerateResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !response.StartTime.Equal(startTime) {
+		t.Errorf("Expected the resolved start time %v in the response, got %v", startTime, response.StartTime)
+	}
+	if header := logHeader(response.MatchID); !strings.HasPrefix(header, expectedHeader) {
+		t.Errorf("Expected the regenerated header to start with %s, got %s", expectedHeader, header)
+	}
+
+	// Without a start time the generation time is used and kept with the request
+	req := GetSampleGenerateRequest()
+	req.Options.MaxRounds = 16
+	before := time.Now()
+	match, err := h.generator.Generate(&req)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if match.StartTime.Before(before) || match.Request.Options.StartTime == nil || !match.Request.Options.StartTime.Equal(match.StartTime) {
+		t.Errorf("Expected a resolved start time kept with the request, got %v", match.StartTime)
+	}
+}
+
+func TestHandler_GetGenerateSchema(t *testing.T) {
+	router := newTestRouter(NewHandler())
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/schema/generate", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	var schema struct {
+		Required   []string `json:"required"`
+		Properties struct {
+			Format struct {
+				Enum []string `json:"enum"`
+			} `json:"format"`
+			Teams struct {
+				MinItems int `json:"minItems"`
+			} `json:"teams"`
+			Options struct {
+				Properties struct {
+					TickRate struct {
+						Minimum int `json:"minimum"`
+						Maximum int `json:"maximum"`
+					} `json:"tick_rate"`
+				} `json:"properties"`
+			} `json:"options"`
+		} `json:"properties"`
+		Definitions struct {
+			MatchConfig struct {
+				Properties map[string]interface{} `json:"properties"`
+			} `json:"MatchConfig"`
+		} `json:"definitions"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &schema); err != nil {
+		t.Fatalf("Failed to decode schema: %v", err)
+	}
+
+	if !reflect.DeepEqual(schema.Properties.Format.Enum, []string{"mr12", "mr15"}) {
+		t.Errorf("Expected format enum [mr12 mr15], got %v", schema.Properties.Format.Enum)
+	}
+	if !reflect.DeepEqual(schema.Required, []string{"teams", "map", "format"}) {
+		t.Errorf("Expected teams, map and format to be required, got %v", schema.Required)
+	}
+	if schema.Properties.Teams.MinItems != 2 {
+		t.Errorf("Expected exactly 2 teams, got minItems %d", schema.Properties.Teams.MinItems)
+	}
+	if tickRate := schema.Properties.Options.Properties.TickRate; tickRate.Minimum != 64 || tickRate.Maximum != 128 {
+		t.Errorf("Expected tick rate between 64 and 128, got %d-%d", tickRate.Minimum, tickRate.Maximum)
+	}
+	if _, ok := schema.Definitions.MatchConfig.Properties["fast_mode"]; !ok {
+		t.Error("Expected the match config definition to list fast_mode")
+	}
+}
+
+func TestHandler_SimulateEngagement(t *testing.T) {
+	router := newTestRouter(NewHandler())
+
+	players := func(prefix string, n int) []models.Player {
+		result := make([]models.Player, n)
+		for i := range result {
+			result[i] = models.Player{Name: fmt.Sprintf("%s%d", prefix, i+1)}
+		}
+		return result
+	}
+
+	tests := []struct {
+		name       string
+		req        models.SimulateEngagementRequest
+		wantStatus int
+	}{
+		{"5v0", models.SimulateEngagementRequest{Attackers: players("T", 5), Seed: 7}, http.StatusOK},
+		{"5v5", models.SimulateEngagementRequest{Attackers: players("T", 5), Defenders: players("CT", 5), Seed: 7}, http.StatusOK},
+		{"no attackers", models.SimulateEngagementRequest{Defenders: players("CT", 5)}, http.StatusBadRequest},
+		{"duplicate names", models.SimulateEngagementRequest{Attackers: players("P", 2), Defenders: players("P", 1)}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := json.Marshal(tt.req)
+			if err != nil {
+				t.Fatalf("Failed to marshal request: %v", err)
+			}
+
+			recorder := httptest.NewRecorder()
+			httpReq := httptest.NewRequest(http.MethodPost, "/api/v1/simulate/engagement", bytes.NewReader(body))
+			httpReq.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(recorder, httpReq)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, recorder.Code, recorder.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				Seed           int64                    `json:"seed"`
+				Events         []map[string]interface{} `json:"events"`
+				AttackersAlive int                      `json:"attackers_alive"`
+				DefendersAlive int                      `json:"defenders_alive"`
+			}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+
+			if resp.Seed != tt.req.Seed {
+				t.Errorf("Expected seed %d, got %d", tt.req.Seed, resp.Seed)
+			}
+			if resp.DefendersAlive != 0 && resp.AttackersAlive != 0 {
+				t.Errorf("Expected one side to be eliminated, got %d attackers and %d defenders alive", resp.AttackersAlive, resp.DefendersAlive)
+			}
+
+			kills := 0
+			for _, event := range resp.Events {
+				if event["type"] == "player_death" {
+					kills++
+				}
+			}
+			if lost := len(tt.req.Attackers) + len(tt.req.Defenders) - resp.AttackersAlive - resp.DefendersAlive; kills != lost {
+				t.Errorf("Expected %d kills for the players lost, got %d", lost, kills)
+			}
+		})
+	}
+}
+
+func TestHandler_GenerateSeries(t *testing.T) {
+	h := NewHandler()
+	router := newTestRouter(h)
+
+	sample := GetSampleGenerateRequest()
+	req := models.SeriesRequest{
+		Teams:   sample.Teams,
+		Maps:    []string{"de_mirage", "de_inferno", "de_nuke"},
+		BestOf:  3,
+		Format:  "mr12",
+		Options: models.MatchOptions{Seed: 3, MaxRounds: 6},
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/generate/series", bytes.NewReader(body)))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	var result models.SeriesResult
+	if err := json.Unmarshal(recorder.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(result.Maps) < 2 || len(result.Maps) > 3 {
+		t.Fatalf("Expected 2 or 3 maps played, got %d", len(result.Maps))
+	}
+	for _, played := range result.Maps {
+		if _, ok := h.store.Get(played.MatchID); !ok {
+			t.Errorf("Expected the match of %s to be stored", played.Map)
+		}
+	}
+
+	req.Maps = req.Maps[:2]
+	body, _ = json.Marshal(req)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/generate/series", bytes.NewReader(body)))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for too few maps, got %d", recorder.Code)
+	}
+}
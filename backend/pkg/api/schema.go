@@ -0,0 +1,162 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/noueii/nocs-log-generator/backend/pkg/models"
+)
+
+// jsonSchemaDraft is the JSON Schema version the generated schemas declare
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// fieldConstraints holds the enums and ranges enforced by request validation that
+// struct tags can't express, keyed by JSON field name
+func fieldConstraints() map[string]map[string]interface{} {
+	maps := []string{}
+	for _, info := range models.GetMaps() {
+		maps = append(maps, info.Name)
+	}
+
+	return map[string]map[string]interface{}{
+		"map":                  {"enum": maps},
+		"tick_rate":            {"minimum": 64, "maximum": 128},
+		"max_rounds":           {"minimum": 0, "maximum": 60, "multipleOf": 2},
+		"force_buy_type":       {"enum": models.BuyTypes},
+		"line_ending":          {"enum": models.LineEndings},
+		"name_policy":          {"enum": models.NamePolicies},
+		"max_name_length":      {"minimum": 0, "maximum": models.MaxPlayerNameLength},
+		"anti_eco_threshold":   {"minimum": 0, "maximum": models.MaxAntiEcoThreshold},
+		"action":               {"enum": models.VetoActions},
+		"damage_pacing":        {"enum": models.DamagePacings},
+		"position_sample_rate": {"minimum": 0, "maximum": models.MaxPositionSampleRate},
+		"min_round_duration":   {"minimum": 0, "maximum": models.MaxMinRoundDuration},
+		"round_time":           {"minimum": 0, "maximum": models.MaxRoundTime},
+		"freeze_time":          {"minimum": 0, "maximum": models.MaxFreezeTime},
+		"buy_time":             {"minimum": 0, "maximum": models.MaxBuyTime},
+		"bomb_timer":           {"minimum": 0, "maximum": models.MaxBombTimer},
+		"defuse_time":          {"minimum": 0, "maximum": models.MaxDefuseTime},
+		"defuse_time_no_kit":   {"minimum": 0, "maximum": models.MaxDefuseTime},
+		"status_interval":      {"minimum": 0},
+		"knife_kill_reward":    {"minimum": 0},
+		"grenade_kill_reward":  {"minimum": 0},
+		"rollback_probability": {"minimum": 0, "maximum": 1},
+		"skill_variance":       {"minimum": 0, "maximum": 1},
+	}
+}
+
+// GetGenerateSchema returns a JSON Schema of the generate request, with the match
+// config used for regenerate overrides under definitions
+func (h *Handler) GetGenerateSchema(c *gin.Context) {
+	constraints := fieldConstraints()
+
+	schema := typeSchema(reflect.TypeOf(models.GenerateRequest{}), constraints, map[reflect.Type]bool{})
+	schema["$schema"] = jsonSchemaDraft
+	schema["title"] = "GenerateRequest"
+	schema["definitions"] = map[string]interface{}{
+		"MatchConfig": typeSchema(reflect.TypeOf(models.MatchConfig{}), constraints, map[reflect.Type]bool{}),
+	}
+
+	c.JSON(http.StatusOK, schema)
+}
+
+// typeSchema builds the JSON Schema of a Go type from its JSON and binding tags.
+// Types already being described higher up are left open to stop recursion.
+func typeSchema(t reflect.Type, constraints map[string]map[string]interface{}, visiting map[reflect.Type]bool) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case reflect.TypeOf(time.Duration(0)):
+		return map[string]interface{}{"type": "integer", "description": "nanoseconds"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), constraints, visiting)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem(), constraints, visiting)}
+	case reflect.Struct:
+		if visiting[t] {
+			return map[string]interface{}{"type": "object"}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		properties := map[string]interface{}{}
+		required := []string{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+			// Embedded structs without a JSON name are flattened into the parent, as encoding/json does
+			if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+				embedded := typeSchema(field.Type, constraints, visiting)
+				for key, value := range embedded["properties"].(map[string]interface{}) {
+					properties[key] = value
+				}
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+
+			property := typeSchema(field.Type, constraints, visiting)
+			if applyBindingTag(property, field.Tag.Get("binding")) {
+				required = append(required, name)
+			}
+			for key, value := range constraints[name] {
+				property[key] = value
+			}
+			properties[name] = property
+		}
+
+		schema := map[string]interface{}{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	}
+
+	// Interfaces such as game events accept any value
+	return map[string]interface{}{}
+}
+
+// applyBindingTag adds the rules of a gin binding tag to a property schema and
+// reports whether the field is required
+func applyBindingTag(property map[string]interface{}, binding string) bool {
+	required := false
+	for _, rule := range strings.Split(binding, ",") {
+		name, value, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "oneof":
+			property["enum"] = strings.Fields(value)
+		case "len":
+			if n, err := strconv.Atoi(value); err == nil && property["type"] == "array" {
+				property["minItems"] = n
+				property["maxItems"] = n
+			}
+		}
+	}
+	return required
+}
@@ -77,10 +77,8 @@ func ValidateGenerateRequest(req *models.GenerateRequest) error {
 		return errors.New("tick rate must be between 64 and 128")
 	}
 
-	if req.Options.MaxRounds != 0 {
-		if req.Options.MaxRounds < 16 || req.Options.MaxRounds > 60 {
-			return errors.New("max rounds must be between 16 and 60")
-		}
+	if req.Options.MaxRounds > 60 {
+		return errors.New("max rounds must be at most 60")
 	}
 
 	return nil
@@ -103,18 +101,7 @@ func isValidSteamIDFormat(steamID string) bool {
 
 // isValidMapName checks if the map name is in our supported list
 func isValidMapName(mapName string) bool {
-	validMaps := []string{
-		"de_mirage", "de_dust2", "de_inferno", "de_cache", "de_overpass",
-		"de_train", "de_nuke", "de_vertigo", "de_ancient", "de_anubis",
-	}
-	
-	mapName = strings.ToLower(mapName)
-	for _, validMap := range validMaps {
-		if mapName == validMap {
-			return true
-		}
-	}
-	return false
+	return models.IsKnownMap(mapName)
 }
 
 // SanitizeTeamData ensures team data is properly formatted
@@ -122,13 +109,9 @@ func SanitizeTeamData(teams []models.Team) []models.Team {
 	for i := range teams {
 		// Trim and capitalize team names
 		teams[i].Name = strings.TrimSpace(teams[i].Name)
-		
-		// Set default sides
-		if i == 0 {
-			teams[i].Side = "CT"
-		} else {
-			teams[i].Side = "TERRORIST" 
-		}
+
+		// Blank sides stay blank so the generator can assign them from the seed
+		teams[i].Side = models.NormalizeSide(teams[i].Side)
 
 		// Initialize team scores
 		teams[i].Score = 0
@@ -152,8 +135,7 @@ func SanitizeTeamData(teams []models.Team) []models.Team {
 			teams[i].Players[j].State.HasHelmet = false
 			teams[i].Players[j].State.Grenades = make([]models.Grenade, 0)
 
-			// Initialize player economy
-			teams[i].Players[j].Economy.Money = 800 // Starting money
+			// Initialize player economy; requested money is kept to seed the starting economy
 			teams[i].Players[j].Economy.Purchases = make([]models.Purchase, 0)
 
 			// Initialize player stats
@@ -0,0 +1,68 @@
+package formatter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/noueii/nocs-log-generator/backend/pkg/models"
+)
+
+// binaryEvents is the gob payload of an encoded event batch
+type binaryEvents struct {
+	Events []models.GameEvent
+}
+
+func init() {
+	// Register every event under its raw __type discriminator so gob tags each
+	// interface value with the same name the raw JSON format uses
+	for name, newEvent := range rawEventTypes {
+		gob.RegisterName(name, newEvent())
+	}
+}
+
+// EncodeEvents encodes events in a compact gzip-compressed gob encoding for
+// archiving; it is several times smaller than the raw JSON encoding
+func EncodeEvents(events []models.GameEvent) ([]byte, error) {
+	for i, event := range events {
+		if event == nil {
+			return nil, fmt.Errorf("event %d: event is nil", i)
+		}
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := gob.NewEncoder(zw).Encode(binaryEvents{Events: events}); err != nil {
+		return nil, fmt.Errorf("error encoding events: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("error compressing events: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeEvents decodes events produced by EncodeEvents. Like ParseRawEvents, every
+// event needs the players its log line refers to.
+func DecodeEvents(data []byte) ([]models.GameEvent, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("encoded events are not compressed: %w", err)
+	}
+	defer zr.Close()
+
+	var decoded binaryEvents
+	if err := gob.NewDecoder(zr).Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("error decoding events: %w", err)
+	}
+
+	for i, event := range decoded.Events {
+		if event == nil {
+			return nil, fmt.Errorf("event %d: event is nil", i)
+		}
+		if err := validateRawEventPlayers(event); err != nil {
+			return nil, fmt.Errorf("event %d (%s): %w", i, event.GetType(), err)
+		}
+	}
+	return decoded.Events, nil
+}
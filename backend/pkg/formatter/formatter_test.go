@@ -1,9 +1,15 @@
 package formatter
 
 import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
+	"github.com/noueii/nocs-log-generator/backend/pkg/generator"
 	"github.com/noueii/nocs-log-generator/backend/pkg/models"
 )
 
@@ -122,6 +128,119 @@ func TestLogFormatter_SanitizePlayerName(t *testing.T) {
 	}
 }
 
+func TestLogFormatter_SanitizePlayerNameUTF8(t *testing.T) {
+	formatter := NewLogFormatter(&models.MatchConfig{Map: "de_mirage"})
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"emoji name", strings.Repeat("🔥", 40), strings.Repeat("🔥", 31)},
+		{"accents kept", "Kévin", "Kévin"},
+		{"invalid UTF-8", "bad\xffname", "bad_name"},
+		{"escape at the limit", strings.Repeat("a", 30) + `"b`, strings.Repeat("a", 30)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := formatter.sanitizePlayerName(tc.input)
+			if !utf8.ValidString(result) {
+				t.Fatalf("Expected valid UTF-8, got %q", result)
+			}
+			if runes := utf8.RuneCountInString(result); runes > models.DefaultMaxNameLength {
+				t.Errorf("Expected at most %d runes, got %d", models.DefaultMaxNameLength, runes)
+			}
+			if result != tc.expected {
+				t.Errorf("sanitizePlayerName(%q) = %q, expected %q", tc.input, result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestLogFormatter_MaxNameLength(t *testing.T) {
+	formatter := NewLogFormatter(&models.MatchConfig{Map: "de_mirage", MaxNameLength: 15})
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"VeryLongPlayerNameThatExceedsTheLimit", "VeryLongPlayerN"},
+		{"ExactlyFifteen_", "ExactlyFifteen_"},
+		{"Short", "Short"},
+		{strings.Repeat("a", 14) + `"b`, strings.Repeat("a", 14)},
+	}
+
+	for _, tc := range testCases {
+		if result := formatter.sanitizePlayerName(tc.input); result != tc.expected {
+			t.Errorf("sanitizePlayerName(%q) = %q, expected %q", tc.input, result, tc.expected)
+		}
+	}
+}
+
+func TestLogFormatter_FormatLogHeaderTournament(t *testing.T) {
+	config := &models.MatchConfig{
+		Map:            "de_mirage",
+		ServerName:     "Test Server",
+		TournamentName: "Fake Major 2025",
+	}
+	
+	formatter := NewLogFormatter(config)
+	match := &models.Match{
+		Title:     `Grand "Final"`,
+		StartTime: time.Date(2025, 3, 14, 18, 30, 5, 0, time.UTC),
+		Config:    *config,
+	}
+	
+	header := formatter.FormatLogHeader(match)
+	
+	expected := []string{
+		`server_cvar: "sv_tournament_name" "Fake Major 2025"`,
+		`server_cvar: "mp_teammatchstat_txt" "Grand 'Final'"`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(header, line) {
+			t.Errorf("Expected header to contain %s, got:\n%s", line, header)
+		}
+	}
+}
+
+func TestLogFormatter_FormatLogHeaderExtraCvars(t *testing.T) {
+	config := &models.MatchConfig{
+		Map:        "de_mirage",
+		ServerName: "Test Server",
+		ExtraCvars: map[string]string{
+			"sv_cheats":          "1",
+			"mp_overtime_enable": "1",
+			"sv_password":        `say "hi"`,
+			"bot_quota":          "0",
+		},
+	}
+	match := &models.Match{StartTime: time.Date(2025, 3, 14, 18, 30, 5, 0, time.UTC), Config: *config}
+
+	expected := []string{
+		`server_cvar: "bot_quota" "0"`,
+		`server_cvar: "mp_overtime_enable" "1"`,
+		`server_cvar: "sv_cheats" "1"`,
+		`server_cvar: "sv_password" "say 'hi'"`,
+	}
+	// Map iteration order varies, so render a few times to check the order is stable
+	for i := 0; i < 5; i++ {
+		header := NewLogFormatter(config).FormatLogHeader(match)
+		last := -1
+		for _, line := range expected {
+			index := strings.Index(header, line)
+			if index < 0 {
+				t.Fatalf("Expected header to contain %s, got:\n%s", line, header)
+			}
+			if index < last {
+				t.Fatalf("Expected %s after the previous cvar, got:\n%s", line, header)
+			}
+			last = index
+		}
+	}
+}
+
 func TestHTTPFormatter_FormatEventAsJSON(t *testing.T) {
 	config := &models.MatchConfig{
 		Map:        "de_mirage",
@@ -262,6 +381,98 @@ func TestStreamFormatter_BroadcastEvent(t *testing.T) {
 	}
 }
 
+func TestFormatRawEvents_RoundTrip(t *testing.T) {
+	player := &models.Player{Name: "RawPlayer", UserID: 3, SteamID: "STEAM_1:0:333333", Side: "CT"}
+	victim := &models.Player{Name: "RawVictim", UserID: 4, SteamID: "STEAM_1:1:444444", Side: "TERRORIST"}
+	base := func(eventType string) models.BaseEvent {
+		return models.BaseEvent{Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Type: eventType, Tick: 640, Round: 2}
+	}
+
+	events := []models.GameEvent{
+		&models.RoundStartEvent{BaseEvent: base("round_start"), CTScore: 1, TScore: 0, CTPlayers: 5, TPlayers: 5},
+		&models.ItemPurchaseEvent{BaseEvent: base("item_purchase"), Player: player, Item: "ak47", Cost: 2700},
+		&models.KillEvent{BaseEvent: base("player_death"), Attacker: player, Victim: victim, Weapon: "ak47", Headshot: true},
+		&models.BombExplodeEvent{BaseEvent: base("bomb_explode"), Site: "A"},
+	}
+
+	data, err := FormatRawEvents(events)
+	if err != nil {
+		t.Fatalf("FormatRawEvents failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"__type":"KillEvent"`) {
+		t.Errorf("Expected raw events to carry a __type discriminator, got %s", data)
+	}
+
+	parsed, err := ParseRawEvents(data)
+	if err != nil {
+		t.Fatalf("ParseRawEvents failed: %v", err)
+	}
+	if len(parsed) != len(events) {
+		t.Fatalf("Expected %d events, got %d", len(events), len(parsed))
+	}
+	for i := range events {
+		if parsed[i].ToLogLine() != events[i].ToLogLine() {
+			t.Errorf("Event %d: expected %q, got %q", i, events[i].ToLogLine(), parsed[i].ToLogLine())
+		}
+		if parsed[i].GetRound() != 2 || parsed[i].GetTick() != 640 {
+			t.Errorf("Event %d: expected round 2 tick 640, got round %d tick %d", i, parsed[i].GetRound(), parsed[i].GetTick())
+		}
+	}
+}
+
+func TestParseRawEvents_Errors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "not an array", input: `{"__type":"KillEvent"}`, want: "JSON array"},
+		{name: "missing discriminator", input: `[{"type":"player_death"}]`, want: "missing __type"},
+		{name: "unknown discriminator", input: `[{"__type":"NukeEvent"}]`, want: "unknown event type"},
+		{name: "missing player", input: `[{"__type":"KillEvent","victim":{"name":"a"}}]`, want: "attacker"},
+		{name: "invalid field", input: `[{"__type":"KillEvent","tick":"soon"}]`, want: "event 0"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseRawEvents([]byte(tc.input))
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestLogFormatter_FormatEventsAsCSV(t *testing.T) {
+	config := models.DefaultMatchConfig()
+	events := []models.GameEvent{
+		&models.ChatEvent{
+			BaseEvent: models.BaseEvent{Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Type: "chat", Tick: 128, Round: 1},
+			Player:    &models.Player{Name: "Chatter", UserID: 1, SteamID: "STEAM_1:0:1", Side: "CT"},
+			Message:   "gg, wp",
+		},
+	}
+
+	data, err := NewLogFormatter(&config).FormatEventsAsCSV(events)
+	if err != nil {
+		t.Fatalf("FormatEventsAsCSV failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected a header and one row, got %d lines", len(lines))
+	}
+	if lines[0] != "timestamp,tick,round,type,log_line" {
+		t.Errorf("Unexpected CSV header %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "2024-01-02T03:04:05Z,128,1,chat,") {
+		t.Errorf("Unexpected CSV row %q", lines[1])
+	}
+}
+
 func BenchmarkLogFormatter_FormatEvent(b *testing.B) {
 	config := &models.MatchConfig{
 		Map:        "de_mirage",
@@ -297,4 +508,399 @@ func BenchmarkLogFormatter_FormatEvent(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_ = formatter.FormatEvent(killEvent)
 	}
-}
\ No newline at end of file
+}
+// generateTestMatch generates a full seeded match to exercise encoders with realistic events
+func generateTestMatch(tb testing.TB) *models.Match {
+	tb.Helper()
+	req := models.GenerateRequest{
+		Teams: []models.Team{
+			{Name: "Alpha", Side: "CT", Players: generator.GenerateRoster("Alpha", 5, 1)},
+			{Name: "Bravo", Side: "TERRORIST", Players: generator.GenerateRoster("Bravo", 5, 2)},
+		},
+		Map:     "de_mirage",
+		Format:  "mr12",
+		Options: models.MatchOptions{Seed: 42},
+	}
+	match, err := generator.NewMatchGenerator().Generate(&req)
+	if err != nil {
+		tb.Fatalf("Generate failed: %v", err)
+	}
+	return match
+}
+
+func TestEncodeEvents_RoundTrip(t *testing.T) {
+	match := generateTestMatch(t)
+
+	data, err := EncodeEvents(match.Events)
+	if err != nil {
+		t.Fatalf("EncodeEvents failed: %v", err)
+	}
+	decoded, err := DecodeEvents(data)
+	if err != nil {
+		t.Fatalf("DecodeEvents failed: %v", err)
+	}
+	if len(decoded) != len(match.Events) {
+		t.Fatalf("Expected %d events, got %d", len(match.Events), len(decoded))
+	}
+	for i, event := range match.Events {
+		if reflect.TypeOf(decoded[i]) != reflect.TypeOf(event) {
+			t.Fatalf("Event %d: expected %T, got %T", i, event, decoded[i])
+		}
+		if decoded[i].ToLogLine() != event.ToLogLine() {
+			t.Fatalf("Event %d: expected %q, got %q", i, event.ToLogLine(), decoded[i].ToLogLine())
+		}
+		if decoded[i].GetTick() != event.GetTick() || decoded[i].GetRound() != event.GetRound() {
+			t.Fatalf("Event %d: expected tick %d round %d, got tick %d round %d", i, event.GetTick(), event.GetRound(), decoded[i].GetTick(), decoded[i].GetRound())
+		}
+	}
+
+	raw, err := FormatRawEvents(match.Events)
+	if err != nil {
+		t.Fatalf("FormatRawEvents failed: %v", err)
+	}
+	if len(data)*4 > len(raw) {
+		t.Errorf("Expected the binary encoding to be at least 4x smaller than raw JSON, got %d vs %d bytes", len(data), len(raw))
+	}
+}
+
+func TestDecodeEvents_Errors(t *testing.T) {
+	if _, err := EncodeEvents([]models.GameEvent{nil}); err == nil {
+		t.Error("Expected encoding a nil event to fail")
+	}
+
+	missingAttacker, err := EncodeEvents([]models.GameEvent{&models.KillEvent{Victim: &models.Player{Name: "a"}}})
+	if err != nil {
+		t.Fatalf("EncodeEvents failed: %v", err)
+	}
+
+	testCases := []struct {
+		name  string
+		input []byte
+		want  string
+	}{
+		{name: "not compressed", input: []byte(`[{"__type":"KillEvent"}]`), want: "not compressed"},
+		{name: "truncated", input: missingAttacker[:len(missingAttacker)/2], want: "decoding"},
+		{name: "missing player", input: missingAttacker, want: "attacker"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := DecodeEvents(tc.input)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+// BenchmarkEncodeEvents reports the encoded size of a full match next to its raw JSON size
+func BenchmarkEncodeEvents(b *testing.B) {
+	events := generateTestMatch(b).Events
+	raw, err := FormatRawEvents(events)
+	if err != nil {
+		b.Fatalf("FormatRawEvents failed: %v", err)
+	}
+
+	var encoded []byte
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if encoded, err = EncodeEvents(events); err != nil {
+			b.Fatalf("EncodeEvents failed: %v", err)
+		}
+	}
+	b.ReportMetric(float64(len(encoded)), "bytes/match")
+	b.ReportMetric(float64(len(raw)), "json-bytes/match")
+}
+
+func BenchmarkFormatRawEvents(b *testing.B) {
+	events := generateTestMatch(b).Events
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := FormatRawEvents(events); err != nil {
+			b.Fatalf("FormatRawEvents failed: %v", err)
+		}
+	}
+}
+
+func TestHTTPFormatter_MatchStatsFirstBloods(t *testing.T) {
+	match := generateTestMatch(t)
+
+	roundsWithKills := make(map[int]bool)
+	for _, event := range match.Events {
+		if kill, ok := event.(*models.KillEvent); ok {
+			roundsWithKills[kill.Round] = true
+		}
+	}
+
+	stats := NewHTTPFormatter(&match.Config).generateMatchStats(match)
+	if stats.FirstBloods != len(roundsWithKills) {
+		t.Errorf("Expected one first blood per round with kills (%d), got %d", len(roundsWithKills), stats.FirstBloods)
+	}
+}
+
+func TestHTTPFormatter_MatchStatsEventsPerRound(t *testing.T) {
+	match := generateTestMatch(t)
+
+	stats := NewHTTPFormatter(&match.Config).generateMatchStats(match)
+	if len(stats.EventsPerRound) != len(match.Rounds) {
+		t.Fatalf("Expected a count per round for %d rounds, got %d", len(match.Rounds), len(stats.EventsPerRound))
+	}
+
+	sum := 0
+	for i, count := range stats.EventsPerRound {
+		if count < stats.MinRoundEvents || count > stats.MaxRoundEvents {
+			t.Errorf("Round %d: %d events outside the reported range %d-%d", i+1, count, stats.MinRoundEvents, stats.MaxRoundEvents)
+		}
+		sum += count
+	}
+	if sum != len(match.Events) {
+		t.Errorf("Expected the rounds to add up to %d events, got %d", len(match.Events), sum)
+	}
+	if expected := float64(sum) / float64(len(match.Rounds)); stats.AverageRoundEvents != expected {
+		t.Errorf("Expected an average of %.2f events per round, got %.2f", expected, stats.AverageRoundEvents)
+	}
+}
+
+func TestHTTPFormatter_PurchaseItemCategory(t *testing.T) {
+	httpFormatter := NewHTTPFormatter(&models.MatchConfig{Map: "de_mirage"})
+	buyer := &models.Player{Name: "Buyer", UserID: 3, SteamID: "STEAM_1:0:111", Side: "CT"}
+
+	testCases := []struct {
+		item     string
+		expected string
+	}{
+		{"defuser", "utility"},
+		{"item_defuser", "utility"},
+		{"item_assaultsuit", "armor"},
+		{"vest", "armor"},
+		{"ak47", "weapon"},
+		{"weapon_awp", "weapon"},
+		{"incgrenade", "grenade"},
+		{"unknown_item", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.item, func(t *testing.T) {
+			purchase := &models.ItemPurchaseEvent{
+				BaseEvent: models.NewBaseEvent("item_purchase", 64, 1),
+				Player:    buyer,
+				Item:      tc.item,
+			}
+			entry, err := httpFormatter.convertEventToJSON(purchase)
+			if err != nil {
+				t.Fatalf("convertEventToJSON failed: %v", err)
+			}
+			if entry.Metadata.ItemCategory != tc.expected {
+				t.Errorf("Expected category %q, got %q", tc.expected, entry.Metadata.ItemCategory)
+			}
+			if entry.Metadata.Weapon != tc.item {
+				t.Errorf("Expected the raw item %q to be kept, got %q", tc.item, entry.Metadata.Weapon)
+			}
+		})
+	}
+}
+
+func TestLogFormatter_LineEnding(t *testing.T) {
+	match := generateTestMatch(t)
+
+	lfConfig := match.Config
+	lfOutput := NewLogFormatter(&lfConfig).FormatMatchToString(match)
+	if strings.Contains(lfOutput, "\r") {
+		t.Error("Expected LF output by default")
+	}
+
+	crlfConfig := match.Config
+	crlfConfig.LineEnding = "crlf"
+	crlfOutput := NewLogFormatter(&crlfConfig).FormatMatchToString(match)
+
+	lines := strings.Split(crlfOutput, "\r\n")
+	if len(lines) != len(strings.Split(lfOutput, "\n")) {
+		t.Fatalf("Expected %d CRLF separated lines, got %d", len(strings.Split(lfOutput, "\n")), len(lines))
+	}
+	for i, line := range lines {
+		if strings.ContainsAny(line, "\r\n") {
+			t.Fatalf("Line %d has a stray line break: %q", i, line)
+		}
+	}
+
+	events := NewLogFormatter(&crlfConfig).FormatEventsToString(match.Events)
+	if strings.Count(events, "\n") != strings.Count(events, "\r\n") {
+		t.Error("Expected every event line break to be CRLF")
+	}
+}
+
+func TestHTTPFormatter_FormatPerRound(t *testing.T) {
+	match := generateTestMatch(t)
+	f := NewHTTPFormatter(&match.Config)
+
+	logs := f.FormatPerRound(match)
+	if len(logs) != len(match.Rounds) {
+		t.Fatalf("Expected a log per round for %d rounds, got %d", len(match.Rounds), len(logs))
+	}
+
+	var joined []string
+	for i, round := range match.Rounds {
+		lines, ok := logs[round.RoundNumber]
+		if !ok {
+			t.Fatalf("Missing log for round %d", round.RoundNumber)
+		}
+		joined = append(joined, lines...)
+
+		// Every log stands on its own: valid lines and exactly one round start and end
+		starts, ends := 0, 0
+		for _, line := range lines {
+			if !f.logFormatter.ValidateLogFormat(line) {
+				t.Fatalf("Round %d: invalid log line %q", round.RoundNumber, line)
+			}
+			if strings.Contains(line, `World triggered "Round_Start"`) {
+				starts++
+			}
+			if strings.Contains(line, `(CT "`) && strings.Contains(line, `(T "`) {
+				ends++
+			}
+		}
+		if starts != 1 || ends != 1 {
+			t.Errorf("Round %d: expected one round start and end, got %d and %d", round.RoundNumber, starts, ends)
+		}
+
+		hasHeader := strings.Contains(lines[0], "Log file started")
+		if hasHeader != (i == 0) {
+			t.Errorf("Round %d: expected the header only in the first log, header=%v", round.RoundNumber, hasHeader)
+		}
+	}
+
+	if !reflect.DeepEqual(joined, f.logFormatter.FormatMatch(match)) {
+		t.Error("Expected the per-round logs joined in order to give the full match log")
+	}
+}
+
+func TestHTTPFormatter_GenerateNarrative(t *testing.T) {
+	match := generateTestMatch(t)
+
+	winner, loser := match.Teams[0].Name, match.Teams[1].Name
+	if match.Scores[loser] > match.Scores[winner] {
+		winner, loser = loser, winner
+	}
+	var top models.Player
+	for _, team := range match.Teams {
+		for _, player := range team.Players {
+			if player.Stats.Kills > top.Stats.Kills ||
+				(player.Stats.Kills == top.Stats.Kills && player.Stats.Deaths < top.Stats.Deaths) ||
+				(player.Stats.Kills == top.Stats.Kills && player.Stats.Deaths == top.Stats.Deaths && player.Name < top.Name) {
+				top = player
+			}
+		}
+	}
+
+	narrative := NewHTTPFormatter(&match.Config).GenerateNarrative(match)
+
+	score := fmt.Sprintf("%s beat %s %d-%d", winner, loser, match.Scores[winner], match.Scores[loser])
+	if match.Scores[winner] != match.Scores[loser] && !strings.Contains(narrative, score) {
+		t.Errorf("Expected the narrative to report %q, got %q", score, narrative)
+	}
+	if !strings.Contains(narrative, top.Name+" of "+top.Team) {
+		t.Errorf("Expected the narrative to name top fragger %s, got %q", top.Name, narrative)
+	}
+}
+
+func TestLogFormatter_HeaderVeto(t *testing.T) {
+	match := generateTestMatch(t)
+	config := match.Config
+	config.Veto = []models.VetoStep{
+		{Team: "Alpha", Action: "ban", Map: "de_nuke"},
+		{Team: "Bravo", Action: "pick", Map: "de_mirage"},
+		{Action: "decider", Map: "de_inferno"},
+	}
+
+	header := NewLogFormatter(&config).FormatLogHeader(match)
+	lines := strings.Split(header, "\n")
+	var vetoLines []string
+	loading := -1
+	for i, line := range lines {
+		if strings.Contains(line, "map_veto:") {
+			vetoLines = append(vetoLines, line)
+			if loading >= 0 {
+				t.Errorf("Expected veto lines before the map loads, got %q", line)
+			}
+		}
+		if strings.Contains(line, "Loading map") {
+			loading = i
+		}
+	}
+	if len(vetoLines) != len(config.Veto) {
+		t.Fatalf("Expected %d veto lines, got %d", len(config.Veto), len(vetoLines))
+	}
+	if !strings.HasSuffix(vetoLines[1], `map_veto: team "Bravo" action "pick" map "de_mirage"`) {
+		t.Errorf("Unexpected veto line %q", vetoLines[1])
+	}
+}
+
+func TestLogFormatter_FormatLogHeaderSeed(t *testing.T) {
+	config := &models.MatchConfig{Map: "de_mirage", ServerName: "Test Server", Seed: 1234}
+	match := &models.Match{StartTime: time.Date(2025, 3, 14, 18, 30, 5, 0, time.UTC), Config: *config}
+
+	if header := NewLogFormatter(config).FormatLogHeader(match); !strings.Contains(header, `server_cvar: "nocs_seed" "1234"`) {
+		t.Errorf("Expected the header to include the seed, got:\n%s", header)
+	}
+}
+
+func TestHTTPFormatter_RoundSummaryScores(t *testing.T) {
+	match := generateTestMatch(t)
+
+	response, err := NewHTTPFormatter(&match.Config).FormatAsHTTPLog(match)
+	if err != nil {
+		t.Fatalf("FormatAsHTTPLog failed: %v", err)
+	}
+	for _, round := range response.Rounds {
+		if round.CTScore+round.TScore != round.RoundNumber {
+			t.Errorf("Round %d: expected the CT and T scores to add up to the rounds played, got %d-%d", round.RoundNumber, round.CTScore, round.TScore)
+		}
+	}
+}
+
+func TestLogFormatter_FlashbangThrowFollowsItsBlinds(t *testing.T) {
+	match := generateTestMatch(t)
+	lines := NewLogFormatter(&match.Config).FormatMatch(match)
+
+	blindLine := regexp.MustCompile(`^L (.+?): .* blinded for [\d.]+ by .* from flashbang entindex (\d+) $`)
+	throwLine := regexp.MustCompile(`^L (.+?): .* threw flashbang \[.*\] flashbang entindex (\d+)\)$`)
+
+	blinds := make(map[string]string) // entindex -> timestamp of its blind lines
+	thrown := make(map[string]bool)
+	blindedFlashes := 0
+	for i, line := range lines {
+		if m := blindLine.FindStringSubmatch(line); m != nil {
+			if thrown[m[2]] {
+				t.Errorf("Line %d: blind line after the throw of flashbang %s", i, m[2])
+			}
+			if at, ok := blinds[m[2]]; ok && at != m[1] {
+				t.Errorf("Line %d: flashbang %s blinds at %s and %s", i, m[2], at, m[1])
+			}
+			blinds[m[2]] = m[1]
+			continue
+		}
+		if m := throwLine.FindStringSubmatch(line); m != nil {
+			if thrown[m[2]] {
+				t.Errorf("Line %d: flashbang %s thrown twice", i, m[2])
+			}
+			thrown[m[2]] = true
+			if at, ok := blinds[m[2]]; ok {
+				blindedFlashes++
+				if at != m[1] {
+					t.Errorf("Line %d: flashbang %s thrown at %s, its blinds at %s", i, m[2], m[1], at)
+				}
+			}
+		}
+	}
+	for index := range blinds {
+		if !thrown[index] {
+			t.Errorf("Flashbang %s blinded players but has no throw line", index)
+		}
+	}
+	if blindedFlashes == 0 {
+		t.Error("Expected flashbangs that blinded players in the generated log")
+	}
+}
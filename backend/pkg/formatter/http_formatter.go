@@ -1,8 +1,12 @@
 package formatter
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/noueii/nocs-log-generator/backend/pkg/models"
@@ -12,6 +16,7 @@ import (
 type HTTPFormatter struct {
 	logFormatter *LogFormatter
 	config       *models.MatchConfig
+	economy      *models.EconomyManager
 }
 
 // NewHTTPFormatter creates a new HTTP formatter
@@ -19,6 +24,7 @@ func NewHTTPFormatter(config *models.MatchConfig) *HTTPFormatter {
 	return &HTTPFormatter{
 		logFormatter: NewLogFormatter(config),
 		config:       config,
+		economy:      models.NewEconomyManager(),
 	}
 }
 
@@ -35,19 +41,22 @@ type JSONLogEntry struct {
 
 // EventMetadata contains additional metadata about the event
 type EventMetadata struct {
-	Players     []string    `json:"players,omitempty"`
-	Teams       []string    `json:"teams,omitempty"`
-	Weapon      string      `json:"weapon,omitempty"`
-	Location    string      `json:"location,omitempty"`
-	Modifiers   []string    `json:"modifiers,omitempty"`
-	Damage      int         `json:"damage,omitempty"`
-	IsKill      bool        `json:"is_kill,omitempty"`
-	IsObjective bool        `json:"is_objective,omitempty"`
+	Players      []string `json:"players,omitempty"`
+	Teams        []string `json:"teams,omitempty"`
+	Weapon       string   `json:"weapon,omitempty"`
+	ItemCategory string   `json:"item_category,omitempty"` // weapon, armor, grenade or utility for purchases
+	Location     string   `json:"location,omitempty"`
+	Modifiers    []string `json:"modifiers,omitempty"`
+	Damage       int      `json:"damage,omitempty"`
+	IsKill       bool     `json:"is_kill,omitempty"`
+	IsObjective  bool     `json:"is_objective,omitempty"`
 }
 
 // HTTPLogResponse represents the complete HTTP response for log data
 type HTTPLogResponse struct {
 	MatchID     string         `json:"match_id"`
+	Title       string         `json:"title,omitempty"`
+	Tournament  string         `json:"tournament,omitempty"`
 	Map         string         `json:"map"`
 	Format      string         `json:"format"`
 	Status      string         `json:"status"`
@@ -79,6 +88,9 @@ type PlayerSummary struct {
 	Assists  int     `json:"assists"`
 	Rating   float64 `json:"rating"`
 	Headshots int    `json:"headshots"`
+	MVPs     int     `json:"mvps"` // Round MVP stars
+	EnemiesFlashed int `json:"enemies_flashed"`
+	FlashAssists   int `json:"flash_assists"`
 }
 
 // RoundSummary provides a summary of round data
@@ -86,7 +98,7 @@ type RoundSummary struct {
 	RoundNumber int           `json:"round_number"`
 	Winner      string        `json:"winner"`
 	Reason      string        `json:"reason"`
-	Duration    time.Duration `json:"duration"`
+	Duration    float64       `json:"duration"` // Seconds
 	MVP         string        `json:"mvp"`
 	CTScore     int           `json:"ct_score"`
 	TScore      int           `json:"t_score"`
@@ -102,9 +114,16 @@ type MatchStats struct {
 	BombDefuses   int                    `json:"bomb_defuses"`
 	BombExplosions int                   `json:"bomb_explosions"`
 	TotalKills    int                    `json:"total_kills"`
+	FirstBloods   int                    `json:"first_bloods"` // Rounds with a first kill
 	TotalDamage   int                    `json:"total_damage"`
 	EventTypes    map[string]int         `json:"event_types"`
 	WeaponStats   map[string]WeaponStat  `json:"weapon_stats"`
+	
+	// Events each round produced, in round order, to spot lopsided rounds
+	EventsPerRound     []int   `json:"events_per_round"`
+	MinRoundEvents     int     `json:"min_round_events"`
+	MaxRoundEvents     int     `json:"max_round_events"`
+	AverageRoundEvents float64 `json:"average_round_events"`
 }
 
 // WeaponStat tracks statistics for individual weapons
@@ -113,12 +132,115 @@ type WeaponStat struct {
 	Headshots int     `json:"headshots"`
 	Damage    int     `json:"damage"`
 	Accuracy  float64 `json:"accuracy,omitempty"`
+	KillsByRound         map[int]int `json:"kills_by_round,omitempty"`
+	HitgroupDistribution map[int]int `json:"hitgroup_distribution,omitempty"` // Kills by hitgroup of the fatal shot
+}
+
+// RoundEconomy is one team's economy at the end of a round
+type RoundEconomy struct {
+	Round          int    `json:"round"`
+	Team           string `json:"team"`
+	Money          int    `json:"money"`           // Total team money
+	AverageMoney   int    `json:"average_money"`
+	EquipmentValue int    `json:"equipment_value"`
+	PickedUpValue  int    `json:"picked_up_value"` // Equipment value picked up rather than bought
+	BuyType        string `json:"buy_type,omitempty"`
+	LossStreak     int    `json:"loss_streak"`
+}
+
+// RoundTimeline is one round's tick span with the ticks of its notable events
+type RoundTimeline struct {
+	Round     int             `json:"round"`
+	StartTick int64           `json:"start_tick"`
+	EndTick   int64           `json:"end_tick"`
+	Winner    string          `json:"winner"`
+	Events    []TimelineEvent `json:"events"`
+}
+
+// TimelineEvent marks a notable event within a round
+type TimelineEvent struct {
+	Tick int64  `json:"tick"`
+	Type string `json:"type"`
+}
+
+// Scoreboard is everything a scoreboard overlay shows, without the event log
+type Scoreboard struct {
+	MatchID string           `json:"match_id"`
+	Map     string           `json:"map"`
+	Status  string           `json:"status"`
+	Round   int              `json:"round"`            // Rounds played so far
+	Winner  string           `json:"winner,omitempty"` // Empty while live or for a draw
+	Teams   []ScoreboardTeam `json:"teams"`
+}
+
+// ScoreboardTeam is a team's score and players on the scoreboard
+type ScoreboardTeam struct {
+	Name    string             `json:"name"`
+	Tag     string             `json:"tag,omitempty"`
+	Side    string             `json:"side"`
+	Score   int                `json:"score"`
+	Players []ScoreboardPlayer `json:"players"`
+}
+
+// ScoreboardPlayer is a player's scoreboard line
+type ScoreboardPlayer struct {
+	Name    string  `json:"name"`
+	Kills   int     `json:"kills"`
+	Deaths  int     `json:"deaths"`
+	Assists int     `json:"assists"`
+	ADR     float64 `json:"adr"`
+	Rating  float64 `json:"rating"`
+	MVPs    int     `json:"mvps"`
+}
+
+// FormatScoreboard builds the scoreboard of a match from its aggregated player stats,
+// with players sorted by kills like the in-game scoreboard
+func (f *HTTPFormatter) FormatScoreboard(match *models.Match) *Scoreboard {
+	scoreboard := &Scoreboard{
+		MatchID: match.ID,
+		Map:     match.Map,
+		Status:  match.Status,
+		Round:   len(match.Rounds),
+		Teams:   make([]ScoreboardTeam, 0, len(match.Teams)),
+	}
+	if match.Status == "completed" {
+		scoreboard.Winner = match.GetWinningTeam()
+	}
+
+	for _, team := range match.Teams {
+		entry := ScoreboardTeam{
+			Name:    team.Name,
+			Tag:     team.Tag,
+			Side:    team.Side,
+			Score:   match.Scores[team.Name],
+			Players: make([]ScoreboardPlayer, 0, len(team.Players)),
+		}
+		for _, player := range team.Players {
+			entry.Players = append(entry.Players, ScoreboardPlayer{
+				Name:    player.Name,
+				Kills:   player.Stats.Kills,
+				Deaths:  player.Stats.Deaths,
+				Assists: player.Stats.Assists,
+				ADR:     player.Stats.ADR,
+				Rating:  player.Stats.Rating,
+				MVPs:    player.Stats.MVPs,
+			})
+		}
+		sort.SliceStable(entry.Players, func(i, j int) bool {
+			return entry.Players[i].Kills > entry.Players[j].Kills
+		})
+		scoreboard.Teams = append(scoreboard.Teams, entry)
+	}
+
+	return scoreboard
 }
 
 // FormatAsHTTPLog converts a match to HTTP JSON format
 func (f *HTTPFormatter) FormatAsHTTPLog(match *models.Match) (*HTTPLogResponse, error) {
 	response := &HTTPLogResponse{
 		MatchID:     match.ID,
+		Title:       match.Title,
+		Tournament:  match.Config.TournamentName,
 		Map:         match.Map,
 		Format:      match.Format,
 		Status:      match.Status,
@@ -150,6 +272,9 @@ func (f *HTTPFormatter) FormatAsHTTPLog(match *models.Match) (*HTTPLogResponse,
 				Assists:   player.Stats.Assists,
 				Rating:    player.Stats.Rating,
 				Headshots: player.Stats.Headshots,
+				MVPs:      player.Stats.MVPs,
+				EnemiesFlashed: player.Stats.EnemiesFlashed,
+				FlashAssists:   player.Stats.FlashAssists,
 			}
 			teamSummary.Players = append(teamSummary.Players, playerSummary)
 		}
@@ -168,14 +293,15 @@ func (f *HTTPFormatter) FormatAsHTTPLog(match *models.Match) (*HTTPLogResponse,
 	
 	// Format rounds
 	for _, round := range match.Rounds {
+		ctScore, tScore := roundEndScores(round)
 		roundSummary := RoundSummary{
 			RoundNumber: round.RoundNumber,
 			Winner:      round.Winner,
 			Reason:      round.Reason,
-			Duration:    round.EndTime.Sub(round.StartTime),
+			Duration:    round.EndTime.Sub(round.StartTime).Seconds(),
 			MVP:         round.MVP,
-			CTScore:     round.Scores["CT"],
-			TScore:      round.Scores["TERRORIST"], 
+			CTScore:     ctScore,
+			TScore:      tScore,
 			EventCount:  len(round.Events),
 		}
 		response.Rounds = append(response.Rounds, roundSummary)
@@ -187,6 +313,17 @@ func (f *HTTPFormatter) FormatAsHTTPLog(match *models.Match) (*HTTPLogResponse,
 	return response, nil
 }
 
+// roundEndScores returns the CT and T scores a round ended on. Round scores are kept
+// per team, so the sides come from the round's round_end event.
+func roundEndScores(round models.RoundData) (int, int) {
+	for _, event := range round.Events {
+		if end, ok := event.(*models.RoundEndEvent); ok {
+			return end.CTScore, end.TScore
+		}
+	}
+	return 0, 0
+}
+
 // FormatEventsAsJSON formats multiple events as JSON array
 func (f *HTTPFormatter) FormatEventsAsJSON(events []models.GameEvent) ([]byte, error) {
 	jsonEvents := make([]JSONLogEntry, 0, len(events))
@@ -302,6 +439,9 @@ func (f *HTTPFormatter) extractEventMetadata(event models.GameEvent) *EventMetad
 		if e.AttackerBlind {
 			modifiers = append(modifiers, "attackerblind")
 		}
+		if e.InAir {
+			modifiers = append(modifiers, "attackerinair")
+		}
 		metadata.Modifiers = modifiers
 		
 	case *models.PlayerHurtEvent:
@@ -329,10 +469,35 @@ func (f *HTTPFormatter) extractEventMetadata(event models.GameEvent) *EventMetad
 		metadata.Location = e.Site
 		metadata.IsObjective = true
 		
+	case *models.BombDropEvent:
+		metadata.Players = []string{e.Player.Name}
+		metadata.Teams = []string{e.Player.Side}
+		
+	case *models.BombPickupEvent:
+		metadata.Players = []string{e.Player.Name}
+		metadata.Teams = []string{e.Player.Side}
+		
+	case *models.WeaponPickupEvent:
+		metadata.Players = []string{e.Player.Name}
+		metadata.Teams = []string{e.Player.Side}
+		metadata.Weapon = e.Weapon
+		metadata.ItemCategory = f.economy.ItemCategory(e.Weapon)
+		
+	case *models.PlayerPositionEvent:
+		metadata.Players = []string{e.Player.Name}
+		metadata.Teams = []string{e.Player.Side}
+		
 	case *models.ItemPurchaseEvent:
 		metadata.Players = []string{e.Player.Name}
 		metadata.Teams = []string{e.Player.Side}
 		metadata.Weapon = e.Item // Item could be weapon or equipment
+		metadata.ItemCategory = f.economy.ItemCategory(e.Item)
+		
+	case *models.PurchaseRejectedEvent:
+		metadata.Players = []string{e.Player.Name}
+		metadata.Teams = []string{e.Player.Side}
+		metadata.Weapon = e.Item
+		metadata.ItemCategory = f.economy.ItemCategory(e.Item)
 		
 	case *models.GrenadeThrowEvent:
 		metadata.Players = []string{e.Player.Name}
@@ -364,6 +529,9 @@ func (f *HTTPFormatter) extractEventMetadata(event models.GameEvent) *EventMetad
 		}
 		metadata.Modifiers = modifiers
 		
+	case *models.ObserverChatEvent:
+		metadata.Modifiers = []string{e.Source}
+		
 	case *models.RoundStartEvent, *models.RoundEndEvent:
 		metadata.IsObjective = true
 	}
@@ -376,18 +544,36 @@ func (f *HTTPFormatter) generateMatchStats(match *models.Match) *MatchStats {
 	stats := &MatchStats{
 		TotalRounds:   len(match.Rounds),
 		EventTypes:    make(map[string]int),
-		WeaponStats:   make(map[string]WeaponStat),
+		WeaponStats:   f.FormatWeaponStats(match),
 	}
 	
 	// Count wins
 	for _, round := range match.Rounds {
-		if round.Winner == "CT" {
+		if models.NormalizeSide(round.Winner) == "CT" {
 			stats.CTWins++
 		} else {
 			stats.TWins++
 		}
 	}
 	
+	// Event distribution over rounds
+	stats.EventsPerRound = make([]int, len(match.Rounds))
+	totalRoundEvents := 0
+	for i, round := range match.Rounds {
+		count := len(round.Events)
+		stats.EventsPerRound[i] = count
+		totalRoundEvents += count
+		if i == 0 || count < stats.MinRoundEvents {
+			stats.MinRoundEvents = count
+		}
+		if count > stats.MaxRoundEvents {
+			stats.MaxRoundEvents = count
+		}
+	}
+	if len(match.Rounds) > 0 {
+		stats.AverageRoundEvents = float64(totalRoundEvents) / float64(len(match.Rounds))
+	}
+	
 	// Analyze events
 	for _, event := range match.Events {
 		eventType := event.GetType()
@@ -396,21 +582,13 @@ func (f *HTTPFormatter) generateMatchStats(match *models.Match) *MatchStats {
 		switch e := event.(type) {
 		case *models.KillEvent:
 			stats.TotalKills++
-			
-			weaponStat := stats.WeaponStats[e.Weapon]
-			weaponStat.Kills++
-			if e.Headshot {
-				weaponStat.Headshots++
+			if e.FirstBlood {
+				stats.FirstBloods++
 			}
-			stats.WeaponStats[e.Weapon] = weaponStat
 			
 		case *models.PlayerHurtEvent:
 			stats.TotalDamage += e.Damage
 			
-			weaponStat := stats.WeaponStats[e.Weapon]
-			weaponStat.Damage += e.Damage
-			stats.WeaponStats[e.Weapon] = weaponStat
-			
 		case *models.BombPlantEvent:
 			stats.BombPlants++
 			
@@ -425,6 +603,94 @@ func (f *HTTPFormatter) generateMatchStats(match *models.Match) *MatchStats {
 	return stats
 }
 
+// MatchComparison holds the aggregate stats of two matches and their difference
+type MatchComparison struct {
+	MatchIDs []string         `json:"match_ids"`
+	Scores   []map[string]int `json:"scores"` // Final score of each match by team name
+	Stats    []*MatchStats    `json:"stats"`
+	Diff     StatsDiff        `json:"diff"`
+}
+
+// StatsDiff is the second match's stats minus the first's
+type StatsDiff struct {
+	TotalRounds int            `json:"total_rounds"`
+	CTWins      int            `json:"ct_wins"`
+	TWins       int            `json:"t_wins"`
+	TotalKills  int            `json:"total_kills"`
+	TotalDamage int            `json:"total_damage"`
+	BombPlants  int            `json:"bomb_plants"`
+	EventTypes  map[string]int `json:"event_types"`  // Per event type, including types only one match has
+	WeaponKills map[string]int `json:"weapon_kills"` // Kills per weapon
+}
+
+// CompareMatches diffs the aggregate stats of two matches, e.g. to see how a config
+// change affected generated output
+func (f *HTTPFormatter) CompareMatches(baseline, other *models.Match) *MatchComparison {
+	baseStats := f.generateMatchStats(baseline)
+	otherStats := f.generateMatchStats(other)
+	
+	diff := StatsDiff{
+		TotalRounds: otherStats.TotalRounds - baseStats.TotalRounds,
+		CTWins:      otherStats.CTWins - baseStats.CTWins,
+		TWins:       otherStats.TWins - baseStats.TWins,
+		TotalKills:  otherStats.TotalKills - baseStats.TotalKills,
+		TotalDamage: otherStats.TotalDamage - baseStats.TotalDamage,
+		BombPlants:  otherStats.BombPlants - baseStats.BombPlants,
+		EventTypes:  make(map[string]int),
+		WeaponKills: make(map[string]int),
+	}
+	for eventType, count := range otherStats.EventTypes {
+		diff.EventTypes[eventType] += count
+	}
+	for eventType, count := range baseStats.EventTypes {
+		diff.EventTypes[eventType] -= count
+	}
+	for weapon, stat := range otherStats.WeaponStats {
+		diff.WeaponKills[weapon] += stat.Kills
+	}
+	for weapon, stat := range baseStats.WeaponStats {
+		diff.WeaponKills[weapon] -= stat.Kills
+	}
+	
+	return &MatchComparison{
+		MatchIDs: []string{baseline.ID, other.ID},
+		Scores:   []map[string]int{baseline.Scores, other.Scores},
+		Stats:    []*MatchStats{baseStats, otherStats},
+		Diff:     diff,
+	}
+}
+
+// FormatWeaponStats summarizes kills and damage per weapon, with kills broken down
+// by round and by the hitgroup of the fatal shot
+func (f *HTTPFormatter) FormatWeaponStats(match *models.Match) map[string]WeaponStat {
+	weaponStats := make(map[string]WeaponStat)
+	
+	for _, event := range match.Events {
+		switch e := event.(type) {
+		case *models.KillEvent:
+			weaponStat := weaponStats[e.Weapon]
+			if weaponStat.KillsByRound == nil {
+				weaponStat.KillsByRound = make(map[int]int)
+				weaponStat.HitgroupDistribution = make(map[int]int)
+			}
+			weaponStat.Kills++
+			if e.Headshot {
+				weaponStat.Headshots++
+			}
+			weaponStat.KillsByRound[e.GetRound()]++
+			weaponStat.HitgroupDistribution[e.Hitgroup]++
+			weaponStats[e.Weapon] = weaponStat
+			
+		case *models.PlayerHurtEvent:
+			weaponStat := weaponStats[e.Weapon]
+			weaponStat.Damage += e.Damage
+			weaponStats[e.Weapon] = weaponStat
+		}
+	}
+	
+	return weaponStats
+}
+
 // FormatTimestamp formats a timestamp for HTTP responses
 func (f *HTTPFormatter) FormatTimestamp(t time.Time) string {
 	return t.Format(time.RFC3339)
@@ -500,4 +766,115 @@ func (f *HTTPFormatter) GetHTTPFormatterStats() map[string]interface{} {
 	}
 	
 	return httpStats
-}
\ No newline at end of file
+}
+
+// FormatEconomyTimeseries flattens the per-round economy snapshots into one entry
+// per team per round, ordered by round and then by team
+func (f *HTTPFormatter) FormatEconomyTimeseries(match *models.Match) []RoundEconomy {
+	series := make([]RoundEconomy, 0, len(match.Rounds)*len(match.Teams))
+	
+	for _, round := range match.Rounds {
+		for _, team := range match.Teams {
+			economy, ok := round.Economy[team.Name]
+			if !ok {
+				continue
+			}
+			series = append(series, RoundEconomy{
+				Round:          round.RoundNumber,
+				Team:           team.Name,
+				Money:          economy.TotalMoney,
+				AverageMoney:   economy.AverageMoney,
+				EquipmentValue: economy.EquipmentValue,
+				PickedUpValue:  economy.PickedUpValue,
+				BuyType:        economy.BuyType,
+				LossStreak:     economy.ConsecutiveLosses,
+			})
+		}
+	}
+	
+	return series
+}
+
+// FormatTimeline builds a compact per-round index of kills, plants and defuses for
+// scrubbing through a match without loading every event
+func (f *HTTPFormatter) FormatTimeline(match *models.Match) []RoundTimeline {
+	timeline := make([]RoundTimeline, len(match.Rounds))
+	roundIndex := make(map[int]int, len(match.Rounds))
+	for i, round := range match.Rounds {
+		timeline[i] = RoundTimeline{
+			Round:     round.RoundNumber,
+			StartTick: round.StartTick,
+			EndTick:   round.EndTick,
+			Winner:    round.Winner,
+			Events:    make([]TimelineEvent, 0),
+		}
+		roundIndex[round.RoundNumber] = i
+	}
+	
+	for _, event := range match.Events {
+		switch event.(type) {
+		case *models.KillEvent, *models.BombPlantEvent, *models.BombDefuseEvent:
+		default:
+			continue
+		}
+		i, ok := roundIndex[event.GetRound()]
+		if !ok {
+			continue
+		}
+		timeline[i].Events = append(timeline[i].Events, TimelineEvent{Tick: event.GetTick(), Type: event.GetType()})
+	}
+	
+	return timeline
+}
+
+// FormatPerRound formats a match as one log per round, keyed by round number, for
+// tools that ingest a file per round. Only the first round's log has the header and
+// only the last one the footer, so joining them in round order gives the full log.
+func (f *HTTPFormatter) FormatPerRound(match *models.Match) map[int][]string {
+	logs := make(map[int][]string, len(match.Rounds))
+	for i, round := range match.Rounds {
+		var lines []string
+		if i == 0 {
+			lines = append(lines, f.logFormatter.FormatLogHeader(match))
+		}
+		lines = append(lines, f.logFormatter.FormatEventLines(round.Events)...)
+		if i == len(match.Rounds)-1 {
+			lines = append(lines, f.logFormatter.FormatLogFooter(match))
+		}
+		logs[round.RoundNumber] = lines
+	}
+
+	return logs
+}
+
+// FormatEconomyTimeseriesCSV formats an economy time series as CSV
+func (f *HTTPFormatter) FormatEconomyTimeseriesCSV(series []RoundEconomy) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	
+	header := []string{"round", "team", "money", "average_money", "equipment_value", "picked_up_value", "buy_type", "loss_streak"}
+	if err := writer.Write(header); err != nil {
+		return nil, fmt.Errorf("error writing CSV header: %w", err)
+	}
+	for _, entry := range series {
+		record := []string{
+			strconv.Itoa(entry.Round),
+			entry.Team,
+			strconv.Itoa(entry.Money),
+			strconv.Itoa(entry.AverageMoney),
+			strconv.Itoa(entry.EquipmentValue),
+			strconv.Itoa(entry.PickedUpValue),
+			entry.BuyType,
+			strconv.Itoa(entry.LossStreak),
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, fmt.Errorf("error writing CSV record: %w", err)
+		}
+	}
+	
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("error writing CSV: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/noueii/nocs-log-generator/backend/pkg/models"
 )
@@ -36,10 +38,22 @@ func (f *LogFormatter) FormatMatch(match *models.Match) []string {
 	var lines []string
 	
 	// Add log header
-	lines = append(lines, f.formatLogHeader(match))
+	lines = append(lines, f.FormatLogHeader(match))
 	
 	// Format all events
-	for _, event := range match.Events {
+	lines = append(lines, f.FormatEventLines(match.Events)...)
+	
+	// Add log footer
+	lines = append(lines, f.FormatLogFooter(match))
+	
+	return lines
+}
+
+// FormatEventLines formats events into log lines, splitting multi-line events
+func (f *LogFormatter) FormatEventLines(events []models.GameEvent) []string {
+	var lines []string
+	
+	for _, event := range events {
 		formatted := f.FormatEvent(event)
 		if formatted != "" {
 			// Handle multi-line events
@@ -52,9 +66,6 @@ func (f *LogFormatter) FormatMatch(match *models.Match) []string {
 		}
 	}
 	
-	// Add log footer
-	lines = append(lines, f.formatLogFooter(match))
-	
 	return lines
 }
 
@@ -79,13 +90,23 @@ func (f *LogFormatter) FormatEventsToString(events []models.GameEvent) string {
 		}
 	}
 	
-	return strings.Join(lines, "\n")
+	return f.joinLines(lines)
 }
 
 // FormatMatchToString formats an entire match as a single string
 func (f *LogFormatter) FormatMatchToString(match *models.Match) string {
 	lines := f.FormatMatch(match)
-	return strings.Join(lines, "\n")
+	return f.joinLines(lines)
+}
+
+// joinLines joins formatted lines with the configured line ending, including the
+// line breaks inside multi-line events
+func (f *LogFormatter) joinLines(lines []string) string {
+	joined := strings.Join(lines, "\n")
+	if terminator := f.config.LineTerminator(); terminator != "\n" {
+		joined = strings.ReplaceAll(joined, "\n", terminator)
+	}
+	return joined
 }
 
 // FormatRound formats all events from a specific round
@@ -102,13 +123,15 @@ func (f *LogFormatter) FormatRound(roundData models.RoundData) []string {
 	return lines
 }
 
-// formatLogHeader creates the standard CS2 log header
-func (f *LogFormatter) formatLogHeader(match *models.Match) string {
-	timestamp := match.StartTime.In(f.timeZone).Format("01/02/2006 - 15:04:05")
+// FormatLogHeader creates the standard CS2 log header. Its timestamps and the log
+// filename come from the match start time, so a fixed start time gives a stable header.
+func (f *LogFormatter) FormatLogHeader(match *models.Match) string {
+	startTime := match.StartTime.In(f.timeZone)
+	timestamp := startTime.Format("01/02/2006 - 15:04:05")
 	
 	header := fmt.Sprintf(`L %s: Log file started (file "logs/L%s.log") (game "%s") (version "%s")`, 
 		timestamp, 
-		match.StartTime.Format("010206"), 
+		startTime.Format("010206"), 
 		"Counter-Strike: Global Offensive",
 		"1.38.5.5")
 	
@@ -116,16 +139,49 @@ func (f *LogFormatter) formatLogHeader(match *models.Match) string {
 	header += fmt.Sprintf(`\nL %s: server_cvar: "hostname" "%s"`, timestamp, f.serverName)
 	header += fmt.Sprintf(`\nL %s: server_cvar: "mp_startmoney" "%d"`, timestamp, f.config.StartMoney)
 	header += fmt.Sprintf(`\nL %s: server_cvar: "mp_maxmoney" "%d"`, timestamp, f.config.MaxMoney)
-	header += fmt.Sprintf(`\nL %s: server_cvar: "mp_roundtime" "115"`, timestamp)
-	header += fmt.Sprintf(`\nL %s: server_cvar: "mp_freezetime" "15"`, timestamp)
+	server := models.DefaultServerConfig()
+	f.config.ServerTimers.Apply(&server)
+	header += fmt.Sprintf(`\nL %s: server_cvar: "mp_roundtime" "%d"`, timestamp, server.RoundTime)
+	header += fmt.Sprintf(`\nL %s: server_cvar: "mp_freezetime" "%d"`, timestamp, server.FreezetimeLength)
+	header += fmt.Sprintf(`\nL %s: server_cvar: "mp_c4timer" "%d"`, timestamp, server.BombTimer)
+	if f.config.Seed != 0 {
+		header += fmt.Sprintf(`\nL %s: server_cvar: "nocs_seed" "%d"`, timestamp, f.config.Seed)
+	}
+	for _, name := range models.SortedCvarNames(f.config.ExtraCvars) {
+		header += fmt.Sprintf(`\nL %s: server_cvar: "%s" "%s"`, timestamp, name, sanitizeCvarValue(f.config.ExtraCvars[name]))
+	}
+	
+	// Add tournament metadata
+	if f.config.TournamentName != "" {
+		header += fmt.Sprintf(`\nL %s: server_cvar: "sv_tournament_name" "%s"`, timestamp, sanitizeCvarValue(f.config.TournamentName))
+	}
+	if match.Title != "" {
+		header += fmt.Sprintf(`\nL %s: server_cvar: "mp_teammatchstat_txt" "%s"`, timestamp, sanitizeCvarValue(match.Title))
+	}
+	for _, step := range f.config.Veto {
+		veto := &models.VetoEvent{
+			BaseEvent: models.NewBaseEvent("map_veto", 0, 0),
+			Team:      step.Team,
+			Action:    step.Action,
+			Map:       step.Map,
+		}
+		veto.Timestamp = startTime
+		header += "\n" + veto.ToLogLine()
+	}
 	header += fmt.Sprintf(`\nL %s: Loading map "%s"`, timestamp, f.mapName)
 	header += fmt.Sprintf(`\nL %s: Started map "%s" (CRC "0")`, timestamp, f.mapName)
 	
 	return header
 }
 
-// formatLogFooter creates the standard CS2 log footer
-func (f *LogFormatter) formatLogFooter(match *models.Match) string {
+// sanitizeCvarValue keeps free-form header values from breaking the quoted cvar format
+func sanitizeCvarValue(value string) string {
+	value = strings.ReplaceAll(value, `"`, `'`)
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
+
+// FormatLogFooter creates the standard CS2 log footer
+func (f *LogFormatter) FormatLogFooter(match *models.Match) string {
 	timestamp := match.EndTime.In(f.timeZone).Format("01/02/2006 - 15:04:05")
 	
 	footer := fmt.Sprintf(`L %s: Log file closed`, timestamp)
@@ -149,27 +205,32 @@ func (f *LogFormatter) sanitizePlayerName(name string) string {
 		return sanitized
 	}
 	
-	// Remove quotes and backslashes that could break log format
-	// First escape backslashes, then escape quotes to avoid double escaping
-	sanitized := strings.ReplaceAll(name, `\`, `\\`)
-	sanitized = strings.ReplaceAll(sanitized, `"`, `\"`)
-	
-	// Remove control characters and non-printable characters
+	// Escape quotes and backslashes that could break log format, and replace control
+	// characters and invalid UTF-8 so the log stays valid UTF-8. Printable Unicode such
+	// as emoji is kept. The length is limited in runes to the configured max name
+	// length and never splits an escape.
+	maxRunes := f.config.GetMaxNameLength()
 	var result strings.Builder
-	for _, r := range sanitized {
-		if r >= 32 && r <= 126 { // ASCII printable characters
-			result.WriteRune(r)
-		} else {
-			result.WriteRune('_') // Replace with underscore
+	length := 0
+	for _, r := range name {
+		replacement := string(r)
+		switch {
+		case r == '\\':
+			replacement = `\\`
+		case r == '"':
+			replacement = `\"`
+		case r == utf8.RuneError || !unicode.IsPrint(r):
+			replacement = "_"
 		}
+		
+		runes := utf8.RuneCountInString(replacement)
+		if length+runes > maxRunes {
+			break
+		}
+		result.WriteString(replacement)
+		length += runes
 	}
-	
-	sanitized = result.String()
-	
-	// Limit length to reasonable size
-	if len(sanitized) > 31 {
-		sanitized = sanitized[:31]
-	}
+	sanitized := result.String()
 	
 	// Cache the sanitized name
 	f.playerNames[name] = sanitized
@@ -332,14 +393,15 @@ func (f *LogFormatter) FormatRoundEnd(winner, reason string, ctScore, tScore int
 	}
 	
 	// Adjust for correct team name based on reason
-	if reason == "elimination" && winner == "CT" {
+	side := models.NormalizeSide(winner)
+	if reason == "elimination" && side == "CT" {
 		logReason = "CTs_Win"
-	} else if reason == "time" && winner == "TERRORIST" {
+	} else if reason == "time" && side == "TERRORIST" {
 		logReason = "Terrorists_Win"
 	}
 	
 	baseLine := fmt.Sprintf(`L %s: Team "%s" triggered "%s" (CT "%d") (T "%d")`, 
-		ts, winner, logReason, ctScore, tScore)
+		ts, models.LogSide(winner), logReason, ctScore, tScore)
 	
 	if mvp != nil {
 		mvpLine := fmt.Sprintf(`L %s: %s triggered "MVP"`, 
@@ -375,31 +437,32 @@ func (f *LogFormatter) FormatChat(player *models.Player, message string, team, d
 }
 
 // FormatGrenade formats a grenade event
-func (f *LogFormatter) FormatGrenade(player *models.Player, grenadeType string, timestamp time.Time) string {
+func (f *LogFormatter) FormatGrenade(player *models.Player, grenadeType string, position models.Vector3, timestamp time.Time) string {
 	ts := f.formatTimestamp(timestamp)
 	
-	return fmt.Sprintf(`L %s: %s threw %s`, 
+	return fmt.Sprintf(`L %s: %s threw %s [%d %d %d]`, 
 		ts, 
 		f.formatPlayerInfo(player), 
-		grenadeType)
+		grenadeType,
+		int(position.X), int(position.Y), int(position.Z))
 }
 
-// FormatFlashbang formats a flashbang event with blinded players
-func (f *LogFormatter) FormatFlashbang(player *models.Player, flashed []*models.Player, duration float64, timestamp time.Time) string {
+// FormatFlashbang formats a flashbang detonation the way CS2 logs it: a blind line
+// per flashed player followed by the throw line, all sharing the entity index
+func (f *LogFormatter) FormatFlashbang(player *models.Player, flashed []*models.Player, duration float64, entityIndex int, position models.Vector3, timestamp time.Time) string {
 	ts := f.formatTimestamp(timestamp)
 	
-	lines := []string{
-		fmt.Sprintf(`L %s: %s threw flashbang`, ts, f.formatPlayerInfo(player)),
-	}
-	
+	var lines []string
 	for _, flashedPlayer := range flashed {
-		line := fmt.Sprintf(`L %s: %s blinded %s with flashbang for %.1f`, 
+		line := fmt.Sprintf(`L %s: %s blinded for %.2f by %s from flashbang entindex %d `, 
 			ts, 
-			f.formatPlayerInfo(player), 
 			f.formatPlayerInfo(flashedPlayer), 
-			duration)
+			duration,
+			f.formatPlayerInfo(player), 
+			entityIndex)
 		lines = append(lines, line)
 	}
+	lines = append(lines, fmt.Sprintf(`%s flashbang entindex %d)`, f.FormatGrenade(player, "flashbang", position, timestamp), entityIndex))
 	
 	return strings.Join(lines, "\n")
 }
@@ -0,0 +1,216 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/noueii/nocs-log-generator/backend/pkg/models"
+)
+
+// minNarrativeStreak is the shortest run of round wins the narrative calls out
+const minNarrativeStreak = 3
+
+// clutch is a round a team won after being left with one player against several
+type clutch struct {
+	Round     int
+	Player    string
+	Opponents int
+}
+
+// GenerateNarrative describes a match in a few sentences: the final score, the top
+// fragger, the decisive rounds and any clutches
+func (f *HTTPFormatter) GenerateNarrative(match *models.Match) string {
+	sentences := []string{narrateResult(match)}
+
+	if sentence := narrateTopFragger(match); sentence != "" {
+		sentences = append(sentences, sentence)
+	}
+
+	winners := roundWinners(match)
+	if sentence := narrateDecisiveRounds(match, winners); sentence != "" {
+		sentences = append(sentences, sentence)
+	}
+	if sentence := narrateClutches(match, winners); sentence != "" {
+		sentences = append(sentences, sentence)
+	}
+
+	return strings.Join(sentences, " ")
+}
+
+// narrateResult describes the final score
+func narrateResult(match *models.Match) string {
+	if len(match.Teams) < 2 {
+		return fmt.Sprintf("The match on %s has no result.", match.Map)
+	}
+
+	first, second := match.Teams[0].Name, match.Teams[1].Name
+	firstScore, secondScore := match.Scores[first], match.Scores[second]
+	switch {
+	case firstScore == secondScore:
+		return fmt.Sprintf("%s and %s drew %d-%d on %s.", first, second, firstScore, secondScore, match.Map)
+	case secondScore > firstScore:
+		first, second = second, first
+		firstScore, secondScore = secondScore, firstScore
+	}
+
+	result := fmt.Sprintf("%s beat %s %d-%d on %s", first, second, firstScore, secondScore, match.Map)
+	if match.Overtime {
+		result += " after overtime"
+	}
+	return result + "."
+}
+
+// narrateTopFragger names the player with the most kills, preferring fewer deaths
+// and then the name on ties
+func narrateTopFragger(match *models.Match) string {
+	var top *models.Player
+	for i := range match.Teams {
+		for j := range match.Teams[i].Players {
+			player := &match.Teams[i].Players[j]
+			if top == nil || player.Stats.Kills > top.Stats.Kills ||
+				(player.Stats.Kills == top.Stats.Kills && player.Stats.Deaths < top.Stats.Deaths) ||
+				(player.Stats.Kills == top.Stats.Kills && player.Stats.Deaths == top.Stats.Deaths && player.Name < top.Name) {
+				top = player
+			}
+		}
+	}
+	if top == nil || top.Stats.Kills == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%s of %s led the server with %d kills and %d deaths.",
+		top.Name, top.Team, top.Stats.Kills, top.Stats.Deaths)
+}
+
+// roundWinners returns the name of the team that won each round, found from the
+// scores after it, with an empty name where it can't be told
+func roundWinners(match *models.Match) []string {
+	winners := make([]string, len(match.Rounds))
+	previous := map[string]int{}
+	for i, round := range match.Rounds {
+		for _, team := range match.Teams {
+			if round.Scores[team.Name] > previous[team.Name] {
+				winners[i] = team.Name
+			}
+		}
+		if round.Scores != nil {
+			previous = round.Scores
+		}
+	}
+	return winners
+}
+
+// narrateDecisiveRounds describes the longest run of round wins and the round
+// that closed the match
+func narrateDecisiveRounds(match *models.Match, winners []string) string {
+	var parts []string
+
+	bestTeam, bestStart, bestLength := "", 0, 0
+	for i := 0; i < len(winners); {
+		j := i
+		for j < len(winners) && winners[j] == winners[i] {
+			j++
+		}
+		if winners[i] != "" && j-i > bestLength {
+			bestTeam, bestStart, bestLength = winners[i], i, j-i
+		}
+		i = j
+	}
+	if bestLength >= minNarrativeStreak {
+		parts = append(parts, fmt.Sprintf("%s won %d rounds in a row from round %d to %d",
+			bestTeam, bestLength, match.Rounds[bestStart].RoundNumber, match.Rounds[bestStart+bestLength-1].RoundNumber))
+	}
+
+	if last := len(winners) - 1; last >= 0 && winners[last] != "" {
+		round := match.Rounds[last]
+		closing := fmt.Sprintf("%s closed it out in round %d", winners[last], round.RoundNumber)
+		if reason := narrateReason(round.Reason); reason != "" {
+			closing += " " + reason
+		}
+		parts = append(parts, closing)
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.Join(parts, ", and ") + "."
+}
+
+// narrateReason describes how a round was won
+func narrateReason(reason string) string {
+	switch reason {
+	case "elimination":
+		return "by elimination"
+	case "bomb_defused":
+		return "with a bomb defuse"
+	case "bomb_exploded":
+		return "with the bomb exploding"
+	case "time":
+		return "as time ran out"
+	}
+	return ""
+}
+
+// narrateClutches lists the clutches of the match
+func narrateClutches(match *models.Match, winners []string) string {
+	clutches := findClutches(match, winners)
+	if len(clutches) == 0 {
+		return ""
+	}
+
+	descriptions := make([]string, 0, len(clutches))
+	for _, c := range clutches {
+		descriptions = append(descriptions, fmt.Sprintf("%s won a 1v%d in round %d", c.Player, c.Opponents, c.Round))
+	}
+	return "Clutches: " + strings.Join(descriptions, "; ") + "."
+}
+
+// findClutches replays the kills of every round to find the rounds a team won
+// after being left with one player against at least two opponents. Teams are
+// told apart by name, as sides switch at halftime.
+func findClutches(match *models.Match, winners []string) []clutch {
+	var clutches []clutch
+	for i, round := range match.Rounds {
+		winner := winners[i]
+		if winner == "" {
+			continue
+		}
+
+		alive := map[string]map[string]bool{}
+		for _, team := range match.Teams {
+			alive[team.Name] = map[string]bool{}
+			for _, player := range team.Players {
+				alive[team.Name][player.Name] = true
+			}
+		}
+
+		var found *clutch
+		for _, event := range round.Events {
+			kill, ok := event.(*models.KillEvent)
+			if !ok || kill.Victim == nil || alive[kill.Victim.Team] == nil {
+				continue
+			}
+			delete(alive[kill.Victim.Team], kill.Victim.Name)
+
+			if found != nil || len(alive[winner]) != 1 {
+				continue
+			}
+			opponents := 0
+			for team, players := range alive {
+				if team != winner {
+					opponents += len(players)
+				}
+			}
+			if opponents >= 2 {
+				for name := range alive[winner] {
+					found = &clutch{Round: round.RoundNumber, Player: name, Opponents: opponents}
+				}
+			}
+		}
+
+		if found != nil {
+			clutches = append(clutches, *found)
+		}
+	}
+	return clutches
+}
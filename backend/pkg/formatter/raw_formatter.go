@@ -0,0 +1,177 @@
+package formatter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/noueii/nocs-log-generator/backend/pkg/models"
+)
+
+// RawTypeField is the discriminator added to every raw event object
+const RawTypeField = "__type"
+
+// rawEventTypes maps raw event discriminators to empty events of that type
+var rawEventTypes = map[string]func() models.GameEvent{
+	"KillEvent":             func() models.GameEvent { return &models.KillEvent{} },
+	"RoundStartEvent":       func() models.GameEvent { return &models.RoundStartEvent{} },
+	"RoundEndEvent":         func() models.GameEvent { return &models.RoundEndEvent{} },
+	"BombPlantEvent":        func() models.GameEvent { return &models.BombPlantEvent{} },
+	"BombDefuseEvent":       func() models.GameEvent { return &models.BombDefuseEvent{} },
+	"BombExplodeEvent":      func() models.GameEvent { return &models.BombExplodeEvent{} },
+	"BombDropEvent":         func() models.GameEvent { return &models.BombDropEvent{} },
+	"BombPickupEvent":       func() models.GameEvent { return &models.BombPickupEvent{} },
+	"WeaponPickupEvent":     func() models.GameEvent { return &models.WeaponPickupEvent{} },
+	"PlayerPositionEvent":   func() models.GameEvent { return &models.PlayerPositionEvent{} },
+	"PlayerHurtEvent":       func() models.GameEvent { return &models.PlayerHurtEvent{} },
+	"PlayerConnectEvent":    func() models.GameEvent { return &models.PlayerConnectEvent{} },
+	"PlayerEnterEvent":      func() models.GameEvent { return &models.PlayerEnterEvent{} },
+	"NameChangeEvent":       func() models.GameEvent { return &models.NameChangeEvent{} },
+	"PlayerDisconnectEvent": func() models.GameEvent { return &models.PlayerDisconnectEvent{} },
+	"ItemPurchaseEvent":     func() models.GameEvent { return &models.ItemPurchaseEvent{} },
+	"PurchaseRejectedEvent": func() models.GameEvent { return &models.PurchaseRejectedEvent{} },
+	"GrenadeThrowEvent":     func() models.GameEvent { return &models.GrenadeThrowEvent{} },
+	"WeaponFireEvent":       func() models.GameEvent { return &models.WeaponFireEvent{} },
+	"FlashbangEvent":        func() models.GameEvent { return &models.FlashbangEvent{} },
+	"ChatEvent":             func() models.GameEvent { return &models.ChatEvent{} },
+	"ObserverChatEvent":     func() models.GameEvent { return &models.ObserverChatEvent{} },
+	"TeamSwitchEvent":       func() models.GameEvent { return &models.TeamSwitchEvent{} },
+	"ServerCommandEvent":    func() models.GameEvent { return &models.ServerCommandEvent{} },
+	"ServerStatusEvent":     func() models.GameEvent { return &models.ServerStatusEvent{} },
+	"MVPEvent":              func() models.GameEvent { return &models.MVPEvent{} },
+}
+
+// RawEventTypes returns the supported raw event discriminators in sorted order
+func RawEventTypes() []string {
+	types := make([]string, 0, len(rawEventTypes))
+	for name := range rawEventTypes {
+		types = append(types, name)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// FormatRawEvents encodes events as a JSON array of their raw data, each tagged
+// with its event type in the __type field so it can be parsed back
+func FormatRawEvents(events []models.GameEvent) ([]byte, error) {
+	rawEvents := make([]map[string]json.RawMessage, 0, len(events))
+
+	for i, event := range events {
+		data, err := event.ToJSON()
+		if err != nil {
+			return nil, fmt.Errorf("event %d: error converting event to raw JSON: %w", i, err)
+		}
+
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, fmt.Errorf("event %d: error parsing event JSON: %w", i, err)
+		}
+
+		typeName, err := json.Marshal(reflect.TypeOf(event).Elem().Name())
+		if err != nil {
+			return nil, fmt.Errorf("event %d: error encoding event type: %w", i, err)
+		}
+		fields[RawTypeField] = typeName
+
+		rawEvents = append(rawEvents, fields)
+	}
+
+	return json.Marshal(rawEvents)
+}
+
+// ParseRawEvents decodes a JSON array produced by FormatRawEvents back into events.
+// Every event needs a known __type and the players its log line refers to.
+func ParseRawEvents(data []byte) ([]models.GameEvent, error) {
+	var rawEvents []json.RawMessage
+	if err := json.Unmarshal(data, &rawEvents); err != nil {
+		return nil, fmt.Errorf("raw events must be a JSON array: %w", err)
+	}
+
+	events := make([]models.GameEvent, 0, len(rawEvents))
+	for i, raw := range rawEvents {
+		var discriminator struct {
+			Type string `json:"__type"`
+		}
+		if err := json.Unmarshal(raw, &discriminator); err != nil {
+			return nil, fmt.Errorf("event %d: %w", i, err)
+		}
+		if discriminator.Type == "" {
+			return nil, fmt.Errorf("event %d: missing %s field", i, RawTypeField)
+		}
+
+		newEvent, ok := rawEventTypes[discriminator.Type]
+		if !ok {
+			return nil, fmt.Errorf("event %d: unknown event type %q (must be one of %s)", i, discriminator.Type, strings.Join(RawEventTypes(), ", "))
+		}
+
+		event := newEvent()
+		if err := json.Unmarshal(raw, event); err != nil {
+			return nil, fmt.Errorf("event %d (%s): %w", i, discriminator.Type, err)
+		}
+		if err := validateRawEventPlayers(event); err != nil {
+			return nil, fmt.Errorf("event %d (%s): %w", i, discriminator.Type, err)
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// validateRawEventPlayers checks that every player field without omitempty is set,
+// since log lines for those events cannot be rendered without them
+func validateRawEventPlayers(event models.GameEvent) error {
+	value := reflect.ValueOf(event).Elem()
+	playerType := reflect.TypeOf(&models.Player{})
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.Type != playerType {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if strings.Contains(tag, "omitempty") {
+			continue
+		}
+		if value.Field(i).IsNil() {
+			return fmt.Errorf("missing required player field %q", strings.Split(tag, ",")[0])
+		}
+	}
+
+	return nil
+}
+
+// FormatEventsAsCSV formats events as CSV with one row per event
+func (f *LogFormatter) FormatEventsAsCSV(events []models.GameEvent) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	if err := writer.Write([]string{"timestamp", "tick", "round", "type", "log_line"}); err != nil {
+		return nil, fmt.Errorf("error writing CSV header: %w", err)
+	}
+	for _, event := range events {
+		record := []string{
+			event.GetTimestamp().In(f.timeZone).Format(time.RFC3339),
+			strconv.FormatInt(event.GetTick(), 10),
+			strconv.Itoa(event.GetRound()),
+			event.GetType(),
+			f.FormatEvent(event),
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, fmt.Errorf("error writing CSV record: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("error writing CSV: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -10,15 +10,48 @@ import (
 
 // EconomyManager handles team and player money management
 type EconomyManager struct {
-	rng           *rand.Rand
-	economySystem *models.EconomyManager
+	rng              *rand.Rand
+	economySystem    *models.EconomyManager
+	maxMoney         int
+	antiEcoThreshold int
 }
 
 // NewEconomyManager creates a new economy manager
 func NewEconomyManager(rng *rand.Rand) *EconomyManager {
 	return &EconomyManager{
-		rng:           rng,
-		economySystem: models.NewEconomyManager(),
+		rng:              rng,
+		economySystem:    models.NewEconomyManager(),
+		maxMoney:         models.DefaultMatchConfig().MaxMoney,
+		antiEcoThreshold: models.DefaultAntiEcoThreshold,
+	}
+}
+
+// SetAntiEcoThreshold sets the opponent team equipment value under which a team
+// plays an anti-eco buy against them
+func (em *EconomyManager) SetAntiEcoThreshold(threshold int) {
+	if threshold > 0 {
+		em.antiEcoThreshold = threshold
+	}
+}
+
+// SetMaxMoney sets the most money a player can hold
+func (em *EconomyManager) SetMaxMoney(maxMoney int) {
+	if maxMoney > 0 {
+		em.maxMoney = maxMoney
+	}
+}
+
+// SetKillReward sets the kill reward for a weapon or weapon type such as knife or grenade
+func (em *EconomyManager) SetKillReward(weapon string, reward int) {
+	if reward > 0 {
+		em.economySystem.KillRewards[weapon] = reward
+	}
+}
+
+// SetLossBonusLadder sets the loss bonus paid for each consecutive loss
+func (em *EconomyManager) SetLossBonusLadder(ladder []int) {
+	if len(ladder) > 0 {
+		em.economySystem.LossBonusLadder = ladder
 	}
 }
 
@@ -27,7 +60,8 @@ func (em *EconomyManager) HandleRoundEnd(match *models.Match, state *models.Matc
 	// Convert side winner to team name
 	var winningTeamName, losingTeamName string
 	
-	if result.Winner == "CT" || result.Winner == "COUNTER-TERRORIST" {
+	winner := models.NormalizeSide(result.Winner)
+	if winner == "CT" {
 		winningTeam := em.getTeamBySide(match, "CT")
 		losingTeam := em.getTeamBySide(match, "TERRORIST")
 		if winningTeam != nil && losingTeam != nil {
@@ -36,7 +70,7 @@ func (em *EconomyManager) HandleRoundEnd(match *models.Match, state *models.Matc
 		} else {
 			return fmt.Errorf("could not find CT/T teams for economy processing")
 		}
-	} else if result.Winner == "TERRORIST" {
+	} else if winner == "TERRORIST" {
 		winningTeam := em.getTeamBySide(match, "TERRORIST") 
 		losingTeam := em.getTeamBySide(match, "CT")
 		if winningTeam != nil && losingTeam != nil {
@@ -65,18 +99,20 @@ func (em *EconomyManager) HandleRoundEnd(match *models.Match, state *models.Matc
 	}
 	
 	// Process win bonuses
-	em.awardWinBonus(winningTeam, state, result.Reason, events)
+	if err := em.awardWinBonus(winningTeam, state, result.Reason, events); err != nil {
+		return err
+	}
 	
 	// Process loss bonuses
 	em.awardLossBonus(losingTeam, state)
 	
 	// Process kill rewards
-	em.awardKillRewards(match, events)
+	em.awardKillRewards(match, state, events)
 	
 	// Process objective rewards
 	em.awardObjectiveRewards(match, events)
 	
-	// Cap money at maximum
+	// Keep money between zero and the configured maximum
 	em.capPlayerMoney(match, state)
 	
 	// Update team economy statistics
@@ -91,7 +127,11 @@ func (em *EconomyManager) PlanTeamBuys(match *models.Match, state *models.MatchS
 	
 	for _, team := range match.Teams {
 		teamEconomy := state.TeamEconomies[team.Name]
-		buyType := em.determineBuyStrategy(teamEconomy, roundNum, team.Side)
+		var opponentEconomy *models.TeamEconomy
+		if opponent := em.getOpponentTeam(match, team.Name); opponent != nil {
+			opponentEconomy = state.TeamEconomies[opponent.Name]
+		}
+		buyType := em.determineBuyStrategy(teamEconomy, opponentEconomy, roundNum, match.HalftimeRound(), team.Side)
 		teamBuyTypes[team.Name] = buyType
 	}
 	
@@ -130,9 +170,12 @@ func (em *EconomyManager) ExecutePlayerBuy(player *models.Player, playerState *m
 	return purchases, nil
 }
 
-// awardWinBonus gives money to the winning team
-func (em *EconomyManager) awardWinBonus(team *models.Team, state *models.MatchState, reason string, events []models.GameEvent) {
-	bonus := em.economySystem.CalculateWinBonus(reason)
+// awardWinBonus gives money to the winning team based on its side and the win reason
+func (em *EconomyManager) awardWinBonus(team *models.Team, state *models.MatchState, reason string, events []models.GameEvent) error {
+	bonus, err := em.economySystem.CalculateRoundWinBonus(team.Side, reason)
+	if err != nil {
+		return fmt.Errorf("could not award win bonus to %s: %w", team.Name, err)
+	}
 	
 	for i := range team.Players {
 		playerState := state.PlayerStates[team.Players[i].Name]
@@ -143,6 +186,7 @@ func (em *EconomyManager) awardWinBonus(team *models.Team, state *models.MatchSt
 	// Reset loss streak
 	teamEconomy := state.TeamEconomies[team.Name]
 	teamEconomy.ConsecutiveLosses = 0
+	return nil
 }
 
 // awardLossBonus gives loss bonus to the losing team
@@ -160,19 +204,27 @@ func (em *EconomyManager) awardLossBonus(team *models.Team, state *models.MatchS
 	}
 }
 
-// awardKillRewards gives money for kills
-func (em *EconomyManager) awardKillRewards(match *models.Match, events []models.GameEvent) {
+// awardKillRewards credits the weapon's kill reward for every enemy killed this round.
+// This is the only place kill rewards are paid: each kill adds its reward once to the
+// attacker's money and to their money earned. Team kills pay nothing.
+func (em *EconomyManager) awardKillRewards(match *models.Match, state *models.MatchState, events []models.GameEvent) {
 	for _, event := range events {
-		if killEvent, ok := event.(*models.KillEvent); ok {
-			reward := em.economySystem.CalculateKillReward(killEvent.Weapon)
-			
-			// Find the attacker in the match and award money
-			attacker := em.findPlayerInMatch(match, killEvent.Attacker.Name)
-			if attacker != nil {
-				// Money is already managed in player state, but track in economy
-				attacker.Economy.MoneyEarned += reward
-			}
+		killEvent, ok := event.(*models.KillEvent)
+		if !ok || killEvent.Attacker == nil || killEvent.Victim == nil {
+			continue
+		}
+		if killEvent.Attacker.Team == killEvent.Victim.Team {
+			continue
 		}
+		
+		attacker := em.findPlayerInMatch(match, killEvent.Attacker.Name)
+		playerState := state.PlayerStates[killEvent.Attacker.Name]
+		if attacker == nil || playerState == nil {
+			continue
+		}
+		reward := em.economySystem.CalculateKillReward(killEvent.Weapon)
+		playerState.Money += reward
+		attacker.Economy.MoneyEarned += reward
 	}
 }
 
@@ -199,18 +251,22 @@ func (em *EconomyManager) awardObjectiveRewards(match *models.Match, events []mo
 	}
 }
 
-// determineBuyStrategy decides what type of buy the team should make
-func (em *EconomyManager) determineBuyStrategy(economy *models.TeamEconomy, roundNum int, side string) string {
+// determineBuyStrategy decides what type of buy the team should make. The opponent's
+// equipment tells whether they are saving, which calls for an anti-eco buy; it may
+// be nil when the opponent is unknown.
+func (em *EconomyManager) determineBuyStrategy(economy, opponent *models.TeamEconomy, roundNum, halftimeRound int, side string) string {
 	avgMoney := economy.AverageMoney
 	
 	// Consider various factors
-	isImportantRound := em.isImportantRound(roundNum)
+	isImportantRound := em.isImportantRound(roundNum, halftimeRound)
+	isPistolRound := roundNum == 1 || roundNum == halftimeRound+1
 	hasGoodEconomy := avgMoney >= 4000
 	hasOkayEconomy := avgMoney >= 2500
-	consecutiveLosses := economy.ConsecutiveLosses
 	
-	// Anti-eco after enemy eco
-	if consecutiveLosses >= 2 && avgMoney >= 2000 && isImportantRound {
+	// Anti-eco against an enemy eco, never against a full buy. Everyone is on
+	// pistols in a pistol round, so that's not an eco.
+	opponentSaving := opponent != nil && opponent.EquipmentValue < em.antiEcoThreshold
+	if opponentSaving && !isPistolRound && avgMoney >= 2000 {
 		return "anti_eco"
 	}
 	
@@ -287,6 +343,7 @@ func (em *EconomyManager) generateFullBuy(side, role string, money int) []string
 	// Utilities
 	buyList = append(buyList, "smokegrenade")
 	buyList = append(buyList, "flashbang")
+	buyList = append(buyList, models.FireGrenadeForSide(side))
 	buyList = append(buyList, "hegrenade")
 	
 	// Defuse kit for CT
@@ -348,6 +405,7 @@ func (em *EconomyManager) generateAntiEcoBuy(side, role string, money int) []str
 	// More grenades for anti-eco
 	buyList = append(buyList, "hegrenade")
 	buyList = append(buyList, "flashbang")
+	buyList = append(buyList, models.FireGrenadeForSide(side))
 	
 	return buyList
 }
@@ -423,22 +481,20 @@ func (em *EconomyManager) purchaseItem(player *models.Player, playerState *model
 		switch info.Type {
 		case "armor":
 			if item == "vesthelm" {
-				playerState.Armor = 100
-				playerState.HasHelmet = true
+				playerState.EquipArmor(true)
 			} else if item == "vest" {
-				playerState.Armor = 100
+				playerState.EquipArmor(false)
 			}
 		case "utility":
 			if item == "defuser" {
 				playerState.HasDefuseKit = true
 			}
 		case "grenade":
-			if len(playerState.Grenades) < 4 { // Max 4 grenades
-				grenade := models.Grenade{
-					Type:  info.Name,
-					Price: info.Price,
-				}
-				playerState.Grenades = append(playerState.Grenades, grenade)
+			if info.Team != "both" && models.NormalizeSide(info.Team) != models.NormalizeSide(player.Side) {
+				return fmt.Errorf("%s cannot be bought on the %s side", info.Name, player.Side)
+			}
+			if !playerState.AddGrenade(models.Grenade{Type: info.Name, Price: info.Price}) {
+				return fmt.Errorf("cannot carry another %s", info.Name)
 			}
 		}
 	} else {
@@ -459,14 +515,10 @@ func (em *EconomyManager) getItemCost(item string) int {
 }
 
 func (em *EconomyManager) capPlayerMoney(match *models.Match, state *models.MatchState) {
-	maxMoney := 16000 // CS2 money cap
-	
 	for _, team := range match.Teams {
 		for _, player := range team.Players {
 			if playerState := state.PlayerStates[player.Name]; playerState != nil {
-				if playerState.Money > maxMoney {
-					playerState.Money = maxMoney
-				}
+				playerState.Money = clampMoney(playerState.Money, em.maxMoney)
 			}
 		}
 	}
@@ -477,17 +529,21 @@ func (em *EconomyManager) updateTeamEconomies(match *models.Match, state *models
 		teamEconomy := state.TeamEconomies[team.Name]
 		totalMoney := 0
 		equipmentValue := 0
+		pickedUpValue := 0
+		players := 0
 		
 		for _, player := range team.Players {
 			if playerState := state.PlayerStates[player.Name]; playerState != nil {
+				players++
 				totalMoney += playerState.Money
 				equipmentValue += em.calculateEquipmentValue(playerState)
+				pickedUpValue += playerState.PickedUpValue()
 			}
 		}
 		
-		teamEconomy.TotalMoney = totalMoney
-		teamEconomy.AverageMoney = totalMoney / len(team.Players)
+		teamEconomy.SetMoney(totalMoney, players)
 		teamEconomy.EquipmentValue = equipmentValue
+		teamEconomy.PickedUpValue = pickedUpValue
 	}
 }
 
@@ -528,7 +584,7 @@ func (em *EconomyManager) getTeamByName(match *models.Match, name string) *model
 
 func (em *EconomyManager) getTeamBySide(match *models.Match, side string) *models.Team {
 	for i := range match.Teams {
-		if match.Teams[i].Side == side {
+		if models.NormalizeSide(match.Teams[i].Side) == models.NormalizeSide(side) {
 			return &match.Teams[i]
 		}
 	}
@@ -544,6 +600,16 @@ func (em *EconomyManager) getLosingTeam(match *models.Match, winnerName string)
 	return nil
 }
 
+// getOpponentTeam returns the team playing against the named one
+func (em *EconomyManager) getOpponentTeam(match *models.Match, teamName string) *models.Team {
+	for i := range match.Teams {
+		if match.Teams[i].Name != teamName {
+			return &match.Teams[i]
+		}
+	}
+	return nil
+}
+
 func (em *EconomyManager) findPlayerInMatch(match *models.Match, playerName string) *models.Player {
 	for i := range match.Teams {
 		for j := range match.Teams[i].Players {
@@ -555,19 +621,26 @@ func (em *EconomyManager) findPlayerInMatch(match *models.Match, playerName stri
 	return nil
 }
 
-func (em *EconomyManager) isImportantRound(roundNum int) bool {
-	// Pistol rounds (1st and 16th in MR12)
-	if roundNum == 1 || roundNum == 13 {
+// isImportantRound reports pistol and anti-eco rounds of either half and every round
+// from the last round of the first half on
+func (em *EconomyManager) isImportantRound(roundNum, halftimeRound int) bool {
+	roundOfHalf := roundNum
+	if roundNum > halftimeRound {
+		roundOfHalf = roundNum - halftimeRound
+	}
+	
+	// Pistol rounds (1st and 13th in MR12, 1st and 16th in MR15)
+	if roundOfHalf == 1 {
 		return true
 	}
 	
-	// Anti-eco rounds (2nd, 3rd, 17th, 18th)
-	if roundNum == 2 || roundNum == 3 || roundNum == 14 || roundNum == 15 {
+	// Anti-eco rounds (the two rounds after each pistol round)
+	if roundOfHalf == 2 || roundOfHalf == 3 {
 		return true
 	}
 	
-	// Match point rounds
-	if roundNum >= 12 || roundNum >= 24 { // Near end of half or match
+	// Near end of half or match
+	if roundNum >= halftimeRound {
 		return true
 	}
 	
@@ -581,7 +654,7 @@ func (em *EconomyManager) CalculateTeamEconomyRating(team *models.Team, teamEcon
 	equipValue := float64(teamEconomy.EquipmentValue)
 	
 	// Normalize values (0.0 to 1.0)
-	moneyRating := avgMoney / 16000.0 // Max money
+	moneyRating := avgMoney / float64(em.maxMoney)
 	if moneyRating > 1.0 {
 		moneyRating = 1.0
 	}
@@ -1,421 +1,671 @@
 package generator
 
 import (
-	"fmt"
 	"math"
 	"math/rand"
+	"sort"
+	"time"
 
 	"github.com/noueii/nocs-log-generator/backend/pkg/models"
 )
 
+// utilityThrowProbability is the chance that a grenade a player holds is thrown during the round
+const utilityThrowProbability = 0.8
+
 // EventGenerator creates realistic CS2 events
 type EventGenerator struct {
 	rng    *rand.Rand
 	config *models.MatchConfig
+	
+	// Damage model
+	damageVariance float64
+	lethalityBias  float64
+	
+	// Assist crediting
+	assistDamageThreshold float64
+	damageTaken           map[string][]damageShare // victim name -> damage dealt to them this round
+	
+	// Engagements of the current round, for pacing the damage pass
+	engagements []engagementWindow
+	
+	// Last entity index handed to a flashbang
+	entityIndex int
+}
+
+// damageShare is the damage one attacker dealt to a victim during a round
+type damageShare struct {
+	attacker *models.Player
+	damage   int
+}
+
+// engagementWindow is the span of ticks a combat engagement lasted and the players on each side of it
+type engagementWindow struct {
+	startTick int64
+	endTick   int64
+	ct        []*models.Player
+	t         []*models.Player
 }
 
+// firstEntityIndex is the entity index before the first flashbang of a match;
+// lower indexes belong to players and map entities
+const firstEntityIndex = 100
+
+// flashbangFuse is how long a flashbang takes to detonate after being thrown
+const flashbangFuse = 2 * time.Second
+
+// Flashbang model: blinds last up to flashMaxDuration seconds when looking straight
+// at a close flash, a facing-away player keeps flashAwayShare of that, and nobody
+// beyond flashRange units is blinded. Flashes pop between flashMinThrow and
+// flashMaxThrow units from the thrower.
+const (
+	flashMaxDuration = 4.87
+	flashMinDuration = 0.1
+	flashAwayShare   = 0.15
+	flashRange       = 1500.0
+	flashMinThrow    = 200.0
+	flashMaxThrow    = 1000.0
+)
+
+// playerMaxHealth is the health a player spawns with
+const playerMaxHealth = 100
+
+// armorAbsorption is the share of a hit's damage that armor absorbs while it lasts
+const armorAbsorption = 0.5
+
 // NewEventGenerator creates a new event generator
 func NewEventGenerator(rng *rand.Rand, config *models.MatchConfig) *EventGenerator {
 	return &EventGenerator{
-		rng:    rng,
-		config: config,
+		rng:                   rng,
+		config:                config,
+		damageVariance:        models.DefaultSimulationConfig().DamageVariance,
+		assistDamageThreshold: models.DefaultSimulationConfig().AssistDamageThreshold,
+		damageTaken:           make(map[string][]damageShare),
+		entityIndex:           firstEntityIndex,
 	}
 }
 
-// GenerateRoundEvents creates all events for a round including detailed combat simulation
-func (eg *EventGenerator) GenerateRoundEvents(match *models.Match, state *models.MatchState, roundNum int, strategy *RoundStrategy) ([]models.GameEvent, error) {
-	var events []models.GameEvent
-	
-	// Add round start event
-	startEvent := eg.createRoundStartEvent(match, state, roundNum)
-	events = append(events, startEvent)
-	
-	// Generate spawn events
-	spawnEvents := eg.generateSpawnEvents(match, state, roundNum)
-	events = append(events, spawnEvents...)
-	
-	// Generate buy phase events (these come from round simulator)
-	// They should already be created, so we don't duplicate them here
-	
-	// Generate combat events based on strategy
-	combatEvents := eg.generateCombatEvents(match, state, roundNum, strategy)
-	events = append(events, combatEvents...)
-	
-	// Generate utility usage events
-	utilityEvents := eg.generateUtilityEvents(match, state, roundNum, strategy)
-	events = append(events, utilityEvents...)
-	
-	// Generate damage events (separate from kills)
-	damageEvents := eg.generateDamageEvents(match, state, roundNum, strategy)
-	events = append(events, damageEvents...)
-	
-	// Generate weapon fire events (optional, can be very verbose)
-	if eg.config.VerboseLogging {
-		fireEvents := eg.generateWeaponFireEvents(match, state, roundNum, strategy)
-		events = append(events, fireEvents...)
+// SetDamageModel sets how far hits stray from the weapon's base damage, as a share
+// of it, and the lethality bias skewing them towards the low or high end. Values
+// out of range are ignored.
+func (eg *EventGenerator) SetDamageModel(variance, lethalityBias float64) {
+	if variance >= 0 && variance <= 1 {
+		eg.damageVariance = variance
+	}
+	if lethalityBias >= -1 && lethalityBias <= 1 {
+		eg.lethalityBias = lethalityBias
 	}
-	
-	// Sort events by timestamp/tick
-	eg.sortEventsByTime(events)
-	
-	return events, nil
 }
 
-// createRoundStartEvent creates the round start event
-func (eg *EventGenerator) createRoundStartEvent(match *models.Match, state *models.MatchState, roundNum int) models.GameEvent {
-	ctTeam := eg.getTeamBySide(match, "CT")
-	tTeam := eg.getTeamBySide(match, "TERRORIST")
-	
-	return &models.RoundStartEvent{
-		BaseEvent:     models.NewBaseEvent("round_start", 0, roundNum),
-		CTScore:       state.Scores[ctTeam.Name],
-		TScore:        state.Scores[tTeam.Name],
-		CTPlayers:     len(ctTeam.Players),
-		TPlayers:      len(tTeam.Players),
-		TeamEconomies: eg.copyTeamEconomies(state.TeamEconomies),
+// SetAssistDamageThreshold sets the share of a victim's health a teammate must deal
+// to be credited an assist. Values outside (0, 1] are ignored.
+func (eg *EventGenerator) SetAssistDamageThreshold(threshold float64) {
+	if threshold > 0 && threshold <= 1 {
+		eg.assistDamageThreshold = threshold
 	}
 }
 
-// generateSpawnEvents creates player spawn events
-func (eg *EventGenerator) generateSpawnEvents(match *models.Match, state *models.MatchState, roundNum int) []models.GameEvent {
-	var events []models.GameEvent
-	tick := int64(eg.config.TickRate) // 1 second after round start
-	
-	for _, team := range match.Teams {
-		for _, player := range team.Players {
-			// Players don't have explicit spawn events in standard CS2 logs
-			// but we can create them for detailed simulation
-			if eg.config.DetailedEvents {
-				spawnEvent := &models.ChatEvent{
-					BaseEvent: models.NewBaseEvent("player_spawn", tick, roundNum),
-					Player:    &player,
-					Message:   fmt.Sprintf("%s spawned", player.Name),
-					Team:      false,
-					Dead:      false,
-				}
-				events = append(events, spawnEvent)
+// maxEngagementShots bounds a simulated engagement in case neither side can finish the other
+const maxEngagementShots = 1000
+
+// SimulateEngagement plays out a fight between attackers and defenders until one side
+// is eliminated and returns its hurt and kill events, to calibrate the damage and
+// headshot models without running a full match. Players start at full health with
+// the armor in their state, and the same seed always plays the same fight.
+func (eg *EventGenerator) SimulateEngagement(attackers, defenders []*models.Player, seed int64) []models.GameEvent {
+	sim := *eg
+	sim.rng = rand.New(rand.NewSource(seed))
+	sim.damageTaken = make(map[string][]damageShare)
+	
+	state := &models.MatchState{PlayerStates: make(map[string]*models.PlayerState)}
+	for _, players := range [][]*models.Player{attackers, defenders} {
+		for _, player := range players {
+			state.PlayerStates[player.Name] = &models.PlayerState{
+				IsAlive:   true,
+				Health:    playerMaxHealth,
+				Armor:     player.State.Armor,
+				HasHelmet: player.State.HasHelmet,
 			}
-			tick += int64(eg.config.TickRate / 10) // Slight delay between spawns
 		}
 	}
 	
-	return events
-}
-
-// generateCombatEvents creates combat-related events
-func (eg *EventGenerator) generateCombatEvents(match *models.Match, state *models.MatchState, roundNum int, strategy *RoundStrategy) []models.GameEvent {
 	var events []models.GameEvent
-	
-	// Number of combat engagements based on strategy intensity
-	baseEngagements := 3
-	maxEngagements := int(float64(baseEngagements) * (1.0 + strategy.Intensity))
-	numEngagements := eg.rng.Intn(maxEngagements-baseEngagements) + baseEngagements
-	
-	roundDuration := int64(115 * eg.config.TickRate) // 115 seconds
-	
-	for i := 0; i < numEngagements; i++ {
-		// Distribute engagements throughout the round
-		engagementTime := int64(float64(i+1) / float64(numEngagements+1) * float64(roundDuration))
-		
-		// Add some randomness to engagement timing
-		randomOffset := int64(eg.rng.Intn(20*eg.config.TickRate)) - int64(10*eg.config.TickRate) // ±10 seconds
-		engagementTime += randomOffset
+	tick := int64(0)
+	for shots := 0; shots < maxEngagementShots && len(attackers) > 0 && len(defenders) > 0; shots++ {
+		shooters, targets := attackers, defenders
+		if sim.rng.Float64() >= 0.5 {
+			shooters, targets = defenders, attackers
+		}
+		attacker := shooters[sim.rng.Intn(len(shooters))]
+		victim := targets[sim.rng.Intn(len(targets))]
 		
-		if engagementTime < 0 {
-			engagementTime = int64(5 * eg.config.TickRate) // Minimum 5 seconds into round
+		shotEvents, killed := sim.shoot(state, attacker, victim, tick, 0)
+		events = append(events, shotEvents...)
+		if killed {
+			state.PlayerStates[victim.Name].IsAlive = false
+			attackers = sim.removePlayerFromList(attackers, victim)
+			defenders = sim.removePlayerFromList(defenders, victim)
 		}
 		
-		// Create engagement
-		engagementEvents := eg.generateEngagement(match, state, roundNum, engagementTime, strategy)
-		events = append(events, engagementEvents...)
+		// Same pace as engagements in a round, 0.5-2 seconds between shots
+		tick += int64(sim.rng.Intn(int(1.5*float64(sim.config.TickRate)))) + int64(0.5*float64(sim.config.TickRate))
 	}
 	
 	return events
 }
 
-// generateEngagement creates a single combat engagement
-func (eg *EventGenerator) generateEngagement(match *models.Match, state *models.MatchState, roundNum int, startTick int64, strategy *RoundStrategy) []models.GameEvent {
-	var events []models.GameEvent
-	
-	ctPlayers := eg.getAlivePlayers(match, state, "CT")
-	tPlayers := eg.getAlivePlayers(match, state, "TERRORIST")
+// engagementLead is how long before a kill its fight starts; the hits leading up to
+// a kill in a live round land within it
+const engagementLead = 4 * time.Second
+
+// returnFireProbability is the chance that a victim hits their killer before dying
+const returnFireProbability = 0.4
+
+// teammateDamageProbability is the chance that one of the killer's teammates also
+// hits the victim during the fight, which can earn them the assist
+const teammateDamageProbability = 0.5
+
+// roundDetail follows the players of a live round while its kills are filled in with
+// the hits and shots behind them. Players start the round at full health with the
+// armor and position they spawned with, and hold the weapon they would kill with.
+type roundDetail struct {
+	players []*models.Player                     // Every player, in team and roster order
+	state   *models.MatchState                   // The players as the round is replayed, apart from the match state
+	weapons map[string]string                    // player name -> weapon they hold
+	armedAt map[string]int64                     // player name -> first tick they can fire the weapon they hold
+	pickups map[string]*models.WeaponPickupEvent // victim name -> weapon taken from their body
+	blinds  map[string][]flashBlind              // player name -> flashes that blinded them
+}
+
+// flashBlind is how long a flash left a player blind during a live round
+type flashBlind struct {
+	thrower *models.Player
+	from    int64 // Detonation tick
+	until   int64 // Tick the player can see again
+}
+
+// plannedHit is a non-lethal hit of a live round, applied in tick order
+type plannedHit struct {
+	tick     int64
+	attacker *models.Player
+	victim   *models.Player
+}
+
+// beginRound records the players of a live round as it starts, before the round
+// simulator decides its kills and weapons change hands
+func (eg *EventGenerator) beginRound(match *models.Match, state *models.MatchState) *roundDetail {
+	detail := &roundDetail{
+		state:   &models.MatchState{PlayerStates: make(map[string]*models.PlayerState)},
+		weapons: make(map[string]string),
+		armedAt: make(map[string]int64),
+		pickups: make(map[string]*models.WeaponPickupEvent),
+		blinds:  make(map[string][]flashBlind),
+	}
+	for i := range match.Teams {
+		for j := range match.Teams[i].Players {
+			player := &match.Teams[i].Players[j]
+			playerState := state.PlayerStates[player.Name]
+			detail.players = append(detail.players, player)
+			detail.state.PlayerStates[player.Name] = &models.PlayerState{
+				IsAlive:   true,
+				Health:    playerMaxHealth,
+				Armor:     playerState.Armor,
+				HasHelmet: playerState.HasHelmet,
+				Position:  playerState.Position,
+			}
+			detail.weapons[player.Name] = eg.selectWeaponForAttack(state, player)
+		}
+	}
 	
-	if len(ctPlayers) == 0 || len(tPlayers) == 0 {
-		return events
+	// Everyone starts the round looking towards the enemy team, so how long a flash
+	// blinds depends on where it pops relative to them
+	centers := make(map[string]models.Vector3)
+	counts := make(map[string]float64)
+	for _, player := range detail.players {
+		side := models.NormalizeSide(player.Side)
+		position := detail.state.PlayerStates[player.Name].Position
+		center := centers[side]
+		center.X += position.X
+		center.Y += position.Y
+		centers[side] = center
+		counts[side]++
+	}
+	for _, player := range detail.players {
+		enemySide := "TERRORIST"
+		if models.NormalizeSide(player.Side) == "TERRORIST" {
+			enemySide = "CT"
+		}
+		if counts[enemySide] == 0 {
+			continue
+		}
+		playerState := detail.state.PlayerStates[player.Name]
+		dx := centers[enemySide].X/counts[enemySide] - playerState.Position.X
+		dy := centers[enemySide].Y/counts[enemySide] - playerState.Position.Y
+		playerState.ViewAngle = models.Vector3{Y: math.Atan2(dy, dx) * 180 / math.Pi}
+	}
+	return detail
+}
+
+// detailRound fills in the hits behind the kills the round simulator decided. Every
+// kill becomes the killer's lethal hit with the kill's weapon and hitgroup, after the
+// hits traded during the fight, and non-lethal hits paced by the damage pacing
+// setting are added on top. Hits are applied in tick order and only between players
+// alive at the time, and with verbose logging each is preceded by the shots fired
+// for it, from where the attacker stands: players who pick up a dropped bomb move to
+// it. Players throw the grenades they hold while alive. Survivors keep the health
+// and armor they end the round with. The round's events are returned in tick order.
+func (eg *EventGenerator) detailRound(detail *roundDetail, state *models.MatchState, combatEvents, itemEvents []models.GameEvent, result *RoundResult, strategy *RoundStrategy, roundNum int) []models.GameEvent {
+	eg.damageTaken = make(map[string][]damageShare)
+	for _, event := range itemEvents {
+		if pickup, ok := event.(*models.WeaponPickupEvent); ok && pickup.From != nil {
+			detail.pickups[pickup.From.Name] = pickup
+		}
 	}
 	
-	// Determine engagement participants (1-3 players per side)
-	ctParticipants := eg.selectEngagementParticipants(ctPlayers, strategy)
-	tParticipants := eg.selectEngagementParticipants(tPlayers, strategy)
+	var kills []*models.KillEvent
+	for _, event := range combatEvents {
+		if kill, ok := event.(*models.KillEvent); ok && kill.Attacker != nil && kill.Victim != nil {
+			kills = append(kills, kill)
+		}
+	}
+	hits := eg.planKillHits(detail, kills)
+	eg.engagements = eg.killEngagements(kills)
+	endTick := models.DurationToTicks(result.Duration, eg.config.TickRate)
+	hits = append(hits, eg.planDamageHits(detail, endTick, strategy)...)
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].tick < hits[j].tick
+	})
+	
+	deaths := make(map[string]int64)
+	for _, kill := range kills {
+		deaths[kill.Victim.Name] = kill.Tick
+	}
+	events := eg.throwUtility(detail, state, deaths, endTick, roundNum)
 	
-	// Simulate the engagement
-	tick := startTick
-	maxEngagementTicks := int64(10 * eg.config.TickRate) // Max 10 seconds per engagement
+	next := 0
+	for _, event := range combatEvents {
+		for ; next < len(hits) && hits[next].tick < event.GetTick(); next++ {
+			events = append(events, eg.applyHit(detail, hits[next], roundNum)...)
+		}
+		if pickup, ok := event.(*models.BombPickupEvent); ok && detail.state.PlayerStates[pickup.Player.Name] != nil {
+			detail.state.PlayerStates[pickup.Player.Name].Position = pickup.Position // Walked to the dropped bomb
+		}
+		kill, ok := event.(*models.KillEvent)
+		if !ok || kill.Attacker == nil || kill.Victim == nil {
+			events = append(events, event)
+			continue
+		}
+		events = append(events, eg.applyKill(detail, kill, roundNum)...)
+	}
+	for ; next < len(hits); next++ {
+		events = append(events, eg.applyHit(detail, hits[next], roundNum)...)
+	}
 	
-	for tick < startTick+maxEngagementTicks && len(ctParticipants) > 0 && len(tParticipants) > 0 {
-		// Determine who shoots first (based on skill, position, etc.)
-		if eg.rng.Float64() < 0.5 {
-			// CT attacks first
-			if len(ctParticipants) > 0 && len(tParticipants) > 0 {
-				attacker := ctParticipants[eg.rng.Intn(len(ctParticipants))]
-				victim := tParticipants[eg.rng.Intn(len(tParticipants))]
-				
-				if damageEvent := eg.createDamageEvent(attacker, victim, tick, roundNum); damageEvent != nil {
-					events = append(events, damageEvent)
-					
-					// Check if damage results in death
-					if killEvent := eg.checkForKill(attacker, victim, tick, roundNum, damageEvent.(*models.PlayerHurtEvent)); killEvent != nil {
-						events = append(events, killEvent)
-						
-						// Remove dead player from participants
-						tParticipants = eg.removePlayerFromList(tParticipants, victim)
-						state.PlayerStates[victim.Name].IsAlive = false
-						state.PlayerStates[victim.Name].Health = 0
-					}
-				}
-			}
-		} else {
-			// T attacks first
-			if len(tParticipants) > 0 && len(ctParticipants) > 0 {
-				attacker := tParticipants[eg.rng.Intn(len(tParticipants))]
-				victim := ctParticipants[eg.rng.Intn(len(ctParticipants))]
-				
-				if damageEvent := eg.createDamageEvent(attacker, victim, tick, roundNum); damageEvent != nil {
-					events = append(events, damageEvent)
-					
-					// Check if damage results in death
-					if killEvent := eg.checkForKill(attacker, victim, tick, roundNum, damageEvent.(*models.PlayerHurtEvent)); killEvent != nil {
-						events = append(events, killEvent)
-						
-						// Remove dead player from participants
-						ctParticipants = eg.removePlayerFromList(ctParticipants, victim)
-						state.PlayerStates[victim.Name].IsAlive = false
-						state.PlayerStates[victim.Name].Health = 0
-					}
-				}
-			}
+	for name, playerState := range state.PlayerStates {
+		if replayed := detail.state.PlayerStates[name]; replayed != nil && playerState.IsAlive {
+			playerState.Health = replayed.Health
+			playerState.Armor = replayed.Armor
+			playerState.ArmorBroken = playerState.ArmorBroken || replayed.ArmorBroken
 		}
-		
-		// Advance time (0.5-2 seconds between shots)
-		tick += int64(eg.rng.Intn(int(1.5*float64(eg.config.TickRate)))) + int64(0.5*float64(eg.config.TickRate))
 	}
 	
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].GetTick() < events[j].GetTick()
+	})
 	return events
 }
 
-// generateUtilityEvents creates grenade and utility usage events
-func (eg *EventGenerator) generateUtilityEvents(match *models.Match, state *models.MatchState, roundNum int, strategy *RoundStrategy) []models.GameEvent {
-	var events []models.GameEvent
-	
-	// Generate grenade throws based on team economies
-	for _, team := range match.Teams {
-		teamEconomy := state.TeamEconomies[team.Name]
+// planKillHits plans the hits of the fights leading up to each kill: 0-2 hits by the
+// killer and, sometimes, one the victim returns before dying and 1-3 by a teammate
+// of the killer
+func (eg *EventGenerator) planKillHits(detail *roundDetail, kills []*models.KillEvent) []plannedHit {
+	lead := models.DurationToTicks(engagementLead, eg.config.TickRate)
+	
+	var hits []plannedHit
+	for _, kill := range kills {
+		start := kill.Tick - lead
+		if start < 0 {
+			start = 0
+		}
+		if kill.Tick <= start {
+			continue
+		}
 		
-		// More utilities in full buy rounds
-		numUtilities := 0
-		if teamEconomy.AverageMoney > 4000 {
-			numUtilities = 2 + eg.rng.Intn(3) // 2-4 utilities
-		} else if teamEconomy.AverageMoney > 2500 {
-			numUtilities = 1 + eg.rng.Intn(2) // 1-2 utilities
-		} else {
-			numUtilities = eg.rng.Intn(2) // 0-1 utilities
+		for i := eg.rng.Intn(3); i > 0; i-- {
+			hits = append(hits, plannedHit{tick: start + eg.rng.Int63n(kill.Tick-start), attacker: kill.Attacker, victim: kill.Victim})
+		}
+		if eg.rng.Float64() < returnFireProbability {
+			hits = append(hits, plannedHit{tick: start + eg.rng.Int63n(kill.Tick-start), attacker: kill.Victim, victim: kill.Attacker})
+		}
+		
+		var teammates []*models.Player
+		for _, player := range detail.players {
+			if models.NormalizeSide(player.Side) == models.NormalizeSide(kill.Attacker.Side) && player.Name != kill.Attacker.Name {
+				teammates = append(teammates, player)
+			}
+		}
+		if len(teammates) == 0 || eg.rng.Float64() >= teammateDamageProbability {
+			continue
+		}
+		teammate := teammates[eg.rng.Intn(len(teammates))]
+		for i := 1 + eg.rng.Intn(3); i > 0; i-- {
+			hits = append(hits, plannedHit{tick: start + eg.rng.Int63n(kill.Tick-start), attacker: teammate, victim: kill.Victim})
+		}
+	}
+	return hits
+}
+
+// throwUtility has players throw the grenades they hold during a live round, each
+// with utilityThrowProbability, at a random tick while they are alive and early
+// enough for it to go off before the round ends. Thrown grenades leave the player's
+// inventory and the others are kept. Flashbangs blind the enemies alive when they
+// detonate.
+func (eg *EventGenerator) throwUtility(detail *roundDetail, state *models.MatchState, deaths map[string]int64, endTick int64, roundNum int) []models.GameEvent {
+	fuse := models.DurationToTicks(flashbangFuse, eg.config.TickRate)
+	
+	var events []models.GameEvent
+	for _, player := range detail.players {
+		playerState := state.PlayerStates[player.Name]
+		if playerState == nil || len(playerState.Grenades) == 0 {
+			continue
 		}
 		
-		for i := 0; i < numUtilities; i++ {
-			// Select random player from team with grenades
-			playersWithGrenades := eg.getPlayersWithUtility(&team, state)
-			if len(playersWithGrenades) == 0 {
+		kept := make([]models.Grenade, 0, len(playerState.Grenades))
+		for _, grenade := range playerState.Grenades {
+			if eg.rng.Float64() >= utilityThrowProbability {
+				kept = append(kept, grenade)
 				continue
 			}
 			
-			player := playersWithGrenades[eg.rng.Intn(len(playersWithGrenades))]
-			playerState := state.PlayerStates[player.Name]
+			latest := endTick
+			if grenade.Type == "flashbang" {
+				latest -= fuse
+			}
+			if deathTick, dead := deaths[player.Name]; dead && deathTick-1 < latest {
+				latest = deathTick - 1
+			}
+			if latest < 0 {
+				kept = append(kept, grenade)
+				continue
+			}
 			
-			if len(playerState.Grenades) > 0 {
-				grenade := playerState.Grenades[0] // Take first grenade
-				
-				// Generate throw time (20-90 seconds into round)
-				throwTime := int64(20*eg.config.TickRate) + int64(eg.rng.Intn(70*eg.config.TickRate))
-				
-				// Create grenade throw event
-				throwEvent := &models.GrenadeThrowEvent{
-					BaseEvent:   models.NewBaseEvent("grenade_throw", throwTime, roundNum),
-					Player:      player,
-					GrenadeType: grenade.Type,
-					Position:    playerState.Position,
-					Velocity:    models.Vector3{X: float64(eg.rng.Intn(200) - 100), Y: float64(eg.rng.Intn(200) - 100), Z: 50},
-				}
-				events = append(events, throwEvent)
+			throwEvent := &models.GrenadeThrowEvent{
+				BaseEvent:   models.NewBaseEvent("grenade_throw", eg.rng.Int63n(latest+1), roundNum),
+				Player:      player,
+				GrenadeType: grenade.Type,
+				Position:    detail.state.PlayerStates[player.Name].Position,
+				Velocity:    models.Vector3{X: float64(eg.rng.Intn(200) - 100), Y: float64(eg.rng.Intn(200) - 100), Z: 50},
+			}
+			
+			// CS2 logs a flashbang's throw when it detonates, after the blind lines it caused
+			if grenade.Type == "flashbang" {
+				eg.entityIndex++
+				throwEvent.Tick += fuse
+				throwEvent.EntityIndex = eg.entityIndex
 				
-				// Handle specific grenade effects
-				if grenade.Type == "flashbang" {
-					flashEvent := eg.createFlashbangEvent(match, state, player, throwTime, roundNum)
-					if flashEvent != nil {
-						events = append(events, flashEvent)
+				var enemies []*models.Player
+				for _, enemy := range detail.players {
+					deathTick, dead := deaths[enemy.Name]
+					if models.NormalizeSide(enemy.Side) != models.NormalizeSide(player.Side) && (!dead || throwEvent.Tick < deathTick) {
+						enemies = append(enemies, enemy)
 					}
 				}
-				
-				// Remove grenade from player inventory
-				if len(playerState.Grenades) > 1 {
-					playerState.Grenades = playerState.Grenades[1:]
-				} else {
-					playerState.Grenades = []models.Grenade{}
+				if flashEvent := eg.flashEnemies(detail.state, player, enemies, throwEvent.Tick, throwEvent.EntityIndex, roundNum); flashEvent != nil {
+					for i, victim := range flashEvent.Flashed {
+						until := flashEvent.Tick + models.DurationToTicks(time.Duration(flashEvent.Durations[i]*float64(time.Second)), eg.config.TickRate)
+						detail.blinds[victim.Name] = append(detail.blinds[victim.Name], flashBlind{thrower: player, from: flashEvent.Tick, until: until})
+					}
+					events = append(events, flashEvent)
 				}
 			}
+			events = append(events, throwEvent)
 		}
+		playerState.Grenades = kept
 	}
-	
 	return events
 }
 
-// generateDamageEvents creates non-lethal damage events
-func (eg *EventGenerator) generateDamageEvents(match *models.Match, state *models.MatchState, roundNum int, strategy *RoundStrategy) []models.GameEvent {
-	var events []models.GameEvent
-	
-	// Generate additional damage events (near misses, body shots that don't kill, etc.)
-	numDamageEvents := int(float64(10+eg.rng.Intn(15)) * strategy.Intensity) // 10-25 damage events based on intensity
-	roundDuration := int64(115 * eg.config.TickRate)
-	
-	for i := 0; i < numDamageEvents; i++ {
-		// Random time in round
-		eventTime := int64(eg.rng.Intn(int(roundDuration)))
-		
-		ctPlayers := eg.getAlivePlayers(match, state, "CT")
-		tPlayers := eg.getAlivePlayers(match, state, "TERRORIST")
-		
-		if len(ctPlayers) == 0 || len(tPlayers) == 0 {
-			continue
+// killEngagements returns the fights of a live round: the window leading up to each
+// kill with its killer and victim, overlapping windows merged into one fight
+func (eg *EventGenerator) killEngagements(kills []*models.KillEvent) []engagementWindow {
+	lead := models.DurationToTicks(engagementLead, eg.config.TickRate)
+	
+	var windows []engagementWindow
+	for _, kill := range kills {
+		start := kill.Tick - lead
+		if start < 0 {
+			start = 0
 		}
-		
-		// Select random attacker and victim
-		var attacker, victim *models.Player
-		if eg.rng.Float64() < 0.5 {
-			attacker = ctPlayers[eg.rng.Intn(len(ctPlayers))]
-			victim = tPlayers[eg.rng.Intn(len(tPlayers))]
-		} else {
-			attacker = tPlayers[eg.rng.Intn(len(tPlayers))]
-			victim = ctPlayers[eg.rng.Intn(len(ctPlayers))]
+		if n := len(windows); n == 0 || start > windows[n-1].endTick {
+			windows = append(windows, engagementWindow{startTick: start, endTick: kill.Tick})
 		}
-		
-		if damageEvent := eg.createNonLethalDamageEvent(attacker, victim, eventTime, roundNum); damageEvent != nil {
-			events = append(events, damageEvent)
+		window := &windows[len(windows)-1]
+		if kill.Tick > window.endTick {
+			window.endTick = kill.Tick
+		}
+		for _, player := range []*models.Player{kill.Attacker, kill.Victim} {
+			if models.NormalizeSide(player.Side) == "CT" {
+				window.ct = appendPlayerOnce(window.ct, player)
+			} else {
+				window.t = appendPlayerOnce(window.t, player)
+			}
 		}
 	}
-	
-	return events
+	return windows
 }
 
-// generateWeaponFireEvents creates weapon fire events (very verbose)
-func (eg *EventGenerator) generateWeaponFireEvents(match *models.Match, state *models.MatchState, roundNum int, strategy *RoundStrategy) []models.GameEvent {
-	var events []models.GameEvent
-	
-	// Only generate these for very detailed logging
-	if !eg.config.VerboseLogging {
-		return events
+// appendPlayerOnce adds a player to the list unless they are already in it
+func appendPlayerOnce(players []*models.Player, player *models.Player) []*models.Player {
+	for _, listed := range players {
+		if listed.Name == player.Name {
+			return players
+		}
 	}
-	
-	numFireEvents := int(float64(20+eg.rng.Intn(40)) * strategy.Intensity) // 20-60 fire events
-	roundDuration := int64(115 * eg.config.TickRate)
-	
-	for i := 0; i < numFireEvents; i++ {
-		eventTime := int64(eg.rng.Intn(int(roundDuration)))
-		
-		// Select random alive player
-		allPlayers := append(eg.getAlivePlayers(match, state, "CT"), eg.getAlivePlayers(match, state, "TERRORIST")...)
-		if len(allPlayers) == 0 {
-			continue
+	return append(players, player)
+}
+
+// planDamageHits plans the non-lethal hits of a live round besides those of its
+// kills, 10-25 scaled by the round's intensity. By default they are traded between
+// the players of the round's fights, during them; with uniform damage pacing they
+// land anywhere in the round between any players.
+func (eg *EventGenerator) planDamageHits(detail *roundDetail, endTick int64, strategy *RoundStrategy) []plannedHit {
+	numHits := int(float64(10+eg.rng.Intn(15)) * strategy.Intensity)
+	
+	var hits []plannedHit
+	for i := 0; i < numHits; i++ {
+		var tick int64
+		var ct, t []*models.Player
+		if eg.config.DamagePacing == "uniform" {
+			tick = eg.rng.Int63n(endTick + 1)
+			for _, player := range detail.players {
+				if models.NormalizeSide(player.Side) == "CT" {
+					ct = append(ct, player)
+				} else {
+					t = append(t, player)
+				}
+			}
+		} else {
+			if len(eg.engagements) == 0 {
+				break
+			}
+			window := eg.engagements[eg.rng.Intn(len(eg.engagements))]
+			tick = window.startTick + eg.rng.Int63n(window.endTick-window.startTick+1)
+			ct, t = window.ct, window.t
 		}
 		
-		player := allPlayers[eg.rng.Intn(len(allPlayers))]
-		playerState := state.PlayerStates[player.Name]
-		
-		weapon := "glock"
-		if playerState.PrimaryWeapon != nil {
-			weapon = playerState.PrimaryWeapon.Name
-		} else if playerState.SecondaryWeapon != nil {
-			weapon = playerState.SecondaryWeapon.Name
+		attackers, victims := ct, t
+		if eg.rng.Float64() >= 0.5 {
+			attackers, victims = t, ct
 		}
-		
-		fireEvent := &models.WeaponFireEvent{
-			BaseEvent: models.NewBaseEvent("weapon_fire", eventTime, roundNum),
-			Player:    player,
-			Weapon:    weapon,
-			Position:  playerState.Position,
-			Angle:     models.Vector3{X: float64(eg.rng.Intn(360)), Y: float64(eg.rng.Intn(180)), Z: 0},
-			Silenced:  weapon == "m4a1_silencer" || weapon == "usp_silencer",
+		if len(attackers) == 0 || len(victims) == 0 {
+			continue
 		}
-		events = append(events, fireEvent)
+		hits = append(hits, plannedHit{
+			tick:     tick,
+			attacker: attackers[eg.rng.Intn(len(attackers))],
+			victim:   victims[eg.rng.Intn(len(victims))],
+		})
 	}
-	
-	return events
+	return hits
 }
 
-// Helper methods
-
-func (eg *EventGenerator) createDamageEvent(attacker, victim *models.Player, tick int64, roundNum int) models.GameEvent {
-	playerState := eg.getPlayerState(victim)
-	if playerState == nil || !playerState.IsAlive {
+// applyHit lands a planned non-lethal hit with the weapon the attacker holds. Hits
+// involving a dead player, and hits that would be lethal, are dropped: only the
+// round's kills take a player's last health.
+func (eg *EventGenerator) applyHit(detail *roundDetail, hit plannedHit, roundNum int) []models.GameEvent {
+	attackerState := detail.state.PlayerStates[hit.attacker.Name]
+	victimState := detail.state.PlayerStates[hit.victim.Name]
+	if attackerState == nil || victimState == nil || !attackerState.IsAlive || !victimState.IsAlive {
 		return nil
 	}
+	if hit.tick < detail.armedAt[hit.attacker.Name] {
+		return nil // Still picking a weapon up
+	}
 	
-	weapon := eg.selectWeaponForAttack(attacker)
-	damage := eg.calculateDamage(attacker, victim, weapon)
-	damageArmor := eg.calculateArmorDamage(damage, playerState)
+	weapon := detail.weapons[hit.attacker.Name]
+	hitgroup := eg.selectHitgroup(hit.attacker, weapon)
+	rawDamage := eg.calculateDamage(hit.attacker, hit.victim, weapon, hitgroup)
+	damageArmor := eg.calculateArmorDamage(rawDamage, victimState)
+	damage := rawDamage - damageArmor
+	if damage >= victimState.Health {
+		return nil
+	}
 	
-	// Apply damage to player state
-	newHealth := playerState.Health - damage
-	newArmor := playerState.Armor - damageArmor
-	if newHealth < 0 {
-		newHealth = 0
+	hurt := eg.applyDamage(victimState, hit.attacker, hit.victim, weapon, hitgroup, damage, damageArmor, hit.tick, roundNum)
+	shots := eg.createShotsFired(detail.state, hit.attacker, weapon, hit.tick, roundNum)
+	for _, shot := range shots {
+		if armedAt := detail.armedAt[hit.attacker.Name]; shot.GetTick() < armedAt {
+			shot.SetTick(armedAt) // No shots with a weapon before it was picked up
+		}
 	}
-	if newArmor < 0 {
-		newArmor = 0
+	return append(shots, hurt)
+}
+
+// applyKill lands a kill's lethal hit, with the kill's weapon and hitgroup, and
+// returns the shots, the hit and the kill. The hit always takes the victim's remaining
+// health, since the round simulator already decided they die. The killer's teammate
+// who dealt the victim the most damage gets the assist if it reaches the threshold.
+func (eg *EventGenerator) applyKill(detail *roundDetail, kill *models.KillEvent, roundNum int) []models.GameEvent {
+	victimState := detail.state.PlayerStates[kill.Victim.Name]
+	if victimState == nil || !victimState.IsAlive {
+		return []models.GameEvent{kill}
 	}
 	
-	hitgroup := eg.selectHitgroup(attacker, weapon)
+	rawDamage := eg.calculateDamage(kill.Attacker, kill.Victim, kill.Weapon, kill.Hitgroup)
+	damageArmor := eg.calculateArmorDamage(rawDamage, victimState)
+	damage := rawDamage - damageArmor
+	if damage < victimState.Health {
+		damage = victimState.Health
+	}
 	
-	damageEvent := &models.PlayerHurtEvent{
-		BaseEvent:   models.NewBaseEvent("player_hurt", tick, roundNum),
-		Attacker:    attacker,
-		Victim:      victim,
-		Weapon:      weapon,
-		Damage:      damage,
-		DamageArmor: damageArmor,
-		Health:      newHealth,
-		Armor:       newArmor,
-		Hitgroup:    hitgroup,
+	events := eg.createShotsFired(detail.state, kill.Attacker, kill.Weapon, kill.Tick, roundNum)
+	events = append(events, eg.applyDamage(victimState, kill.Attacker, kill.Victim, kill.Weapon, kill.Hitgroup, damage, damageArmor, kill.Tick, roundNum))
+	victimState.IsAlive = false
+	
+	if kill.Assister == nil && models.NormalizeSide(kill.Attacker.Side) != models.NormalizeSide(kill.Victim.Side) {
+		if assister := eg.selectAssister(kill.Attacker, kill.Victim); assister != nil {
+			kill.Assister = assister
+			assister.AddAssist()
+		}
 	}
 	
-	// Update player state
-	playerState.Health = newHealth
-	playerState.Armor = newArmor
+	// A teammate of the killer whose flash still blinds the victim gets a flash assist
+	for _, blind := range detail.blinds[kill.Victim.Name] {
+		if kill.Tick >= blind.from && kill.Tick <= blind.until && blind.thrower.Name != kill.Attacker.Name &&
+			models.NormalizeSide(blind.thrower.Side) == models.NormalizeSide(kill.Attacker.Side) {
+			kill.FlashAssister = blind.thrower
+			blind.thrower.Stats.FlashAssists++
+			break
+		}
+	}
+	
+	detail.weapons[kill.Attacker.Name] = kill.Weapon
+	if pickup := detail.pickups[kill.Victim.Name]; pickup != nil && pickup.Player != nil {
+		detail.weapons[pickup.Player.Name] = pickup.Weapon
+		detail.armedAt[pickup.Player.Name] = pickup.Tick + 1
+	}
+	return append(events, kill)
+}
+
+// shoot resolves one attack: the shots fired, the damage they dealt and, if it was
+// lethal, the kill. It reports whether the victim died.
+func (eg *EventGenerator) shoot(state *models.MatchState, attacker, victim *models.Player, tick int64, roundNum int) ([]models.GameEvent, bool) {
+	damageEvent := eg.createDamageEvent(state, attacker, victim, tick, roundNum)
+	if damageEvent == nil {
+		return nil, false
+	}
+	hurt := damageEvent.(*models.PlayerHurtEvent)
 	
-	// Update attacker stats
-	attacker.Stats.Damage += damage
+	events := eg.createShotsFired(state, attacker, hurt.Weapon, tick, roundNum)
+	events = append(events, hurt)
 	
-	return damageEvent
+	killEvent := eg.checkForKill(attacker, victim, tick, roundNum, hurt)
+	if killEvent == nil {
+		return events, false
+	}
+	if attackerState := state.PlayerStates[attacker.Name]; attackerState != nil {
+		killEvent.(*models.KillEvent).AttackerPos = attackerState.Position
+	}
+	return append(events, killEvent), true
 }
 
-func (eg *EventGenerator) createNonLethalDamageEvent(attacker, victim *models.Player, tick int64, roundNum int) models.GameEvent {
-	playerState := eg.getPlayerState(victim)
-	if playerState == nil || !playerState.IsAlive || playerState.Health <= 20 {
-		return nil // Don't create damage that would kill or near-kill
+// createShotsFired creates the 1-3 weapon fire events leading up to a hit at hitTick,
+// fired by the attacker with the hitting weapon from their position. They are only
+// generated with verbose logging, but the shot count is drawn either way so verbose
+// logging never changes what happens in a match.
+func (eg *EventGenerator) createShotsFired(state *models.MatchState, attacker *models.Player, weapon string, hitTick int64, roundNum int) []models.GameEvent {
+	shots := 1 + eg.rng.Intn(3)
+	if !eg.config.VerboseLogging {
+		return nil
 	}
 	
-	weapon := eg.selectWeaponForAttack(attacker)
-	damage := 5 + eg.rng.Intn(15) // 5-19 damage (non-lethal)
-	damageArmor := eg.calculateArmorDamage(damage, playerState)
+	var position models.Vector3
+	if attackerState := state.PlayerStates[attacker.Name]; attackerState != nil {
+		position = attackerState.Position
+	}
 	
-	newHealth := playerState.Health - damage
-	newArmor := playerState.Armor - damageArmor
-	if newHealth < 1 {
-		newHealth = 1 // Keep alive
+	spacing := int64(eg.config.TickRate / 10) // Roughly 100ms between shots
+	events := make([]models.GameEvent, 0, shots)
+	for i := shots; i >= 1; i-- {
+		tick := hitTick - int64(i)*spacing
+		if tick < 0 {
+			tick = 0
+		}
+		events = append(events, &models.WeaponFireEvent{
+			BaseEvent: models.NewBaseEvent("weapon_fire", tick, roundNum),
+			Player:    attacker,
+			Weapon:    weapon,
+			Position:  position,
+			Silenced:  weapon == "m4a1_silencer" || weapon == "usp_silencer",
+		})
 	}
-	if newArmor < 0 {
-		newArmor = 0
+	return events
+}
+
+// Helper methods
+
+func (eg *EventGenerator) createDamageEvent(state *models.MatchState, attacker, victim *models.Player, tick int64, roundNum int) models.GameEvent {
+	playerState := eg.playerStateFor(state, victim)
+	if playerState == nil || !playerState.IsAlive || playerState.Health <= 0 {
+		return nil
 	}
 	
+	weapon := eg.selectWeaponForAttack(state, attacker)
 	hitgroup := eg.selectHitgroup(attacker, weapon)
+	rawDamage := eg.calculateDamage(attacker, victim, weapon, hitgroup)
+	damageArmor := eg.calculateArmorDamage(rawDamage, playerState)
+	damage := rawDamage - damageArmor
+	
+	return eg.applyDamage(playerState, attacker, victim, weapon, hitgroup, damage, damageArmor, tick, roundNum)
+}
+
+// applyDamage takes a hit's damage from the victim's health and armor, credits the
+// health taken to the attacker and returns the hurt event for it
+func (eg *EventGenerator) applyDamage(playerState *models.PlayerState, attacker, victim *models.Player, weapon string, hitgroup, damage, damageArmor int, tick int64, roundNum int) *models.PlayerHurtEvent {
+	newHealth := playerState.Health - damage
+	if newHealth < 0 {
+		newHealth = 0
+	}
+	newArmor := playerState.Armor - damageArmor
 	
 	damageEvent := &models.PlayerHurtEvent{
 		BaseEvent:   models.NewBaseEvent("player_hurt", tick, roundNum),
@@ -430,11 +680,10 @@ func (eg *EventGenerator) createNonLethalDamageEvent(attacker, victim *models.Pl
 	}
 	
 	// Update player state
+	eg.recordDamage(attacker, victim, playerState.Health-newHealth)
+	attacker.Stats.Damage += playerState.Health - newHealth
 	playerState.Health = newHealth
-	playerState.Armor = newArmor
-	
-	// Update attacker stats
-	attacker.Stats.Damage += damage
+	eg.damageArmor(playerState, newArmor)
 	
 	return damageEvent
 }
@@ -449,6 +698,7 @@ func (eg *EventGenerator) checkForKill(attacker, victim *models.Player, tick int
 			Victim:        victim,
 			Weapon:        damageEvent.Weapon,
 			Headshot:      headshot,
+			Hitgroup:      damageEvent.Hitgroup,
 			Penetrated:    0,
 			NoScope:       false,
 			AttackerBlind: false,
@@ -463,46 +713,70 @@ func (eg *EventGenerator) checkForKill(attacker, victim *models.Player, tick int
 		if headshot {
 			attacker.Stats.Headshots++
 		}
+		if assister := eg.selectAssister(attacker, victim); assister != nil {
+			killEvent.Assister = assister
+			assister.AddAssist()
+		}
 		
 		return killEvent
 	}
 	return nil
 }
 
-func (eg *EventGenerator) createFlashbangEvent(match *models.Match, state *models.MatchState, thrower *models.Player, tick int64, roundNum int) models.GameEvent {
-	// Find players who might be flashed (simplified logic)
-	var flashed []*models.Player
-	
-	// Get all alive players from the opposite team
-	oppositeTeam := "TERRORIST"
-	if thrower.Side == "TERRORIST" {
-		oppositeTeam = "CT"
-	}
-	
-	potentialVictims := eg.getAlivePlayers(match, state, oppositeTeam)
-	
-	// Randomly select 0-3 players to be flashed
-	numFlashed := eg.rng.Intn(4) // 0-3 players
-	if numFlashed > len(potentialVictims) {
-		numFlashed = len(potentialVictims)
+// flashEnemies detonates a flashbang among the given enemies and returns the blind
+// event, or nil when nobody was blinded. With positions included, the enemies within
+// the flash's range are blinded for as long as their facing allows; otherwise up to
+// three random enemies are caught at random angles and distances.
+func (eg *EventGenerator) flashEnemies(state *models.MatchState, thrower *models.Player, potentialVictims []*models.Player, tick int64, entityIndex int, roundNum int) *models.FlashbangEvent {
+	// The flash pops a few meters to tens of meters away from the thrower
+	throwerPos := eg.playerStateFor(state, thrower).Position
+	heading := eg.rng.Float64() * 2 * math.Pi
+	reach := flashMinThrow + eg.rng.Float64()*(flashMaxThrow-flashMinThrow)
+	detonation := models.Vector3{
+		X: throwerPos.X + math.Cos(heading)*reach,
+		Y: throwerPos.Y + math.Sin(heading)*reach,
+		Z: throwerPos.Z,
 	}
 	
-	for i := 0; i < numFlashed; i++ {
-		flashed = append(flashed, potentialVictims[i])
+	var flashed []*models.Player
+	var durations []float64
+	if eg.config.IncludePositions {
+		for _, player := range potentialVictims {
+			if duration, ok := eg.blindDuration(detonation, state.PlayerStates[player.Name]); ok {
+				flashed = append(flashed, player)
+				durations = append(durations, duration)
+			}
+		}
+	} else {
+		// Randomly select 0-3 players to be flashed
+		numFlashed := eg.rng.Intn(4) // 0-3 players
+		if numFlashed > len(potentialVictims) {
+			numFlashed = len(potentialVictims)
+		}
+		flashed = append(flashed, eg.shufflePlayers(potentialVictims)[:numFlashed]...)
+		for range flashed {
+			angle := eg.rng.Float64() * 180
+			distance := eg.rng.Float64() * flashRange
+			durations = append(durations, flashDuration(angle, distance))
+		}
 	}
 	
 	if len(flashed) > 0 {
+		longest := 0.0
+		for _, duration := range durations {
+			longest = math.Max(longest, duration)
+		}
 		flashEvent := &models.FlashbangEvent{
-			BaseEvent: models.NewBaseEvent("flashbang_detonate", tick+int64(2*eg.config.TickRate), roundNum),
+			BaseEvent: models.NewBaseEvent("flashbang_detonate", tick, roundNum),
 			Player:    thrower,
-			Position:  eg.getPlayerState(thrower).Position,
+			Position:  detonation,
 			Flashed:   flashed,
-			Duration:  1.0 + eg.rng.Float64()*3.0, // 1-4 seconds flash
+			Duration:  longest,
+			Durations: durations,
+			EntityIndex: entityIndex,
 		}
 		
-		// Update thrower stats
-		thrower.Stats.EnemiesFlashed += len(flashed)
-		thrower.Stats.FlashAssists += len(flashed)
+		eg.recordFlash(thrower, flashed)
 		
 		return flashEvent
 	}
@@ -510,28 +784,96 @@ func (eg *EventGenerator) createFlashbangEvent(match *models.Match, state *model
 	return nil
 }
 
-// Utility methods
+// blindDuration returns how long a flash detonating at the given point blinds a
+// player, from the distance and the angle between where they look and the flash.
+// Players out of the flash's range aren't blinded.
+func (eg *EventGenerator) blindDuration(detonation models.Vector3, playerState *models.PlayerState) (float64, bool) {
+	if playerState == nil {
+		return 0, false
+	}
+	dx := detonation.X - playerState.Position.X
+	dy := detonation.Y - playerState.Position.Y
+	distance := math.Hypot(dx, dy)
+	if distance >= flashRange {
+		return 0, false
+	}
+	
+	angle := 0.0 // Looking straight at a flash on top of them
+	if distance > 0 {
+		toFlash := math.Atan2(dy, dx) * 180 / math.Pi
+		angle = math.Abs(math.Mod(toFlash-playerState.ViewAngle.Y+540, 360) - 180)
+	}
+	return flashDuration(angle, distance), true
+}
 
-func (eg *EventGenerator) getTeamBySide(match *models.Match, side string) *models.Team {
-	for i := range match.Teams {
-		if match.Teams[i].Side == side {
-			return &match.Teams[i]
+// flashDuration models a blind from the angle in degrees between the victim's view
+// and the flash and from its distance. Looking at a close flash is a full blind of
+// several seconds, a pop flash caught at the edge of the screen is shorter, and
+// turning away leaves only a brief white-out.
+func flashDuration(angle, distance float64) float64 {
+	facing := (1 + math.Cos(angle*math.Pi/180)) / 2 // 1 looking at it, 0 facing away
+	falloff := math.Max(0, 1-distance/flashRange)
+	duration := flashMaxDuration * (flashAwayShare + (1-flashAwayShare)*facing*facing) * falloff
+	return math.Round(math.Max(flashMinDuration, duration)*100) / 100
+}
+
+// recordDamage adds health taken from a victim to the attacker's share for this round
+func (eg *EventGenerator) recordDamage(attacker, victim *models.Player, damage int) {
+	if damage <= 0 {
+		return
+	}
+	shares := eg.damageTaken[victim.Name]
+	for i := range shares {
+		if shares[i].attacker.Name == attacker.Name {
+			shares[i].damage += damage
+			return
 		}
 	}
-	return nil
+	eg.damageTaken[victim.Name] = append(shares, damageShare{attacker: attacker, damage: damage})
 }
 
-func (eg *EventGenerator) getAlivePlayers(match *models.Match, state *models.MatchState, side string) []*models.Player {
-	var alive []*models.Player
-	team := eg.getTeamBySide(match, side)
-	if team != nil {
-		for i, player := range team.Players {
-			if playerState := state.PlayerStates[player.Name]; playerState != nil && playerState.IsAlive {
-				alive = append(alive, &team.Players[i])
-			}
+// selectAssister returns the killer's teammate who dealt the victim the most damage
+// this round, if that damage reaches the assist threshold
+func (eg *EventGenerator) selectAssister(killer, victim *models.Player) *models.Player {
+	minDamage := eg.assistDamageThreshold * playerMaxHealth
+	
+	var assister *models.Player
+	best := 0
+	for _, share := range eg.damageTaken[victim.Name] {
+		if share.attacker.Name == killer.Name || models.NormalizeSide(share.attacker.Side) != models.NormalizeSide(killer.Side) {
+			continue
+		}
+		if float64(share.damage) >= minDamage && share.damage > best {
+			assister = share.attacker
+			best = share.damage
 		}
 	}
-	return alive
+	return assister
+}
+
+// recordFlash credits the thrower for every enemy caught in a flash; teammates
+// caught by the same flash do not count. Flash assists are only credited once a
+// teammate kills a blinded enemy.
+func (eg *EventGenerator) recordFlash(thrower *models.Player, flashed []*models.Player) {
+	for _, player := range flashed {
+		if models.NormalizeSide(player.Side) == models.NormalizeSide(thrower.Side) {
+			continue
+		}
+		thrower.Stats.EnemiesFlashed++
+	}
+}
+
+// Utility methods
+
+// playerStateFor returns the player's state in the match, falling back to a fresh
+// full-health state for players the match state does not track
+func (eg *EventGenerator) playerStateFor(state *models.MatchState, player *models.Player) *models.PlayerState {
+	if state != nil {
+		if playerState := state.PlayerStates[player.Name]; playerState != nil {
+			return playerState
+		}
+	}
+	return eg.getPlayerState(player)
 }
 
 func (eg *EventGenerator) getPlayerState(player *models.Player) *models.PlayerState {
@@ -544,32 +886,14 @@ func (eg *EventGenerator) getPlayerState(player *models.Player) *models.PlayerSt
 	}
 }
 
-func (eg *EventGenerator) selectEngagementParticipants(players []*models.Player, strategy *RoundStrategy) []*models.Player {
-	if len(players) == 0 {
-		return players
-	}
-	
-	maxParticipants := 3
-	if strategy.Intensity > 0.7 {
-		maxParticipants = int(math.Min(float64(len(players)), 4)) // More chaotic fights
-	} else {
-		maxParticipants = int(math.Min(float64(len(players)), 2)) // Smaller engagements
-	}
-	
-	numParticipants := 1 + eg.rng.Intn(maxParticipants)
-	if numParticipants > len(players) {
-		numParticipants = len(players)
+// shufflePlayers returns a shuffled copy of players. The shuffle only depends on the
+// rng and the input order, so callers must pass players in a stable order.
+func (eg *EventGenerator) shufflePlayers(players []*models.Player) []*models.Player {
+	shuffled := make([]*models.Player, len(players))
+	for i, idx := range eg.rng.Perm(len(players)) {
+		shuffled[i] = players[idx]
 	}
-	
-	// Randomly select participants
-	selected := make([]*models.Player, numParticipants)
-	selectedIndices := eg.rng.Perm(len(players))[:numParticipants]
-	
-	for i, idx := range selectedIndices {
-		selected[i] = players[idx]
-	}
-	
-	return selected
+	return shuffled
 }
 
 func (eg *EventGenerator) removePlayerFromList(players []*models.Player, toRemove *models.Player) []*models.Player {
@@ -582,25 +906,41 @@ func (eg *EventGenerator) removePlayerFromList(players []*models.Player, toRemov
 	return result
 }
 
-func (eg *EventGenerator) getPlayersWithUtility(team *models.Team, state *models.MatchState) []*models.Player {
-	var players []*models.Player
-	for i, player := range team.Players {
-		if playerState := state.PlayerStates[player.Name]; playerState != nil && len(playerState.Grenades) > 0 {
-			players = append(players, &team.Players[i])
-		}
+// selectWeaponForAttack returns the weapon the attacker shoots with: their primary,
+// else their pistol, else the side's default pistol
+func (eg *EventGenerator) selectWeaponForAttack(state *models.MatchState, attacker *models.Player) string {
+	if playerState := eg.playerStateFor(state, attacker); playerState.PrimaryWeapon != nil {
+		return playerState.PrimaryWeapon.Name
+	} else if playerState.SecondaryWeapon != nil {
+		return playerState.SecondaryWeapon.Name
+	}
+	
+	if models.NormalizeSide(attacker.Side) == "CT" {
+		return "usp_silencer"
 	}
-	return players
+	return "glock"
 }
 
-func (eg *EventGenerator) selectWeaponForAttack(attacker *models.Player) string {
-	// Default based on side
-	if attacker.Side == "CT" {
-		return "m4a4"
+// hitgroupDamageMultipliers scales weapon damage by where the bullet lands
+var hitgroupDamageMultipliers = map[int]float64{
+	1: 4.0,  // Head
+	2: 1.0,  // Chest
+	3: 1.25, // Stomach
+	4: 1.0,  // Left arm
+	5: 1.0,  // Right arm
+	6: 0.75, // Left leg
+	7: 0.75, // Right leg
+}
+
+// hitgroupDamageMultiplier returns the damage multiplier for a hitgroup
+func hitgroupDamageMultiplier(hitgroup int) float64 {
+	if multiplier, ok := hitgroupDamageMultipliers[hitgroup]; ok {
+		return multiplier
 	}
-	return "ak47"
+	return 1.0
 }
 
-func (eg *EventGenerator) calculateDamage(attacker, victim *models.Player, weapon string) int {
+func (eg *EventGenerator) calculateDamage(attacker, victim *models.Player, weapon string, hitgroup int) int {
 	baseDamage := 25
 	
 	// Weapon-specific damage
@@ -617,9 +957,18 @@ func (eg *EventGenerator) calculateDamage(attacker, victim *models.Player, weapo
 		baseDamage = 28
 	}
 	
-	// Add randomness (±20%)
-	variation := int(float64(baseDamage) * 0.2)
-	damage := baseDamage + eg.rng.Intn(variation*2) - variation
+	// Vary the base damage, with the lethality bias curving the roll towards either end
+	damage := baseDamage
+	if eg.damageVariance > 0 {
+		roll := math.Pow(eg.rng.Float64(), math.Pow(2, -eg.lethalityBias))
+		damage += int(math.Round(float64(baseDamage) * eg.damageVariance * (2*roll - 1)))
+	}
+	if damage < 1 {
+		damage = 1
+	}
+	
+	// Apply hitgroup multiplier
+	damage = int(float64(damage) * hitgroupDamageMultiplier(hitgroup))
 	
 	if damage < 1 {
 		damage = 1
@@ -628,12 +977,15 @@ func (eg *EventGenerator) calculateDamage(attacker, victim *models.Player, weapo
 	return damage
 }
 
+// calculateArmorDamage returns the part of a hit absorbed by armor. Armor absorbs
+// half of every hit (simplified) but never more than what is left of it, so once
+// it breaks the remaining damage and every later hit go to health in full.
 func (eg *EventGenerator) calculateArmorDamage(damage int, playerState *models.PlayerState) int {
 	if playerState.Armor <= 0 {
 		return 0
 	}
 	
-	armorDamage := int(float64(damage) * 0.5) // Simplified armor calculation
+	armorDamage := int(float64(damage) * armorAbsorption)
 	if armorDamage > playerState.Armor {
 		armorDamage = playerState.Armor
 	}
@@ -641,6 +993,14 @@ func (eg *EventGenerator) calculateArmorDamage(damage int, playerState *models.P
 	return armorDamage
 }
 
+// damageArmor sets a player's remaining armor, marking it broken when a hit took the last of it
+func (eg *EventGenerator) damageArmor(playerState *models.PlayerState, armor int) {
+	if playerState.Armor > 0 && armor <= 0 {
+		playerState.ArmorBroken = true
+	}
+	playerState.Armor = armor
+}
+
 func (eg *EventGenerator) selectHitgroup(attacker *models.Player, weapon string) int {
 	// Hitgroup probabilities
 	// 0=generic, 1=head, 2=chest, 3=stomach, 4=leftarm, 5=rightarm, 6=leftleg, 7=rightleg
@@ -674,24 +1034,4 @@ func (eg *EventGenerator) selectHitgroup(attacker *models.Player, weapon string)
 		}
 		return 7 // Right leg
 	}
-}
-
-func (eg *EventGenerator) copyTeamEconomies(economies map[string]*models.TeamEconomy) map[string]models.TeamEconomy {
-	copied := make(map[string]models.TeamEconomy)
-	for name, economy := range economies {
-		copied[name] = *economy
-	}
-	return copied
-}
-
-func (eg *EventGenerator) sortEventsByTime(events []models.GameEvent) {
-	// Simple bubble sort by tick - for production use sort.Slice
-	n := len(events)
-	for i := 0; i < n-1; i++ {
-		for j := 0; j < n-i-1; j++ {
-			if events[j].GetTick() > events[j+1].GetTick() {
-				events[j], events[j+1] = events[j+1], events[j]
-			}
-		}
-	}
 }
\ No newline at end of file
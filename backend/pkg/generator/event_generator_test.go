@@ -0,0 +1,472 @@
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/noueii/nocs-log-generator/backend/pkg/models"
+)
+
+func TestEventGenerator_HitgroupDamage(t *testing.T) {
+	config := models.DefaultMatchConfig()
+	attacker := &models.Player{Name: "attacker", Side: "TERRORIST"}
+	victim := &models.Player{Name: "victim", Side: "CT"}
+
+	for seed := int64(1); seed <= 20; seed++ {
+		// Same seed so both shots roll the same base damage
+		head := NewEventGenerator(rand.New(rand.NewSource(seed)), &config).calculateDamage(attacker, victim, "ak47", 1)
+		leg := NewEventGenerator(rand.New(rand.NewSource(seed)), &config).calculateDamage(attacker, victim, "ak47", 6)
+		chest := NewEventGenerator(rand.New(rand.NewSource(seed)), &config).calculateDamage(attacker, victim, "ak47", 2)
+
+		if head != chest*4 {
+			t.Errorf("seed %d: expected head damage %d to be 4x chest damage %d", seed, head, chest)
+		}
+		if leg != int(float64(chest)*0.75) {
+			t.Errorf("seed %d: expected leg damage %d to be 0.75x chest damage %d", seed, leg, chest)
+		}
+		if head < 100 {
+			t.Errorf("seed %d: expected an ak47 headshot to be lethal, got %d damage", seed, head)
+		}
+		if leg >= 100 {
+			t.Errorf("seed %d: expected an ak47 leg shot to be non-lethal, got %d damage", seed, leg)
+		}
+	}
+}
+
+func TestEventGenerator_ZeroDamageVariance(t *testing.T) {
+	config := models.DefaultMatchConfig()
+	attacker := &models.Player{Name: "attacker", Side: "TERRORIST"}
+	victim := &models.Player{Name: "victim", Side: "CT"}
+
+	for _, bias := range []float64{-1, 0, 1} {
+		eg := NewEventGenerator(rand.New(rand.NewSource(1)), &config)
+		eg.SetDamageModel(0, bias)
+		for i := 0; i < 50; i++ {
+			for _, hitgroup := range []int{1, 2, 3, 6} {
+				want := int(36 * hitgroupDamageMultiplier(hitgroup))
+				if got := eg.calculateDamage(attacker, victim, "ak47", hitgroup); got != want {
+					t.Fatalf("bias %v: expected exactly %d damage to hitgroup %d, got %d", bias, want, hitgroup, got)
+				}
+			}
+		}
+	}
+}
+
+func TestEventGenerator_LethalityBias(t *testing.T) {
+	config := models.DefaultMatchConfig()
+	attacker := &models.Player{Name: "attacker", Side: "TERRORIST"}
+	victim := &models.Player{Name: "victim", Side: "CT"}
+
+	average := func(bias float64) float64 {
+		eg := NewEventGenerator(rand.New(rand.NewSource(1)), &config)
+		eg.SetDamageModel(0.5, bias)
+		total := 0
+		for i := 0; i < 1000; i++ {
+			damage := eg.calculateDamage(attacker, victim, "glock", 2)
+			if damage < 14 || damage > 42 {
+				t.Fatalf("bias %v: damage %d strays more than the variance from the base of 28", bias, damage)
+			}
+			total += damage
+		}
+		return float64(total) / 1000
+	}
+
+	grindy, neutral, bloody := average(-1), average(0), average(1)
+	if !(grindy < neutral && neutral < bloody) {
+		t.Errorf("Expected average damage to grow with the bias, got %.1f, %.1f and %.1f", grindy, neutral, bloody)
+	}
+
+	// The variance never takes a hit below 1 damage
+	eg := NewEventGenerator(rand.New(rand.NewSource(1)), &config)
+	eg.SetDamageModel(1, -1)
+	for i := 0; i < 1000; i++ {
+		if damage := eg.calculateDamage(attacker, victim, "glock", 6); damage < 1 {
+			t.Fatalf("Expected at least 1 damage, got %d", damage)
+		}
+	}
+}
+
+func TestEventGenerator_KillMatchesFinalDamage(t *testing.T) {
+	config := models.DefaultMatchConfig()
+	eg := NewEventGenerator(rand.New(rand.NewSource(7)), &config)
+
+	for i := 0; i < 200; i++ {
+		attacker := &models.Player{Name: "attacker", Side: "TERRORIST"}
+		victim := &models.Player{Name: "victim", Side: "CT"}
+
+		damageEvent := eg.createDamageEvent(nil, attacker, victim, 100, 1).(*models.PlayerHurtEvent)
+		if damageEvent.Health != 100-damageEvent.Damage && damageEvent.Health != 0 {
+			t.Fatalf("Health %d does not reflect %d damage", damageEvent.Health, damageEvent.Damage)
+		}
+
+		kill := eg.checkForKill(attacker, victim, 100, 1, damageEvent)
+		if (kill != nil) != (damageEvent.Damage >= 100) {
+			t.Fatalf("Kill = %v for %d damage to a full health player", kill != nil, damageEvent.Damage)
+		}
+		if kill != nil && kill.(*models.KillEvent).Headshot != (damageEvent.Hitgroup == 1) {
+			t.Fatalf("Kill headshot flag does not match hitgroup %d", damageEvent.Hitgroup)
+		}
+	}
+}
+
+func TestEventGenerator_ArmorBreak(t *testing.T) {
+	config := models.DefaultMatchConfig()
+	eg := NewEventGenerator(rand.New(rand.NewSource(3)), &config)
+	attacker := &models.Player{Name: "attacker", Side: "TERRORIST"}
+	victim := &models.Player{Name: "victim", Side: "CT"}
+	victimState := &models.PlayerState{IsAlive: true, Health: 100000}
+	victimState.EquipArmor(true)
+	state := &models.MatchState{PlayerStates: map[string]*models.PlayerState{victim.Name: victimState}}
+
+	crossed := false
+	for i := 0; i < 200 && !crossed; i++ {
+		armorBefore := victimState.Armor
+		hurt := eg.createDamageEvent(state, attacker, victim, int64(i), 1).(*models.PlayerHurtEvent)
+		total := hurt.Damage + hurt.DamageArmor
+
+		// Armor absorbs half of each hit until it runs out, the rest goes to health in full
+		expectedArmor := total / 2
+		if expectedArmor > armorBefore {
+			expectedArmor = armorBefore
+		}
+		if hurt.DamageArmor != expectedArmor {
+			t.Fatalf("Hit %d: expected %d armor damage from %d damage with %d armor, got %d", i, expectedArmor, total, armorBefore, hurt.DamageArmor)
+		}
+		if hurt.Armor != armorBefore-hurt.DamageArmor || victimState.Armor != hurt.Armor {
+			t.Fatalf("Hit %d: expected armor %d, event has %d and state %d", i, armorBefore-hurt.DamageArmor, hurt.Armor, victimState.Armor)
+		}
+		if victimState.ArmorBroken != (victimState.Armor == 0) {
+			t.Fatalf("Hit %d: armor broken = %v with %d armor left", i, victimState.ArmorBroken, victimState.Armor)
+		}
+		crossed = armorBefore > 0 && victimState.Armor == 0
+	}
+	if !crossed {
+		t.Fatal("Expected the armor to break")
+	}
+
+	// Without armor every hit does full damage to health
+	for i := 0; i < 10; i++ {
+		healthBefore := victimState.Health
+		hurt := eg.createDamageEvent(state, attacker, victim, int64(200+i), 1).(*models.PlayerHurtEvent)
+		if hurt.DamageArmor != 0 || hurt.Health != healthBefore-hurt.Damage {
+			t.Fatalf("Expected full health damage after the armor broke, got %d health and %d armor damage", healthBefore-hurt.Health, hurt.DamageArmor)
+		}
+	}
+
+	victimState.EquipArmor(false)
+	if victimState.ArmorBroken || victimState.Armor != 100 {
+		t.Error("Expected buying armor to restore it")
+	}
+}
+
+func TestEventGenerator_RecordFlashCountsEnemiesOnly(t *testing.T) {
+	config := models.DefaultMatchConfig()
+	eg := NewEventGenerator(rand.New(rand.NewSource(1)), &config)
+
+	thrower := &models.Player{Name: "thrower", Side: "CT"}
+	flashed := []*models.Player{
+		{Name: "enemy1", Side: "TERRORIST"},
+		{Name: "teammate", Side: "CT"},
+		{Name: "enemy2", Side: "T"},
+	}
+
+	eg.recordFlash(thrower, flashed)
+	if thrower.Stats.EnemiesFlashed != 2 {
+		t.Errorf("Expected 2 enemies flashed, got %d", thrower.Stats.EnemiesFlashed)
+	}
+	// Being flashed alone is no assist; a teammate has to kill the blinded enemy
+	if thrower.Stats.FlashAssists != 0 {
+		t.Errorf("Expected no flash assists without kills, got %d", thrower.Stats.FlashAssists)
+	}
+}
+
+func TestEventGenerator_UtilityComesFromGrenadesHeld(t *testing.T) {
+	engine := newTestEngine(t, 42)
+	ct := engine.getTeamBySide("CT")
+	terrorists := engine.getTeamBySide("TERRORIST")
+
+	held := 0
+	for _, player := range ct.Players {
+		engine.state.PlayerStates[player.Name].Grenades = []models.Grenade{
+			{Type: "flashbang"}, {Type: "smokegrenade"}, {Type: "hegrenade"},
+		}
+		held += 3
+	}
+	for _, player := range terrorists.Players {
+		engine.state.PlayerStates[player.Name].Grenades = []models.Grenade{}
+	}
+
+	// A player killed as the round goes live has no time to throw anything
+	deadOnArrival := ct.Players[0].Name
+	deaths := map[string]int64{deadOnArrival: 0}
+
+	eg := engine.eventGenerator
+	detail := eg.beginRound(engine.match, engine.state)
+	events := eg.throwUtility(detail, engine.state, deaths, int64(115*engine.config.TickRate), 1)
+
+	thrown := 0
+	for _, event := range events {
+		throw, ok := event.(*models.GrenadeThrowEvent)
+		if !ok {
+			continue
+		}
+		if throw.Player.Side != "CT" {
+			t.Fatalf("Expected a team without grenades to throw none, %s threw a %s", throw.Player.Name, throw.GrenadeType)
+		}
+		if throw.Player.Name == deadOnArrival {
+			t.Fatalf("Expected %s, dead from the start, to throw nothing", deadOnArrival)
+		}
+		thrown++
+	}
+	if kept := len(engine.state.PlayerStates[deadOnArrival].Grenades); kept != 3 {
+		t.Errorf("Expected %s to keep all 3 grenades, kept %d", deadOnArrival, kept)
+	}
+	if thrown == 0 {
+		t.Fatal("Expected the team with full utility to throw grenades")
+	}
+
+	remaining := 0
+	for _, player := range ct.Players {
+		remaining += len(engine.state.PlayerStates[player.Name].Grenades)
+	}
+	if thrown+remaining != held {
+		t.Errorf("Expected thrown grenades to be consumed: %d thrown + %d remaining != %d held", thrown, remaining, held)
+	}
+}
+
+func TestEventGenerator_AssistDamageThreshold(t *testing.T) {
+	testCases := []struct {
+		name      string
+		threshold float64
+		damage    int
+		assist    bool
+	}{
+		{"below default threshold", 0, 30, false},
+		{"above default threshold", 0, 60, true},
+		{"lowered threshold", 0.25, 30, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := models.DefaultMatchConfig()
+			eg := NewEventGenerator(rand.New(rand.NewSource(1)), &config)
+			if tc.threshold > 0 {
+				eg.SetAssistDamageThreshold(tc.threshold)
+			}
+
+			killer := &models.Player{Name: "killer", Side: "TERRORIST"}
+			teammate := &models.Player{Name: "teammate", Side: "TERRORIST"}
+			victim := &models.Player{Name: "victim", Side: "CT"}
+
+			eg.recordDamage(teammate, victim, tc.damage)
+			eg.recordDamage(killer, victim, 100-tc.damage)
+			hurt := &models.PlayerHurtEvent{Attacker: killer, Victim: victim, Weapon: "ak47", Damage: 100 - tc.damage, Health: 0, Hitgroup: 2}
+
+			kill := eg.checkForKill(killer, victim, 100, 1, hurt).(*models.KillEvent)
+			if (kill.Assister != nil) != tc.assist {
+				t.Fatalf("Expected assist=%v for %d damage, got assister %v", tc.assist, tc.damage, kill.Assister)
+			}
+			if tc.assist && (kill.Assister != teammate || teammate.Stats.Assists != 1) {
+				t.Errorf("Expected the teammate to be credited one assist, got %d", teammate.Stats.Assists)
+			}
+			if !tc.assist && teammate.Stats.Assists != 0 {
+				t.Errorf("Expected no assist to be credited, got %d", teammate.Stats.Assists)
+			}
+			if killer.Stats.Assists != 0 {
+				t.Error("Expected the killer not to assist their own kill")
+			}
+		})
+	}
+}
+
+func TestEventGenerator_DamageFallsWithinEngagements(t *testing.T) {
+	engine := newTestEngine(t, 7)
+	eg := engine.eventGenerator
+	ct := engine.getTeamBySide("CT")
+	terrorists := engine.getTeamBySide("TERRORIST")
+	tickRate := int64(engine.config.TickRate)
+	endTick := 115 * tickRate
+
+	// Two fights: an opening pick and a trade a few seconds apart later on
+	var kills []*models.KillEvent
+	for i, tick := range []int64{20 * tickRate, 60 * tickRate, 62 * tickRate} {
+		kills = append(kills, &models.KillEvent{
+			BaseEvent: models.NewBaseEvent("player_death", tick, 1),
+			Attacker:  &ct.Players[i],
+			Victim:    &terrorists.Players[i],
+		})
+	}
+	strategy := &RoundStrategy{Type: "elimination", Intensity: 1}
+
+	eg.engagements = eg.killEngagements(kills)
+	if len(eg.engagements) != 2 {
+		t.Fatalf("Expected overlapping fights to merge into 2 engagements, got %d", len(eg.engagements))
+	}
+	hits := eg.planDamageHits(eg.beginRound(engine.match, engine.state), endTick, strategy)
+	if len(hits) == 0 {
+		t.Fatal("Expected some hits to be planned")
+	}
+	for _, hit := range hits {
+		within := false
+		for _, window := range eg.engagements {
+			fought := func(player *models.Player) bool {
+				for _, fighter := range append(append([]*models.Player(nil), window.ct...), window.t...) {
+					if fighter == player {
+						return true
+					}
+				}
+				return false
+			}
+			within = within || (hit.tick >= window.startTick && hit.tick <= window.endTick && fought(hit.attacker) && fought(hit.victim))
+		}
+		if !within {
+			t.Errorf("Hit by %s on %s at tick %d is outside every fight they were in", hit.attacker.Name, hit.victim.Name, hit.tick)
+		}
+		if hit.attacker.Side == hit.victim.Side {
+			t.Errorf("Expected hits between enemies, %s hit teammate %s", hit.attacker.Name, hit.victim.Name)
+		}
+	}
+
+	// Uniform pacing spreads hits over the whole round instead
+	engine.config.DamagePacing = "uniform"
+	outside := 0
+	for _, hit := range eg.planDamageHits(eg.beginRound(engine.match, engine.state), endTick, strategy) {
+		if hit.tick > endTick {
+			t.Errorf("Hit at tick %d after the round ended at %d", hit.tick, endTick)
+		}
+		within := false
+		for _, window := range eg.engagements {
+			within = within || (hit.tick >= window.startTick && hit.tick <= window.endTick)
+		}
+		if !within {
+			outside++
+		}
+	}
+	if outside == 0 {
+		t.Error("Expected uniform pacing to put some hits outside engagements")
+	}
+}
+
+func TestEventGenerator_SimulateEngagement(t *testing.T) {
+	config := models.DefaultMatchConfig()
+	newPlayers := func(prefix, side string, n int) []*models.Player {
+		players := make([]*models.Player, n)
+		for i := range players {
+			players[i] = &models.Player{Name: fmt.Sprintf("%s%d", prefix, i+1), Side: side, Profile: models.DefaultPlayerProfile()}
+		}
+		return players
+	}
+
+	for _, size := range []struct{ attackers, defenders int }{{5, 1}, {5, 5}, {1, 1}} {
+		for seed := int64(1); seed <= 10; seed++ {
+			attackers := newPlayers("T", "TERRORIST", size.attackers)
+			defenders := newPlayers("CT", "CT", size.defenders)
+			events := NewEventGenerator(rand.New(rand.NewSource(0)), &config).SimulateEngagement(attackers, defenders, seed)
+
+			dead := map[string]int{}
+			for _, event := range events {
+				switch e := event.(type) {
+				case *models.KillEvent:
+					dead[models.NormalizeSide(e.Victim.Side)]++
+				case *models.PlayerHurtEvent:
+				default:
+					t.Errorf("%dv%d seed %d: unexpected %s event", size.attackers, size.defenders, seed, event.GetType())
+				}
+			}
+			if dead["CT"] != size.defenders && dead["TERRORIST"] != size.attackers {
+				t.Errorf("%dv%d seed %d: expected one side to be eliminated, got deaths %v", size.attackers, size.defenders, seed, dead)
+			}
+
+			again := NewEventGenerator(rand.New(rand.NewSource(0)), &config).SimulateEngagement(
+				newPlayers("T", "TERRORIST", size.attackers), newPlayers("CT", "CT", size.defenders), seed)
+			if len(again) != len(events) {
+				t.Errorf("%dv%d seed %d: expected the same fight for the same seed, got %d and %d events", size.attackers, size.defenders, seed, len(events), len(again))
+			}
+		}
+	}
+}
+
+func TestEventGenerator_FlashDurationByFacing(t *testing.T) {
+	for _, distance := range []float64{100, 600, 1200} {
+		facing, sideways, away := flashDuration(0, distance), flashDuration(90, distance), flashDuration(180, distance)
+		if !(facing > sideways && sideways > away) {
+			t.Errorf("At %.0f units expected facing > sideways > away, got %.2f, %.2f and %.2f", distance, facing, sideways, away)
+		}
+	}
+	if close, far := flashDuration(0, 100), flashDuration(0, 1200); close <= far {
+		t.Errorf("Expected a close flash to blind longer, got %.2f vs %.2f", close, far)
+	}
+
+	config := models.DefaultMatchConfig()
+	eg := NewEventGenerator(rand.New(rand.NewSource(1)), &config)
+	detonation := models.Vector3{X: 500}
+	lookingAt := &models.PlayerState{ViewAngle: models.Vector3{Y: 0}}
+	lookingAway := &models.PlayerState{ViewAngle: models.Vector3{Y: 180}}
+	facingDuration, _ := eg.blindDuration(detonation, lookingAt)
+	awayDuration, _ := eg.blindDuration(detonation, lookingAway)
+	if facingDuration <= awayDuration {
+		t.Errorf("Expected a facing-away victim to be blinded shorter, got %.2f vs %.2f facing", awayDuration, facingDuration)
+	}
+	if _, blinded := eg.blindDuration(detonation, &models.PlayerState{Position: models.Vector3{X: 5000}}); blinded {
+		t.Error("Expected a player out of range not to be blinded")
+	}
+}
+
+func TestEventGenerator_FlashbangLineOfSight(t *testing.T) {
+	engine := newTestEngine(t, 42)
+	engine.config.IncludePositions = true
+	for _, team := range engine.match.Teams {
+		for i, player := range team.Players {
+			playerState := engine.state.PlayerStates[player.Name]
+			playerState.Grenades = []models.Grenade{{Type: "flashbang"}, {Type: "flashbang"}}
+			playerState.Position = models.Vector3{X: float64(i * 100)}
+			if team.Side == "TERRORIST" {
+				playerState.Position.Y = 400
+			}
+			if i >= 3 {
+				// Far from every flash, which pop around their throwers
+				playerState.Grenades = nil
+				playerState.Position = models.Vector3{X: 20000}
+			}
+		}
+	}
+
+	eg := engine.eventGenerator
+	detail := eg.beginRound(engine.match, engine.state)
+	endTick := int64(115 * engine.config.TickRate)
+	events := eg.throwUtility(detail, engine.state, map[string]int64{}, endTick, 1)
+
+	blinds := 0
+	for _, event := range events {
+		flash, ok := event.(*models.FlashbangEvent)
+		if !ok {
+			continue
+		}
+		if len(flash.Durations) != len(flash.Flashed) {
+			t.Fatalf("Expected a duration per flashed player, got %d for %d", len(flash.Durations), len(flash.Flashed))
+		}
+		lines := strings.Split(flash.ToLogLine(), "\n")
+		for i, player := range flash.Flashed {
+			blinds++
+			playerState := detail.state.PlayerStates[player.Name]
+			if playerState.Position.X >= 20000 {
+				t.Errorf("%s was blinded out of the flash's range", player.Name)
+			}
+			// How long they are blinded follows from where they look at the round's start
+			if want, _ := eg.blindDuration(flash.Position, playerState); flash.Durations[i] != want {
+				t.Errorf("Expected %s to be blinded for %.2f facing %.0f degrees, got %.2f", player.Name, want, playerState.ViewAngle.Y, flash.Durations[i])
+			}
+			if !strings.Contains(lines[i], fmt.Sprintf("blinded for %.2f", flash.Durations[i])) {
+				t.Errorf("Expected %s's own duration in %q", player.Name, lines[i])
+			}
+			if flash.Durations[i] > flash.Duration {
+				t.Errorf("Expected the event duration to be the longest blind")
+			}
+		}
+	}
+	if blinds == 0 {
+		t.Fatal("Expected flashes to blind the enemies in range")
+	}
+}
@@ -1,8 +1,12 @@
 package generator
 
 import (
+	"encoding/binary"
 	"fmt"
+	"hash/fnv"
 	"math/rand"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/noueii/nocs-log-generator/backend/pkg/models"
@@ -18,6 +22,7 @@ type WebSocketManager interface {
 // MatchEngine handles the core match generation logic
 type MatchEngine struct {
 	config           *models.MatchConfig
+	simConfig        *models.SimulationConfig
 	match            *models.Match
 	state            *models.MatchState
 	eventFactory     *models.EventFactory
@@ -26,6 +31,8 @@ type MatchEngine struct {
 	economyManager   *EconomyManager
 	logFormatter     *LogFormatter
 	rng              *rand.Rand
+	rngSource        *snapshotSource
+	seed             int64
 	wsManager        WebSocketManager
 	
 	// Match settings
@@ -36,14 +43,38 @@ type MatchEngine struct {
 	// Economics
 	startMoney       int
 	maxMoney         int
-	killReward       int
 	winBonus         int
 	lossBonus        []int // Escalating loss bonus
 	
 	// Simulation state
 	currentTick      int64
+	roundStartTick   int64 // Tick the current round went live
+	roundFirstEvent  int   // Index of the current round's first event in the match events
+	roundRNGState    models.RNGState // Position of the random stream when the current round started
 	tickRate         int
 	totalEvents      int64
+	eventLimitErr    error
+	
+	// Player statistics at the end of the previous round
+	prevStats        map[string]models.PlayerStats
+	roundStatDeltas  []PlayerStatDelta
+}
+
+// casterName is the speaker caster recaps are attributed to
+const casterName = "Caster"
+
+// nameChangeProbability is the chance a player joins under an alias during warmup
+const nameChangeProbability = 0.1
+
+// PlayerStatDelta is a compact per-player stat update for a single round
+type PlayerStatDelta struct {
+	Name   string  `json:"name"`
+	Kills  int     `json:"kills"`  // Kills this round
+	Deaths int     `json:"deaths"` // Deaths this round
+	EnemiesFlashed int `json:"enemies_flashed"` // Enemies flashed this round
+	FlashAssists   int `json:"flash_assists"`   // Flash assists this round
+	ADR    float64 `json:"adr"`
+	Rating float64 `json:"rating"`
 }
 
 // NewMatchEngine creates a new match engine with the given configuration
@@ -53,11 +84,17 @@ func NewMatchEngine(config *models.MatchConfig, match *models.Match) *MatchEngin
 		seed = time.Now().UnixNano()
 	}
 	
+	simConfig := models.DefaultSimulationConfig()
+	rngSource := newSnapshotSource(seed)
+	
 	engine := &MatchEngine{
 		config:       config,
+		simConfig:    &simConfig,
 		match:        match,
 		eventFactory: models.NewEventFactory(),
-		rng:          rand.New(rand.NewSource(seed)),
+		rng:          rand.New(rngSource),
+		rngSource:    rngSource,
+		seed:         seed,
 		
 		// Standard CS2 settings
 		roundTime:    time.Second * 115,
@@ -67,28 +104,124 @@ func NewMatchEngine(config *models.MatchConfig, match *models.Match) *MatchEngin
 		// Economics
 		startMoney:   config.StartMoney,
 		maxMoney:     config.MaxMoney,
-		killReward:   300,
 		winBonus:     3250,
-		lossBonus:    []int{1400, 1900, 2400, 2900, 3400}, // CS2 loss bonus progression
+		lossBonus:    models.DefaultLossBonusLadder(), // CS2 loss bonus progression
 		
 		// Technical settings
 		tickRate:     config.TickRate,
 		currentTick:  0,
 		totalEvents:  0,
+		prevStats:    make(map[string]models.PlayerStats),
+	}
+	
+	if engine.maxMoney <= 0 {
+		engine.maxMoney = models.DefaultMatchConfig().MaxMoney
 	}
 	
 	// Initialize subsystems
 	engine.roundSimulator = NewRoundSimulator(engine.rng, models.NewEconomyManager(), config)
 	engine.eventGenerator = NewEventGenerator(engine.rng, config)
+	engine.roundSimulator.SetEventGenerator(engine.eventGenerator)
 	engine.economyManager = NewEconomyManager(engine.rng)
+	engine.economyManager.SetMaxMoney(engine.maxMoney)
+	engine.economyManager.SetAntiEcoThreshold(config.GetAntiEcoThreshold())
+	engine.economyManager.SetKillReward("knife", config.KnifeKillReward)
+	engine.economyManager.SetKillReward("grenade", config.GrenadeKillReward)
 	engine.logFormatter = NewLogFormatter(config)
 	
 	// Initialize match state
 	engine.initializeMatchState()
+	serverConfig := models.DefaultServerConfig()
+	config.ServerTimers.Apply(&serverConfig)
+	engine.SetServerConfig(&serverConfig)
+	if len(config.LossBonusLadder) > 0 {
+		engine.setLossBonusLadder(config.LossBonusLadder)
+	}
 	
 	return engine
 }
 
+// SetSimulationConfig sets the simulation settings used by the engine. Missing
+// limits are filled with their defaults, and an invalid configuration is rejected
+// without changing the engine's settings.
+func (e *MatchEngine) SetSimulationConfig(simConfig *models.SimulationConfig) error {
+	if simConfig == nil {
+		return nil
+	}
+	simConfig.ApplyDefaults()
+	if err := simConfig.Validate(); err != nil {
+		return fmt.Errorf("invalid simulation config: %w", err)
+	}
+	
+	e.simConfig = simConfig
+	e.eventGenerator.SetAssistDamageThreshold(simConfig.AssistDamageThreshold)
+	e.eventGenerator.SetDamageModel(simConfig.DamageVariance, simConfig.LethalityBias)
+	e.roundSimulator.SetSimulationConfig(simConfig)
+	return nil
+}
+
+// SetServerConfig sets the server settings used for round timers
+func (e *MatchEngine) SetServerConfig(serverConfig *models.ServerConfig) {
+	if serverConfig == nil {
+		return
+	}
+	e.roundTime = time.Duration(serverConfig.RoundTime) * time.Second
+	e.freezeTime = time.Duration(serverConfig.FreezetimeLength) * time.Second
+	e.bombTimer = time.Duration(serverConfig.BombTimer) * time.Second
+	e.roundSimulator.SetServerConfig(serverConfig)
+	if len(serverConfig.LossBonusLadder) > 0 {
+		e.setLossBonusLadder(serverConfig.LossBonusLadder)
+	}
+}
+
+// setLossBonusLadder replaces the loss bonus ladder used for round end payouts
+func (e *MatchEngine) setLossBonusLadder(ladder []int) {
+	e.lossBonus = ladder
+	e.economyManager.SetLossBonusLadder(ladder)
+	for _, teamEconomy := range e.state.TeamEconomies {
+		teamEconomy.LossBonus = models.LossBonusForStreak(ladder, teamEconomy.ConsecutiveLosses)
+	}
+}
+
+// StateAtRound reconstructs the match state as it was at the end of round n from
+// the snapshots kept in the round history
+func (e *MatchEngine) StateAtRound(n int) (*models.MatchState, error) {
+	var round *models.RoundData
+	for i := range e.match.Rounds {
+		if e.match.Rounds[i].RoundNumber == n {
+			round = &e.match.Rounds[i]
+			break
+		}
+	}
+	if round == nil {
+		return nil, fmt.Errorf("round %d has not been played (%d rounds played)", n, len(e.match.Rounds))
+	}
+	
+	state := &models.MatchState{
+		CurrentRound:   n,
+		Scores:         make(map[string]int),
+		TeamEconomies:  make(map[string]*models.TeamEconomy),
+		PlayerStates:   make(map[string]*models.PlayerState),
+		RoundStartTime: round.StartTime,
+	}
+	for teamName, score := range round.Scores {
+		state.Scores[teamName] = score
+	}
+	for teamName, economy := range round.Economy {
+		state.TeamEconomies[teamName] = &economy
+	}
+	for playerName, playerState := range round.PlayerStates {
+		state.PlayerStates[playerName] = playerState.Clone()
+	}
+	
+	return state, nil
+}
+
+// Match returns the match being generated by the engine
+func (e *MatchEngine) Match() *models.Match {
+	return e.match
+}
+
 // SetWebSocketManager sets the WebSocket manager for streaming events
 func (e *MatchEngine) SetWebSocketManager(wsManager WebSocketManager) {
 	e.wsManager = wsManager
@@ -106,57 +239,143 @@ func (e *MatchEngine) initializeMatchState() {
 		CurrentTick:   0,
 	}
 	
-	// Initialize team scores and economies
-	for _, team := range e.match.Teams {
+	// Initialize team scores and economies. A loss streak or player money given in
+	// the request seeds the starting economy, e.g. for a match joined in progress.
+	for i := range e.match.Teams {
+		team := &e.match.Teams[i]
 		e.state.Scores[team.Name] = 0
 		
 		teamEconomy := &models.TeamEconomy{
-			TotalMoney:        e.startMoney * 5,
-			AverageMoney:      e.startMoney,
-			ConsecutiveLosses: 0,
-			LossBonus:         e.lossBonus[0],
+			ConsecutiveLosses: team.Economy.ConsecutiveLosses,
+			LossBonus:         models.LossBonusForStreak(e.lossBonus, team.Economy.ConsecutiveLosses),
 		}
 		e.state.TeamEconomies[team.Name] = teamEconomy
 		
 		// Initialize player states
-		for i, player := range team.Players {
+		for j, player := range team.Players {
+			money := e.startMoney
+			if player.Economy.Money > 0 {
+				money = e.capMoney(player.Economy.Money)
+			}
+			
 			playerState := &models.PlayerState{
 				IsAlive:      true,
 				Health:       100,
 				Armor:        0,
 				HasHelmet:    false,
 				HasDefuseKit: false,
-				Money:        e.startMoney,
-				Position:     e.getSpawnPosition(team.Side, i),
+				Money:        money,
+				Position:     e.getSpawnPosition(team.Side, j),
 				Grenades:     make([]models.Grenade, 0),
 			}
 			e.state.PlayerStates[player.Name] = playerState
 		}
+		e.updateTeamEconomy(team)
 	}
 }
 
 // GenerateMatch executes the complete match generation process
 func (e *MatchEngine) GenerateMatch() error {
 	e.match.Status = "generating"
-	e.match.StartTime = time.Now()
+	if e.match.StartTime.IsZero() {
+		e.match.StartTime = time.Now()
+	}
 	
-	// Generate match events
-	for e.state.CurrentRound < e.match.MaxRounds && !e.isMatchFinished() {
-		if err := e.playRound(); err != nil {
-			return fmt.Errorf("error playing round %d: %w", e.state.CurrentRound+1, err)
+	// Generate match events round by round
+	for e.HasNextRound() {
+		if _, _, err := e.PlayNextRound(); err != nil {
+			return err
 		}
 	}
 	
-	// Finalize match
-	e.finalizeMatch()
-	
 	return nil
 }
 
+// RNGState returns the current position of the engine's random stream
+func (e *MatchEngine) RNGState() models.RNGState {
+	return e.rngSource.State()
+}
+
+// RestoreRNGState moves the engine's random stream back (or forward) to a position
+// returned by RNGState or recorded in a round, so the next round replays the
+// randomness that produced it
+func (e *MatchEngine) RestoreRNGState(state models.RNGState) {
+	e.rngSource.Restore(state)
+}
+
+// RoundSeed returns the child seed the given round uses when seed-per-round is enabled
+func (e *MatchEngine) RoundSeed(roundNum int) int64 {
+	return roundSeed(e.seed, roundNum)
+}
+
+// reseedRound restarts the shared RNG from the current round's child seed, so a
+// round plays out the same no matter how much randomness earlier rounds used
+func (e *MatchEngine) reseedRound() {
+	if e.config.SeedPerRound {
+		e.rng.Seed(e.RoundSeed(e.state.CurrentRound))
+	}
+}
+
+// roundSeed derives a round's child seed by hashing the match seed with the round number
+func roundSeed(matchSeed int64, roundNum int) int64 {
+	var buf [16]byte
+	binary.LittleEndian.PutUint64(buf[:8], uint64(matchSeed))
+	binary.LittleEndian.PutUint64(buf[8:], uint64(roundNum))
+	
+	hash := fnv.New64a()
+	hash.Write(buf[:])
+	return int64(hash.Sum64())
+}
+
+// HasNextRound reports whether the match has another round to play
+func (e *MatchEngine) HasNextRound() bool {
+	return e.match.Status != "completed" && e.state.CurrentRound < e.match.MaxRounds && !e.isMatchFinished()
+}
+
+// PlayNextRound advances the match by exactly one round and returns its data and events.
+// The match is finalized once the last round has been played.
+func (e *MatchEngine) PlayNextRound() (*models.RoundData, []models.GameEvent, error) {
+	if !e.HasNextRound() {
+		return nil, nil, fmt.Errorf("match %s has no rounds left to play", e.match.ID)
+	}
+	if err := models.ValidateAssignedSides(e.match.Teams); err != nil {
+		return nil, nil, fmt.Errorf("match %s cannot be played: %w", e.match.ID, err)
+	}
+	
+	if e.match.Status != "generating" {
+		e.match.Status = "generating"
+		if e.match.StartTime.IsZero() {
+			e.match.StartTime = time.Now()
+		}
+	}
+	
+	firstEvent := len(e.match.Events)
+	if err := e.playRound(); err != nil {
+		return nil, nil, fmt.Errorf("error playing round %d: %w", e.state.CurrentRound, err)
+	}
+	
+	roundEvents := make([]models.GameEvent, len(e.match.Events)-firstEvent)
+	copy(roundEvents, e.match.Events[firstEvent:])
+	roundData := &e.match.Rounds[len(e.match.Rounds)-1]
+	
+	if !e.HasNextRound() {
+		if err := e.finalizeMatch(); err != nil {
+			return nil, nil, err
+		}
+	}
+	
+	return roundData, roundEvents, nil
+}
+
 // GenerateMatchWithStreaming executes the complete match generation process with WebSocket streaming
 func (e *MatchEngine) GenerateMatchWithStreaming() error {
+	if err := models.ValidateAssignedSides(e.match.Teams); err != nil {
+		return fmt.Errorf("match %s cannot be played: %w", e.match.ID, err)
+	}
 	e.match.Status = "generating"
-	e.match.StartTime = time.Now()
+	if e.match.StartTime.IsZero() {
+		e.match.StartTime = time.Now()
+	}
 	
 	// Broadcast match start event
 	if e.wsManager != nil {
@@ -192,7 +411,12 @@ func (e *MatchEngine) GenerateMatchWithStreaming() error {
 	}
 	
 	// Finalize match
-	e.finalizeMatch()
+	if err := e.finalizeMatch(); err != nil {
+		if e.wsManager != nil {
+			e.wsManager.BroadcastMatchError(e.match.ID, err.Error())
+		}
+		return err
+	}
 	
 	// Broadcast match completion
 	if e.wsManager != nil {
@@ -211,11 +435,21 @@ func (e *MatchEngine) GenerateMatchWithStreaming() error {
 
 // playRound executes a single round of the match
 func (e *MatchEngine) playRound() error {
+	e.roundFirstEvent = len(e.match.Events)
+	e.roundRNGState = e.RNGState()
+	if e.state.CurrentRound == 0 {
+		e.addWarmupPreamble()
+		e.addServerCvar("mp_warmup_end", "1")
+		e.addServerCvar("mp_restartgame", "1")
+	}
+	
 	e.state.CurrentRound++
 	e.eventFactory.SetRound(e.state.CurrentRound)
+	e.reseedRound()
 	
 	// Check for side switch at halftime
-	if e.state.CurrentRound == (e.match.MaxRounds/2)+1 {
+	if e.state.CurrentRound == e.match.HalftimeRound()+1 {
+		e.addServerCvar("mp_halftime", "1")
 		e.switchSides()
 	}
 	
@@ -224,15 +458,20 @@ func (e *MatchEngine) playRound() error {
 		return fmt.Errorf("buy phase error: %w", err)
 	}
 	
-	// Start round
-	roundStartTime := time.Now()
-	e.state.RoundStartTime = roundStartTime
+	// Start round once freeze time is over
+	e.currentTick += models.DurationToTicks(e.freezeTime, e.tickRate)
+	roundStartTick := e.currentTick
+	e.roundStartTick = roundStartTick
+	e.eventFactory.SetTick(roundStartTick)
+	e.state.RoundStartTime = e.match.StartTime.Add(models.TicksToDuration(roundStartTick, e.tickRate))
 	e.state.IsFreezeTime = false
 	e.state.IsLive = true
 	
 	// Create round start event
-	ctTeam := e.getTeamBySide("CT")
-	tTeam := e.getTeamBySide("TERRORIST")
+	ctTeam, tTeam, err := e.sideTeams()
+	if err != nil {
+		return err
+	}
 	
 	startEvent := e.eventFactory.CreateRoundStartEvent(
 		e.state.Scores[ctTeam.Name],
@@ -243,10 +482,12 @@ func (e *MatchEngine) playRound() error {
 	e.addEvent(startEvent)
 	
 	// Simulate round events using the round simulator
+	e.roundSimulator.SetRoundWinner(e.plannedRoundWinner())
 	roundResult, roundEvents, err := e.roundSimulator.SimulateRound(e.match, e.state, e.state.CurrentRound)
 	if err != nil {
 		return fmt.Errorf("round simulation error: %w", err)
 	}
+	e.alignRoundEvents(roundEvents, roundStartTick, roundResult)
 	
 	// Add all round events to the match
 	for _, event := range roundEvents {
@@ -261,26 +502,40 @@ func (e *MatchEngine) playRound() error {
 	// Update match state
 	e.updateMatchStatistics()
 	
-	return nil
+	return e.eventLimitErr
 }
 
 // playRoundWithStreaming executes a single round of the match with WebSocket streaming
 func (e *MatchEngine) playRoundWithStreaming() error {
+	e.roundFirstEvent = len(e.match.Events)
+	e.roundRNGState = e.RNGState()
+	if e.state.CurrentRound == 0 {
+		e.addWarmupPreamble()
+		e.addServerCvar("mp_warmup_end", "1")
+		e.addServerCvar("mp_restartgame", "1")
+	}
+	
 	e.state.CurrentRound++
 	e.eventFactory.SetRound(e.state.CurrentRound)
+	e.reseedRound()
 	
 	// Broadcast round start event
 	if e.wsManager != nil {
+		ctTeam, tTeam, err := e.sideTeams()
+		if err != nil {
+			return err
+		}
 		e.wsManager.BroadcastMatchEvent(e.match.ID, "round_start", map[string]interface{}{
 			"match_id": e.match.ID,
 			"round_number": e.state.CurrentRound,
-			"ct_score": e.state.Scores[e.getTeamBySide("CT").Name],
-			"t_score": e.state.Scores[e.getTeamBySide("TERRORIST").Name],
+			"ct_score": e.state.Scores[ctTeam.Name],
+			"t_score": e.state.Scores[tTeam.Name],
 		})
 	}
 	
 	// Check for side switch at halftime
-	if e.state.CurrentRound == (e.match.MaxRounds/2)+1 {
+	if e.state.CurrentRound == e.match.HalftimeRound()+1 {
+		e.addServerCvar("mp_halftime", "1")
 		e.switchSides()
 		
 		// Broadcast side switch event
@@ -316,15 +571,20 @@ func (e *MatchEngine) playRoundWithStreaming() error {
 		})
 	}
 	
-	// Start round
-	roundStartTime := time.Now()
-	e.state.RoundStartTime = roundStartTime
+	// Start round once freeze time is over
+	e.currentTick += models.DurationToTicks(e.freezeTime, e.tickRate)
+	roundStartTick := e.currentTick
+	e.roundStartTick = roundStartTick
+	e.eventFactory.SetTick(roundStartTick)
+	e.state.RoundStartTime = e.match.StartTime.Add(models.TicksToDuration(roundStartTick, e.tickRate))
 	e.state.IsFreezeTime = false
 	e.state.IsLive = true
 	
 	// Create round start event
-	ctTeam := e.getTeamBySide("CT")
-	tTeam := e.getTeamBySide("TERRORIST")
+	ctTeam, tTeam, err := e.sideTeams()
+	if err != nil {
+		return err
+	}
 	
 	startEvent := e.eventFactory.CreateRoundStartEvent(
 		e.state.Scores[ctTeam.Name],
@@ -335,10 +595,12 @@ func (e *MatchEngine) playRoundWithStreaming() error {
 	e.addEvent(startEvent)
 	
 	// Simulate round events using the round simulator
+	e.roundSimulator.SetRoundWinner(e.plannedRoundWinner())
 	roundResult, roundEvents, err := e.roundSimulator.SimulateRound(e.match, e.state, e.state.CurrentRound)
 	if err != nil {
 		return fmt.Errorf("round simulation error: %w", err)
 	}
+	e.alignRoundEvents(roundEvents, roundStartTick, roundResult)
 	
 	// Add all round events to the match and broadcast them
 	for _, event := range roundEvents {
@@ -355,24 +617,45 @@ func (e *MatchEngine) playRoundWithStreaming() error {
 		return fmt.Errorf("round end handling error: %w", err)
 	}
 	
+	// Update match state
+	e.updateMatchStatistics()
+	
 	// Broadcast round end event
 	if e.wsManager != nil {
-		e.wsManager.BroadcastMatchEvent(e.match.ID, "round_end", map[string]interface{}{
+		roundEndData := map[string]interface{}{
 			"match_id": e.match.ID,
 			"round_number": e.state.CurrentRound,
 			"winner": roundResult.Winner,
 			"reason": roundResult.Reason,
-			"mvp": roundResult.MVP.Name,
+			"mvp": mvpName(roundResult.MVP),
 			"ct_score": e.state.Scores[ctTeam.Name],
 			"t_score": e.state.Scores[tTeam.Name],
 			"duration": roundResult.Duration.Seconds(),
-		})
+		}
+		if e.config.IncludeRoundStats {
+			roundEndData["player_stats"] = e.roundStatDeltas
+		}
+		e.wsManager.BroadcastMatchEvent(e.match.ID, "round_end", roundEndData)
 	}
 	
-	// Update match state
-	e.updateMatchStatistics()
+	return e.eventLimitErr
+}
+
+// alignRoundEvents moves round-relative event ticks onto the match clock, orders them
+// by tick and advances the clock to the end of the round
+func (e *MatchEngine) alignRoundEvents(roundEvents []models.GameEvent, roundStartTick int64, result *RoundResult) {
+	for _, event := range roundEvents {
+		event.SetTick(roundStartTick + event.GetTick())
+	}
+	sort.SliceStable(roundEvents, func(i, j int) bool {
+		return roundEvents[i].GetTick() < roundEvents[j].GetTick()
+	})
 	
-	return nil
+	e.currentTick = roundStartTick + models.DurationToTicks(result.Duration, e.tickRate)
+	if n := len(roundEvents); n > 0 && roundEvents[n-1].GetTick() > e.currentTick {
+		e.currentTick = roundEvents[n-1].GetTick()
+	}
+	e.eventFactory.SetTick(e.currentTick)
 }
 
 // broadcastGameEvent broadcasts specific game events via WebSocket
@@ -416,13 +699,25 @@ func (e *MatchEngine) broadcastGameEvent(event models.GameEvent) {
 			"round": e.state.CurrentRound,
 			"site": evt.Site,
 		})
+
+	case *models.PlayerPositionEvent:
+		// Only delivered to subscribers that opted in to position samples
+		e.wsManager.BroadcastMatchEvent(e.match.ID, "player_position", map[string]interface{}{
+			"match_id": e.match.ID,
+			"round": e.state.CurrentRound,
+			"tick": evt.Tick,
+			"player": evt.Player.Name,
+			"side": evt.Player.Side,
+			"position": evt.Position,
+			"view_angle": evt.ViewAngle,
+		})
 	}
 }
 
 // simulateRoundEvents generates events for a single round (legacy method, now unused)
 func (e *MatchEngine) simulateRoundEvents() (*RoundResult, error) {
 	roundStartTick := e.currentTick
-	maxRoundTicks := int64(e.roundTime.Seconds()) * int64(e.tickRate)
+	maxRoundTicks := models.DurationToTicks(e.roundTime, e.tickRate)
 	
 	// Initialize round state
 	e.resetPlayerStates()
@@ -508,7 +803,7 @@ func (e *MatchEngine) simulateBombPlant() *RoundResult {
 			Winner:   "CT",
 			Reason:   "bomb_defused",
 			MVP:      defuser,
-			Duration: time.Duration(e.currentTick/int64(e.tickRate)) * time.Second,
+			Duration: models.TicksToDuration(e.currentTick, e.tickRate),
 			EndTick:  e.currentTick,
 		}
 	} else {
@@ -524,7 +819,7 @@ func (e *MatchEngine) simulateBombPlant() *RoundResult {
 			Winner:   "TERRORIST",
 			Reason:   "bomb_exploded",
 			MVP:      planter,
-			Duration: time.Duration(e.currentTick/int64(e.tickRate)) * time.Second,
+			Duration: models.TicksToDuration(e.currentTick, e.tickRate),
 			EndTick:  e.currentTick,
 		}
 	}
@@ -551,7 +846,7 @@ func (e *MatchEngine) simulateElimination() *RoundResult {
 			Winner:   "TERRORIST",
 			Reason:   "elimination",
 			MVP:      e.selectMVP(tTeam),
-			Duration: time.Duration(e.currentTick/int64(e.tickRate)) * time.Second,
+			Duration: models.TicksToDuration(e.currentTick, e.tickRate),
 			EndTick:  e.currentTick,
 		}
 	} else if tAlive == 0 {
@@ -559,7 +854,7 @@ func (e *MatchEngine) simulateElimination() *RoundResult {
 			Winner:   "CT",
 			Reason:   "elimination",
 			MVP:      e.selectMVP(ctTeam),
-			Duration: time.Duration(e.currentTick/int64(e.tickRate)) * time.Second,
+			Duration: models.TicksToDuration(e.currentTick, e.tickRate),
 			EndTick:  e.currentTick,
 		}
 	}
@@ -624,19 +919,21 @@ func (e *MatchEngine) generateKillEvent() *models.KillEvent {
 
 // handleBuyPhase manages the economy and equipment purchases
 func (e *MatchEngine) handleBuyPhase() error {
+	e.roundSimulator.decideBuyTypes(e.match, e.state, e.state.CurrentRound)
+	
 	for _, team := range e.match.Teams {
 		teamEconomy := e.state.TeamEconomies[team.Name]
 		
 		// Simple buy logic based on team economy
 		avgMoney := teamEconomy.AverageMoney
+		teamSave := e.isCoordinatedSave(teamEconomy)
 		
 		for i, player := range team.Players {
 			playerState := e.state.PlayerStates[player.Name]
 			
 			// Buy armor if affordable
-			if playerState.Money >= 650 && playerState.Armor == 0 {
-				playerState.Armor = 100
-				playerState.HasHelmet = true
+			if !teamSave && playerState.Money >= 1000 && playerState.Armor == 0 {
+				playerState.EquipArmor(true)
 				playerState.Money -= 1000 // Helmet + armor
 				
 				purchaseEvent := &models.ItemPurchaseEvent{
@@ -645,12 +942,15 @@ func (e *MatchEngine) handleBuyPhase() error {
 					Item:      "item_assaultsuit",
 					Cost:      1000,
 				}
-				e.addEvent(purchaseEvent)
+				e.addPurchaseEvent(purchaseEvent)
 			}
 			
-			// Buy primary weapon based on economy
-			if playerState.PrimaryWeapon == nil {
+			// Buy primary weapon based on economy; pistol rounds never allow one
+			if !teamSave && playerState.PrimaryWeapon == nil && !e.match.IsPistolRound(e.state.CurrentRound) {
 				weapon := e.selectBuyWeapon(avgMoney, player.Role)
+				if e.config.ForceBuyType != "" {
+					weapon = e.selectForcedBuyWeapon(playerState.Money, player.Role)
+				}
 				if weapon != nil && playerState.Money >= weapon.Price {
 					playerState.PrimaryWeapon = weapon
 					playerState.Money -= weapon.Price
@@ -661,28 +961,28 @@ func (e *MatchEngine) handleBuyPhase() error {
 						Item:      weapon.Name,
 						Cost:      weapon.Price,
 					}
-					e.addEvent(purchaseEvent)
+					e.addPurchaseEvent(purchaseEvent)
 				}
 			}
 			
-			// Buy grenades
+			// Buy a grenade the player still has room for
 			if playerState.Money >= 300 && len(playerState.Grenades) < 2 {
 				grenadeType := e.selectGrenade(team.Side)
-				grenade := models.Grenade{Type: grenadeType, Price: 300}
-				playerState.Grenades = append(playerState.Grenades, grenade)
-				playerState.Money -= 300
-				
-				purchaseEvent := &models.ItemPurchaseEvent{
-					BaseEvent: models.NewBaseEvent("item_purchase", e.currentTick, e.state.CurrentRound),
-					Player:    &team.Players[i],
-					Item:      grenadeType,
-					Cost:      300,
+				if playerState.AddGrenade(models.Grenade{Type: grenadeType, Price: 300}) {
+					playerState.Money -= 300
+					
+					purchaseEvent := &models.ItemPurchaseEvent{
+						BaseEvent: models.NewBaseEvent("item_purchase", e.currentTick, e.state.CurrentRound),
+						Player:    &team.Players[i],
+						Item:      grenadeType,
+						Cost:      300,
+					}
+					e.addPurchaseEvent(purchaseEvent)
 				}
-				e.addEvent(purchaseEvent)
 			}
 			
 			// Buy defuse kit for CTs
-			if team.Side == "CT" && !playerState.HasDefuseKit && playerState.Money >= 400 {
+			if models.NormalizeSide(team.Side) == "CT" && !playerState.HasDefuseKit && playerState.Money >= 400 {
 				playerState.HasDefuseKit = true
 				playerState.Money -= 400
 				
@@ -692,7 +992,7 @@ func (e *MatchEngine) handleBuyPhase() error {
 					Item:      "item_defuser",
 					Cost:      400,
 				}
-				e.addEvent(purchaseEvent)
+				e.addPurchaseEvent(purchaseEvent)
 			}
 		}
 		
@@ -703,11 +1003,108 @@ func (e *MatchEngine) handleBuyPhase() error {
 	return nil
 }
 
+// isCoordinatedSave reports whether a team saves together this round, so that no player
+// buys a primary or armor however much money they hold. A team saves when its economy
+// calls for an eco round, and EconomicRealism is the chance every player sticks to it.
+func (e *MatchEngine) isCoordinatedSave(teamEconomy *models.TeamEconomy) bool {
+	if e.config.ForceBuyType != "" || e.match.IsPistolRound(e.state.CurrentRound) {
+		return false
+	}
+	if teamEconomy.BuyType != "eco" {
+		return false
+	}
+	return e.rng.Float64() < e.simConfig.EconomicRealism
+}
+
+// addWarmupPreamble logs every player connecting and entering the game before round 1.
+// Some players join under an alias and are renamed to their roster name, so the
+// roster name used to key match state never changes once rounds are played.
+func (e *MatchEngine) addWarmupPreamble() {
+	if e.config.FastMode {
+		return
+	}
+	
+	for t := range e.match.Teams {
+		for i := range e.match.Teams[t].Players {
+			player := &e.match.Teams[t].Players[i]
+			address := fmt.Sprintf("%d.%d.%d.%d:27005", 10+e.rng.Intn(180), e.rng.Intn(256), e.rng.Intn(256), 1+e.rng.Intn(254))
+			
+			joined := player
+			if e.rng.Float64() < nameChangeProbability {
+				// Connect events keep a copy so they render the alias rather than the roster name
+				alias := *player
+				alias.Name = fmt.Sprintf("%s%d", strings.ToLower(player.Name), e.rng.Intn(100))
+				joined = &alias
+			}
+			
+			e.addEvent(e.eventFactory.CreatePlayerConnectEvent(joined, address))
+			e.addEvent(e.eventFactory.CreatePlayerEnterEvent(joined))
+			if joined != player {
+				e.addEvent(e.eventFactory.CreateNameChangeEvent(player, joined.Name, player.Name))
+			}
+			if e.config.AutoAssignedSides {
+				// Players join unassigned and are moved onto the side the coin flip picked
+				e.addEvent(e.eventFactory.CreateTeamSwitchEvent(player, "Unassigned", player.Side))
+			}
+		}
+	}
+}
+
+// addServerCvar logs a server_cvar line at the current tick when phase transition
+// cvars are enabled
+func (e *MatchEngine) addServerCvar(name, value string) {
+	if !e.config.ServerCvars || e.config.FastMode {
+		return
+	}
+	e.eventFactory.SetTick(e.currentTick)
+	e.addEvent(e.eventFactory.CreateServerCommandEvent(name, value))
+}
+
+// addServerStatus logs a server status snapshot after every StatusInterval rounds
+func (e *MatchEngine) addServerStatus() {
+	interval := e.config.StatusInterval
+	if interval <= 0 || e.config.FastMode || e.state.CurrentRound%interval != 0 {
+		return
+	}
+	
+	players := 0
+	for _, team := range e.match.Teams {
+		players += len(team.Players)
+	}
+	e.addEvent(&models.ServerStatusEvent{
+		BaseEvent: models.NewBaseEvent("server_status", e.currentTick, e.state.CurrentRound),
+		Hostname:  e.config.ServerName,
+		Map:       e.config.Map,
+		Players:   players,
+		Uptime:    int64(models.TicksToDuration(e.currentTick, e.tickRate) / time.Second),
+	})
+}
+
+// addPurchaseEvent records a purchase unless fast mode skips purchase logging
+func (e *MatchEngine) addPurchaseEvent(event *models.ItemPurchaseEvent) {
+	if e.config.FastMode {
+		return
+	}
+	e.addEvent(event)
+}
+
+// creditRoundWin adds the round to the score of the team playing the winning side.
+// Scores are kept per team, not per side, so they carry over when sides switch.
+func (e *MatchEngine) creditRoundWin(winner string) {
+	if winningTeam := e.getTeamBySide(winner); winningTeam != nil {
+		e.state.Scores[winningTeam.Name]++
+		e.match.Scores[winningTeam.Name]++
+	}
+}
+
 // handleRoundEnd processes the end of a round
 func (e *MatchEngine) handleRoundEnd(result *RoundResult, roundEvents []models.GameEvent) error {
-	// Update scores
-	e.state.Scores[result.Winner]++
-	e.match.Scores[result.Winner]++
+	e.creditRoundWin(result.Winner)
+	
+	// Award the round MVP a star
+	if result.MVP != nil {
+		result.MVP.Stats.MVPs++
+	}
 	
 	// Handle economy rewards using the economy manager
 	if err := e.economyManager.HandleRoundEnd(e.match, e.state, result, roundEvents); err != nil {
@@ -715,101 +1112,96 @@ func (e *MatchEngine) handleRoundEnd(result *RoundResult, roundEvents []models.G
 	}
 	
 	// Create round end event
-	ctScore := e.state.Scores[e.getTeamBySide("CT").Name]
-	tScore := e.state.Scores[e.getTeamBySide("TERRORIST").Name]
+	ctTeam, tTeam, err := e.sideTeams()
+	if err != nil {
+		return err
+	}
+	ctScore := e.state.Scores[ctTeam.Name]
+	tScore := e.state.Scores[tTeam.Name]
 	
+	// The MVP moves out of round_end into its own event when requested
+	separateMVP := e.config.MVPEvents && !e.config.FastMode && result.MVP != nil
 	endEvent := &models.RoundEndEvent{
 		BaseEvent: models.NewBaseEvent("round_end", e.currentTick, e.state.CurrentRound),
 		Winner:    result.Winner,
 		Reason:    result.Reason,
 		CTScore:   ctScore,
 		TScore:    tScore,
-		MVP:       result.MVP,
+	}
+	if !separateMVP {
+		endEvent.MVP = result.MVP
 	}
 	e.addEvent(endEvent)
+	if separateMVP {
+		e.addEvent(&models.MVPEvent{
+			BaseEvent: models.NewBaseEvent("round_mvp", e.currentTick, e.state.CurrentRound),
+			Player:    result.MVP,
+			Reason:    mvpReason(result, roundEvents),
+		})
+	}
+	
+	if e.config.CasterRecaps && !e.config.FastMode {
+		e.addEvent(e.casterRecap(result, ctScore, tScore))
+	}
+	e.addServerStatus()
 	
 	// Create round data
 	roundData := models.RoundData{
 		RoundNumber: e.state.CurrentRound,
+		Events:      append([]models.GameEvent(nil), e.match.Events[e.roundFirstEvent:]...),
 		StartTime:   e.state.RoundStartTime,
-		EndTime:     time.Now(),
+		EndTime:     e.state.RoundStartTime.Add(result.Duration),
+		StartTick:   e.roundStartTick,
+		EndTick:     e.currentTick,
 		Winner:      result.Winner,
 		Reason:      result.Reason,
-		MVP:         result.MVP.Name,
+		MVP:         mvpName(result.MVP),
 		Scores:      make(map[string]int),
 		Economy:     make(map[string]models.TeamEconomy),
+		PlayerStates: make(map[string]models.PlayerState),
+		RNGState:    e.roundRNGState,
 	}
 	
-	// Copy scores and economies
+	// Copy scores, economies and player states
 	for teamName, score := range e.state.Scores {
 		roundData.Scores[teamName] = score
 	}
 	for teamName, economy := range e.state.TeamEconomies {
 		roundData.Economy[teamName] = *economy
 	}
+	for playerName, playerState := range e.state.PlayerStates {
+		roundData.PlayerStates[playerName] = *playerState.Clone()
+	}
 	
 	e.match.Rounds = append(e.match.Rounds, roundData)
 	return nil
 }
 
-// handleEconomyRewards manages money rewards after round end
-func (e *MatchEngine) handleEconomyRewards(result *RoundResult) {
-	winningTeamName := result.Winner
-	losingTeamName := ""
-	
-	// Identify losing team
-	for _, team := range e.match.Teams {
-		if team.Name != winningTeamName {
-			losingTeamName = team.Name
-			break
-		}
-	}
-	
-	// Award win bonus
-	winningTeam := e.getTeamByName(winningTeamName)
-	winBonus := e.winBonus
-	if result.Reason == "bomb_exploded" {
-		winBonus = 3500 // Bomb plant bonus
-	}
-	
-	for i := range winningTeam.Players {
-		playerState := e.state.PlayerStates[winningTeam.Players[i].Name]
-		playerState.Money = e.capMoney(playerState.Money + winBonus)
-	}
-	
-	// Reset winning team loss streak
-	e.state.TeamEconomies[winningTeamName].ConsecutiveLosses = 0
-	
-	// Award loss bonus
-	losingTeam := e.getTeamByName(losingTeamName)
-	teamEconomy := e.state.TeamEconomies[losingTeamName]
-	teamEconomy.ConsecutiveLosses++
-	
-	lossIndex := teamEconomy.ConsecutiveLosses - 1
-	if lossIndex >= len(e.lossBonus) {
-		lossIndex = len(e.lossBonus) - 1
-	}
-	lossBonus := e.lossBonus[lossIndex]
-	teamEconomy.LossBonus = lossBonus
-	
-	for i := range losingTeam.Players {
-		playerState := e.state.PlayerStates[losingTeam.Players[i].Name]
-		playerState.Money = e.capMoney(playerState.Money + lossBonus)
-	}
-}
-
 // Helper functions
 
 // getTeamBySide returns the team playing on the specified side
 func (e *MatchEngine) getTeamBySide(side string) *models.Team {
 	for i := range e.match.Teams {
-		if e.match.Teams[i].Side == side {
+		if models.NormalizeSide(e.match.Teams[i].Side) == models.NormalizeSide(side) {
 			return &e.match.Teams[i]
 		}
 	}
 	return nil
 }
 
+// sideTeams returns the teams playing CT and TERRORIST, or an error when a side has no team
+func (e *MatchEngine) sideTeams() (*models.Team, *models.Team, error) {
+	ctTeam := e.getTeamBySide("CT")
+	if ctTeam == nil {
+		return nil, nil, fmt.Errorf("no team is playing side CT")
+	}
+	tTeam := e.getTeamBySide("TERRORIST")
+	if tTeam == nil {
+		return nil, nil, fmt.Errorf("no team is playing side TERRORIST")
+	}
+	return ctTeam, tTeam, nil
+}
+
 // getTeamByName returns the team with the specified name
 func (e *MatchEngine) getTeamByName(name string) *models.Team {
 	for i := range e.match.Teams {
@@ -849,8 +1241,12 @@ func (e *MatchEngine) resetPlayerStates() {
 	}
 }
 
-// selectMVP selects the MVP for a team based on performance
+// selectMVP selects the MVP for a team based on performance, or nil for an empty team
 func (e *MatchEngine) selectMVP(team *models.Team) *models.Player {
+	if team == nil || len(team.Players) == 0 {
+		return nil
+	}
+	
 	// Simple MVP selection - player with most kills this round
 	// In a real implementation, this would consider damage, assists, objective play, etc.
 	mvp := &team.Players[0]
@@ -884,14 +1280,24 @@ func (e *MatchEngine) selectBuyWeapon(money int, role string) *models.Weapon {
 	return nil
 }
 
+// selectForcedBuyWeapon selects a primary weapon that fits the configured force buy type
+func (e *MatchEngine) selectForcedBuyWeapon(money int, role string) *models.Weapon {
+	switch e.config.ForceBuyType {
+	case "full_buy":
+		if weapon := e.selectBuyWeapon(money, role); weapon != nil && weapon.Type != "smg" {
+			return weapon
+		}
+	case "force_buy":
+		if money >= 1200 {
+			return &models.Weapon{Name: "ump45", Type: "smg", Price: 1200}
+		}
+	}
+	return nil // Eco rounds keep pistols
+}
+
 // selectGrenade selects a grenade type to buy
 func (e *MatchEngine) selectGrenade(side string) string {
-	grenades := []string{"hegrenade", "flashbang", "smokegrenade"}
-	if side == "TERRORIST" {
-		grenades = append(grenades, "molotov")
-	} else {
-		grenades = append(grenades, "incgrenade")
-	}
+	grenades := []string{"hegrenade", "flashbang", "smokegrenade", models.FireGrenadeForSide(side)}
 	return grenades[e.rng.Intn(len(grenades))]
 }
 
@@ -899,7 +1305,7 @@ func (e *MatchEngine) selectGrenade(side string) string {
 func (e *MatchEngine) getSpawnPosition(side string, playerIndex int) models.Vector3 {
 	// Simple spawn positions - in a real implementation these would be map-specific
 	baseX := float64(playerIndex * 100)
-	if side == "CT" {
+	if models.NormalizeSide(side) == "CT" {
 		return models.Vector3{X: baseX, Y: 0, Z: 0}
 	}
 	return models.Vector3{X: baseX, Y: 1000, Z: 0}
@@ -918,16 +1324,23 @@ func (e *MatchEngine) updateTeamEconomy(team *models.Team) {
 	economy := e.state.TeamEconomies[team.Name]
 	totalMoney := 0
 	equipmentValue := 0
+	pickedUpValue := 0
+	players := 0
 	
 	for _, player := range team.Players {
 		playerState := e.state.PlayerStates[player.Name]
+		if playerState == nil {
+			continue
+		}
+		players++
 		totalMoney += playerState.Money
 		equipmentValue += e.calculateEquipmentValue(playerState)
+		pickedUpValue += playerState.PickedUpValue()
 	}
 	
-	economy.TotalMoney = totalMoney
-	economy.AverageMoney = totalMoney / len(team.Players)
+	economy.SetMoney(totalMoney, players)
 	economy.EquipmentValue = equipmentValue
+	economy.PickedUpValue = pickedUpValue
 }
 
 // calculateEquipmentValue calculates the value of a player's equipment
@@ -957,33 +1370,145 @@ func (e *MatchEngine) calculateEquipmentValue(state *models.PlayerState) int {
 	return value
 }
 
-// capMoney ensures money doesn't exceed the maximum
+// capMoney keeps money between zero and the configured maximum
 func (e *MatchEngine) capMoney(money int) int {
-	if money > e.maxMoney {
-		return e.maxMoney
+	return clampMoney(money, e.maxMoney)
+}
+
+// casterRecap creates the caster's Console say summary of a finished round
+func (e *MatchEngine) casterRecap(result *RoundResult, ctScore, tScore int) models.GameEvent {
+	winner := models.LogSide(result.Winner)
+	if winningTeam := e.getTeamBySide(result.Winner); winningTeam != nil {
+		winner = winningTeam.Name
+	}
+	recap := fmt.Sprintf("Round %d goes to %s (%s), CT %d - T %d",
+		e.state.CurrentRound, winner, result.Reason, ctScore, tScore)
+	if result.MVP != nil {
+		recap += ", MVP " + result.MVP.Name
+	}
+	
+	e.eventFactory.SetTick(e.currentTick)
+	return e.eventFactory.CreateObserverChatEvent(models.ObserverSourceConsole, casterName, recap)
+}
+
+// mvpReason tells whether the round MVP earned it with the objective, by planting
+// the bomb that exploded or defusing it, or with kills
+func mvpReason(result *RoundResult, roundEvents []models.GameEvent) string {
+	for _, event := range roundEvents {
+		switch e := event.(type) {
+		case *models.BombPlantEvent:
+			if result.Reason == "bomb_exploded" && e.Player != nil && e.Player.Name == result.MVP.Name {
+				return "objective"
+			}
+		case *models.BombDefuseEvent:
+			if result.Reason == "bomb_defused" && e.Player != nil && e.Player.Name == result.MVP.Name {
+				return "objective"
+			}
+		}
+	}
+	return "kills"
+}
+
+// mvpName returns the MVP's name, or an empty string when the round had none
+func mvpName(mvp *models.Player) string {
+	if mvp == nil {
+		return ""
+	}
+	return mvp.Name
+}
+
+// clampMoney keeps money between zero and maxMoney
+func clampMoney(money, maxMoney int) int {
+	if money < 0 {
+		return 0
+	}
+	if money > maxMoney {
+		return maxMoney
 	}
 	return money
 }
 
-// switchSides switches team sides at halftime
+// switchSides switches team sides at halftime and resets the economy for the
+// second half pistol round
 func (e *MatchEngine) switchSides() {
 	for i := range e.match.Teams {
-		if e.match.Teams[i].Side == "CT" {
+		if models.NormalizeSide(e.match.Teams[i].Side) == "CT" {
 			e.match.Teams[i].Side = "TERRORIST"
 		} else {
 			e.match.Teams[i].Side = "CT"
 		}
 		
-		// Update all players in the team
+		// Update all players in the team. The players are copied first, as events of the
+		// first half point at the old ones and must keep logging their first-half side.
+		e.match.Teams[i].Players = append([]models.Player(nil), e.match.Teams[i].Players...)
 		for j := range e.match.Teams[i].Players {
 			e.match.Teams[i].Players[j].Side = e.match.Teams[i].Side
 		}
+		
+		e.resetTeamLoadouts(&e.match.Teams[i])
+	}
+}
+
+// resetTeamLoadouts strips a team's equipment, restores start money and clears
+// its loss streak, as happens when teams swap sides
+func (e *MatchEngine) resetTeamLoadouts(team *models.Team) {
+	for _, player := range team.Players {
+		playerState := e.state.PlayerStates[player.Name]
+		playerState.Money = e.startMoney
+		playerState.PrimaryWeapon = nil
+		playerState.SecondaryWeapon = nil
+		playerState.Armor = 0
+		playerState.ArmorBroken = false
+		playerState.HasHelmet = false
+		playerState.HasDefuseKit = false
+		playerState.Grenades = make([]models.Grenade, 0)
 	}
+	
+	economy := e.state.TeamEconomies[team.Name]
+	economy.ConsecutiveLosses = 0
+	economy.LossBonus = e.lossBonus[0]
+	e.updateTeamEconomy(team)
+}
+
+// plannedRoundWinner picks the side that wins the next round when the match is steered
+// towards a requested scoreline, or "" when outcomes are left to the simulation. Teams
+// win in proportion to the rounds they still need, and the team closing out the match
+// takes its final round last.
+func (e *MatchEngine) plannedRoundWinner() string {
+	if len(e.config.Scoreline) != len(e.match.Teams) {
+		return ""
+	}
+	
+	winThreshold := e.match.WinThreshold()
+	needs := make([]int, len(e.match.Teams))
+	total := 0
+	for i, team := range e.match.Teams {
+		needs[i] = max(e.config.Scoreline[i]-e.state.Scores[team.Name], 0)
+		total += needs[i]
+	}
+	for i := range needs {
+		if needs[i] == 1 && e.config.Scoreline[i] >= winThreshold && total > 1 {
+			needs[i] = 0
+			total--
+		}
+	}
+	if total == 0 {
+		return ""
+	}
+	
+	pick := e.rng.Intn(total)
+	for i, need := range needs {
+		if pick < need {
+			return models.NormalizeSide(e.match.Teams[i].Side)
+		}
+		pick -= need
+	}
+	return ""
 }
 
 // isMatchFinished checks if the match is complete
 func (e *MatchEngine) isMatchFinished() bool {
-	winThreshold := (e.match.MaxRounds / 2) + 1
+	winThreshold := e.match.WinThreshold()
 	for _, score := range e.state.Scores {
 		if score >= winThreshold {
 			return true
@@ -995,18 +1520,31 @@ func (e *MatchEngine) isMatchFinished() bool {
 // updateMatchStatistics updates overall match statistics
 func (e *MatchEngine) updateMatchStatistics() {
 	// Update player statistics
+	e.roundStatDeltas = e.roundStatDeltas[:0]
 	for _, team := range e.match.Teams {
 		for i := range team.Players {
 			player := &team.Players[i]
 			player.CalculateRating(e.state.CurrentRound)
+			
+			prev := e.prevStats[player.Name]
+			e.roundStatDeltas = append(e.roundStatDeltas, PlayerStatDelta{
+				Name:   player.Name,
+				Kills:  player.Stats.Kills - prev.Kills,
+				Deaths: player.Stats.Deaths - prev.Deaths,
+				EnemiesFlashed: player.Stats.EnemiesFlashed - prev.EnemiesFlashed,
+				FlashAssists:   player.Stats.FlashAssists - prev.FlashAssists,
+				ADR:    player.Stats.ADR,
+				Rating: player.Stats.Rating,
+			})
+			e.prevStats[player.Name] = player.Stats
 		}
 	}
 }
 
-// finalizeMatch completes the match generation
-func (e *MatchEngine) finalizeMatch() {
+// finalizeMatch completes the match generation and sanity checks the event log
+func (e *MatchEngine) finalizeMatch() error {
 	e.match.Status = "completed"
-	e.match.EndTime = time.Now()
+	e.match.EndTime = e.match.StartTime.Add(models.TicksToDuration(e.currentTick, e.tickRate)) // On the match clock, like event timestamps
 	e.match.Duration = e.match.EndTime.Sub(e.match.StartTime)
 	e.match.CurrentRound = e.state.CurrentRound
 	e.match.TotalEvents = e.totalEvents
@@ -1015,10 +1553,26 @@ func (e *MatchEngine) finalizeMatch() {
 	for teamName, score := range e.state.Scores {
 		e.match.Scores[teamName] = score
 	}
+	
+	// Derive timestamps from the match clock so they follow tick order
+	models.RepairEventTimestamps(e.match.Events, e.match.StartTime, e.tickRate)
+	if err := models.ValidateEventOrdering(e.match.Events); err != nil {
+		return fmt.Errorf("generated events are out of order: %w", err)
+	}
+	
+	return nil
 }
 
 // addEvent adds an event to the match and increments counters
 func (e *MatchEngine) addEvent(event models.GameEvent) {
+	if e.eventLimitErr != nil {
+		return
+	}
+	if limit := e.simConfig.MaxEventsPerMatch; limit > 0 && e.totalEvents >= limit {
+		e.eventLimitErr = fmt.Errorf("event limit of %d events per match exceeded in round %d", limit, e.state.CurrentRound)
+		return
+	}
+	
 	e.match.Events = append(e.match.Events, event)
 	e.totalEvents++
 	e.eventFactory.SetTick(e.currentTick)
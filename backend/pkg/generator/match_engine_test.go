@@ -0,0 +1,2238 @@
+package generator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/noueii/nocs-log-generator/backend/pkg/models"
+)
+
+func TestMatchEngine_PlayNextRound(t *testing.T) {
+	engine := newTestEngine(t, 42)
+
+	rounds := 0
+	totalEvents := 0
+	for engine.HasNextRound() {
+		roundData, events, err := engine.PlayNextRound()
+		if err != nil {
+			t.Fatalf("PlayNextRound failed: %v", err)
+		}
+		rounds++
+		totalEvents += len(events)
+
+		if roundData.RoundNumber != rounds {
+			t.Errorf("Expected round number %d, got %d", rounds, roundData.RoundNumber)
+		}
+		if len(events) == 0 {
+			t.Errorf("Round %d returned no events", rounds)
+		}
+	}
+
+	if engine.match.Status != "completed" {
+		t.Errorf("Expected match to be completed, got status %s", engine.match.Status)
+	}
+	if rounds != len(engine.match.Rounds) {
+		t.Errorf("Played %d rounds but match has %d", rounds, len(engine.match.Rounds))
+	}
+	if totalEvents != len(engine.match.Events) {
+		t.Errorf("Returned %d events but match has %d", totalEvents, len(engine.match.Events))
+	}
+
+	if _, _, err := engine.PlayNextRound(); err == nil {
+		t.Error("Expected error when playing past the end of the match")
+	}
+
+	// Full generation with the same seed must produce the same match
+	full := newTestEngine(t, 42)
+	if err := full.GenerateMatch(); err != nil {
+		t.Fatalf("GenerateMatch failed: %v", err)
+	}
+	if len(full.match.Rounds) != rounds {
+		t.Errorf("Expected %d rounds from GenerateMatch, got %d", rounds, len(full.match.Rounds))
+	}
+	if len(full.match.Events) != totalEvents {
+		t.Errorf("Expected %d events from GenerateMatch, got %d", totalEvents, len(full.match.Events))
+	}
+}
+
+func TestMatchEngine_MVPStars(t *testing.T) {
+	for seed := int64(1); seed <= 5; seed++ {
+		engine := newTestEngine(t, seed)
+		if err := engine.GenerateMatch(); err != nil {
+			t.Fatalf("seed %d: GenerateMatch failed: %v", seed, err)
+		}
+
+		stars := 0
+		for _, team := range engine.match.Teams {
+			for _, player := range team.Players {
+				stars += player.Stats.MVPs
+			}
+		}
+
+		if stars != len(engine.match.Rounds) {
+			t.Errorf("seed %d: expected %d MVP stars, got %d", seed, len(engine.match.Rounds), stars)
+		}
+	}
+}
+
+func TestMatchEngine_MaxEventsPerMatch(t *testing.T) {
+	engine := newTestEngine(t, 42)
+
+	simConfig := models.DefaultSimulationConfig()
+	simConfig.MaxEventsPerMatch = 50
+	engine.SetSimulationConfig(&simConfig)
+
+	err := engine.GenerateMatch()
+	if err == nil {
+		t.Fatal("Expected generation to fail when the event limit is exceeded")
+	}
+	if !strings.Contains(err.Error(), "event limit") {
+		t.Errorf("Expected event limit error, got: %v", err)
+	}
+	if int64(len(engine.match.Events)) > simConfig.MaxEventsPerMatch {
+		t.Errorf("Expected at most %d events, got %d", simConfig.MaxEventsPerMatch, len(engine.match.Events))
+	}
+}
+
+func TestMatchEngine_FastMode(t *testing.T) {
+	engine := newTestEngine(t, 42)
+	engine.config.FastMode = true
+
+	if err := engine.GenerateMatch(); err != nil {
+		t.Fatalf("GenerateMatch failed: %v", err)
+	}
+
+	allowed := map[string]bool{
+		"round_start":  true,
+		"round_end":    true,
+		"player_death": true,
+		"bomb_plant":   true,
+		"bomb_defuse":  true,
+		"bomb_explode": true,
+	}
+	for _, event := range engine.match.Events {
+		if !allowed[event.GetType()] {
+			t.Fatalf("Unexpected event type %s in fast mode", event.GetType())
+		}
+	}
+
+	// No hits are generated, so nothing is credited from them
+	for _, team := range engine.match.Teams {
+		for _, player := range team.Players {
+			if player.Stats.Damage != 0 || player.Stats.Assists != 0 || player.Stats.EnemiesFlashed != 0 {
+				t.Errorf("%s: expected no damage, assists or flashes in fast mode, got %+v", player.Name, player.Stats)
+			}
+		}
+	}
+}
+
+func TestMatchEngine_WarmupPreamble(t *testing.T) {
+	engine := newTestEngine(t, 42)
+
+	if _, _, err := engine.PlayNextRound(); err != nil {
+		t.Fatalf("PlayNextRound failed: %v", err)
+	}
+
+	entered := make(map[string]bool)
+	for _, event := range engine.match.Events {
+		if event.GetType() == "round_start" {
+			break
+		}
+		if event.GetTick() != 0 {
+			t.Errorf("Expected preamble event %s at tick 0, got tick %d", event.GetType(), event.GetTick())
+		}
+		switch e := event.(type) {
+		case *models.PlayerEnterEvent:
+			entered[e.Player.SteamID] = true
+		case *models.NameChangeEvent:
+			if e.NewName != e.Player.Name {
+				t.Errorf("Expected name change to roster name %s, got %s", e.Player.Name, e.NewName)
+			}
+		}
+	}
+
+	for _, team := range engine.match.Teams {
+		for _, player := range team.Players {
+			if !entered[player.SteamID] {
+				t.Errorf("Player %s did not enter the game before round 1", player.Name)
+			}
+			if _, ok := engine.state.PlayerStates[player.Name]; !ok {
+				t.Errorf("Player state for %s is not keyed by roster name", player.Name)
+			}
+		}
+	}
+}
+
+func TestMatchEngine_ForceBuyTypeEco(t *testing.T) {
+	weaponInfo := models.NewEconomyManager().GetWeaponInfo()
+	isRifle := func(weapon string) bool {
+		info, ok := weaponInfo[weapon]
+		return ok && (info.Type == "rifle" || info.Type == "sniper")
+	}
+
+	for seed := int64(1); seed <= 5; seed++ {
+		engine := newTestEngine(t, seed)
+		engine.config.ForceBuyType = "eco"
+		engine.state.TeamEconomies["Team1"].AverageMoney = 16000
+		engine.state.TeamEconomies["Team2"].AverageMoney = 16000
+
+		if err := engine.GenerateMatch(); err != nil {
+			t.Fatalf("seed %d: GenerateMatch failed: %v", seed, err)
+		}
+
+		for _, event := range engine.match.Events {
+			switch e := event.(type) {
+			case *models.ItemPurchaseEvent:
+				if isRifle(e.Item) {
+					t.Fatalf("seed %d: %s bought %s during a forced eco", seed, e.Player.Name, e.Item)
+				}
+			case *models.KillEvent:
+				if isRifle(e.Weapon) {
+					t.Fatalf("seed %d: %s got a kill with %s during a forced eco", seed, e.Attacker.Name, e.Weapon)
+				}
+			}
+		}
+	}
+}
+
+func TestMatchEngine_PistolRoundsOnlyAllowPistols(t *testing.T) {
+	weaponInfo := models.NewEconomyManager().GetWeaponInfo()
+	isPrimary := func(weapon string) bool {
+		info, ok := weaponInfo[weapon]
+		return ok && info.Type != "pistol"
+	}
+
+	for seed := int64(1); seed <= 5; seed++ {
+		match := newTestEngine(t, seed).match
+		match.Config.StartMoney = 10000
+		for i := range match.Teams {
+			for j := range match.Teams[i].Players {
+				match.Teams[i].Players[j].Economy.Money = 10000
+			}
+		}
+		engine := NewMatchEngine(&match.Config, match)
+
+		if err := engine.GenerateMatch(); err != nil {
+			t.Fatalf("seed %d: GenerateMatch failed: %v", seed, err)
+		}
+
+		pistolRounds := 0
+		for _, round := range engine.match.Rounds {
+			if engine.match.IsPistolRound(round.RoundNumber) {
+				pistolRounds++
+			}
+		}
+		if pistolRounds != 2 {
+			t.Fatalf("seed %d: expected both pistol rounds to be played, got %d", seed, pistolRounds)
+		}
+
+		for _, event := range engine.match.Events {
+			if !engine.match.IsPistolRound(event.GetRound()) {
+				continue
+			}
+			switch e := event.(type) {
+			case *models.ItemPurchaseEvent:
+				if isPrimary(e.Item) {
+					t.Fatalf("seed %d: %s bought %s on pistol round %d", seed, e.Player.Name, e.Item, e.GetRound())
+				}
+			case *models.KillEvent:
+				if isPrimary(e.Weapon) {
+					t.Fatalf("seed %d: %s got a kill with %s on pistol round %d", seed, e.Attacker.Name, e.Weapon, e.GetRound())
+				}
+			}
+		}
+	}
+}
+
+func TestMatchEngine_EventOrdering(t *testing.T) {
+	for seed := int64(1); seed <= 5; seed++ {
+		engine := newTestEngine(t, seed)
+		if err := engine.GenerateMatch(); err != nil {
+			t.Fatalf("seed %d: GenerateMatch failed: %v", seed, err)
+		}
+
+		if err := models.ValidateEventOrdering(engine.match.Events); err != nil {
+			t.Errorf("seed %d: %v", seed, err)
+		}
+
+		// Rounds must not overlap on the match clock
+		for i := 1; i < len(engine.match.Rounds); i++ {
+			if engine.match.Rounds[i].StartTime.Before(engine.match.Rounds[i-1].EndTime) {
+				t.Errorf("seed %d: round %d starts before round %d ends", seed, i+1, i)
+			}
+		}
+	}
+}
+
+func benchmarkGenerateMatch(b *testing.B, fastMode bool) {
+	for i := 0; i < b.N; i++ {
+		engine := newTestEngine(b, int64(i+1))
+		engine.config.FastMode = fastMode
+		if err := engine.GenerateMatch(); err != nil {
+			b.Fatalf("GenerateMatch failed: %v", err)
+		}
+	}
+}
+
+func BenchmarkMatchEngine_GenerateMatch(b *testing.B) {
+	benchmarkGenerateMatch(b, false)
+}
+
+func BenchmarkMatchEngine_GenerateMatchFastMode(b *testing.B) {
+	benchmarkGenerateMatch(b, true)
+}
+
+func TestMatchEngine_CustomLossBonusLadder(t *testing.T) {
+	config := models.DefaultMatchConfig()
+	config.LossBonusLadder = []int{500, 700, 900}
+	match := newTestEngine(t, 42).match
+	match.Config = config
+	engine := NewMatchEngine(&match.Config, match)
+
+	loser := engine.getTeamBySide("TERRORIST")
+	loserEconomy := engine.state.TeamEconomies[loser.Name]
+	if loserEconomy.LossBonus != 500 {
+		t.Fatalf("Expected initial loss bonus 500, got %d", loserEconomy.LossBonus)
+	}
+
+	for i, want := range []int{500, 700, 900, 900} {
+		before := engine.state.PlayerStates[loser.Players[0].Name].Money
+		result := &RoundResult{Winner: "CT", Reason: "elimination"}
+		if err := engine.economyManager.HandleRoundEnd(engine.match, engine.state, result, nil); err != nil {
+			t.Fatalf("HandleRoundEnd failed: %v", err)
+		}
+
+		if loserEconomy.LossBonus != want {
+			t.Errorf("Loss %d: expected loss bonus %d, got %d", i+1, want, loserEconomy.LossBonus)
+		}
+		if got := engine.state.PlayerStates[loser.Players[0].Name].Money - before; got != want {
+			t.Errorf("Loss %d: expected player to earn %d, got %d", i+1, want, got)
+		}
+	}
+}
+
+func TestMatchEngine_KillRewards(t *testing.T) {
+	engine := newTestEngine(t, 42)
+	ct, terrorists := engine.match.Teams[0].Players, engine.match.Teams[1].Players
+	for _, playerState := range engine.state.PlayerStates {
+		playerState.Money = 1000
+	}
+
+	kill := func(attacker, victim *models.Player, weapon string) models.GameEvent {
+		return &models.KillEvent{BaseEvent: models.NewBaseEvent("player_death", 0, 1), Attacker: attacker, Victim: victim, Weapon: weapon}
+	}
+	events := []models.GameEvent{
+		kill(&ct[0], &terrorists[0], "ak47"),
+		kill(&ct[0], &terrorists[1], "awp"),
+		kill(&ct[1], &terrorists[2], "mac10"),
+		kill(&terrorists[3], &ct[2], "knife"),
+		kill(&terrorists[4], &terrorists[3], "ak47"), // Team kills pay nothing
+	}
+	result := &RoundResult{Winner: "CT", Reason: "elimination"}
+	if err := engine.economyManager.HandleRoundEnd(engine.match, engine.state, result, events); err != nil {
+		t.Fatalf("HandleRoundEnd failed: %v", err)
+	}
+
+	// Players without kills only get the round bonus
+	winBonus := engine.state.PlayerStates[ct[4].Name].Money - 1000
+	lossBonus := engine.state.PlayerStates[terrorists[0].Name].Money - 1000
+	expected := map[*models.Player]int{
+		&ct[0]:         1000 + winBonus + 300 + 100,
+		&ct[1]:         1000 + winBonus + 600,
+		&ct[2]:         1000 + winBonus,
+		&terrorists[3]: 1000 + lossBonus + 1500,
+		&terrorists[4]: 1000 + lossBonus,
+	}
+	for player, money := range expected {
+		if got := engine.state.PlayerStates[player.Name].Money; got != money {
+			t.Errorf("%s: expected %d money, got %d", player.Name, money, got)
+		}
+		if earned := player.Economy.MoneyEarned; earned != money-1000 {
+			t.Errorf("%s: expected %d money earned, got %d", player.Name, money-1000, earned)
+		}
+	}
+}
+
+func TestMatchEngine_KnifeAndGrenadeKillRewards(t *testing.T) {
+	tests := []struct {
+		name           string
+		knife, grenade int
+		wantKnife      int
+		wantGrenade    int
+	}{
+		{"defaults", 0, 0, 1500, 300},
+		{"configured", 2000, 500, 2000, 500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := newTestEngine(t, 42).match
+			match.Config.KnifeKillReward = tt.knife
+			match.Config.GrenadeKillReward = tt.grenade
+			engine := NewMatchEngine(&match.Config, match)
+			ct, terrorists := match.Teams[0].Players, match.Teams[1].Players
+
+			events := []models.GameEvent{
+				&models.KillEvent{BaseEvent: models.NewBaseEvent("player_death", 0, 1), Attacker: &terrorists[0], Victim: &ct[0], Weapon: "knife_t"},
+				&models.KillEvent{BaseEvent: models.NewBaseEvent("player_death", 0, 1), Attacker: &terrorists[1], Victim: &ct[1], Weapon: "hegrenade"},
+			}
+			if err := engine.economyManager.HandleRoundEnd(match, engine.state, &RoundResult{Winner: "CT", Reason: "elimination"}, events); err != nil {
+				t.Fatalf("HandleRoundEnd failed: %v", err)
+			}
+
+			// Compare against a teammate without a kill to leave out the loss bonus
+			base := terrorists[2].Economy.MoneyEarned
+			if got := terrorists[0].Economy.MoneyEarned - base; got != tt.wantKnife {
+				t.Errorf("Expected a knife kill to pay %d, got %d", tt.wantKnife, got)
+			}
+			if got := terrorists[1].Economy.MoneyEarned - base; got != tt.wantGrenade {
+				t.Errorf("Expected a grenade kill to pay %d, got %d", tt.wantGrenade, got)
+			}
+		})
+	}
+}
+
+func TestMatchEngine_MaxMoneyCapsRewards(t *testing.T) {
+	config := models.DefaultMatchConfig()
+	config.MaxMoney = 10000
+	match := newTestEngine(t, 42).match
+	match.Config = config
+	engine := NewMatchEngine(&match.Config, match)
+
+	winner := engine.getTeamBySide("CT")
+	for _, player := range winner.Players {
+		engine.state.PlayerStates[player.Name].Money = 9500
+	}
+	result := &RoundResult{Winner: "CT", Reason: "elimination"}
+	if err := engine.economyManager.HandleRoundEnd(engine.match, engine.state, result, nil); err != nil {
+		t.Fatalf("HandleRoundEnd failed: %v", err)
+	}
+	for _, player := range winner.Players {
+		if money := engine.state.PlayerStates[player.Name].Money; money != 10000 {
+			t.Errorf("Expected %s to be capped at the configured 10000, got %d", player.Name, money)
+		}
+	}
+
+	// Rewards and purchases over a whole match keep every player within the cap
+	for engine.HasNextRound() {
+		if _, _, err := engine.PlayNextRound(); err != nil {
+			t.Fatalf("PlayNextRound failed: %v", err)
+		}
+		for name, playerState := range engine.state.PlayerStates {
+			if playerState.Money < 0 || playerState.Money > 10000 {
+				t.Fatalf("Round %d: %s has %d money, expected 0-10000", engine.state.CurrentRound, name, playerState.Money)
+			}
+		}
+	}
+}
+
+func TestMatchGenerator_AutoAssignsSides(t *testing.T) {
+	newRequest := func(seed int64, firstSide, secondSide string) *models.GenerateRequest {
+		return &models.GenerateRequest{
+			Map:    "de_mirage",
+			Format: "mr12",
+			Teams: []models.Team{
+				{Name: "Team1", Side: firstSide},
+				{Name: "Team2", Side: secondSide},
+			},
+			Options: models.MatchOptions{Seed: seed, AutoRoster: true, MaxRounds: 16},
+		}
+	}
+	generator := NewMatchGenerator()
+
+	firstOnCT := make(map[bool]bool)
+	for seed := int64(1); seed <= 8; seed++ {
+		engine, err := generator.NewEngine(newRequest(seed, "", ""))
+		if err != nil {
+			t.Fatalf("Seed %d: NewEngine failed: %v", seed, err)
+		}
+		again, err := generator.NewEngine(newRequest(seed, "", ""))
+		if err != nil {
+			t.Fatalf("Seed %d: NewEngine failed: %v", seed, err)
+		}
+
+		teams := engine.match.Teams
+		if !engine.config.AutoAssignedSides {
+			t.Errorf("Seed %d: expected blank sides to be auto-assigned", seed)
+		}
+		if teams[0].Side == teams[1].Side || teams[0].Side == "" || teams[1].Side == "" {
+			t.Fatalf("Seed %d: expected opposite sides, got %q/%q", seed, teams[0].Side, teams[1].Side)
+		}
+		if again.match.Teams[0].Side != teams[0].Side {
+			t.Errorf("Seed %d: expected the same seed to assign the same sides", seed)
+		}
+		firstOnCT[teams[0].Side == "CT"] = true
+
+		if _, _, err := engine.PlayNextRound(); err != nil {
+			t.Fatalf("Seed %d: PlayNextRound failed: %v", seed, err)
+		}
+		switches := 0
+		for _, event := range engine.match.Events {
+			if event.GetType() == "round_start" {
+				break
+			}
+			if e, ok := event.(*models.TeamSwitchEvent); ok {
+				switches++
+				if e.FromTeam != "Unassigned" || e.ToTeam != e.Player.Side {
+					t.Errorf("Seed %d: expected %s to switch from Unassigned to %s, got %s to %s", seed, e.Player.Name, e.Player.Side, e.FromTeam, e.ToTeam)
+				}
+			}
+		}
+		if switches != len(teams[0].Players)+len(teams[1].Players) {
+			t.Errorf("Seed %d: expected a team switch per player in the warmup, got %d", seed, switches)
+		}
+	}
+	if len(firstOnCT) != 2 {
+		t.Error("Expected the coin flip to put each team on CT for some seed")
+	}
+
+	engine, err := generator.NewEngine(newRequest(1, "TERRORIST", "CT"))
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	if engine.config.AutoAssignedSides || engine.match.Teams[0].Side != "TERRORIST" {
+		t.Error("Expected requested sides to be kept")
+	}
+	if _, _, err := engine.PlayNextRound(); err != nil {
+		t.Fatalf("PlayNextRound failed: %v", err)
+	}
+	for _, event := range engine.match.Events {
+		if _, ok := event.(*models.TeamSwitchEvent); ok {
+			t.Fatal("Expected no team switch events when sides were requested")
+		}
+	}
+}
+
+func TestMatchGenerator_RecoversFromPanic(t *testing.T) {
+	match := newTestEngine(t, 42).match
+	var nilTeam *models.Team
+
+	var err error
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Fatalf("Expected the panic to be recovered, got %v", r)
+			}
+		}()
+		err = runEngine(match, func() error {
+			// A nil dereference deep in the simulator
+			_ = nilTeam.Name
+			return nil
+		})
+	}()
+
+	if !errors.Is(err, ErrGenerationPanic) {
+		t.Fatalf("Expected an ErrGenerationPanic error, got %v", err)
+	}
+	if match.Status != "error" || match.Error != err.Error() {
+		t.Errorf("Expected the match to be marked failed, got status %q and error %q", match.Status, match.Error)
+	}
+}
+
+func TestMatchGenerator_PlayNextRoundRecovers(t *testing.T) {
+	engine := newTestEngine(t, 42)
+	// A missing simulator panics in the middle of the round
+	engine.roundSimulator = nil
+
+	_, _, err := NewMatchGenerator().PlayNextRound(engine)
+	if !errors.Is(err, ErrGenerationPanic) {
+		t.Fatalf("Expected an ErrGenerationPanic error, got %v", err)
+	}
+	if engine.match.Status != "error" || engine.match.Error != err.Error() {
+		t.Errorf("Expected the match to be marked failed, got status %q and error %q", engine.match.Status, engine.match.Error)
+	}
+}
+
+func TestMatchEngine_MisassignedSides(t *testing.T) {
+	engine := newTestEngine(t, 42)
+	// Both teams on CT leaves no team on TERRORIST
+	engine.match.Teams[1].Side = "CT"
+
+	if _, _, err := engine.sideTeams(); err == nil || !strings.Contains(err.Error(), "TERRORIST") {
+		t.Errorf("Expected sideTeams to report the missing TERRORIST team, got %v", err)
+	}
+	if err := engine.GenerateMatch(); err == nil || !strings.Contains(err.Error(), "exactly one team on side") {
+		t.Errorf("Expected GenerateMatch to fail on the misassigned sides, got %v", err)
+	}
+	if len(engine.match.Rounds) != 0 {
+		t.Errorf("Expected no rounds to be played, got %d", len(engine.match.Rounds))
+	}
+	if _, _, err := engine.roundSimulator.SimulateRound(engine.match, engine.state, 1); err == nil {
+		t.Error("Expected SimulateRound to fail on the missing TERRORIST team")
+	}
+}
+
+func TestMatchEngine_StateAtRound(t *testing.T) {
+	engine := newTestEngine(t, 42)
+
+	var scores map[string]int
+	var money map[string]int
+	for engine.HasNextRound() {
+		if _, _, err := engine.PlayNextRound(); err != nil {
+			t.Fatalf("PlayNextRound failed: %v", err)
+		}
+		if engine.state.CurrentRound != 5 {
+			continue
+		}
+		scores = make(map[string]int)
+		for team, score := range engine.state.Scores {
+			scores[team] = score
+		}
+		money = make(map[string]int)
+		for name, playerState := range engine.state.PlayerStates {
+			money[name] = playerState.Money
+		}
+	}
+	if scores == nil {
+		t.Fatal("Expected the match to play at least 5 rounds")
+	}
+
+	state, err := engine.StateAtRound(5)
+	if err != nil {
+		t.Fatalf("StateAtRound failed: %v", err)
+	}
+	if state.CurrentRound != 5 {
+		t.Errorf("Expected current round 5, got %d", state.CurrentRound)
+	}
+	if !reflect.DeepEqual(state.Scores, scores) {
+		t.Errorf("Expected round 5 scores %v, got %v", scores, state.Scores)
+	}
+	for name, want := range money {
+		playerState, ok := state.PlayerStates[name]
+		if !ok {
+			t.Fatalf("Expected a player state for %s", name)
+		}
+		if playerState.Money != want {
+			t.Errorf("Expected %s to have %d money after round 5, got %d", name, want, playerState.Money)
+		}
+	}
+
+	// Snapshots are copies, so changing them does not rewrite history
+	for _, playerState := range state.PlayerStates {
+		playerState.Money = -1
+	}
+	again, err := engine.StateAtRound(5)
+	if err != nil {
+		t.Fatalf("StateAtRound failed: %v", err)
+	}
+	for name, playerState := range again.PlayerStates {
+		if playerState.Money != money[name] {
+			t.Fatalf("Expected the round 5 snapshot of %s to be unchanged, got %d", name, playerState.Money)
+		}
+	}
+
+	for _, round := range []int{0, len(engine.match.Rounds) + 1} {
+		if _, err := engine.StateAtRound(round); err == nil {
+			t.Errorf("Expected an error for unplayed round %d", round)
+		}
+	}
+}
+
+func TestMatchEngine_AverageMoneyAgrees(t *testing.T) {
+	engine := newTestEngine(t, 42)
+	team := &engine.match.Teams[0]
+
+	// 5003 over five players averages 1000.6, which truncation would report as 1000
+	for i, money := range []int{1000, 1000, 1000, 1000, 1003} {
+		engine.state.PlayerStates[team.Players[i].Name].Money = money
+		team.Players[i].Economy.Money = money
+	}
+
+	updates := map[string]func(){
+		"engine":          func() { engine.updateTeamEconomy(team) },
+		"round simulator": func() { engine.roundSimulator.updateTeamEconomyAfterBuy(team, engine.state) },
+		"economy manager": func() { engine.economyManager.updateTeamEconomies(engine.match, engine.state) },
+	}
+	for name, update := range updates {
+		*engine.state.TeamEconomies[team.Name] = models.TeamEconomy{}
+		update()
+		economy := engine.state.TeamEconomies[team.Name]
+		if economy.TotalMoney != 5003 || economy.AverageMoney != 1001 || economy.AverageMoneyExact != 1000.6 {
+			t.Errorf("%s: expected 5003 total and an average of 1001 (1000.6), got %d and %d (%v)",
+				name, economy.TotalMoney, economy.AverageMoney, economy.AverageMoneyExact)
+		}
+	}
+
+	team.UpdateEconomy()
+	if team.Economy.AverageMoney != 1001 || team.Economy.AverageMoneyExact != 1000.6 {
+		t.Errorf("Expected the team's own average to be 1001 (1000.6), got %d (%v)", team.Economy.AverageMoney, team.Economy.AverageMoneyExact)
+	}
+
+	// A player without a state isn't counted in the total or the average
+	delete(engine.state.PlayerStates, team.Players[4].Name)
+	for name, update := range updates {
+		update()
+		if economy := engine.state.TeamEconomies[team.Name]; economy.TotalMoney != 4000 || economy.AverageMoney != 1000 {
+			t.Errorf("%s: expected 4000 over the four remaining players, got %d averaging %d", name, economy.TotalMoney, economy.AverageMoney)
+		}
+	}
+}
+
+func TestMatchEngine_EmptyTeam(t *testing.T) {
+	engine := newTestEngine(t, 42)
+	team := &engine.match.Teams[1]
+
+	// Players without a state, as if they had all disconnected
+	for _, player := range team.Players {
+		delete(engine.state.PlayerStates, player.Name)
+	}
+	engine.updateTeamEconomy(team)
+	engine.economyManager.updateTeamEconomies(engine.match, engine.state)
+	if economy := engine.state.TeamEconomies[team.Name]; economy.TotalMoney != 0 || economy.AverageMoney != 0 {
+		t.Errorf("Expected no money for a disconnected team, got total %d average %d", economy.TotalMoney, economy.AverageMoney)
+	}
+
+	// A team without any players
+	team.Players = nil
+	engine.updateTeamEconomy(team)
+	engine.economyManager.updateTeamEconomies(engine.match, engine.state)
+	if economy := engine.state.TeamEconomies[team.Name]; economy.AverageMoney != 0 {
+		t.Errorf("Expected an average of 0 for an empty team, got %d", economy.AverageMoney)
+	}
+	team.UpdateEconomy()
+	if team.Economy.AverageMoney != 0 {
+		t.Errorf("Expected the team's own average to be 0, got %d", team.Economy.AverageMoney)
+	}
+	if mvp := engine.selectMVP(team); mvp != nil {
+		t.Errorf("Expected no MVP for an empty team, got %s", mvp.Name)
+	}
+	if defuser := engine.roundSimulator.selectDefuser(nil, engine.state); defuser != nil {
+		t.Errorf("Expected no defuser without alive CTs, got %s", defuser.Name)
+	}
+
+	_, _, err := engine.PlayNextRound()
+	if err == nil || !strings.Contains(err.Error(), "has no players") {
+		t.Fatalf("Expected an error for a team without players, got %v", err)
+	}
+	if len(engine.match.Rounds) != 0 {
+		t.Errorf("Expected no rounds to be recorded, got %d", len(engine.match.Rounds))
+	}
+}
+
+func TestMatchEngine_SeedPerRound(t *testing.T) {
+	// playTo plays both halves of the comparison up to round 7, drawing extra
+	// randomness after round 3 in the second engine, and returns round 7's log
+	playTo := func(seedPerRound, drain bool) []string {
+		match := newTestEngine(t, 42).match
+		match.Config.SeedPerRound = seedPerRound
+		engine := NewMatchEngine(&match.Config, match)
+
+		var lines []string
+		for round := 1; round <= 7; round++ {
+			_, events, err := engine.PlayNextRound()
+			if err != nil {
+				t.Fatalf("PlayNextRound failed: %v", err)
+			}
+			if round == 3 && drain {
+				for i := 0; i < 17; i++ {
+					engine.rng.Int63()
+				}
+			}
+			if round == 7 {
+				models.RepairEventTimestamps(events, time.Time{}, engine.tickRate)
+				for _, event := range events {
+					lines = append(lines, event.ToLogLine())
+				}
+			}
+		}
+		return lines
+	}
+
+	if !reflect.DeepEqual(playTo(true, false), playTo(true, true)) {
+		t.Error("Expected round 7 to be unchanged by extra randomness in round 3 with seed-per-round")
+	}
+	if reflect.DeepEqual(playTo(false, false), playTo(false, true)) {
+		t.Error("Expected extra randomness in round 3 to change round 7 with a single match RNG")
+	}
+
+	engine := newTestEngine(t, 42)
+	if engine.RoundSeed(7) != engine.RoundSeed(7) || engine.RoundSeed(7) == engine.RoundSeed(8) {
+		t.Error("Expected round seeds to be stable per round and distinct across rounds")
+	}
+}
+
+func TestMatchEngine_MinRoundDuration(t *testing.T) {
+	const minSeconds = 20
+
+	shortest := func(minRoundDuration int) int64 {
+		match := newTestEngine(t, 42).match
+		match.Config.MinRoundDuration = minRoundDuration
+		engine := NewMatchEngine(&match.Config, match)
+		if err := engine.GenerateMatch(); err != nil {
+			t.Fatalf("GenerateMatch failed: %v", err)
+		}
+
+		shortest := int64(-1)
+		for _, round := range match.Rounds {
+			if length := round.EndTick - round.StartTick; shortest < 0 || length < shortest {
+				shortest = length
+			}
+			for _, event := range round.Events {
+				if event.GetTick() > round.EndTick {
+					t.Errorf("Round %d: %s at tick %d after the round end at %d", round.RoundNumber, event.GetType(), event.GetTick(), round.EndTick)
+				}
+			}
+		}
+		return shortest
+	}
+
+	minTicks := int64(minSeconds * 64)
+	if got := shortest(0); got >= minTicks {
+		t.Fatalf("Expected a round shorter than %ds without a minimum, shortest was %d ticks", minSeconds, got)
+	}
+	if got := shortest(minSeconds); got < minTicks {
+		t.Errorf("Expected no round shorter than %d ticks, shortest was %d", minTicks, got)
+	}
+}
+
+func TestMatchEngine_CasterRecaps(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		match := newTestEngine(t, 42).match
+		match.Config.CasterRecaps = enabled
+		engine := NewMatchEngine(&match.Config, match)
+		if err := engine.GenerateMatch(); err != nil {
+			t.Fatalf("GenerateMatch failed: %v", err)
+		}
+
+		recaps := 0
+		for i, event := range match.Events {
+			switch e := event.(type) {
+			case *models.ObserverChatEvent:
+				recaps++
+				if _, ok := match.Events[i-1].(*models.RoundEndEvent); !ok {
+					t.Errorf("Expected the recap to follow a round end, got %s", match.Events[i-1].GetType())
+				}
+				if !strings.Contains(e.ToLogLine(), `"Console<0><Console><Console>" say "[Caster] Round`) {
+					t.Errorf("Expected a Console say recap, got %q", e.ToLogLine())
+				}
+			case *models.ChatEvent:
+				if strings.Contains(e.ToLogLine(), "Console") {
+					t.Errorf("Expected player chat to stay separate from caster lines, got %q", e.ToLogLine())
+				}
+			}
+		}
+
+		expected := 0
+		if enabled {
+			expected = len(match.Rounds)
+		}
+		if recaps != expected {
+			t.Errorf("CasterRecaps=%v: expected %d recaps, got %d", enabled, expected, recaps)
+		}
+	}
+}
+
+func TestMatchEngine_ServerStatusInterval(t *testing.T) {
+	for _, interval := range []int{0, 1, 5} {
+		match := newTestEngine(t, 42).match
+		match.Config.StatusInterval = interval
+		engine := NewMatchEngine(&match.Config, match)
+		if err := engine.GenerateMatch(); err != nil {
+			t.Fatalf("GenerateMatch failed: %v", err)
+		}
+
+		var rounds []int
+		var lastUptime int64
+		for _, event := range match.Events {
+			status, ok := event.(*models.ServerStatusEvent)
+			if !ok {
+				continue
+			}
+			rounds = append(rounds, status.Round)
+			if status.Players != 10 {
+				t.Errorf("Expected 10 connected players, got %d", status.Players)
+			}
+			if status.Map != match.Config.Map {
+				t.Errorf("Expected map %q, got %q", match.Config.Map, status.Map)
+			}
+			if status.Uptime <= lastUptime {
+				t.Errorf("Expected uptime to grow, got %d after %d", status.Uptime, lastUptime)
+			}
+			lastUptime = status.Uptime
+		}
+
+		var expected []int
+		if interval > 0 {
+			for round := interval; round <= len(match.Rounds); round += interval {
+				expected = append(expected, round)
+			}
+		}
+		if fmt.Sprint(rounds) != fmt.Sprint(expected) {
+			t.Errorf("StatusInterval=%d: expected status in rounds %v, got %v", interval, expected, rounds)
+		}
+	}
+}
+
+func TestMatchEngine_MVPEvents(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		match := newTestEngine(t, 42).match
+		match.Config.MVPEvents = enabled
+		engine := NewMatchEngine(&match.Config, match)
+		if err := engine.GenerateMatch(); err != nil {
+			t.Fatalf("GenerateMatch failed: %v", err)
+		}
+
+		for _, round := range match.Rounds {
+			var mvps []*models.MVPEvent
+			for _, event := range round.Events {
+				switch e := event.(type) {
+				case *models.MVPEvent:
+					mvps = append(mvps, e)
+				case *models.RoundEndEvent:
+					if enabled && e.MVP != nil {
+						t.Errorf("Round %d: expected the MVP to be left out of round_end", round.RoundNumber)
+					}
+					if !enabled && e.MVP == nil {
+						t.Errorf("Round %d: expected the MVP in round_end", round.RoundNumber)
+					}
+				}
+			}
+
+			if !enabled {
+				if len(mvps) != 0 {
+					t.Errorf("Round %d: expected no MVP events when disabled, got %d", round.RoundNumber, len(mvps))
+				}
+				continue
+			}
+			if len(mvps) != 1 {
+				t.Fatalf("Round %d: expected exactly one MVP event, got %d", round.RoundNumber, len(mvps))
+			}
+			if mvps[0].Player.Name != round.MVP {
+				t.Errorf("Round %d: expected MVP %s, got %s", round.RoundNumber, round.MVP, mvps[0].Player.Name)
+			}
+			if mvps[0].Reason != "kills" && mvps[0].Reason != "objective" {
+				t.Errorf("Round %d: unexpected MVP reason %q", round.RoundNumber, mvps[0].Reason)
+			}
+			if !strings.HasSuffix(mvps[0].ToLogLine(), `triggered "MVP"`) {
+				t.Errorf("Round %d: unexpected MVP log line %q", round.RoundNumber, mvps[0].ToLogLine())
+			}
+		}
+	}
+}
+
+func TestMatchEngine_ServerCvars(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		match := newTestEngine(t, 42).match
+		match.Config.ServerCvars = enabled
+		engine := NewMatchEngine(&match.Config, match)
+		if err := engine.GenerateMatch(); err != nil {
+			t.Fatalf("GenerateMatch failed: %v", err)
+		}
+
+		var cvars []string
+		halftimeRound := match.HalftimeRound()
+		for i, event := range match.Events {
+			cvar, ok := event.(*models.ServerCommandEvent)
+			if !ok {
+				continue
+			}
+			cvars = append(cvars, cvar.Command)
+			if cvar.Command != "mp_halftime" {
+				continue
+			}
+
+			// The halftime cvar sits between the last first-half round and the second half
+			previous, ok := match.Events[i-1].(*models.RoundEndEvent)
+			if !ok || previous.Round != halftimeRound {
+				t.Errorf("Expected mp_halftime to follow the round %d end, got %s in round %d", halftimeRound, match.Events[i-1].GetType(), match.Events[i-1].GetRound())
+			}
+			for _, next := range match.Events[i+1:] {
+				if start, ok := next.(*models.RoundStartEvent); ok {
+					if start.Round != halftimeRound+1 {
+						t.Errorf("Expected mp_halftime to precede round %d, next round start is round %d", halftimeRound+1, start.Round)
+					}
+					break
+				}
+			}
+			if !strings.Contains(cvar.ToLogLine(), `server_cvar: "mp_halftime" "1"`) {
+				t.Errorf("Unexpected cvar line %q", cvar.ToLogLine())
+			}
+		}
+
+		var expected []string
+		if enabled {
+			expected = []string{"mp_warmup_end", "mp_restartgame", "mp_halftime"}
+		}
+		if !reflect.DeepEqual(cvars, expected) {
+			t.Errorf("ServerCvars=%v: expected cvars %v, got %v", enabled, expected, cvars)
+		}
+		if enabled {
+			if _, ok := match.Events[0].(*models.ServerCommandEvent); ok {
+				t.Error("Expected the warmup preamble before the match start cvars")
+			}
+		}
+	}
+}
+
+func TestMatchEngine_StartingEconomyFromRequest(t *testing.T) {
+	match := newTestEngine(t, 42).match
+	for i := range match.Teams {
+		match.Teams[i].Economy.ConsecutiveLosses = 3
+	}
+	match.Teams[0].Players[0].Economy.Money = 4750
+	match.Teams[0].Players[1].Economy.Money = 50000
+	engine := NewMatchEngine(&match.Config, match)
+
+	ladder := models.DefaultLossBonusLadder()
+	for _, team := range match.Teams {
+		economy := engine.state.TeamEconomies[team.Name]
+		if economy.ConsecutiveLosses != 3 || economy.LossBonus != ladder[2] {
+			t.Errorf("%s: expected to start on a 3-loss streak with bonus %d, got %d losses and bonus %d", team.Name, ladder[2], economy.ConsecutiveLosses, economy.LossBonus)
+		}
+	}
+	if money := engine.state.PlayerStates["Player1_1"].Money; money != 4750 {
+		t.Errorf("Expected requested money 4750, got %d", money)
+	}
+	if money := engine.state.PlayerStates["Player1_2"].Money; money != engine.maxMoney {
+		t.Errorf("Expected requested money to be capped at %d, got %d", engine.maxMoney, money)
+	}
+	if money := engine.state.PlayerStates["Player1_3"].Money; money != engine.startMoney {
+		t.Errorf("Expected players without requested money to get start money %d, got %d", engine.startMoney, money)
+	}
+
+	round, _, err := engine.PlayNextRound()
+	if err != nil {
+		t.Fatalf("PlayNextRound failed: %v", err)
+	}
+	for _, team := range match.Teams {
+		if models.NormalizeSide(team.Side) == models.NormalizeSide(round.Winner) {
+			continue
+		}
+		// The round 1 loser extends its streak to four losses
+		economy := round.Economy[team.Name]
+		if economy.ConsecutiveLosses != 4 || economy.LossBonus != ladder[3] {
+			t.Errorf("%s: expected a 4-loss streak with bonus %d after losing round 1, got %d losses and bonus %d", team.Name, ladder[3], economy.ConsecutiveLosses, economy.LossBonus)
+		}
+	}
+}
+
+func TestMatchEngine_MR15Halftime(t *testing.T) {
+	match := newTestEngine(t, 42).match
+	match.Config.Format = "mr15"
+	match.MaxRounds = models.MaxRoundsForFormat("mr15")
+	engine := NewMatchEngine(&match.Config, match)
+
+	for round := 1; round <= 16; round++ {
+		if _, _, err := engine.PlayNextRound(); err != nil {
+			t.Fatalf("PlayNextRound failed: %v", err)
+		}
+		expected := "Team1"
+		if round > 15 {
+			expected = "Team2"
+		}
+		if ct := engine.getTeamBySide("CT"); ct.Name != expected {
+			t.Fatalf("Round %d: expected %s on CT, got %s", round, expected, ct.Name)
+		}
+	}
+
+	for _, testCase := range []struct {
+		round     int
+		important bool
+	}{{1, true}, {4, false}, {13, false}, {15, true}, {16, true}, {18, true}, {19, true}} {
+		if got := engine.economyManager.isImportantRound(testCase.round, match.HalftimeRound()); got != testCase.important {
+			t.Errorf("Round %d: expected important=%v in mr15, got %v", testCase.round, testCase.important, got)
+		}
+	}
+}
+
+func TestMatchEngine_ShowMatchMaxRounds(t *testing.T) {
+	base := newTestEngine(t, 42).match
+	config := base.Config
+	config.MaxRounds = 6
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Expected a 6 round config to be valid: %v", err)
+	}
+	match := models.NewMatch(config, base.Teams)
+	if match.MaxRounds != 6 || match.HalftimeRound() != 3 || match.WinThreshold() != 4 {
+		t.Fatalf("Expected 6 rounds with halftime after 3 and 4 to win, got %d, %d and %d", match.MaxRounds, match.HalftimeRound(), match.WinThreshold())
+	}
+	engine := NewMatchEngine(&match.Config, match)
+
+	for round := 1; engine.HasNextRound(); round++ {
+		if _, _, err := engine.PlayNextRound(); err != nil {
+			t.Fatalf("PlayNextRound failed: %v", err)
+		}
+		expected := "Team1"
+		if round > 3 {
+			expected = "Team2"
+		}
+		if ct := engine.getTeamBySide("CT"); ct.Name != expected {
+			t.Fatalf("Round %d: expected %s on CT, got %s", round, expected, ct.Name)
+		}
+	}
+
+	if len(match.Rounds) > 6 {
+		t.Errorf("Expected at most 6 rounds, got %d", len(match.Rounds))
+	}
+	if winner := match.GetWinningTeam(); winner != "" && match.Scores[winner] != 4 {
+		t.Errorf("Expected the winner to stop at 4 rounds, got %v", match.Scores)
+	} else if winner == "" && len(match.Rounds) != 6 {
+		t.Errorf("Expected a drawn match to play all 6 rounds, got %d", len(match.Rounds))
+	}
+	if !match.IsPistolRound(4) || match.IsPistolRound(3) {
+		t.Error("Expected round 4 to be the second half pistol round")
+	}
+
+	for _, maxRounds := range []int{-2, 7} {
+		config.MaxRounds = maxRounds
+		if err := config.Validate(); err == nil {
+			t.Errorf("Expected max rounds %d to be rejected", maxRounds)
+		}
+	}
+}
+
+func TestMatchEngine_Scoreline(t *testing.T) {
+	testCases := []struct {
+		name      string
+		scoreline []int
+	}{
+		{"shutout", []int{13, 0}},
+		{"comeback loss", []int{11, 13}},
+		{"draw", []int{12, 12}},
+	}
+
+	for _, tc := range testCases {
+		for _, seed := range []int64{1, 42, 1234} {
+			t.Run(fmt.Sprintf("%s/seed %d", tc.name, seed), func(t *testing.T) {
+				match := newTestEngine(t, seed).match
+				match.Config.Scoreline = tc.scoreline
+				engine := NewMatchEngine(&match.Config, match)
+				if err := engine.GenerateMatch(); err != nil {
+					t.Fatalf("GenerateMatch failed: %v", err)
+				}
+
+				for i, team := range match.Teams {
+					if match.Scores[team.Name] != tc.scoreline[i] {
+						t.Errorf("Expected %s to finish on %d, got %d", team.Name, tc.scoreline[i], match.Scores[team.Name])
+					}
+				}
+				if expected := tc.scoreline[0] + tc.scoreline[1]; len(match.Rounds) != expected {
+					t.Errorf("Expected %d rounds, got %d", expected, len(match.Rounds))
+				}
+
+				// No team ever wins more rounds than the scoreline gives it
+				loser := match.Teams[1].Name
+				for _, round := range match.Rounds {
+					for i, team := range match.Teams {
+						if round.Scores[team.Name] > tc.scoreline[i] {
+							t.Fatalf("Round %d: %s is on %d, past its final score of %d", round.RoundNumber, team.Name, round.Scores[team.Name], tc.scoreline[i])
+						}
+					}
+					if tc.scoreline[1] == 0 && round.Scores[loser] != 0 {
+						t.Fatalf("Round %d: %s won a round in a %d-0 match", round.RoundNumber, loser, tc.scoreline[0])
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestEconomyManager_FireGrenadesBySide(t *testing.T) {
+	em := NewEconomyManager(rand.New(rand.NewSource(42)))
+	optimal := models.NewEconomyManager()
+
+	testCases := []struct {
+		side      string
+		expected  string
+		forbidden string
+	}{
+		{"CT", "incgrenade", "molotov"},
+		{"TERRORIST", "molotov", "incgrenade"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.side, func(t *testing.T) {
+			for _, buyType := range []string{"full_buy", "anti_eco"} {
+				player := &models.Player{Name: "Buyer", Side: tc.side, Role: "rifler"}
+				playerState := &models.PlayerState{Money: 16000}
+				purchases, err := em.ExecutePlayerBuy(player, playerState, buyType, 2)
+				if err != nil {
+					t.Fatalf("ExecutePlayerBuy failed: %v", err)
+				}
+				if !containsItem(purchases, tc.expected) || containsItem(purchases, tc.forbidden) {
+					t.Errorf("%s: expected a %s and no %s, bought %v", buyType, tc.expected, tc.forbidden, purchases)
+				}
+			}
+
+			// The buy the round simulator uses follows the same rule
+			player := &models.Player{Name: "Buyer", Side: tc.side, Role: "rifler"}
+			player.Economy.Money = 16000
+			buy := optimal.GetOptimalBuy(player, &models.TeamEconomy{}, "full_buy")
+			if !containsItem(buy, tc.expected) || containsItem(buy, tc.forbidden) {
+				t.Errorf("Optimal full buy: expected a %s and no %s, got %v", tc.expected, tc.forbidden, buy)
+			}
+
+			// The other side's fire grenade cannot be bought at all
+			if err := em.purchaseItem(player, &models.PlayerState{Money: 16000}, tc.forbidden, 600); err == nil {
+				t.Errorf("Expected a %s to be unable to buy a %s", tc.side, tc.forbidden)
+			}
+		})
+	}
+}
+
+func containsItem(items []string, item string) bool {
+	for _, candidate := range items {
+		if candidate == item {
+			return true
+		}
+	}
+	return false
+}
+
+func TestMatchEngine_CoordinatedSave(t *testing.T) {
+	for _, realism := range []float64{1, 0} {
+		engine := newTestEngine(t, 42)
+		simConfig := models.DefaultSimulationConfig()
+		simConfig.EconomicRealism = realism
+		engine.SetSimulationConfig(&simConfig)
+		engine.state.CurrentRound = 3
+
+		// One player could full-buy alone, but the team average calls for a save
+		team := &engine.match.Teams[0]
+		for i, player := range team.Players {
+			engine.state.PlayerStates[player.Name].Money = 0
+			if i == 0 {
+				engine.state.PlayerStates[player.Name].Money = 9000
+			}
+		}
+		engine.updateTeamEconomy(team)
+
+		if err := engine.handleBuyPhase(); err != nil {
+			t.Fatalf("handleBuyPhase failed: %v", err)
+		}
+
+		rich := engine.state.PlayerStates[team.Players[0].Name]
+		if realism == 1 && (rich.PrimaryWeapon != nil || rich.Armor > 0) {
+			t.Errorf("Expected a coordinated save, but %s bought a %v and %d armor", team.Players[0].Name, rich.PrimaryWeapon, rich.Armor)
+		}
+		if realism == 0 && rich.PrimaryWeapon == nil {
+			t.Errorf("Expected %s to buy a primary when saves are never coordinated", team.Players[0].Name)
+		}
+	}
+}
+
+func TestMatchEngine_FirstBlood(t *testing.T) {
+	match := newTestEngine(t, 42).match
+	match.Config.FirstBlood = true
+	engine := NewMatchEngine(&match.Config, match)
+	if err := engine.GenerateMatch(); err != nil {
+		t.Fatalf("GenerateMatch failed: %v", err)
+	}
+
+	kills := make(map[int]int)
+	firstBloods := make(map[int]int)
+	notices := make(map[int]int)
+	for _, event := range match.Events {
+		switch e := event.(type) {
+		case *models.KillEvent:
+			kills[e.Round]++
+			if e.FirstBlood {
+				firstBloods[e.Round]++
+				if kills[e.Round] != 1 {
+					t.Errorf("Round %d: first blood was kill %d of the round", e.Round, kills[e.Round])
+				}
+			}
+		case *models.ObserverChatEvent:
+			if strings.HasPrefix(e.Message, "First blood: ") {
+				notices[e.Round]++
+			}
+		}
+	}
+
+	totalFirstKills := 0
+	for _, team := range match.Teams {
+		for _, player := range team.Players {
+			totalFirstKills += player.Stats.FirstKills
+		}
+	}
+	if totalFirstKills != len(kills) {
+		t.Errorf("Expected %d first kills, one per round with kills, got %d", len(kills), totalFirstKills)
+	}
+
+	for _, round := range match.Rounds {
+		expected := 0
+		if kills[round.RoundNumber] > 0 {
+			expected = 1
+		}
+		if notices[round.RoundNumber] != expected || firstBloods[round.RoundNumber] != expected {
+			t.Errorf("Round %d: expected %d first blood notice, got %d notices and %d first blood kills", round.RoundNumber, expected, notices[round.RoundNumber], firstBloods[round.RoundNumber])
+		}
+	}
+}
+
+func TestMatchEngine_RestoreRNGStateReplaysRound(t *testing.T) {
+	const replayRound = 4
+	startTime := time.Date(2025, 3, 14, 18, 30, 0, 0, time.UTC)
+
+	// playTo plays the rounds before the replayed one on a fresh engine
+	playTo := func() *MatchEngine {
+		engine := newTestEngine(t, 42)
+		engine.match.StartTime = startTime
+		for i := 1; i < replayRound; i++ {
+			if _, _, err := engine.PlayNextRound(); err != nil {
+				t.Fatalf("PlayNextRound failed: %v", err)
+			}
+		}
+		return engine
+	}
+	logLines := func(events []models.GameEvent) []string {
+		lines := make([]string, len(events))
+		for i, event := range events {
+			lines[i] = event.ToLogLine()
+		}
+		return lines
+	}
+
+	original := playTo()
+	saved := original.RNGState()
+	round, events, err := original.PlayNextRound()
+	if err != nil {
+		t.Fatalf("PlayNextRound failed: %v", err)
+	}
+	if round.RNGState != saved {
+		t.Errorf("Expected round %d to record the RNG state %+v it started from, got %+v", replayRound, saved, round.RNGState)
+	}
+	want := logLines(events)
+
+	// Draw a few values so the replay starts from the wrong position
+	// unless the state is restored
+	replay := playTo()
+	for i := 0; i < 17; i++ {
+		replay.rng.Float64()
+	}
+	replay.RestoreRNGState(round.RNGState)
+	if replay.RNGState() != saved {
+		t.Fatalf("Expected the restored state %+v, got %+v", saved, replay.RNGState())
+	}
+	_, events, err = replay.PlayNextRound()
+	if err != nil {
+		t.Fatalf("PlayNextRound failed: %v", err)
+	}
+
+	got := logLines(events)
+	if len(got) != len(want) {
+		t.Fatalf("Expected the replayed round to have %d events, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Event %d differs:\nwant %s\ngot  %s", i, want[i], got[i])
+		}
+	}
+}
+
+func TestMatchGenerator_SimulationConfig(t *testing.T) {
+	req := func() *models.GenerateRequest {
+		return &models.GenerateRequest{
+			Map:     "de_mirage",
+			Format:  "mr12",
+			Teams:   []models.Team{{Name: "Team1"}, {Name: "Team2"}},
+			Options: models.MatchOptions{Seed: 3, AutoRoster: true, MaxRounds: 4},
+		}
+	}
+
+	if _, err := NewMatchGeneratorWithSimulationConfig(&models.SimulationConfig{DamageVariance: 2}); err == nil || !strings.Contains(err.Error(), "damage variance") {
+		t.Errorf("Expected an invalid config to be rejected on construction, got %v", err)
+	}
+
+	// Only the settings of interest are given, the limits are filled in
+	simConfig := &models.SimulationConfig{NoScopeProbability: 0.5, AggressionEffect: 1}
+	generator, err := NewMatchGeneratorWithSimulationConfig(simConfig)
+	if err != nil {
+		t.Fatalf("Expected a partial config to be accepted, got %v", err)
+	}
+	if simConfig.MaxEventsPerMatch != models.DefaultSimulationConfig().MaxEventsPerMatch {
+		t.Errorf("Expected the event limit to default, got %d", simConfig.MaxEventsPerMatch)
+	}
+	engine, err := generator.NewEngine(req())
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	if engine.simConfig != simConfig || engine.roundSimulator.simConfig != simConfig {
+		t.Error("Expected the engine to use the generator's simulation config")
+	}
+
+	// A config broken after construction is still caught when generating
+	simConfig.LethalityBias = 3
+	match, err := generator.Generate(req())
+	if err == nil || !strings.Contains(err.Error(), "lethality bias") {
+		t.Fatalf("Expected generation to reject the invalid config, got %v", err)
+	}
+	if match != nil {
+		t.Error("Expected no match to be generated")
+	}
+}
+
+func TestMatchGenerator_ServerTimers(t *testing.T) {
+	timers := models.ServerTimers{RoundTime: 90, FreezeTime: 10, BombTimer: 35, DefuseTime: 4, DefuseTimeNoKit: 8}
+	req := func(timers models.ServerTimers) *models.GenerateRequest {
+		return &models.GenerateRequest{
+			Map:     "de_mirage",
+			Format:  "mr12",
+			Teams:   []models.Team{{Name: "Team1"}, {Name: "Team2"}},
+			Options: models.MatchOptions{Seed: 3, AutoRoster: true, ServerTimers: timers},
+		}
+	}
+
+	generator := NewMatchGenerator()
+	engine, err := generator.NewEngine(req(timers))
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	if engine.freezeTime != 10*time.Second || engine.roundSimulator.roundTimeTicks() != int64(90*engine.tickRate) {
+		t.Errorf("Expected a 10s freeze time and 90s round timer, got %v and %d ticks", engine.freezeTime, engine.roundSimulator.roundTimeTicks())
+	}
+
+	match, err := generator.Generate(req(timers))
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	var plantTick int64
+	explosions := 0
+	for _, event := range match.Events {
+		switch e := event.(type) {
+		case *models.BombPlantEvent:
+			plantTick = e.Tick
+		case *models.BombExplodeEvent:
+			explosions++
+			if fuse := e.Tick - plantTick; fuse != int64(35*match.Config.TickRate) {
+				t.Errorf("Round %d: expected the bomb to explode 35s after the plant, got %d ticks", e.Round, fuse)
+			}
+		}
+	}
+	if explosions == 0 {
+		t.Error("Expected at least one bomb to explode")
+	}
+
+	if _, err := generator.Generate(req(models.ServerTimers{BombTimer: 8})); err == nil || !strings.Contains(err.Error(), "bomb timer") {
+		t.Errorf("Expected a bomb timer shorter than a defuse without a kit to be rejected, got %v", err)
+	}
+}
+
+func TestEconomyManager_AntiEcoFollowsOpponentEquipment(t *testing.T) {
+	engine := newTestEngine(t, 42)
+	em := NewEconomyManager(rand.New(rand.NewSource(42)))
+	team, enemy := engine.state.TeamEconomies["Team1"], engine.state.TeamEconomies["Team2"]
+	team.AverageMoney = 3000
+
+	testCases := []struct {
+		name           string
+		round          int
+		enemyEquipment int
+		threshold      int
+		expected       string
+	}{
+		{"enemy eco", 5, 1200, 0, "anti_eco"},
+		{"enemy full buy", 5, 24000, 0, "force_buy"},
+		{"pistol round", 1, 1000, 0, "full_buy"},
+		{"second half pistol round", 13, 1000, 0, "full_buy"},
+		{"enemy force buy under a raised threshold", 5, 9000, 10000, "anti_eco"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			enemy.EquipmentValue = tc.enemyEquipment
+			em.SetAntiEcoThreshold(tc.threshold)
+			buys, err := em.PlanTeamBuys(engine.match, engine.state, tc.round)
+			if err != nil {
+				t.Fatalf("PlanTeamBuys failed: %v", err)
+			}
+			if buys["Team1"] != tc.expected {
+				t.Errorf("Expected %s against %d of enemy equipment, got %s", tc.expected, tc.enemyEquipment, buys["Team1"])
+			}
+		})
+	}
+}
+
+func TestMatchEngine_BuyTypeFromPreBuyMoney(t *testing.T) {
+	engine := newTestEngine(t, 42)
+	if _, _, err := engine.PlayNextRound(); err != nil {
+		t.Fatalf("PlayNextRound failed: %v", err)
+	}
+
+	// 5200 each calls for a full buy, which the engine's own purchases would
+	// drop below the force-buy line if the type were read after buying
+	for i := range engine.match.Teams {
+		team := &engine.match.Teams[i]
+		for _, player := range team.Players {
+			engine.state.PlayerStates[player.Name].Money = 5200
+		}
+		engine.updateTeamEconomy(team)
+	}
+
+	roundData, _, err := engine.PlayNextRound()
+	if err != nil {
+		t.Fatalf("PlayNextRound failed: %v", err)
+	}
+	for _, team := range engine.match.Teams {
+		if buyType := roundData.Economy[team.Name].BuyType; buyType != "full_buy" {
+			t.Errorf("%s: expected round 2 to be recorded as full_buy, got %q", team.Name, buyType)
+		}
+	}
+}
+
+func TestMatchEngine_GrenadeLimitsHoldAllMatch(t *testing.T) {
+	for seed := int64(1); seed <= 5; seed++ {
+		engine := newTestEngine(t, seed)
+		if err := engine.GenerateMatch(); err != nil {
+			t.Fatalf("Seed %d: GenerateMatch failed: %v", seed, err)
+		}
+
+		for _, round := range engine.match.Rounds {
+			for name, state := range round.PlayerStates {
+				// Handing the inventory out again one grenade at a time must never hit a limit
+				var carried []models.Grenade
+				for _, grenade := range state.Grenades {
+					if !models.CanCarryGrenade(carried, grenade.Type) {
+						t.Errorf("Seed %d round %d: %s carries more grenades than allowed: %v", seed, round.RoundNumber, name, state.Grenades)
+						break
+					}
+					carried = append(carried, grenade)
+				}
+			}
+		}
+	}
+}
+
+func TestMatchEngine_AntiEcoOnGeneratedRounds(t *testing.T) {
+	testCases := []struct {
+		name        string
+		enemyMoney  int
+		enemyRifles bool
+		threshold   int
+		expected    string
+	}{
+		{"enemy eco", 1000, false, 0, "anti_eco"},
+		{"enemy full buy", 6000, false, 0, "force_buy"},
+		{"enemy saving with rifles carried in", 1000, true, 0, "force_buy"},
+		{"enemy saving with rifles under a raised threshold", 1000, true, 20000, "anti_eco"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			engine := newTestEngine(t, 42)
+			engine.config.AntiEcoThreshold = tc.threshold
+			if _, _, err := engine.PlayNextRound(); err != nil {
+				t.Fatalf("PlayNextRound failed: %v", err)
+			}
+
+			// Team1 can afford SMGs but not a full buy
+			for i := range engine.match.Teams {
+				team := &engine.match.Teams[i]
+				money := 3000
+				if i == 1 {
+					money = tc.enemyMoney
+				}
+				for _, player := range team.Players {
+					state := engine.state.PlayerStates[player.Name]
+					state.Money = money
+					state.PrimaryWeapon, state.Armor, state.HasHelmet, state.Grenades = nil, 0, false, nil
+					if i == 1 && tc.enemyRifles {
+						state.PrimaryWeapon = &models.Weapon{Name: "ak47", Price: 2700}
+					}
+				}
+				engine.updateTeamEconomy(team)
+			}
+
+			roundData, _, err := engine.PlayNextRound()
+			if err != nil {
+				t.Fatalf("PlayNextRound failed: %v", err)
+			}
+			if buyType := roundData.Economy["Team1"].BuyType; buyType != tc.expected {
+				t.Errorf("Expected Team1 to play a %s round, got %s", tc.expected, buyType)
+			}
+		})
+	}
+}
+
+func TestMatchEngine_ScoresFollowTeamsAcrossHalftime(t *testing.T) {
+	engine := newTestEngine(t, 42)
+	if err := engine.GenerateMatch(); err != nil {
+		t.Fatalf("GenerateMatch failed: %v", err)
+	}
+
+	// Team1 starts on CT and switches to T after halftime
+	halftime := engine.match.HalftimeRound()
+	previous := map[string]int{}
+	for _, round := range engine.match.Rounds {
+		winner := "Team1"
+		if (models.NormalizeSide(round.Winner) == "CT") != (round.RoundNumber <= halftime) {
+			winner = "Team2"
+		}
+		loser := map[string]string{"Team1": "Team2", "Team2": "Team1"}[winner]
+
+		if round.Scores[winner] != previous[winner]+1 || round.Scores[loser] != previous[loser] {
+			t.Fatalf("Round %d won by %s (%s): expected scores %s %d, %s %d, got %v",
+				round.RoundNumber, round.Winner, winner, winner, previous[winner]+1, loser, previous[loser], round.Scores)
+		}
+		previous = round.Scores
+	}
+	if len(engine.match.Rounds) <= halftime {
+		t.Fatalf("Expected the match to go past halftime, it ended after %d rounds", len(engine.match.Rounds))
+	}
+	for name, score := range previous {
+		if engine.match.Scores[name] != score {
+			t.Errorf("%s: expected a final score of %d, got %d", name, score, engine.match.Scores[name])
+		}
+	}
+}
+
+// generateDetailedMatch generates a verbose match through the public generator
+func generateDetailedMatch(t *testing.T, generator *MatchGenerator, seed int64) *models.Match {
+	t.Helper()
+
+	match, err := generator.Generate(&models.GenerateRequest{
+		Map:     "de_mirage",
+		Format:  "mr12",
+		Teams:   []models.Team{{Name: "Team1"}, {Name: "Team2"}},
+		Options: models.MatchOptions{Seed: seed, AutoRoster: true, VerboseLogging: true},
+	})
+	if err != nil {
+		t.Fatalf("Seed %d: Generate failed: %v", seed, err)
+	}
+	return match
+}
+
+func TestMatchGenerator_HitsArePrecededByWeaponFire(t *testing.T) {
+	for seed := int64(1); seed <= 3; seed++ {
+		match := generateDetailedMatch(t, NewMatchGenerator(), seed)
+		spacing := int64(match.Config.TickRate / 10)
+
+		kills, hurts := 0, 0
+		var fires []*models.WeaponFireEvent
+		for i, event := range match.Events {
+			switch e := event.(type) {
+			case *models.WeaponFireEvent:
+				fires = append(fires, e)
+			case *models.PlayerHurtEvent:
+				hurts++
+				fired := false
+				for j := len(fires) - 1; j >= 0 && e.Tick-fires[j].Tick <= 3*spacing; j-- {
+					fired = fired || (fires[j].Player.Name == e.Attacker.Name && fires[j].Weapon == e.Weapon)
+				}
+				if !fired {
+					t.Fatalf("Seed %d: %s hit %s with %s at tick %d without firing it first", seed, e.Attacker.Name, e.Victim.Name, e.Weapon, e.Tick)
+				}
+			case *models.KillEvent:
+				kills++
+				hurt, ok := match.Events[i-1].(*models.PlayerHurtEvent)
+				if !ok || hurt.Attacker.Name != e.Attacker.Name || hurt.Victim.Name != e.Victim.Name || hurt.Tick != e.Tick {
+					t.Fatalf("Seed %d: expected %s killing %s to follow their lethal hit, got %s", seed, e.Attacker.Name, e.Victim.Name, match.Events[i-1].ToLogLine())
+				}
+				if hurt.Weapon != e.Weapon || hurt.Hitgroup != e.Hitgroup || hurt.Health != 0 {
+					t.Errorf("Seed %d: expected the lethal hit to use %s on hitgroup %d and leave 0 health, got %s", seed, e.Weapon, e.Hitgroup, hurt.ToLogLine())
+				}
+			}
+		}
+		if kills == 0 || hurts <= kills {
+			t.Errorf("Seed %d: expected hits besides the %d lethal ones, got %d hits", seed, kills, hurts)
+		}
+	}
+}
+
+func TestMatchGenerator_KillsArePrecededByWeaponFire(t *testing.T) {
+	for _, verbose := range []bool{true, false} {
+		match, err := NewMatchGenerator().Generate(&models.GenerateRequest{
+			Map:     "de_mirage",
+			Format:  "mr12",
+			Teams:   []models.Team{{Name: "Team1"}, {Name: "Team2"}},
+			Options: models.MatchOptions{Seed: 42, AutoRoster: true, VerboseLogging: verbose},
+		})
+		if err != nil {
+			t.Fatalf("Generate failed: %v", err)
+		}
+
+		kills, fires := 0, 0
+		for i, event := range match.Events {
+			switch e := event.(type) {
+			case *models.WeaponFireEvent:
+				fires++
+			case *models.KillEvent:
+				kills++
+				if !verbose {
+					continue
+				}
+				preceded := false
+				for _, earlier := range match.Events[:i] {
+					if fire, ok := earlier.(*models.WeaponFireEvent); ok && fire.Player.Name == e.Attacker.Name && fire.Weapon == e.Weapon && fire.Position == e.AttackerPos {
+						preceded = true
+					}
+				}
+				if !preceded {
+					t.Errorf("Round %d: kill of %s by %s with %s has no preceding weapon fire", e.Round, e.Victim.Name, e.Attacker.Name, e.Weapon)
+				}
+			}
+		}
+
+		if kills == 0 {
+			t.Fatalf("Expected some kills with verbose logging %v", verbose)
+		}
+		if !verbose && fires != 0 {
+			t.Errorf("Expected no weapon fire without verbose logging, got %d", fires)
+		}
+	}
+}
+
+func TestMatchGenerator_AssistsFollowDamageThreshold(t *testing.T) {
+	assistsAt := func(threshold float64) int {
+		generator, err := NewMatchGeneratorWithSimulationConfig(&models.SimulationConfig{AssistDamageThreshold: threshold})
+		if err != nil {
+			t.Fatalf("NewMatchGeneratorWithSimulationConfig failed: %v", err)
+		}
+		match := generateDetailedMatch(t, generator, 5)
+
+		// Health each player took from each victim per round, as the hurt lines report it
+		type key struct {
+			round            int
+			attacker, victim string
+		}
+		dealt := make(map[key]int)
+		credited := make(map[string]int)
+		assists := 0
+		for _, event := range match.Events {
+			switch e := event.(type) {
+			case *models.PlayerHurtEvent:
+				if e.Health > 0 {
+					dealt[key{e.Round, e.Attacker.Name, e.Victim.Name}] += e.Damage
+				}
+			case *models.KillEvent:
+				if e.Assister == nil {
+					continue
+				}
+				assists++
+				credited[e.Assister.Name]++
+				if e.Assister.Side != e.Attacker.Side || e.Assister.Name == e.Attacker.Name {
+					t.Errorf("Threshold %v: %s assisted their own kill or an enemy's", threshold, e.Assister.Name)
+				}
+				if damage := dealt[key{e.Round, e.Assister.Name, e.Victim.Name}]; float64(damage) < threshold*100 {
+					t.Errorf("Threshold %v: %s got an assist on %s for %d damage", threshold, e.Assister.Name, e.Victim.Name, damage)
+				}
+			}
+		}
+		for _, team := range match.Teams {
+			for _, player := range team.Players {
+				if player.Stats.Assists != credited[player.Name] {
+					t.Errorf("Threshold %v: expected %s to have %d assists, got %d", threshold, player.Name, credited[player.Name], player.Stats.Assists)
+				}
+			}
+		}
+		return assists
+	}
+
+	// The threshold decides nothing but assists, so both runs play the same kills
+	low, high := assistsAt(0.1), assistsAt(0.9)
+	if high >= low || low == 0 {
+		t.Errorf("Expected fewer assists at a 90%% threshold than at 10%%, got %d and %d", high, low)
+	}
+	if def := assistsAt(models.DefaultSimulationConfig().AssistDamageThreshold); def == 0 {
+		t.Error("Expected assists at the default threshold")
+	}
+}
+
+func TestMatchGenerator_DamagePacing(t *testing.T) {
+	generator := NewMatchGenerator()
+	original := generateDetailedMatch(t, generator, 9)
+	lead := models.DurationToTicks(engagementLead, original.Config.TickRate)
+
+	// outsideFights counts the non-lethal hits that land outside every fight leading up to a kill
+	outsideFights := func(match *models.Match) (int, int) {
+		killTicks := make(map[int][]int64)
+		for _, event := range match.Events {
+			if kill, ok := event.(*models.KillEvent); ok {
+				killTicks[kill.Round] = append(killTicks[kill.Round], kill.Tick)
+			}
+		}
+		hits, outside := 0, 0
+		for _, event := range match.Events {
+			hurt, ok := event.(*models.PlayerHurtEvent)
+			if !ok || hurt.Health == 0 {
+				continue
+			}
+			hits++
+			within := false
+			for _, tick := range killTicks[hurt.Round] {
+				within = within || (hurt.Tick >= tick-lead && hurt.Tick <= tick)
+			}
+			if !within {
+				outside++
+			}
+		}
+		return hits, outside
+	}
+
+	if hits, outside := outsideFights(original); hits == 0 || outside > 0 {
+		t.Errorf("Expected every hit to land in a fight, got %d of %d outside", outside, hits)
+	}
+
+	config := original.Config
+	config.DamagePacing = "uniform"
+	uniform, err := generator.Regenerate(original, config)
+	if err != nil {
+		t.Fatalf("Regenerate failed: %v", err)
+	}
+	if hits, outside := outsideFights(uniform); outside == 0 {
+		t.Errorf("Expected uniform pacing to put some of %d hits outside fights", hits)
+	}
+}
+
+func TestMatchGenerator_DamageModel(t *testing.T) {
+	config := models.DefaultMatchConfig()
+	base := NewEventGenerator(rand.New(rand.NewSource(1)), &config)
+	base.SetDamageModel(0, 0)
+
+	// damageRatios returns every non-lethal hit's damage over the weapon's hitgroup-adjusted base damage
+	damageRatios := func(variance, bias float64) []float64 {
+		generator, err := NewMatchGeneratorWithSimulationConfig(&models.SimulationConfig{DamageVariance: variance, LethalityBias: bias})
+		if err != nil {
+			t.Fatalf("NewMatchGeneratorWithSimulationConfig failed: %v", err)
+		}
+		var ratios []float64
+		for _, event := range generateDetailedMatch(t, generator, 11).Events {
+			hurt, ok := event.(*models.PlayerHurtEvent)
+			if !ok || hurt.Health == 0 {
+				continue
+			}
+			// Armor takes its share of the hit out of the damage
+			want := base.calculateDamage(hurt.Attacker, hurt.Victim, hurt.Weapon, hurt.Hitgroup)
+			ratios = append(ratios, float64(hurt.Damage+hurt.DamageArmor)/float64(want))
+		}
+		if len(ratios) == 0 {
+			t.Fatalf("Variance %v bias %v: expected non-lethal hits", variance, bias)
+		}
+		return ratios
+	}
+	mean := func(values []float64) float64 {
+		sum := 0.0
+		for _, value := range values {
+			sum += value
+		}
+		return sum / float64(len(values))
+	}
+
+	for _, ratio := range damageRatios(0, 0) {
+		if ratio != 1 {
+			t.Fatalf("Expected every hit to deal exactly its base damage without variance, got %.2fx", ratio)
+		}
+	}
+	if grindy, bloody := mean(damageRatios(0.5, -1)), mean(damageRatios(0.5, 1)); grindy >= bloody {
+		t.Errorf("Expected a positive lethality bias to hit harder, got %.2fx vs %.2fx", bloody, grindy)
+	}
+}
+
+func TestMatchGenerator_UtilityComesFromInventory(t *testing.T) {
+	throws := 0
+	for seed := int64(1); seed <= 5; seed++ {
+		match := generateDetailedMatch(t, NewMatchGenerator(), seed)
+
+		thrown := make(map[int]map[string][]models.Grenade)
+		bought := make(map[int]map[string]int)
+		for _, event := range match.Events {
+			switch e := event.(type) {
+			case *models.GrenadeThrowEvent:
+				throws++
+				if thrown[e.Round] == nil {
+					thrown[e.Round] = make(map[string][]models.Grenade)
+				}
+				thrown[e.Round][e.Player.Name] = append(thrown[e.Round][e.Player.Name], models.Grenade{Type: e.GrenadeType})
+			case *models.ItemPurchaseEvent:
+				if bought[e.Round] == nil {
+					bought[e.Round] = make(map[string]int)
+				}
+				bought[e.Round][e.Player.Name+" "+e.Item]++
+			}
+		}
+
+		for _, round := range match.Rounds {
+			pistolRound := match.IsPistolRound(round.RoundNumber)
+			for name, state := range round.PlayerStates {
+				// What a player threw and what they kept made up one legal inventory
+				var held []models.Grenade
+				for _, grenade := range append(thrown[round.RoundNumber][name], state.Grenades...) {
+					if !models.CanCarryGrenade(held, grenade.Type) {
+						t.Fatalf("Seed %d round %d: %s threw %v and kept %v, more than they could carry", seed, round.RoundNumber, name, thrown[round.RoundNumber][name], state.Grenades)
+					}
+					held = append(held, grenade)
+				}
+
+				// Pistol rounds start empty-handed, so every grenade thrown was bought
+				for _, grenade := range thrown[round.RoundNumber][name] {
+					if !pistolRound {
+						break
+					}
+					if bought[round.RoundNumber][name+" "+grenade.Type]--; bought[round.RoundNumber][name+" "+grenade.Type] < 0 {
+						t.Errorf("Seed %d round %d: %s threw a %s they never bought", seed, round.RoundNumber, name, grenade.Type)
+					}
+				}
+			}
+		}
+	}
+	if throws == 0 {
+		t.Fatal("Expected players to throw the grenades they bought")
+	}
+}
+
+func TestMatchGenerator_FlashDurationsFollowPositions(t *testing.T) {
+	generator := NewMatchGenerator()
+	original := generateDetailedMatch(t, generator, 13)
+
+	// flashes returns every blind of the match, failing on durations outside the model
+	flashes := func(match *models.Match) []*models.FlashbangEvent {
+		var events []*models.FlashbangEvent
+		for _, event := range match.Events {
+			flash, ok := event.(*models.FlashbangEvent)
+			if !ok {
+				continue
+			}
+			for i, duration := range flash.Durations {
+				if duration < flashMinDuration || duration > flashMaxDuration {
+					t.Errorf("Round %d: %s blinded for %.2fs, outside [%v, %v]", flash.Round, flash.Flashed[i].Name, duration, flashMinDuration, flashMaxDuration)
+				}
+			}
+			events = append(events, flash)
+		}
+		return events
+	}
+	if len(flashes(original)) == 0 {
+		t.Fatal("Expected flashes in the generated match")
+	}
+
+	config := original.Config
+	config.IncludePositions = true
+	positioned, err := generator.Regenerate(original, config)
+	if err != nil {
+		t.Fatalf("Regenerate failed: %v", err)
+	}
+
+	// Players who picked up a dropped bomb moved during the round
+	moved := make(map[int]map[string]bool)
+	for _, event := range positioned.Events {
+		if pickup, ok := event.(*models.BombPickupEvent); ok {
+			if moved[pickup.Round] == nil {
+				moved[pickup.Round] = make(map[string]bool)
+			}
+			moved[pickup.Round][pickup.Player.Name] = true
+		}
+	}
+
+	short, long := 0, 0
+	for _, flash := range flashes(positioned) {
+		round := positioned.Rounds[flash.Round-1]
+		for i, player := range flash.Flashed {
+			if flash.Durations[i] < 1 {
+				short++
+			} else if flash.Durations[i] > 3 {
+				long++
+			}
+			if moved[flash.Round][player.Name] {
+				continue
+			}
+			position := round.PlayerStates[player.Name].Position
+			if distance := math.Hypot(position.X-flash.Position.X, position.Y-flash.Position.Y); distance >= flashRange {
+				t.Errorf("Round %d: %s blinded by a flash %.0f units away", flash.Round, player.Name, distance)
+			}
+		}
+	}
+	if short == 0 || long == 0 {
+		t.Errorf("Expected both glancing and full blinds, got %d under 1s and %d over 3s", short, long)
+	}
+}
+
+func TestMatchGenerator_FlashAssistsNeedABlindKill(t *testing.T) {
+	enemiesFlashed, flashAssists := 0, 0
+	for seed := int64(1); seed <= 5; seed++ {
+		match := generateDetailedMatch(t, NewMatchGenerator(), seed)
+		tickRate := match.Config.TickRate
+
+		// blinds[round][victim] lists the flashes that blinded a player
+		type blind struct {
+			thrower     *models.Player
+			from, until int64
+		}
+		blinds := make(map[int]map[string][]blind)
+		credited := make(map[string]int)
+		for _, event := range match.Events {
+			switch event := event.(type) {
+			case *models.FlashbangEvent:
+				if blinds[event.Round] == nil {
+					blinds[event.Round] = make(map[string][]blind)
+				}
+				for i, victim := range event.Flashed {
+					until := event.Tick + models.DurationToTicks(time.Duration(event.Durations[i]*float64(time.Second)), tickRate)
+					blinds[event.Round][victim.Name] = append(blinds[event.Round][victim.Name], blind{event.Player, event.Tick, until})
+				}
+			case *models.KillEvent:
+				var eligible *models.Player
+				for _, b := range blinds[event.Round][event.Victim.Name] {
+					if event.Tick >= b.from && event.Tick <= b.until && b.thrower.Name != event.Attacker.Name && b.thrower.Side == event.Attacker.Side {
+						eligible = b.thrower
+						break
+					}
+				}
+				switch {
+				case eligible == nil && event.FlashAssister != nil:
+					t.Errorf("Seed %d round %d: %s flash-assisted killing %s, who was not blinded by them", seed, event.Round, event.FlashAssister.Name, event.Victim.Name)
+				case eligible != nil && event.FlashAssister == nil:
+					t.Errorf("Seed %d round %d: %s killed %s blinded by %s without a flash assist", seed, event.Round, event.Attacker.Name, event.Victim.Name, eligible.Name)
+				case event.FlashAssister != nil:
+					credited[event.FlashAssister.Name]++
+				}
+			}
+		}
+
+		for _, team := range match.Teams {
+			for _, player := range team.Players {
+				if player.Stats.FlashAssists != credited[player.Name] {
+					t.Errorf("Seed %d: %s has %d flash assists, %d kills were flash-assisted", seed, player.Name, player.Stats.FlashAssists, credited[player.Name])
+				}
+				enemiesFlashed += player.Stats.EnemiesFlashed
+				flashAssists += player.Stats.FlashAssists
+			}
+		}
+	}
+	if enemiesFlashed == 0 || flashAssists == 0 {
+		t.Errorf("Expected enemies flashed and flash assists, got %d and %d", enemiesFlashed, flashAssists)
+	}
+	if flashAssists >= enemiesFlashed {
+		t.Errorf("Expected fewer flash assists than enemies flashed, got %d of %d", flashAssists, enemiesFlashed)
+	}
+}
+
+func TestMatchGenerator_KillsAgreeWithTheirLethalHit(t *testing.T) {
+	for seed := int64(1); seed <= 5; seed++ {
+		match := generateDetailedMatch(t, NewMatchGenerator(), seed)
+
+		kills := make(map[string]int)
+		headshots := make(map[string]int)
+		for i, event := range match.Events {
+			kill, ok := event.(*models.KillEvent)
+			if !ok {
+				continue
+			}
+			if kill.Headshot != (kill.Hitgroup == 1) {
+				t.Errorf("Seed %d: kill on hitgroup %d has headshot %v", seed, kill.Hitgroup, kill.Headshot)
+			}
+			if strings.Contains(kill.ToLogLine(), "(headshot)") != kill.Headshot {
+				t.Errorf("Seed %d: kill log line disagrees with headshot %v: %s", seed, kill.Headshot, kill.ToLogLine())
+			}
+			hurt, ok := match.Events[i-1].(*models.PlayerHurtEvent)
+			if !ok || hurt.Victim.Name != kill.Victim.Name || hurt.Weapon != kill.Weapon || hurt.Hitgroup != kill.Hitgroup {
+				t.Errorf("Seed %d: expected the lethal hit of %s to use %s on hitgroup %d, got %s", seed, kill.ToLogLine(), kill.Weapon, kill.Hitgroup, match.Events[i-1].ToLogLine())
+			}
+			kills[kill.Attacker.Name]++
+			if kill.Headshot {
+				headshots[kill.Attacker.Name]++
+			}
+		}
+
+		total, totalHeadshots := 0, 0
+		for _, team := range match.Teams {
+			for _, player := range team.Players {
+				if player.Stats.Kills != kills[player.Name] || player.Stats.Headshots != headshots[player.Name] {
+					t.Errorf("Seed %d: %s has %d kills and %d headshots, the log has %d and %d", seed, player.Name, player.Stats.Kills, player.Stats.Headshots, kills[player.Name], headshots[player.Name])
+				}
+				total += kills[player.Name]
+				totalHeadshots += headshots[player.Name]
+			}
+		}
+		if totalHeadshots == 0 || totalHeadshots == total {
+			t.Errorf("Seed %d: expected a mix of headshots and body kills, got %d headshots of %d kills", seed, totalHeadshots, total)
+		}
+	}
+}
+
+func TestMatchGenerator_BrokenArmorStaysBroken(t *testing.T) {
+	// Hits rarely leave a player alive with no armor, so matches are generated until one does
+	breaks, survivors := 0, 0
+	for seed := int64(1); seed <= 5 || (survivors == 0 && seed <= 50); seed++ {
+		match := generateDetailedMatch(t, NewMatchGenerator(), seed)
+
+		// broken[round][victim] is set once a hit takes a player's last armor in a round
+		broken := make(map[int]map[string]bool)
+		dead := make(map[int]map[string]bool)
+		for _, event := range match.Events {
+			switch e := event.(type) {
+			case *models.PlayerHurtEvent:
+				if broken[e.Round] == nil {
+					broken[e.Round] = make(map[string]bool)
+				}
+				if broken[e.Round][e.Victim.Name] && (e.DamageArmor != 0 || e.Armor != 0) {
+					t.Errorf("Seed %d round %d: %s took armor damage after their armor broke: %s", seed, e.Round, e.Victim.Name, e.ToLogLine())
+				}
+				if e.DamageArmor > 0 && e.Armor == 0 {
+					broken[e.Round][e.Victim.Name] = true
+					breaks++
+				}
+			case *models.KillEvent:
+				if dead[e.Round] == nil {
+					dead[e.Round] = make(map[string]bool)
+				}
+				dead[e.Round][e.Victim.Name] = true
+			}
+		}
+
+		for _, round := range match.Rounds {
+			for name := range broken[round.RoundNumber] {
+				if dead[round.RoundNumber][name] {
+					continue
+				}
+				survivors++
+				if playerState := round.PlayerStates[name]; !playerState.ArmorBroken || playerState.Armor != 0 {
+					t.Errorf("Seed %d round %d: %s survived with broken armor but ended with %d armor, broken %v", seed, round.RoundNumber, name, playerState.Armor, playerState.ArmorBroken)
+				}
+			}
+		}
+	}
+	if breaks == 0 || survivors == 0 {
+		t.Errorf("Expected armor to break, including on survivors, got %d breaks and %d survivors", breaks, survivors)
+	}
+}
+
+func TestMatchGenerator_DetailedMatchIsReproducible(t *testing.T) {
+	// lines returns the match's log lines without their wall-clock timestamps
+	lines := func(match *models.Match) []string {
+		var described []string
+		for _, event := range match.Events {
+			for _, line := range strings.Split(event.ToLogLine(), "\n") {
+				if _, rest, ok := strings.Cut(line, ": "); ok {
+					line = rest
+				}
+				described = append(described, fmt.Sprintf("%d %s", event.GetTick(), line))
+			}
+		}
+		return described
+	}
+
+	first := lines(generateDetailedMatch(t, NewMatchGenerator(), 17))
+	second := lines(generateDetailedMatch(t, NewMatchGenerator(), 17))
+	if len(first) != len(second) {
+		t.Fatalf("Expected the same seed to log the same %d lines, got %d", len(first), len(second))
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("Line %d differs between runs of the same seed:\n%s\n%s", i, first[i], second[i])
+		}
+	}
+	for _, want := range []string{"threw flashbang", "blinded for", "attacked"} {
+		found := false
+		for _, line := range first {
+			found = found || strings.Contains(line, want)
+		}
+		if !found {
+			t.Errorf("Expected the generated log to contain %q lines", want)
+		}
+	}
+}
+
+// recordingBroadcaster keeps the JSON payload of every round_end broadcast
+type recordingBroadcaster struct {
+	roundEnds []map[string]json.RawMessage
+}
+
+func (b *recordingBroadcaster) BroadcastMatchEvent(matchID string, eventType string, data interface{}) error {
+	if eventType != "round_end" {
+		return nil
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return err
+	}
+	b.roundEnds = append(b.roundEnds, fields)
+	return nil
+}
+
+func (b *recordingBroadcaster) BroadcastMatchStatus(matchID string, status string, data interface{}) error {
+	return nil
+}
+
+func (b *recordingBroadcaster) BroadcastMatchError(matchID string, errorMsg string) error {
+	return nil
+}
+
+func TestMatchGenerator_RoundStatDeltas(t *testing.T) {
+	stream := func(roundStats bool) (*models.Match, *recordingBroadcaster) {
+		broadcaster := &recordingBroadcaster{}
+		match, err := NewMatchGenerator().GenerateWithStreaming(&models.GenerateRequest{
+			Map:     "de_mirage",
+			Format:  "mr12",
+			Teams:   []models.Team{{Name: "Team1"}, {Name: "Team2"}},
+			Options: models.MatchOptions{Seed: 11, AutoRoster: true, RoundStats: roundStats},
+		}, broadcaster)
+		if err != nil {
+			t.Fatalf("GenerateWithStreaming failed: %v", err)
+		}
+		if len(broadcaster.roundEnds) != match.CurrentRound {
+			t.Fatalf("Expected a round_end broadcast for each of the %d rounds, got %d", match.CurrentRound, len(broadcaster.roundEnds))
+		}
+		return match, broadcaster
+	}
+
+	match, broadcaster := stream(true)
+	totals := make(map[string]PlayerStatDelta)
+	var last map[string]PlayerStatDelta
+	for i, roundEnd := range broadcaster.roundEnds {
+		var deltas []PlayerStatDelta
+		if err := json.Unmarshal(roundEnd["player_stats"], &deltas); err != nil {
+			t.Fatalf("Round %d: decoding player_stats failed: %v", i+1, err)
+		}
+		if len(deltas) != 10 {
+			t.Fatalf("Round %d: expected a delta for each of the 10 players, got %d", i+1, len(deltas))
+		}
+		last = make(map[string]PlayerStatDelta)
+		for _, delta := range deltas {
+			if delta.Kills < 0 || delta.Deaths < 0 || delta.Deaths > 1 {
+				t.Errorf("Round %d: %s has %d kills and %d deaths this round", i+1, delta.Name, delta.Kills, delta.Deaths)
+			}
+			total := totals[delta.Name]
+			total.Kills += delta.Kills
+			total.Deaths += delta.Deaths
+			total.EnemiesFlashed += delta.EnemiesFlashed
+			total.FlashAssists += delta.FlashAssists
+			totals[delta.Name] = total
+			last[delta.Name] = delta
+		}
+	}
+
+	kills := 0
+	for _, team := range match.Teams {
+		for _, player := range team.Players {
+			total := totals[player.Name]
+			kills += total.Kills
+			if total.Kills != player.Stats.Kills || total.Deaths != player.Stats.Deaths {
+				t.Errorf("%s: round deltas add up to %d/%d, final stats are %d/%d", player.Name, total.Kills, total.Deaths, player.Stats.Kills, player.Stats.Deaths)
+			}
+			if total.EnemiesFlashed != player.Stats.EnemiesFlashed || total.FlashAssists != player.Stats.FlashAssists {
+				t.Errorf("%s: round deltas add up to %d flashed and %d flash assists, final stats are %d and %d", player.Name, total.EnemiesFlashed, total.FlashAssists, player.Stats.EnemiesFlashed, player.Stats.FlashAssists)
+			}
+
+			// ADR and rating are running values, so the last round reports the final ones
+			wantADR := float64(player.Stats.Damage) / float64(match.CurrentRound)
+			if math.Abs(last[player.Name].ADR-wantADR) > 1e-9 || math.Abs(last[player.Name].ADR-player.Stats.ADR) > 1e-9 {
+				t.Errorf("%s: last round ADR %.2f, expected %.2f", player.Name, last[player.Name].ADR, wantADR)
+			}
+			if last[player.Name].Rating <= 0 || math.Abs(last[player.Name].Rating-player.Stats.Rating) > 1e-9 {
+				t.Errorf("%s: last round rating %.3f, final rating %.3f", player.Name, last[player.Name].Rating, player.Stats.Rating)
+			}
+		}
+	}
+
+	if kills == 0 {
+		t.Fatal("Expected the round deltas to report some kills")
+	}
+
+	_, broadcaster = stream(false)
+	for i, roundEnd := range broadcaster.roundEnds {
+		if _, ok := roundEnd["player_stats"]; ok {
+			t.Fatalf("Round %d: expected no player_stats without round stats", i+1)
+		}
+	}
+}
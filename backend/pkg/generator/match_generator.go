@@ -1,12 +1,23 @@
 package generator
 
 import (
+	"errors"
 	"fmt"
+	"log"
+	"math/rand"
+	"runtime/debug"
+	"strings"
 	"time"
 
 	"github.com/noueii/nocs-log-generator/backend/pkg/models"
 )
 
+// sideSeedOffset keeps the starting side coin flip independent from the simulation RNG
+const sideSeedOffset = 0x51de5
+
+// ErrGenerationPanic marks a generation error recovered from a panic in the simulator
+var ErrGenerationPanic = errors.New("panic during match generation")
+
 // Event structures for WebSocket streaming
 type GenerationStartEvent struct {
 	MatchID   string    `json:"match_id"`
@@ -26,27 +37,48 @@ type GenerationErrorEvent struct {
 // MatchGenerator handles CS2 match log generation
 type MatchGenerator struct {
 	economyManager *models.EconomyManager
+	simConfig      *models.SimulationConfig
 }
 
 // NewMatchGenerator creates a new match generator instance
 func NewMatchGenerator() *MatchGenerator {
+	simConfig := models.DefaultSimulationConfig()
 	return &MatchGenerator{
 		economyManager: models.NewEconomyManager(),
+		simConfig:      &simConfig,
 	}
 }
 
-// Generate creates a CS2 match log from the given configuration
-func (g *MatchGenerator) Generate(req *models.GenerateRequest) (*models.Match, error) {
-	if req == nil {
-		return nil, fmt.Errorf("generate request cannot be nil")
+// NewMatchGeneratorWithSimulationConfig creates a match generator whose engines use
+// the given simulation settings. Missing limits are filled with their defaults and
+// an invalid configuration is rejected.
+func NewMatchGeneratorWithSimulationConfig(simConfig *models.SimulationConfig) (*MatchGenerator, error) {
+	if simConfig == nil {
+		return nil, fmt.Errorf("simulation config cannot be nil")
+	}
+	simConfig.ApplyDefaults()
+	if err := simConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid simulation config: %w", err)
 	}
+	
+	return &MatchGenerator{
+		economyManager: models.NewEconomyManager(),
+		simConfig:      simConfig,
+	}, nil
+}
 
-	// Validate request
-	if err := req.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid request: %w", err)
+// newEngine creates the engine that plays a prepared match with the generator's
+// simulation settings
+func (g *MatchGenerator) newEngine(config *models.MatchConfig, match *models.Match) (*MatchEngine, error) {
+	engine := NewMatchEngine(config, match)
+	if err := engine.SetSimulationConfig(g.simConfig); err != nil {
+		return nil, err
 	}
+	return engine, nil
+}
 
-	// Create match configuration from request
+// BuildMatchConfig creates the match configuration for a generate request
+func BuildMatchConfig(req *models.GenerateRequest) models.MatchConfig {
 	config := models.DefaultMatchConfig()
 	config.Format = req.Format
 	config.Map = req.Map
@@ -58,84 +90,136 @@ func (g *MatchGenerator) Generate(req *models.GenerateRequest) (*models.Match, e
 	if req.Options.Seed > 0 {
 		config.Seed = req.Options.Seed
 	}
+	config.StartTime = req.Options.StartTime
 	if req.Options.MaxRounds > 0 {
 		config.MaxRounds = req.Options.MaxRounds
 	}
 	config.Overtime = req.Options.Overtime
-
-	// Prepare teams with proper side assignments
-	teams := make([]models.Team, len(req.Teams))
-	copy(teams, req.Teams)
-	
-	// Assign sides (first team CT, second team T)
-	teams[0].Side = "CT"
-	teams[1].Side = "TERRORIST"
-	
-	// Update player sides and assign user IDs
-	for i := range teams {
-		for j := range teams[i].Players {
-			teams[i].Players[j].Side = teams[i].Side
-			teams[i].Players[j].Team = teams[i].Name
-			teams[i].Players[j].UserID = (i * 5) + j + 1 // Simple user ID assignment
-		}
+	config.IncludeRoundStats = req.Options.RoundStats
+	config.FastMode = req.Options.FastMode
+	config.VerboseLogging = req.Options.VerboseLogging
+	config.WeaponSkins = req.Options.WeaponSkins
+	config.CasterRecaps = req.Options.CasterRecaps
+	config.KillStreaks = req.Options.KillStreaks
+	config.FirstBlood = req.Options.FirstBlood
+	config.ServerCvars = req.Options.ServerCvars
+	config.StatusInterval = req.Options.StatusInterval
+	config.MVPEvents = req.Options.MVPEvents
+	config.MinRoundDuration = req.Options.MinRoundDuration
+	config.ServerTimers = req.Options.ServerTimers
+	config.KnifeKillReward = req.Options.KnifeKillReward
+	config.GrenadeKillReward = req.Options.GrenadeKillReward
+	config.SeedPerRound = req.Options.SeedPerRound
+	config.ForceBuyType = req.Options.ForceBuyType
+	config.AntiEcoThreshold = req.Options.AntiEcoThreshold
+	config.LineEnding = req.Options.LineEnding
+	config.MaxNameLength = req.Options.MaxNameLength
+	config.NamePolicy = req.Options.NamePolicy
+	config.EmitPositions = req.Options.EmitPositions
+	config.PositionSampleRate = req.Options.PositionSampleRate
+	if len(req.Options.LossBonusLadder) > 0 {
+		config.LossBonusLadder = req.Options.LossBonusLadder
 	}
+	if req.Options.MaxMoney > 0 {
+		config.MaxMoney = req.Options.MaxMoney
+	}
+	if len(req.Options.Scoreline) > 0 {
+		config.Scoreline = req.Options.Scoreline
+	}
+	if len(req.Options.ExtraCvars) > 0 {
+		config.ExtraCvars = req.Options.ExtraCvars
+	}
+	if len(req.Options.Veto) > 0 {
+		config.Veto = req.Options.Veto
+	}
+	config.TournamentName = strings.TrimSpace(req.Options.TournamentName)
+	config.MatchTitle = strings.TrimSpace(req.Options.MatchTitle)
+	
+	return config
+}
 
-	// Create match
-	match := models.NewMatch(config, teams)
-	match.Status = "generating"
-	match.StartTime = time.Now()
+// Generate creates a CS2 match log from the given configuration
+func (g *MatchGenerator) Generate(req *models.GenerateRequest) (*models.Match, error) {
+	match, config, err := g.prepareMatch(req)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create match engine and generate the match
-	engine := NewMatchEngine(&config, match)
-	if err := engine.GenerateMatch(); err != nil {
-		match.Status = "error"
-		match.Error = err.Error()
+	engine, err := g.newEngine(config, match)
+	if err != nil {
+		return nil, err
+	}
+	if err := runEngine(match, engine.GenerateMatch); err != nil {
 		return match, fmt.Errorf("match generation failed: %w", err)
 	}
 
 	return match, nil
 }
 
-// GenerateWithStreaming creates a CS2 match log with WebSocket streaming support
-func (g *MatchGenerator) GenerateWithStreaming(req *models.GenerateRequest, wsManager WebSocketManager) (*models.Match, error) {
+// NewEngine prepares a match for the request and returns an engine that plays it
+// round by round with HasNextRound and PlayNextRound
+func (g *MatchGenerator) NewEngine(req *models.GenerateRequest) (*MatchEngine, error) {
+	match, config, err := g.prepareMatch(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.newEngine(config, match)
+}
+
+// prepareMatch validates the request and creates the match with sides and user IDs assigned
+func (g *MatchGenerator) prepareMatch(req *models.GenerateRequest) (*models.Match, *models.MatchConfig, error) {
 	if req == nil {
-		return nil, fmt.Errorf("generate request cannot be nil")
+		return nil, nil, fmt.Errorf("generate request cannot be nil")
+	}
+
+	if req.Options.AutoRoster {
+		FillRosters(req)
 	}
 
 	// Validate request
 	if err := req.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid request: %w", err)
+		return nil, nil, fmt.Errorf("invalid request: %w", err)
 	}
 
 	// Create match configuration from request
-	config := models.DefaultMatchConfig()
-	config.Format = req.Format
-	config.Map = req.Map
+	config := BuildMatchConfig(req)
 	
-	// Apply options if provided
-	if req.Options.TickRate > 0 {
-		config.TickRate = req.Options.TickRate
+	return g.prepareMatchWithConfig(req, config)
+}
+
+// prepareMatchWithConfig creates the match for a validated request and its configuration.
+// An unseeded configuration gets a seed here, and the start time is resolved, so the
+// match can be regenerated later.
+func (g *MatchGenerator) prepareMatchWithConfig(req *models.GenerateRequest, config models.MatchConfig) (*models.Match, *models.MatchConfig, error) {
+	if config.Seed == 0 {
+		config.Seed = time.Now().UnixNano()
 	}
-	if req.Options.Seed > 0 {
-		config.Seed = req.Options.Seed
+	startTime := time.Now()
+	if config.StartTime != nil {
+		startTime = *config.StartTime
 	}
-	if req.Options.MaxRounds > 0 {
-		config.MaxRounds = req.Options.MaxRounds
-	}
-	config.Overtime = req.Options.Overtime
+	
+	// Keep the request as played, before sides are assigned to its teams
+	played := *req
+	played.Options.Seed = config.Seed
+	played.Options.StartTime = &startTime
+	played.Teams = cloneTeams(req.Teams)
 
 	// Prepare teams with proper side assignments
 	teams := make([]models.Team, len(req.Teams))
 	copy(teams, req.Teams)
 	
-	// Assign sides (first team CT, second team T)
-	teams[0].Side = "CT"
-	teams[1].Side = "TERRORIST"
+	// Keep requested sides, or flip a seeded coin for which team starts as CT
+	config.AutoAssignedSides = models.AssignTeamSides(teams, sideCoinFlip(config.Seed))
+	if err := models.ValidateAssignedSides(teams); err != nil {
+		return nil, nil, fmt.Errorf("invalid team sides: %w", err)
+	}
 	
-	// Update player sides and assign user IDs
+	// Update player teams and assign user IDs
 	for i := range teams {
 		for j := range teams[i].Players {
-			teams[i].Players[j].Side = teams[i].Side
 			teams[i].Players[j].Team = teams[i].Name
 			teams[i].Players[j].UserID = (i * 5) + j + 1 // Simple user ID assignment
 		}
@@ -144,29 +228,128 @@ func (g *MatchGenerator) GenerateWithStreaming(req *models.GenerateRequest, wsMa
 	// Create match
 	match := models.NewMatch(config, teams)
 	match.Status = "generating"
-	match.StartTime = time.Now()
+	match.StartTime = startTime
+	match.Request = &played
+
+	return match, &config, nil
+}
+
+// Regenerate replays a stored match's request with a changed configuration. The
+// original seed is kept, so settings that only affect output leave outcomes unchanged,
+// and so is the start time unless the configuration sets another.
+func (g *MatchGenerator) Regenerate(original *models.Match, config models.MatchConfig) (*models.Match, error) {
+	if original == nil || original.Request == nil {
+		return nil, fmt.Errorf("match has no generate request to replay")
+	}
+	
+	config.Seed = original.Config.Seed
+	if config.StartTime == nil {
+		config.StartTime = original.Request.Options.StartTime
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if err := models.ValidatePlayerNameLengths(original.Request.Teams, config.MaxNameLength, config.NamePolicy); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	
+	req := *original.Request
+	req.Teams = cloneTeams(original.Request.Teams)
+	match, prepared, err := g.prepareMatchWithConfig(&req, config)
+	if err != nil {
+		return nil, err
+	}
+	
+	engine, err := g.newEngine(prepared, match)
+	if err != nil {
+		return nil, err
+	}
+	if err := runEngine(match, engine.GenerateMatch); err != nil {
+		return match, fmt.Errorf("match generation failed: %w", err)
+	}
+	
+	return match, nil
+}
+
+// PlayNextRound plays an engine's next round for a streamed match. Errors and recovered
+// panics mark the match failed the same way Generate does.
+func (g *MatchGenerator) PlayNextRound(engine *MatchEngine) (*models.RoundData, []models.GameEvent, error) {
+	var roundData *models.RoundData
+	var roundEvents []models.GameEvent
+	err := runEngine(engine.Match(), func() error {
+		var err error
+		roundData, roundEvents, err = engine.PlayNextRound()
+		return err
+	})
+	return roundData, roundEvents, err
+}
+
+// runEngine runs an engine's generation and marks the match failed when it returns an
+// error. A panic in the simulator is logged with its stack trace and returned as an
+// ErrGenerationPanic error instead of crashing the request.
+func runEngine(match *models.Match, generate func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Recovered from panic generating match %s: %v\n%s", match.ID, r, debug.Stack())
+			err = fmt.Errorf("%w: %v", ErrGenerationPanic, r)
+		}
+		if err != nil {
+			match.Status = "error"
+			match.Error = err.Error()
+		}
+	}()
+	
+	return generate()
+}
+
+// cloneTeams copies teams along with their players, so changes to the copy's
+// players do not leak back
+func cloneTeams(teams []models.Team) []models.Team {
+	cloned := make([]models.Team, len(teams))
+	for i, team := range teams {
+		cloned[i] = team
+		cloned[i].Players = append([]models.Player(nil), team.Players...)
+	}
+	return cloned
+}
+
+// sideCoinFlip reports whether the first team starts as CT. It draws from its own
+// RNG so the flip never shifts the simulation's random sequence.
+func sideCoinFlip(seed int64) bool {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return rand.New(rand.NewSource(seed + sideSeedOffset)).Intn(2) == 0
+}
+
+// GenerateWithStreaming creates a CS2 match log with WebSocket streaming support
+func (g *MatchGenerator) GenerateWithStreaming(req *models.GenerateRequest, wsManager WebSocketManager) (*models.Match, error) {
+	match, config, err := g.prepareMatch(req)
+	if err != nil {
+		return nil, err
+	}
+	engine, err := g.newEngine(config, match)
+	if err != nil {
+		return nil, err
+	}
 
 	// Broadcast generation start event
 	if wsManager != nil {
 		startEvent := GenerationStartEvent{
 			MatchID:   match.ID,
-			Teams:     []string{teams[0].Name, teams[1].Name},
+			Teams:     []string{match.Teams[0].Name, match.Teams[1].Name},
 			Map:       config.Map,
 			Format:    config.Format,
-			MaxRounds: config.MaxRounds,
+			MaxRounds: config.GetMaxRounds(),
 			StartedAt: match.StartTime,
 		}
 		wsManager.BroadcastMatchEvent(match.ID, "generation_start", startEvent)
 	}
 
-	// Create match engine with streaming support and generate the match
-	engine := NewMatchEngine(&config, match)
+	// Generate the match with streaming support
 	engine.SetWebSocketManager(wsManager)
 	
-	if err := engine.GenerateMatchWithStreaming(); err != nil {
-		match.Status = "error"
-		match.Error = err.Error()
-		
+	if err := runEngine(match, engine.GenerateMatchWithStreaming); err != nil {
 		// Broadcast error event
 		if wsManager != nil {
 			errorEvent := GenerationErrorEvent{
@@ -175,6 +358,9 @@ func (g *MatchGenerator) GenerateWithStreaming(req *models.GenerateRequest, wsMa
 				Time:    time.Now(),
 			}
 			wsManager.BroadcastMatchEvent(match.ID, "generation_error", errorEvent)
+			if errors.Is(err, ErrGenerationPanic) {
+				wsManager.BroadcastMatchError(match.ID, err.Error())
+			}
 		}
 		
 		return match, fmt.Errorf("match generation failed: %w", err)
@@ -0,0 +1,161 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"strings"
+
+	"github.com/noueii/nocs-log-generator/backend/pkg/models"
+)
+
+// averageRating is the player rating of an average professional, the 1.0 of HLTV style ratings
+const averageRating = 1.0
+
+// ratingSkillScale converts a rating's distance from average into profile skill,
+// so a 1.3 rated star aims at about 0.9 and a 0.8 rated player at about 0.25
+const ratingSkillScale = 1.3
+
+// RankingDataset is an external team ranking with player stats, such as an export
+// of a ranking site, used to build realistic rosters
+type RankingDataset struct {
+	Teams []RankedTeam `json:"teams"`
+}
+
+// RankedTeam is a ranked team and its lineup
+type RankedTeam struct {
+	Name    string         `json:"name"`
+	Tag     string         `json:"tag,omitempty"`
+	Country string         `json:"country,omitempty"`
+	Rank    int            `json:"rank,omitempty"`
+	Players []RankedPlayer `json:"players"`
+}
+
+// RankedPlayer is a player's stats from a ranking dataset. Only the name and
+// rating are required.
+type RankedPlayer struct {
+	Name         string  `json:"name"`
+	SteamID      string  `json:"steam_id,omitempty"`
+	Role         string  `json:"role,omitempty"`
+	Rating       float64 `json:"rating"`                  // 1.0 is an average professional
+	HeadshotRate float64 `json:"headshot_rate,omitempty"` // Share of kills that are headshots
+	OpeningRate  float64 `json:"opening_rate,omitempty"`  // Share of rounds the player takes the opening duel
+}
+
+// LoadRankingDataset reads a ranking dataset from JSON
+func LoadRankingDataset(r io.Reader) (*RankingDataset, error) {
+	var dataset RankingDataset
+	if err := json.NewDecoder(r).Decode(&dataset); err != nil {
+		return nil, fmt.Errorf("failed to decode ranking dataset: %w", err)
+	}
+
+	for _, team := range dataset.Teams {
+		if strings.TrimSpace(team.Name) == "" {
+			return nil, fmt.Errorf("ranking dataset has a team without a name")
+		}
+		for _, player := range team.Players {
+			if strings.TrimSpace(player.Name) == "" {
+				return nil, fmt.Errorf("team %s has a player without a name", team.Name)
+			}
+			if player.Rating <= 0 {
+				return nil, fmt.Errorf("player %s of %s has an invalid rating %.2f", player.Name, team.Name, player.Rating)
+			}
+		}
+	}
+
+	return &dataset, nil
+}
+
+// Team returns the dataset's team with the given name, ignoring case
+func (d *RankingDataset) Team(name string) *RankedTeam {
+	if d == nil {
+		return nil
+	}
+	for i := range d.Teams {
+		if strings.EqualFold(d.Teams[i].Name, name) {
+			return &d.Teams[i]
+		}
+	}
+	return nil
+}
+
+// TeamsFromRanking builds the two teams of a matchup. Players found in the dataset
+// get profiles derived from their stats, with higher rated players getting higher
+// skills, and lineups are topped up to five with generated players. The dataset
+// is optional: teams missing from it get a generated roster.
+func TeamsFromRanking(team1, team2 string, dataset *RankingDataset, seed int64) []models.Team {
+	rng := rand.New(rand.NewSource(seed))
+	usedNames := make(map[string]bool)
+	usedSteamIDs := make(map[string]bool)
+
+	teams := make([]models.Team, 0, 2)
+	for _, name := range []string{team1, team2} {
+		team := models.Team{Name: name}
+		if ranked := dataset.Team(name); ranked != nil {
+			team.Name, team.Tag, team.Country, team.Ranking = ranked.Name, ranked.Tag, ranked.Country, ranked.Rank
+			for i, rankedPlayer := range ranked.Players {
+				if i == 5 {
+					break
+				}
+				player := rankedPlayerToPlayer(rankedPlayer, team.Name, i, rng, usedSteamIDs)
+				usedNames[strings.ToLower(player.Name)] = true
+				team.Players = append(team.Players, player)
+			}
+		}
+		if missing := 5 - len(team.Players); missing > 0 {
+			team.Players = append(team.Players, generateRoster(team.Name, missing, rng, usedNames, usedSteamIDs)...)
+		}
+		teams = append(teams, team)
+	}
+
+	return teams
+}
+
+// rankedPlayerToPlayer creates a player with a profile derived from their stats. The
+// role shapes the profile like a generated one, then every skill is shifted by how
+// far the rating is from average and the aim skill follows the rating directly.
+func rankedPlayerToPlayer(ranked RankedPlayer, teamName string, index int, rng *rand.Rand, usedSteamIDs map[string]bool) models.Player {
+	steamID := ranked.SteamID
+	for steamID == "" || usedSteamIDs[steamID] {
+		steamID = generateSteamID(rng)
+	}
+	usedSteamIDs[steamID] = true
+
+	player := models.NewPlayer(ranked.Name, steamID)
+	player.Team = teamName
+	player.Role = ranked.Role
+	if player.Role == "" {
+		player.Role = rosterRoles[index%len(rosterRoles)]
+	}
+
+	skill := clampSkill(0.5 + (ranked.Rating-averageRating)*ratingSkillScale)
+	shift := skill - 0.55 // Generated skills center on 0.55
+	profile := generatePlayerProfile(rng, player.Role)
+	for _, value := range []*float64{
+		&profile.ReflexSpeed, &profile.GameSense, &profile.Positioning, &profile.RifleSkill,
+		&profile.AWPSkill, &profile.PistolSkill, &profile.EntryFragging, &profile.ClutchFactor,
+		&profile.ConsistencyFactor,
+	} {
+		*value = clampSkill(*value + shift)
+	}
+	profile.AimSkill = skill
+	if ranked.HeadshotRate > 0 {
+		// Headshot-heavy players aim a little better than their rating alone suggests
+		profile.AimSkill = clampSkill(skill + (ranked.HeadshotRate-0.5)*0.2)
+	}
+	if ranked.OpeningRate > 0 {
+		// An average player takes about a fifth of the opening duels
+		profile.Aggression = clampSkill(0.5 + (ranked.OpeningRate-0.2)*2)
+		profile.EntryFragging = clampSkill(profile.EntryFragging + (ranked.OpeningRate-0.2)*2)
+	}
+	player.Profile = profile
+
+	return *player
+}
+
+// clampSkill keeps a profile value within 0.05-0.95
+func clampSkill(value float64) float64 {
+	return math.Max(0.05, math.Min(0.95, value))
+}
@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"math/rand"
+
+	"github.com/noueii/nocs-log-generator/backend/pkg/models"
+)
+
+// snapshotSource is a rand.Source that can report and jump back to its position in
+// the random stream. math/rand doesn't expose a source's internal state, so the
+// position is kept as the last seed and the number of values drawn since, and
+// restoring replays the draws from the seed.
+type snapshotSource struct {
+	src   rand.Source64
+	state models.RNGState
+}
+
+// newSnapshotSource creates a snapshot source seeded with the given seed
+func newSnapshotSource(seed int64) *snapshotSource {
+	return &snapshotSource{
+		src:   rand.NewSource(seed).(rand.Source64),
+		state: models.RNGState{Seed: seed},
+	}
+}
+
+// Int63 returns a non-negative pseudo-random 63-bit integer
+func (s *snapshotSource) Int63() int64 {
+	s.state.Draws++
+	return s.src.Int63()
+}
+
+// Uint64 returns a pseudo-random 64-bit integer
+func (s *snapshotSource) Uint64() uint64 {
+	s.state.Draws++
+	return s.src.Uint64()
+}
+
+// Seed restarts the stream from the given seed
+func (s *snapshotSource) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.state = models.RNGState{Seed: seed}
+}
+
+// State returns the current position in the stream
+func (s *snapshotSource) State() models.RNGState {
+	return s.state
+}
+
+// Restore moves the stream to a position returned by State
+func (s *snapshotSource) Restore(state models.RNGState) {
+	s.src.Seed(state.Seed)
+	for i := uint64(0); i < state.Draws; i++ {
+		s.src.Uint64()
+	}
+	s.state = state
+}
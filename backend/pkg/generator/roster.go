@@ -0,0 +1,136 @@
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/noueii/nocs-log-generator/backend/pkg/models"
+)
+
+// Name fragments combined into generated player names
+var (
+	rosterNamePrefixes = []string{"zy", "kr", "ne", "va", "bl", "fo", "sh", "ra", "mi", "to", "el", "qu", "dr", "ix", "jo", "st"}
+	rosterNameCores    = []string{"ro", "xi", "ma", "ley", "tan", "vo", "ke", "lin", "zo", "ra", "nu", "pe", "dex", "gar", "sol", "ti"}
+	rosterNameSuffixes = []string{"", "x", "y", "z", "o", "n", "k", "1", "7", "99"}
+
+	// rosterRoles is the role order for a standard five player lineup
+	rosterRoles = []string{"igl", "awp", "entry", "support", "lurker"}
+)
+
+// GenerateRoster creates a seeded roster of players with randomized names, roles and profiles
+func GenerateRoster(teamName string, size int, seed int64) []models.Player {
+	return generateRoster(teamName, size, rand.New(rand.NewSource(seed)), make(map[string]bool), make(map[string]bool))
+}
+
+// FillRosters adds generated players to any team with fewer than five players.
+// Generated names and SteamIDs never collide with players already in the request.
+func FillRosters(req *models.GenerateRequest) {
+	seed := req.Options.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	usedNames := make(map[string]bool)
+	usedSteamIDs := make(map[string]bool)
+	for _, team := range req.Teams {
+		for _, player := range team.Players {
+			usedNames[strings.ToLower(strings.TrimSpace(player.Name))] = true
+			usedSteamIDs[player.SteamID] = true
+		}
+	}
+
+	for i := range req.Teams {
+		missing := 5 - len(req.Teams[i].Players)
+		if missing <= 0 {
+			continue
+		}
+		generated := generateRoster(req.Teams[i].Name, missing, rng, usedNames, usedSteamIDs)
+		req.Teams[i].Players = append(req.Teams[i].Players, generated...)
+	}
+}
+
+// generateRoster creates players while skipping names and SteamIDs that are already used
+func generateRoster(teamName string, size int, rng *rand.Rand, usedNames, usedSteamIDs map[string]bool) []models.Player {
+	players := make([]models.Player, 0, size)
+	roleOffset := rng.Intn(len(rosterRoles))
+
+	for i := 0; i < size; i++ {
+		name := generatePlayerName(rng)
+		for usedNames[strings.ToLower(name)] {
+			name = generatePlayerName(rng)
+		}
+		usedNames[strings.ToLower(name)] = true
+
+		steamID := generateSteamID(rng)
+		for usedSteamIDs[steamID] {
+			steamID = generateSteamID(rng)
+		}
+		usedSteamIDs[steamID] = true
+
+		player := models.NewPlayer(name, steamID)
+		player.Team = teamName
+		player.Role = rosterRoles[(roleOffset+i)%len(rosterRoles)]
+		player.Profile = generatePlayerProfile(rng, player.Role)
+		players = append(players, *player)
+	}
+
+	return players
+}
+
+// generatePlayerName builds a handle from random name fragments
+func generatePlayerName(rng *rand.Rand) string {
+	return rosterNamePrefixes[rng.Intn(len(rosterNamePrefixes))] +
+		rosterNameCores[rng.Intn(len(rosterNameCores))] +
+		rosterNameSuffixes[rng.Intn(len(rosterNameSuffixes))]
+}
+
+// generateSteamID creates a legacy format SteamID
+func generateSteamID(rng *rand.Rand) string {
+	return fmt.Sprintf("STEAM_1:%d:%d", rng.Intn(2), 10000000+rng.Intn(90000000))
+}
+
+// generatePlayerProfile randomizes a profile around average skill with a bias for the role
+func generatePlayerProfile(rng *rand.Rand, role string) models.PlayerProfile {
+	skill := func() float64 {
+		return 0.3 + rng.Float64()*0.5 // 0.3-0.8
+	}
+
+	profile := models.PlayerProfile{
+		AimSkill:          skill(),
+		ReflexSpeed:       skill(),
+		GameSense:         skill(),
+		Positioning:       skill(),
+		Teamwork:          skill(),
+		UtilityUsage:      skill(),
+		Aggression:        skill(),
+		EconomyDiscipline: skill(),
+		ClutchFactor:      skill(),
+		RifleSkill:        skill(),
+		AWPSkill:          skill() * 0.6,
+		PistolSkill:       skill(),
+		EntryFragging:     skill(),
+		SupportPlay:       skill(),
+		IGLSkill:          skill() * 0.6,
+		ConsistencyFactor: skill(),
+	}
+
+	switch role {
+	case "awp":
+		profile.AWPSkill = 0.7 + rng.Float64()*0.3
+	case "entry":
+		profile.EntryFragging = 0.7 + rng.Float64()*0.3
+		profile.Aggression = 0.7 + rng.Float64()*0.3
+	case "support":
+		profile.SupportPlay = 0.7 + rng.Float64()*0.3
+		profile.UtilityUsage = 0.7 + rng.Float64()*0.3
+	case "igl":
+		profile.IGLSkill = 0.7 + rng.Float64()*0.3
+	case "lurker":
+		profile.Positioning = 0.7 + rng.Float64()*0.3
+	}
+
+	return profile
+}
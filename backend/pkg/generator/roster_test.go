@@ -0,0 +1,124 @@
+package generator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/noueii/nocs-log-generator/backend/pkg/models"
+)
+
+func TestGenerateRoster_Deterministic(t *testing.T) {
+	first := GenerateRoster("Team1", 5, 42)
+	second := GenerateRoster("Team1", 5, 42)
+
+	if !reflect.DeepEqual(first, second) {
+		t.Fatal("Expected identical rosters for the same seed")
+	}
+
+	other := GenerateRoster("Team1", 5, 43)
+	if reflect.DeepEqual(first, other) {
+		t.Error("Expected different rosters for different seeds")
+	}
+
+	names := make(map[string]bool)
+	steamIDs := make(map[string]bool)
+	for _, player := range first {
+		if err := player.Validate(); err != nil {
+			t.Errorf("Generated player %s is invalid: %v", player.Name, err)
+		}
+		if names[player.Name] || steamIDs[player.SteamID] {
+			t.Errorf("Duplicate generated player %s (%s)", player.Name, player.SteamID)
+		}
+		names[player.Name] = true
+		steamIDs[player.SteamID] = true
+	}
+}
+
+func TestFillRosters(t *testing.T) {
+	req := &models.GenerateRequest{
+		Map:    "de_mirage",
+		Format: "mr12",
+		Teams: []models.Team{
+			{Name: "Team1", Players: []models.Player{{Name: "captain", SteamID: "STEAM_1:0:1"}}},
+			{Name: "Team2"},
+		},
+		Options: models.MatchOptions{Seed: 7, AutoRoster: true},
+	}
+
+	FillRosters(req)
+
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Filled request is invalid: %v", err)
+	}
+	if req.Teams[0].Players[0].Name != "captain" {
+		t.Errorf("Expected existing player to be kept, got %s", req.Teams[0].Players[0].Name)
+	}
+
+	steamIDs := make(map[string]bool)
+	for _, team := range req.Teams {
+		for _, player := range team.Players {
+			if steamIDs[player.SteamID] {
+				t.Errorf("Duplicate SteamID %s across teams", player.SteamID)
+			}
+			steamIDs[player.SteamID] = true
+		}
+	}
+}
+
+func TestTeamsFromRanking(t *testing.T) {
+	dataset, err := LoadRankingDataset(strings.NewReader(`{"teams": [
+		{"name": "Stars", "rank": 1, "players": [
+			{"name": "ace", "role": "awp", "rating": 1.32},
+			{"name": "blaze", "rating": 1.24, "headshot_rate": 0.6},
+			{"name": "core", "rating": 1.18, "opening_rate": 0.3},
+			{"name": "dash", "rating": 1.15},
+			{"name": "echo", "rating": 1.21}
+		]},
+		{"name": "Rookies", "rank": 40, "players": [
+			{"name": "fern", "rating": 0.84},
+			{"name": "gale", "rating": 0.9},
+			{"name": "hush", "rating": 0.88}
+		]}
+	]}`))
+	if err != nil {
+		t.Fatalf("LoadRankingDataset failed: %v", err)
+	}
+
+	teams := TeamsFromRanking("stars", "Rookies", dataset, 7)
+	averageAim := func(team models.Team) float64 {
+		total := 0.0
+		for _, player := range team.Players {
+			total += player.Profile.AimSkill
+		}
+		return total / float64(len(team.Players))
+	}
+
+	if teams[0].Name != "Stars" || teams[0].Ranking != 1 {
+		t.Errorf("Expected the dataset's team, got %s ranked %d", teams[0].Name, teams[0].Ranking)
+	}
+	if len(teams[1].Players) != 5 || teams[1].Players[0].Name != "fern" {
+		t.Errorf("Expected the short lineup to be topped up behind its own players, got %d players", len(teams[1].Players))
+	}
+	if stars, rookies := averageAim(teams[0]), averageAim(teams[1]); stars <= rookies {
+		t.Errorf("Expected the higher rated roster to have more aim skill, got %.2f vs %.2f", stars, rookies)
+	}
+	if teams[0].Players[0].Role != "awp" || teams[0].Players[0].Profile.AimSkill <= teams[0].Players[3].Profile.AimSkill {
+		t.Errorf("Expected the 1.32 rated AWPer to out-aim a 1.15 teammate")
+	}
+	for _, team := range teams {
+		if err := team.Validate(); err != nil {
+			t.Errorf("Team %s is invalid: %v", team.Name, err)
+		}
+	}
+
+	// Without a dataset both teams get generated rosters
+	generated := TeamsFromRanking("Alpha", "Bravo", nil, 7)
+	if len(generated[0].Players) != 5 || len(generated[1].Players) != 5 {
+		t.Errorf("Expected generated rosters without a dataset")
+	}
+
+	if _, err := LoadRankingDataset(strings.NewReader(`{"teams": [{"name": "X", "players": [{"name": "y", "rating": 0}]}]}`)); err == nil {
+		t.Error("Expected a player without a rating to be rejected")
+	}
+}
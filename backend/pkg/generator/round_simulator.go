@@ -9,35 +9,118 @@ import (
 	"github.com/noueii/nocs-log-generator/backend/pkg/models"
 )
 
+// lateBuyProbability is the chance that a player leaves spawn before finishing their buy
+const lateBuyProbability = 0.05
+
+// bodyHitgroups are the hitgroups a fatal non-headshot can land in, weighted towards the chest
+var bodyHitgroups = []int{2, 2, 2, 3, 3, 4, 5, 6, 7}
+
+// noScopeWeapons are the scoped rifles whose kills can be no-scopes
+var noScopeWeapons = map[string]bool{"awp": true, "ssg08": true}
+
+// lastDuelTradeProbability is the chance that the last two players standing trade each other
+const lastDuelTradeProbability = 0.15
+
+// tradeWindow is how long a dying player can still fire back
+const tradeWindow = 500 * time.Millisecond
+
+// minDuelWeight keeps the most passive players in the duels at full aggression effect
+const minDuelWeight = 0.05
+
+// duelAdvantageWeight is how far each point of economy or skill advantage tips a duel
+const duelAdvantageWeight = 0.2
+
 // RoundSimulator handles individual round simulation
 type RoundSimulator struct {
 	rng            *rand.Rand
 	economyManager *models.EconomyManager
 	config         *models.MatchConfig
+	serverConfig   *models.ServerConfig
+	simConfig      *models.SimulationConfig
+	skins          *SkinAssigner // nil unless weapon skins are enabled
+	details        *EventGenerator // Fills kills in with the hits and shots behind them, nil to log kills only
+	roundWinner    string        // Side the next round is steered towards, empty to leave it open
+	itemEvents     []models.GameEvent // Bomb drops and weapon pickups of the current round, logged after its kills
+	droppedBomb    *models.Vector3    // Where the bomb lies while nobody carries it
 }
 
 // NewRoundSimulator creates a new round simulator
 func NewRoundSimulator(rng *rand.Rand, economyManager *models.EconomyManager, config *models.MatchConfig) *RoundSimulator {
-	return &RoundSimulator{
+	serverConfig := models.DefaultServerConfig()
+	simConfig := models.DefaultSimulationConfig()
+	
+	rs := &RoundSimulator{
 		rng:            rng,
 		economyManager: economyManager,
 		config:         config,
+		serverConfig:   &serverConfig,
+		simConfig:      &simConfig,
+	}
+	if config.WeaponSkins {
+		rs.skins = NewSkinAssigner(config.Seed)
+	}
+	return rs
+}
+
+// SetServerConfig sets the server settings used for bomb and defuse timers
+func (rs *RoundSimulator) SetServerConfig(serverConfig *models.ServerConfig) {
+	if serverConfig != nil {
+		rs.serverConfig = serverConfig
 	}
 }
 
+// SetSimulationConfig sets the probabilities used for kill modifiers
+func (rs *RoundSimulator) SetSimulationConfig(simConfig *models.SimulationConfig) {
+	if simConfig != nil {
+		rs.simConfig = simConfig
+	}
+}
+
+// SetEventGenerator sets the event generator that fills every round's kills in with
+// the hits and shots leading up to them. Fast mode rounds are left without them.
+func (rs *RoundSimulator) SetEventGenerator(eventGenerator *EventGenerator) {
+	rs.details = eventGenerator
+}
+
+// SetRoundWinner steers the following rounds towards a win for the given side.
+// An empty side leaves round outcomes to the simulation.
+func (rs *RoundSimulator) SetRoundWinner(side string) {
+	rs.roundWinner = models.NormalizeSide(side)
+}
+
 // SimulateRound executes the full round simulation including buy phase and combat
 func (rs *RoundSimulator) SimulateRound(match *models.Match, state *models.MatchState, roundNum int) (*RoundResult, []models.GameEvent, error) {
 	events := make([]models.GameEvent, 0, 100) // Pre-allocate for ~100 events per round
 	
+	for _, team := range match.Teams {
+		if len(team.Players) == 0 {
+			return nil, nil, fmt.Errorf("team %s has no players", team.Name)
+		}
+	}
+	if err := models.ValidateAssignedSides(match.Teams); err != nil {
+		return nil, nil, err
+	}
+	
 	// Execute buy phase
 	buyEvents, err := rs.simulateBuyPhase(match, state, roundNum)
 	if err != nil {
 		return nil, nil, fmt.Errorf("buy phase simulation failed: %w", err)
 	}
 	events = append(events, buyEvents...)
+	
+	if rs.skins != nil {
+		rs.skins.AssignSkins(match, state)
+	}
 
-	// Reset player states for the round
+	// Reset player states for the round and hand out the bomb
 	rs.resetPlayerStatesForRound(match, state)
+	if pickup := rs.giveBomb(match, state, roundNum); pickup != nil {
+		events = append(events, pickup)
+	}
+	var detail *roundDetail
+	if rs.details != nil && !rs.config.FastMode {
+		detail = rs.details.beginRound(match, state)
+	}
 
 	// Determine round strategy and flow
 	roundStrategy := rs.determineRoundStrategy(match, state)
@@ -60,15 +143,111 @@ func (rs *RoundSimulator) SimulateRound(match *models.Match, state *models.Match
 	if err != nil {
 		return nil, nil, fmt.Errorf("round simulation failed: %w", err)
 	}
+	rs.stretchShortRound(result, combatEvents)
+	if detail != nil {
+		combatEvents = rs.details.detailRound(detail, state, combatEvents, rs.itemEvents, result, roundStrategy, roundNum)
+	}
 	
 	events = append(events, combatEvents...)
-	
+	events = append(events, rs.itemEvents...)
+	events = append(events, rs.recordFirstBlood(combatEvents, roundNum)...)
+	events = append(events, rs.recordKillStreaks(combatEvents, roundNum)...)
+	if rs.config.EmitPositions && !rs.config.FastMode {
+		events = append(events, rs.samplePositions(match, combatEvents, result, roundNum)...)
+	}
+
 	// Select MVP
 	result.MVP = rs.selectMVP(match, result.Winner, events)
 
 	return result, events, nil
 }
 
+// stretchShortRound spreads the kills of an elimination round that ended before the
+// minimum round duration evenly across it, so high intensity rounds are not over in seconds
+func (rs *RoundSimulator) stretchShortRound(result *RoundResult, combatEvents []models.GameEvent) {
+	minTicks := int64(rs.config.MinRoundDuration * rs.config.TickRate)
+	endTick := models.DurationToTicks(result.Duration, rs.config.TickRate)
+	if result.Reason != "elimination" || endTick >= minTicks {
+		return
+	}
+	
+	if endTick > 0 {
+		for _, events := range [][]models.GameEvent{combatEvents, rs.itemEvents} {
+			for _, event := range events {
+				event.SetTick(event.GetTick() * minTicks / endTick)
+			}
+		}
+	}
+	result.Duration = models.TicksToDuration(minTicks, rs.config.TickRate)
+}
+
+// recordFirstBlood marks the round's first kill of an enemy as first blood, credits the
+// first kill and death, and returns a Server say notice for it when enabled
+func (rs *RoundSimulator) recordFirstBlood(events []models.GameEvent, roundNum int) []models.GameEvent {
+	for _, event := range events {
+		kill, ok := event.(*models.KillEvent)
+		if !ok || kill.Attacker == nil || kill.Victim == nil {
+			continue
+		}
+		if models.NormalizeSide(kill.Attacker.Side) == models.NormalizeSide(kill.Victim.Side) {
+			continue // Team kills are not first blood
+		}
+		
+		kill.FirstBlood = true
+		kill.Attacker.Stats.FirstKills++
+		kill.Victim.Stats.FirstDeaths++
+		
+		if !rs.config.FirstBlood || rs.config.FastMode {
+			return nil
+		}
+		return []models.GameEvent{&models.ObserverChatEvent{
+			BaseEvent: models.NewBaseEvent("observer_chat", kill.Tick, roundNum),
+			Source:    models.ObserverSourceServer,
+			Message:   fmt.Sprintf("First blood: %s killed %s with %s", kill.Attacker.Name, kill.Victim.Name, kill.Weapon),
+		}}
+	}
+	return nil
+}
+
+// killStreakHighlight is the kill streak from which streaks are announced
+const killStreakHighlight = 3
+
+// recordKillStreaks tracks each player's kills without dying in between over a round's
+// kills, updates their longest streak and returns a Server say highlight for every
+// streak of killStreakHighlight or more when kill streaks are enabled. Streaks end with
+// the player's death or the round.
+func (rs *RoundSimulator) recordKillStreaks(events []models.GameEvent, roundNum int) []models.GameEvent {
+	var highlights []models.GameEvent
+	streaks := make(map[string]int)
+	
+	for _, event := range events {
+		kill, ok := event.(*models.KillEvent)
+		if !ok || kill.Attacker == nil || kill.Victim == nil {
+			continue
+		}
+		delete(streaks, kill.Victim.Name)
+		if models.NormalizeSide(kill.Attacker.Side) == models.NormalizeSide(kill.Victim.Side) {
+			continue // Team kills do not extend a streak
+		}
+		
+		streaks[kill.Attacker.Name]++
+		streak := streaks[kill.Attacker.Name]
+		if streak > kill.Attacker.Stats.LongestKillStreak {
+			kill.Attacker.Stats.LongestKillStreak = streak
+		}
+		
+		if streak >= killStreakHighlight && rs.config.KillStreaks && !rs.config.FastMode {
+			highlights = append(highlights, &models.ObserverChatEvent{
+				BaseEvent: models.NewBaseEvent("observer_chat", kill.Tick, roundNum),
+				Source:    models.ObserverSourceServer,
+				Message:   fmt.Sprintf("%s is on a %d kill streak", kill.Attacker.Name, streak),
+			})
+		}
+	}
+	
+	return highlights
+}
+
 // RoundStrategy defines how the round should play out
 type RoundStrategy struct {
 	Type           string  // "bomb_scenario", "elimination", "timeout"
@@ -79,31 +258,11 @@ type RoundStrategy struct {
 
 // determineRoundStrategy analyzes the match state and determines round flow
 func (rs *RoundSimulator) determineRoundStrategy(match *models.Match, state *models.MatchState) *RoundStrategy {
-	ctTeam := rs.getTeamBySide(match, "CT")
-	tTeam := rs.getTeamBySide(match, "TERRORIST")
-	
 	// Calculate team advantages based on economy and skill
-	ctEconomy := state.TeamEconomies[ctTeam.Name]
-	tEconomy := state.TeamEconomies[tTeam.Name]
-	
-	economyAdvantage := float64(ctEconomy.AverageMoney-tEconomy.AverageMoney) / 5000.0
-	if economyAdvantage > 1.0 {
-		economyAdvantage = 1.0
-	} else if economyAdvantage < -1.0 {
-		economyAdvantage = -1.0
-	}
+	economyAdvantage := rs.calculateEconomyAdvantage(match, state)
 
 	// Determine round type probabilities
-	bombProb := 0.4
-	eliminationProb := 0.5
-	timeoutProb := 0.1
-	
-	// Adjust probabilities based on round number and score
-	if state.CurrentRound > 15 { // Second half
-		bombProb += 0.1 // More tactical play
-		timeoutProb += 0.05
-		eliminationProb -= 0.15
-	}
+	bombProb, eliminationProb, _ := rs.roundTypeProbabilities(state.CurrentRound, match.HalftimeRound())
 	
 	// Select round type
 	randValue := rs.rng.Float64()
@@ -115,6 +274,9 @@ func (rs *RoundSimulator) determineRoundStrategy(match *models.Match, state *mod
 	} else {
 		roundType = "timeout"
 	}
+	if roundType == "timeout" && rs.roundWinner == "TERRORIST" {
+		roundType = "elimination" // Terrorists cannot win on time
+	}
 
 	// Calculate intensity based on economy differential
 	intensity := 0.5 + math.Abs(economyAdvantage)*0.3
@@ -132,6 +294,130 @@ func (rs *RoundSimulator) determineRoundStrategy(match *models.Match, state *mod
 	}
 }
 
+// ctDuelProbability returns the chance that the CT side wins a duel, tipped by its
+// economy and aim skill advantage over the terrorists
+func (rs *RoundSimulator) ctDuelProbability(match *models.Match, state *models.MatchState) float64 {
+	advantage := rs.calculateEconomyAdvantage(match, state) + rs.calculateSkillAdvantage(match)
+	return math.Max(0.1, math.Min(0.9, 0.5+advantage*duelAdvantageWeight))
+}
+
+// calculateEconomyAdvantage returns the CT economy advantage in the range -1.0 to 1.0,
+// from the money and equipment per player, which buying doesn't change
+func (rs *RoundSimulator) calculateEconomyAdvantage(match *models.Match, state *models.MatchState) float64 {
+	ctTeam := rs.getTeamBySide(match, "CT")
+	tTeam := rs.getTeamBySide(match, "TERRORIST")
+	if ctTeam == nil || tTeam == nil {
+		return 0
+	}
+	
+	ctEconomy := state.TeamEconomies[ctTeam.Name]
+	tEconomy := state.TeamEconomies[tTeam.Name]
+	if ctEconomy == nil || tEconomy == nil {
+		return 0
+	}
+	
+	wealth := func(team *models.Team, economy *models.TeamEconomy) float64 {
+		if len(team.Players) == 0 {
+			return float64(economy.AverageMoney)
+		}
+		return float64(economy.AverageMoney) + float64(economy.EquipmentValue)/float64(len(team.Players))
+	}
+	economyAdvantage := (wealth(ctTeam, ctEconomy) - wealth(tTeam, tEconomy)) / 5000.0
+	if economyAdvantage > 1.0 {
+		economyAdvantage = 1.0
+	} else if economyAdvantage < -1.0 {
+		economyAdvantage = -1.0
+	}
+	return economyAdvantage
+}
+
+// calculateSkillAdvantage returns the CT aim skill advantage in the range -1.0 to 1.0
+func (rs *RoundSimulator) calculateSkillAdvantage(match *models.Match) float64 {
+	averageSkill := func(team *models.Team) float64 {
+		if team == nil || len(team.Players) == 0 {
+			return 0
+		}
+		total := 0.0
+		for _, player := range team.Players {
+			total += player.Profile.AimSkill
+		}
+		return total / float64(len(team.Players))
+	}
+	
+	return averageSkill(rs.getTeamBySide(match, "CT")) - averageSkill(rs.getTeamBySide(match, "TERRORIST"))
+}
+
+// roundTypeProbabilities returns the bomb, elimination and timeout probabilities for a round
+func (rs *RoundSimulator) roundTypeProbabilities(roundNum, halftimeRound int) (float64, float64, float64) {
+	bombProb := 0.4
+	eliminationProb := 0.5
+	timeoutProb := 0.1
+	
+	// Adjust probabilities based on round number and score
+	if roundNum > halftimeRound { // Second half
+		bombProb += 0.1 // More tactical play
+		timeoutProb += 0.05
+		eliminationProb -= 0.15
+	}
+	
+	return bombProb, eliminationProb, timeoutProb
+}
+
+// PredictRoundOutcome estimates the CT round-win probability without generating events,
+// following how rounds are simulated: a timeout round is a CT win on time, and every
+// other round goes to the side that wins enough duels to eliminate the other, even
+// around the bomb, each duel won with ctDuelProbability
+func (rs *RoundSimulator) PredictRoundOutcome(match *models.Match, state *models.MatchState) (float64, map[string]float64) {
+	economyAdvantage := rs.calculateEconomyAdvantage(match, state)
+	skillAdvantage := rs.calculateSkillAdvantage(match)
+	bombProb, eliminationProb, timeoutProb := rs.roundTypeProbabilities(state.CurrentRound, match.HalftimeRound())
+	duelProb := rs.ctDuelProbability(match, state)
+	
+	ctPlayers, tPlayers := 0, 0
+	if team := rs.getTeamBySide(match, "CT"); team != nil {
+		ctPlayers = len(team.Players)
+	}
+	if team := rs.getTeamBySide(match, "TERRORIST"); team != nil {
+		tPlayers = len(team.Players)
+	}
+	contestedProb := eliminationProbability(duelProb, ctPlayers, tPlayers)
+	ctWinProb := timeoutProb + (1.0-timeoutProb)*contestedProb
+	
+	details := map[string]float64{
+		"economy_advantage":       economyAdvantage,
+		"skill_advantage":         skillAdvantage,
+		"duel_probability":        duelProb,
+		"intensity":               math.Min(1.0, 0.5+math.Abs(economyAdvantage)*0.3),
+		"bomb_probability":        bombProb,
+		"elimination_probability": eliminationProb,
+		"timeout_probability":     timeoutProb,
+		"ct_win_probability":      ctWinProb,
+		"t_win_probability":       1.0 - ctWinProb,
+	}
+	
+	return ctWinProb, details
+}
+
+// eliminationProbability returns the chance that a side of own players, winning each
+// duel with probability p, takes out all of its opponents before losing everyone
+func eliminationProbability(p float64, own, opponents int) float64 {
+	if own == 0 {
+		return 0
+	}
+	
+	// The last opponent goes down after the side has lost between 0 and own-1 players,
+	// in any order of the duels before that last one
+	total := 0.0
+	orderings := 1.0
+	for losses := 0; losses < own; losses++ {
+		if losses > 0 {
+			orderings *= float64(opponents-1+losses) / float64(losses)
+		}
+		total += orderings * math.Pow(p, float64(opponents)) * math.Pow(1-p, float64(losses))
+	}
+	return total
+}
+
 // simulateBuyPhase handles equipment purchasing for all players
 func (rs *RoundSimulator) simulateBuyPhase(match *models.Match, state *models.MatchState, roundNum int) ([]models.GameEvent, error) {
 	var events []models.GameEvent
@@ -139,33 +425,43 @@ func (rs *RoundSimulator) simulateBuyPhase(match *models.Match, state *models.Ma
 	for _, team := range match.Teams {
 		teamEconomy := state.TeamEconomies[team.Name]
 		
-		// Determine team buy strategy
-		buyType := rs.determineBuyStrategy(teamEconomy, roundNum)
+		// The team's buy type was decided from its money before the engine's buys
+		buyType := teamEconomy.BuyType
+		if buyType == "" {
+			buyType = rs.determineBuyStrategy(match, teamEconomy, rs.opponentEconomy(match, state, team.Name), roundNum)
+			teamEconomy.BuyType = buyType
+		}
 		
 		for i, player := range team.Players {
 			playerState := state.PlayerStates[player.Name]
+			buyer := &match.Teams[rs.getTeamIndex(match, team.Name)].Players[i]
 			
 			// Get optimal buy for this player
 			playerBuy := rs.economyManager.GetOptimalBuy(&player, teamEconomy, buyType)
 			
+			// Some players leave spawn before finishing their buy and only try
+			// the rest of it once the buy time is over
+			leftSpawnAt := len(playerBuy)
+			if rs.rng.Float64() < lateBuyProbability {
+				leftSpawnAt = rs.rng.Intn(len(playerBuy) + 1)
+			}
+			
 			// Process purchases
-			for _, item := range playerBuy {
+			for j, item := range playerBuy {
+				if match.IsPistolRound(roundNum) && !rs.allowedOnPistolRound(item) {
+					continue
+				}
 				cost := rs.getItemCost(item)
-				if playerState.Money >= cost {
-					// Execute purchase
-					playerState.Money -= cost
-					
-					// Apply item to player state
-					rs.applyPurchaseToPlayer(playerState, item)
-					
-					// Create purchase event
-					purchaseEvent := &models.ItemPurchaseEvent{
-						BaseEvent: models.NewBaseEvent("item_purchase", 0, roundNum),
-						Player:    &match.Teams[rs.getTeamIndex(match, team.Name)].Players[i],
-						Item:      item,
-						Cost:      cost,
-					}
-					events = append(events, purchaseEvent)
+				if playerState.Money < cost || !rs.canCarryItem(playerState, item) {
+					continue
+				}
+				
+				tick := int64(0)
+				if j >= leftSpawnAt {
+					tick = rs.buyWindowTicks() + int64(1+rs.rng.Intn(5))*int64(rs.config.TickRate)
+				}
+				if event := rs.buyItem(buyer, playerState, item, cost, tick, roundNum); event != nil {
+					events = append(events, event)
 				}
 			}
 		}
@@ -180,49 +476,43 @@ func (rs *RoundSimulator) simulateBuyPhase(match *models.Match, state *models.Ma
 // simulateBombRound simulates a round with bomb plant/defuse scenario
 func (rs *RoundSimulator) simulateBombRound(match *models.Match, state *models.MatchState, roundNum int, strategy *RoundStrategy) (*RoundResult, []models.GameEvent, error) {
 	var events []models.GameEvent
-	currentTick := int64(0)
+	currentTick := rs.openingContactTicks(match, state)
 	
 	// Simulate initial engagements (20-40 seconds)
 	initialDuration := time.Duration(20+rs.rng.Intn(20)) * time.Second
-	initialTicks := int64(initialDuration.Seconds()) * int64(rs.config.TickRate)
+	initialTicks := models.DurationToTicks(initialDuration, rs.config.TickRate)
 	
 	// Generate some early kills
-	for currentTick < initialTicks && rs.getAliveCount(match, state, "CT") > 0 && rs.getAliveCount(match, state, "TERRORIST") > 0 {
+	winner := ""
+	var kills []models.GameEvent
+	for currentTick < initialTicks && winner == "" {
 		if rs.rng.Float64() < 0.3 { // 30% chance of engagement per interval
-			if killEvent := rs.generateKillEvent(match, state, currentTick, roundNum); killEvent != nil {
-				events = append(events, killEvent)
-			}
+			kills, winner = rs.duel(match, state, currentTick, roundNum, false)
+			events = append(events, kills...)
 		}
-		currentTick += int64(rs.config.TickRate * 2) // Advance 2 seconds
+		currentTick += rs.pacedTicks(match, state, 2) // Advance 2 seconds
 	}
 	
 	// Check if round should end early
-	if rs.getAliveCount(match, state, "CT") == 0 {
-		return &RoundResult{
-			Winner:   "TERRORIST",
-			Reason:   "elimination",
-			Duration: time.Duration(currentTick/int64(rs.config.TickRate)) * time.Second,
-		}, events, nil
-	}
-	if rs.getAliveCount(match, state, "TERRORIST") == 0 {
-		return &RoundResult{
-			Winner:   "CT",
-			Reason:   "elimination",
-			Duration: time.Duration(currentTick/int64(rs.config.TickRate)) * time.Second,
-		}, events, nil
+	if winner != "" {
+		return rs.eliminationResult(winner, kills), events, nil
 	}
 	
 	// Bomb plant phase
 	if rs.getAliveCount(match, state, "TERRORIST") > 0 {
-		plantSuccess := rs.rng.Float64() < 0.7 // 70% bomb plant success
+		plantSuccess := rs.rng.Float64() < 0.7 || rs.roundWinner == "TERRORIST" // 70% bomb plant success
 		
 		if plantSuccess {
-			// Select planter
-			aliveTPlayers := rs.getAlivePlayers(match, state, "TERRORIST")
-			if len(aliveTPlayers) > 0 {
-				planter := aliveTPlayers[rs.rng.Intn(len(aliveTPlayers))]
-				bombSite := []string{"A", "B"}[rs.rng.Intn(2)]
+			// The bomb carrier plants, after a teammate picks up a dropped bomb
+			planter, pickup := rs.bombPlanter(match, state, currentTick, roundNum)
+			if pickup != nil {
+				events = append(events, pickup)
+			}
+			if planter != nil {
+				bombSite := rs.selectBombSite(match.Map)
 				
+				// The plant is logged once it completes, which starts the bomb timer
+				currentTick += int64(rs.config.TickRate * 5) // 5 seconds for plant
 				plantEvent := &models.BombPlantEvent{
 					BaseEvent: models.NewBaseEvent("bomb_plant", currentTick, roundNum),
 					Player:    planter,
@@ -230,7 +520,8 @@ func (rs *RoundSimulator) simulateBombRound(match *models.Match, state *models.M
 					Position:  rs.getBombSitePosition(bombSite),
 				}
 				events = append(events, plantEvent)
-				currentTick += int64(rs.config.TickRate * 5) // 5 seconds for plant
+				state.PlayerStates[planter.Name].HasBomb = false
+				state.BombCarrier = nil
 				
 				// Post-plant scenario
 				return rs.simulatePostPlant(match, state, roundNum, currentTick, bombSite, events, strategy)
@@ -239,86 +530,65 @@ func (rs *RoundSimulator) simulateBombRound(match *models.Match, state *models.M
 	}
 	
 	// If no bomb plant, continue until elimination or time
-	for currentTick < int64(115*rs.config.TickRate) { // 115 seconds round time
-		if killEvent := rs.generateKillEvent(match, state, currentTick, roundNum); killEvent != nil {
-			events = append(events, killEvent)
-			
-			// Check for round end
-			if rs.getAliveCount(match, state, "CT") == 0 {
-				return &RoundResult{
-					Winner:   "TERRORIST",
-					Reason:   "elimination",
-					Duration: time.Duration(currentTick/int64(rs.config.TickRate)) * time.Second,
-				}, events, nil
-			}
-			if rs.getAliveCount(match, state, "TERRORIST") == 0 {
-				return &RoundResult{
-					Winner:   "CT",
-					Reason:   "elimination",
-					Duration: time.Duration(currentTick/int64(rs.config.TickRate)) * time.Second,
-				}, events, nil
-			}
+	maxTicks := rs.roundTimeTicks()
+	for currentTick < maxTicks {
+		kills, winner := rs.duel(match, state, currentTick, roundNum, false)
+		events = append(events, kills...)
+		
+		// Check for round end
+		if winner != "" {
+			return rs.eliminationResult(winner, kills), events, nil
 		}
-		currentTick += int64(rs.config.TickRate * 3) // Advance 3 seconds
+		currentTick += rs.pacedTicks(match, state, 3) // Advance 3 seconds
 	}
 	
-	// Time expired
-	return &RoundResult{
-		Winner:   "CT",
-		Reason:   "time",
-		Duration: time.Duration(115) * time.Second,
-	}, events, nil
+	// Time expired without a plant
+	result, events := rs.timeExpiredResult(nil, maxTicks, roundNum, events)
+	return result, events, nil
 }
 
 // simulatePostPlant handles the post-bomb-plant scenario
 func (rs *RoundSimulator) simulatePostPlant(match *models.Match, state *models.MatchState, roundNum int, currentTick int64, bombSite string, events []models.GameEvent, strategy *RoundStrategy) (*RoundResult, []models.GameEvent, error) {
-	bombTimer := 40 * time.Second // 40 second bomb timer
-	bombTicks := int64(bombTimer.Seconds()) * int64(rs.config.TickRate)
-	maxTick := currentTick + bombTicks
-	
-	// Post-plant engagements
-	for currentTick < maxTick-int64(10*rs.config.TickRate) { // Leave 10 seconds for defuse
-		if killEvent := rs.generateKillEvent(match, state, currentTick, roundNum); killEvent != nil {
-			events = append(events, killEvent)
-			
-			// Check for elimination
-			if rs.getAliveCount(match, state, "CT") == 0 {
-				// Bomb explodes
-				explodeEvent := &models.BombExplodeEvent{
-					BaseEvent: models.NewBaseEvent("bomb_explode", maxTick, roundNum),
-					Site:      bombSite,
-					Position:  rs.getBombSitePosition(bombSite),
-				}
-				events = append(events, explodeEvent)
-				
-				return &RoundResult{
-					Winner:   "TERRORIST",
-					Reason:   "bomb_exploded",
-					Duration: time.Duration(maxTick/int64(rs.config.TickRate)) * time.Second,
-				}, events, nil
-			}
-			if rs.getAliveCount(match, state, "TERRORIST") == 0 {
-				break // CTs can try to defuse
-			}
+	bomb := &plantedBomb{
+		site:        bombSite,
+		explodeTick: currentTick + int64(rs.serverConfig.BombTimer*rs.config.TickRate),
+	}
+	maxTick := bomb.explodeTick
+	
+	// Post-plant engagements, leaving time for a defuse without a kit
+	for currentTick < maxTick-rs.defuseTicks(false) {
+		kills, _ := rs.duel(match, state, currentTick, roundNum, true)
+		events = append(events, kills...)
+		
+		// No CTs are left to defuse, so the bomb runs out its timer, even when the
+		// last terrorist was traded by the last CT
+		if rs.getAliveCount(match, state, "CT") == 0 {
+			result, events := rs.timeExpiredResult(bomb, maxTick, roundNum, events)
+			return result, events, nil
+		}
+		if rs.getAliveCount(match, state, "TERRORIST") == 0 {
+			break // CTs can try to defuse
 		}
 		currentTick += int64(rs.config.TickRate * 2) // Advance 2 seconds
 	}
 	
-	// Defuse attempt
+	// Defuse attempt, contested while terrorists are still alive
 	aliveCTPlayers := rs.getAlivePlayers(match, state, "CT")
-	if len(aliveCTPlayers) > 0 && currentTick < maxTick {
-		defuseSuccess := rs.rng.Float64() < 0.4 // 40% defuse success rate
+	retake := len(aliveCTPlayers) > 0 && rs.winsRetake(len(aliveCTPlayers), rs.getAliveCount(match, state, "TERRORIST"))
+	if rs.roundWinner != "" {
+		retake = rs.roundWinner == "CT" && len(aliveCTPlayers) > 0
+	}
+	if retake {
+		defuser := rs.selectDefuser(aliveCTPlayers, state)
+		hasKit := false
+		if playerState := state.PlayerStates[defuser.Name]; playerState != nil {
+			hasKit = playerState.HasDefuseKit
+		}
 		
-		if defuseSuccess {
-			defuser := aliveCTPlayers[0]
-			hasKit := rs.rng.Float64() < 0.6 // 60% chance of having kit
-			defuseTime := 10
-			if hasKit {
-				defuseTime = 5
-			}
-			
+		if rs.canDefuse(maxTick-currentTick, hasKit) {
+			defuseTick := currentTick + rs.defuseTicks(hasKit)
 			defuseEvent := &models.BombDefuseEvent{
-				BaseEvent: models.NewBaseEvent("bomb_defuse", currentTick+int64(defuseTime*rs.config.TickRate), roundNum),
+				BaseEvent: models.NewBaseEvent("bomb_defuse", defuseTick, roundNum),
 				Player:    defuser,
 				Site:      bombSite,
 				WithKit:   hasKit,
@@ -329,55 +599,186 @@ func (rs *RoundSimulator) simulatePostPlant(match *models.Match, state *models.M
 			return &RoundResult{
 				Winner:   "CT",
 				Reason:   "bomb_defused",
-				Duration: time.Duration((currentTick+int64(defuseTime*rs.config.TickRate))/int64(rs.config.TickRate)) * time.Second,
+				Duration: models.TicksToDuration(defuseTick, rs.config.TickRate),
 			}, events, nil
 		}
 	}
 	
-	// Bomb explodes
-	explodeEvent := &models.BombExplodeEvent{
-		BaseEvent: models.NewBaseEvent("bomb_explode", maxTick, roundNum),
-		Site:      bombSite,
-		Position:  rs.getBombSitePosition(bombSite),
+	// No defuse in time
+	result, events := rs.timeExpiredResult(bomb, maxTick, roundNum, events)
+	return result, events, nil
+}
+
+// duel generates the next kill of a round. When it takes down the last player of a
+// side and their opponent was the last one standing, the victim may trade their
+// killer in the same exchange, returned after the kill. As in CS2 the round is
+// decided the moment a side is wiped out, so the winner is the side that eliminated
+// the other first, and empty while both still have players. Once the bomb is
+// planted a trade leaves nobody to defuse it, so the caller lets it explode.
+func (rs *RoundSimulator) duel(match *models.Match, state *models.MatchState, tick int64, roundNum int, bombPlanted bool) ([]models.GameEvent, string) {
+	killEvent := rs.generateKillEvent(match, state, tick, roundNum)
+	if killEvent == nil {
+		return nil, ""
+	}
+	kills := []models.GameEvent{killEvent}
+	
+	killerSide := "CT"
+	switch {
+	case rs.getAliveCount(match, state, "CT") == 0:
+		killerSide = "TERRORIST"
+	case rs.getAliveCount(match, state, "TERRORIST") > 0:
+		return kills, ""
+	}
+	
+	// A round steered towards a retake can't lose its last CT to a trade
+	steeredRetake := bombPlanted && rs.roundWinner == "CT"
+	if rs.getAliveCount(match, state, killerSide) == 1 && !steeredRetake && rs.rng.Float64() < lastDuelTradeProbability {
+		kill := killEvent.(*models.KillEvent)
+		kills = append(kills, rs.tradeKill(state, kill, tick+rs.rng.Int63n(rs.tradeWindowTicks()+1), roundNum))
+	}
+	return kills, killerSide
+}
+
+// tradeKill returns the kill of a player by the victim they just killed
+func (rs *RoundSimulator) tradeKill(state *models.MatchState, kill *models.KillEvent, tick int64, roundNum int) models.GameEvent {
+	attacker, victim := kill.Victim, kill.Attacker
+	weapon := rs.selectWeaponForKill(attacker, state)
+	headshot := rs.rng.Float64() < rs.getHeadshotProbability(attacker, weapon)
+	hitgroup := 1
+	if !headshot {
+		hitgroup = bodyHitgroups[rs.rng.Intn(len(bodyHitgroups))]
+	}
+	
+	trade := &models.KillEvent{
+		BaseEvent:   models.NewBaseEvent("player_death", tick, roundNum),
+		Attacker:    attacker,
+		Victim:      victim,
+		Weapon:      weapon,
+		Headshot:    headshot,
+		Hitgroup:    hitgroup,
+		Distance:    kill.Distance,
+		AttackerPos: kill.VictimPos,
+		VictimPos:   kill.AttackerPos,
+	}
+	if rs.skins != nil {
+		rs.skins.RecordKill(trade, state.PlayerStates[attacker.Name])
+	}
+	
+	state.PlayerStates[victim.Name].IsAlive = false
+	state.PlayerStates[victim.Name].Health = 0
+	if state.PlayerStates[victim.Name].HasBomb {
+		rs.dropBomb(state, victim, tick, roundNum)
+	}
+	
+	attacker.Stats.Kills++
+	victim.Stats.Deaths++
+	if headshot {
+		attacker.Stats.Headshots++
+	}
+	
+	return trade
+}
+
+// tradeWindowTicks is how long after a kill its victim can still take the killer down
+func (rs *RoundSimulator) tradeWindowTicks() int64 {
+	return models.DurationToTicks(tradeWindow, rs.config.TickRate)
+}
+
+// eliminationResult ends a round won by eliminating the other side, once its last kills are logged
+func (rs *RoundSimulator) eliminationResult(winner string, kills []models.GameEvent) *RoundResult {
+	return &RoundResult{
+		Winner:   winner,
+		Reason:   "elimination",
+		Duration: models.TicksToDuration(kills[len(kills)-1].GetTick(), rs.config.TickRate),
+	}
+}
+
+// plantedBomb tracks a planted bomb so the round end can account for it
+type plantedBomb struct {
+	site        string
+	explodeTick int64
+}
+
+// timeExpiredResult is the authoritative end of a round whose clock ran out. A planted
+// bomb replaces the round timer and explodes for a terrorist win; otherwise the CTs
+// win on time once the round timer expires.
+func (rs *RoundSimulator) timeExpiredResult(bomb *plantedBomb, roundEndTick int64, roundNum int, events []models.GameEvent) (*RoundResult, []models.GameEvent) {
+	if bomb != nil {
+		explodeEvent := &models.BombExplodeEvent{
+			BaseEvent: models.NewBaseEvent("bomb_explode", bomb.explodeTick, roundNum),
+			Site:      bomb.site,
+			Position:  rs.getBombSitePosition(bomb.site),
+		}
+		events = append(events, explodeEvent)
+		
+		return &RoundResult{
+			Winner:   "TERRORIST",
+			Reason:   "bomb_exploded",
+			Duration: models.TicksToDuration(bomb.explodeTick, rs.config.TickRate),
+		}, events
 	}
-	events = append(events, explodeEvent)
 	
 	return &RoundResult{
-		Winner:   "TERRORIST",
-		Reason:   "bomb_exploded",
-		Duration: time.Duration(maxTick/int64(rs.config.TickRate)) * time.Second,
-	}, events, nil
+		Winner:   "CT",
+		Reason:   "time",
+		Duration: models.TicksToDuration(roundEndTick, rs.config.TickRate),
+	}, events
+}
+
+// roundTimeTicks returns the length of the round timer in ticks
+func (rs *RoundSimulator) roundTimeTicks() int64 {
+	return int64(rs.serverConfig.RoundTime * rs.config.TickRate)
+}
+
+// defuseTicks returns how many ticks a defuse takes with or without a kit
+func (rs *RoundSimulator) defuseTicks(hasKit bool) int64 {
+	seconds := rs.serverConfig.DefuseTimeNoKit
+	if hasKit {
+		seconds = rs.serverConfig.DefuseTime
+	}
+	return int64(seconds * rs.config.TickRate)
+}
+
+// canDefuse reports whether a defuse started with the given ticks left finishes before the explosion
+func (rs *RoundSimulator) canDefuse(remainingTicks int64, hasKit bool) bool {
+	return remainingTicks > 0 && rs.defuseTicks(hasKit) <= remainingTicks
+}
+
+// winsRetake decides whether the CTs get a defuse started against the remaining terrorists
+func (rs *RoundSimulator) winsRetake(ctAlive, tAlive int) bool {
+	if tAlive == 0 {
+		return true
+	}
+	return rs.rng.Float64() < float64(ctAlive)/float64(ctAlive+tAlive)
+}
+
+// selectDefuser prefers an alive CT carrying a defuse kit, returning nil when none are alive
+func (rs *RoundSimulator) selectDefuser(aliveCTPlayers []*models.Player, state *models.MatchState) *models.Player {
+	if len(aliveCTPlayers) == 0 {
+		return nil
+	}
+	for _, player := range aliveCTPlayers {
+		if playerState := state.PlayerStates[player.Name]; playerState != nil && playerState.HasDefuseKit {
+			return player
+		}
+	}
+	return aliveCTPlayers[0]
 }
 
 // simulateEliminationRound simulates a round ending in elimination
 func (rs *RoundSimulator) simulateEliminationRound(match *models.Match, state *models.MatchState, roundNum int, strategy *RoundStrategy) (*RoundResult, []models.GameEvent, error) {
 	var events []models.GameEvent
-	currentTick := int64(0)
-	maxTicks := int64(115 * rs.config.TickRate) // 115 seconds
+	currentTick := rs.openingContactTicks(match, state)
+	maxTicks := rs.roundTimeTicks()
 	
 	// Generate kills until one team is eliminated
 	for currentTick < maxTicks {
-		if killEvent := rs.generateKillEvent(match, state, currentTick, roundNum); killEvent != nil {
-			events = append(events, killEvent)
-			
-			// Check for elimination
-			ctAlive := rs.getAliveCount(match, state, "CT")
-			tAlive := rs.getAliveCount(match, state, "TERRORIST")
-			
-			if ctAlive == 0 {
-				return &RoundResult{
-					Winner:   "TERRORIST",
-					Reason:   "elimination",
-					Duration: time.Duration(currentTick/int64(rs.config.TickRate)) * time.Second,
-				}, events, nil
-			}
-			if tAlive == 0 {
-				return &RoundResult{
-					Winner:   "CT",
-					Reason:   "elimination",
-					Duration: time.Duration(currentTick/int64(rs.config.TickRate)) * time.Second,
-				}, events, nil
-			}
+		kills, winner := rs.duel(match, state, currentTick, roundNum, false)
+		events = append(events, kills...)
+		
+		// Check for elimination
+		if winner != "" {
+			return rs.eliminationResult(winner, kills), events, nil
 		}
 		
 		// Advance time based on intensity
@@ -385,22 +786,19 @@ func (rs *RoundSimulator) simulateEliminationRound(match *models.Match, state *m
 		if strategy.Intensity > 0.7 {
 			advanceTime = 1 // Faster paced round
 		}
-		currentTick += int64(rs.config.TickRate) * advanceTime
+		currentTick += rs.pacedTicks(match, state, float64(advanceTime))
 	}
 	
 	// Time expired - CT wins
-	return &RoundResult{
-		Winner:   "CT",
-		Reason:   "time",
-		Duration: time.Duration(115) * time.Second,
-	}, events, nil
+	result, events := rs.timeExpiredResult(nil, maxTicks, roundNum, events)
+	return result, events, nil
 }
 
 // simulateTimeoutRound simulates a round ending in timeout
 func (rs *RoundSimulator) simulateTimeoutRound(match *models.Match, state *models.MatchState, roundNum int, strategy *RoundStrategy) (*RoundResult, []models.GameEvent, error) {
 	var events []models.GameEvent
 	currentTick := int64(0)
-	maxTicks := int64(115 * rs.config.TickRate) // 115 seconds
+	maxTicks := rs.roundTimeTicks()
 	
 	// Generate fewer kills, round times out
 	killCount := 1 + rs.rng.Intn(3) // 1-3 kills max
@@ -408,33 +806,170 @@ func (rs *RoundSimulator) simulateTimeoutRound(match *models.Match, state *model
 	
 	for i := 0; i < killCount && currentTick < maxTicks; i++ {
 		currentTick += killInterval
-		if killEvent := rs.generateKillEvent(match, state, currentTick, roundNum); killEvent != nil {
-			events = append(events, killEvent)
-			
-			// Check if elimination occurred anyway
-			if rs.getAliveCount(match, state, "CT") == 0 {
-				return &RoundResult{
-					Winner:   "TERRORIST",
-					Reason:   "elimination",
-					Duration: time.Duration(currentTick/int64(rs.config.TickRate)) * time.Second,
-				}, events, nil
-			}
-			if rs.getAliveCount(match, state, "TERRORIST") == 0 {
-				return &RoundResult{
-					Winner:   "CT",
-					Reason:   "elimination",
-					Duration: time.Duration(currentTick/int64(rs.config.TickRate)) * time.Second,
-				}, events, nil
-			}
+		kills, winner := rs.duel(match, state, currentTick, roundNum, false)
+		events = append(events, kills...)
+		
+		// Check if elimination occurred anyway
+		if winner != "" {
+			return rs.eliminationResult(winner, kills), events, nil
 		}
 	}
 	
 	// Time expired - CT wins
-	return &RoundResult{
-		Winner:   "CT",
-		Reason:   "time",
-		Duration: time.Duration(115) * time.Second,
-	}, events, nil
+	result, events := rs.timeExpiredResult(nil, maxTicks, roundNum, events)
+	return result, events, nil
+}
+
+// giveBomb hands the bomb to a terrorist at spawn, rotating the carrier from round to
+// round, and returns the pickup to log
+func (rs *RoundSimulator) giveBomb(match *models.Match, state *models.MatchState, roundNum int) models.GameEvent {
+	rs.itemEvents = nil
+	rs.droppedBomb = nil
+	state.BombCarrier = nil
+	
+	terrorists := rs.getAlivePlayers(match, state, "TERRORIST")
+	if len(terrorists) == 0 {
+		return nil
+	}
+	carrier := terrorists[roundNum%len(terrorists)]
+	state.PlayerStates[carrier.Name].HasBomb = true
+	state.BombCarrier = carrier
+	
+	if rs.config.FastMode {
+		return nil
+	}
+	return &models.BombPickupEvent{
+		BaseEvent: models.NewBaseEvent("bomb_pickup", 0, roundNum),
+		Player:    carrier,
+		Position:  state.PlayerStates[carrier.Name].Position,
+	}
+}
+
+// dropBomb leaves the bomb where its dying carrier stood
+func (rs *RoundSimulator) dropBomb(state *models.MatchState, carrier *models.Player, tick int64, roundNum int) {
+	position := state.PlayerStates[carrier.Name].Position
+	state.PlayerStates[carrier.Name].HasBomb = false
+	state.BombCarrier = nil
+	rs.droppedBomb = &position
+	
+	if rs.config.FastMode {
+		return
+	}
+	rs.itemEvents = append(rs.itemEvents, &models.BombDropEvent{
+		BaseEvent: models.NewBaseEvent("bomb_drop", tick, roundNum),
+		Player:    carrier,
+		Position:  position,
+	})
+}
+
+// pickUpWeapon lets a killer take their victim's primary weapon when it is worth more
+// than their own. The weapon changes hands without money being spent.
+func (rs *RoundSimulator) pickUpWeapon(state *models.MatchState, attacker, victim *models.Player, tick int64, roundNum int) {
+	attackerState, victimState := state.PlayerStates[attacker.Name], state.PlayerStates[victim.Name]
+	if attacker.Team == victim.Team || victimState.PrimaryWeapon == nil {
+		return
+	}
+	if attackerState.PrimaryWeapon != nil && attackerState.PrimaryWeapon.Price >= victimState.PrimaryWeapon.Price {
+		return
+	}
+	
+	weapon := *victimState.PrimaryWeapon
+	weapon.PickedUp = true
+	attackerState.PrimaryWeapon = &weapon
+	victimState.PrimaryWeapon = nil
+	
+	if rs.config.FastMode {
+		return
+	}
+	rs.itemEvents = append(rs.itemEvents, &models.WeaponPickupEvent{
+		BaseEvent: models.NewBaseEvent("weapon_pickup", tick, roundNum),
+		Player:    attacker,
+		From:      victim,
+		Weapon:    weapon.Name,
+		Position:  victimState.Position,
+	})
+}
+
+// bombPlanter returns the terrorist who plants: the carrier, or when the bomb was
+// dropped a random surviving teammate, along with the pickup to log for them
+func (rs *RoundSimulator) bombPlanter(match *models.Match, state *models.MatchState, tick int64, roundNum int) (*models.Player, models.GameEvent) {
+	if state.BombCarrier != nil && state.PlayerStates[state.BombCarrier.Name].IsAlive {
+		return state.BombCarrier, nil
+	}
+	
+	terrorists := rs.getAlivePlayers(match, state, "TERRORIST")
+	if len(terrorists) == 0 || rs.droppedBomb == nil {
+		return nil, nil
+	}
+	picker := terrorists[rs.rng.Intn(len(terrorists))]
+	pickerState := state.PlayerStates[picker.Name]
+	pickerState.Position = *rs.droppedBomb
+	pickerState.HasBomb = true
+	state.BombCarrier = picker
+	rs.droppedBomb = nil
+	
+	if rs.config.FastMode {
+		return picker, nil
+	}
+	return picker, &models.BombPickupEvent{
+		BaseEvent: models.NewBaseEvent("bomb_pickup", tick, roundNum),
+		Player:    picker,
+		Position:  pickerState.Position,
+	}
+}
+
+// positionWalkSpeed is how far a player moves per second, in game units, on the way to their site
+const positionWalkSpeed = 250.0
+
+// samplePositions returns a player_position event for every alive player at each sample
+// tick of the round. Players walk from their spawn towards a bomb site, alternating sites
+// by roster slot, and face the way they walk. No random numbers are drawn, so enabling
+// positions leaves the rest of the match unchanged.
+func (rs *RoundSimulator) samplePositions(match *models.Match, combatEvents []models.GameEvent, result *RoundResult, roundNum int) []models.GameEvent {
+	interval := int64(rs.config.TickRate / rs.config.GetPositionSampleRate())
+	if interval < 1 {
+		interval = 1
+	}
+	endTick := models.DurationToTicks(result.Duration, rs.config.TickRate)
+
+	deaths := make(map[string]int64)
+	for _, event := range combatEvents {
+		if kill, ok := event.(*models.KillEvent); ok && kill.Victim != nil {
+			deaths[kill.Victim.Name] = kill.Tick
+		}
+	}
+
+	var samples []models.GameEvent
+	for _, team := range match.Teams {
+		for i := range team.Players {
+			player := &team.Players[i]
+			spawn := rs.getSpawnPosition(team.Side, i)
+			site := rs.getBombSitePosition([]string{"A", "B"}[i%2])
+			dx, dy := site.X-spawn.X, site.Y-spawn.Y
+			distance := math.Hypot(dx, dy)
+			yaw := math.Atan2(dy, dx) * 180 / math.Pi
+
+			lastTick := endTick
+			if deathTick, dead := deaths[player.Name]; dead && deathTick < lastTick {
+				lastTick = deathTick
+			}
+			for tick := int64(0); tick < lastTick; tick += interval {
+				walked := math.Min(positionWalkSpeed*float64(tick)/float64(rs.config.TickRate), distance)
+				position := spawn
+				if distance > 0 {
+					position.X += dx / distance * walked
+					position.Y += dy / distance * walked
+				}
+				samples = append(samples, &models.PlayerPositionEvent{
+					BaseEvent: models.NewBaseEvent("player_position", tick, roundNum),
+					Player:    player,
+					Position:  position,
+					ViewAngle: models.Vector3{Y: yaw},
+				})
+			}
+		}
+	}
+	return samples
 }
 
 // Helper methods
@@ -457,9 +992,47 @@ func (rs *RoundSimulator) resetPlayerStatesForRound(match *models.Match, state *
 	}
 }
 
-func (rs *RoundSimulator) determineBuyStrategy(economy *models.TeamEconomy, roundNum int) string {
+// decideBuyTypes records every team's buy type for the round from the money and
+// equipment the teams hold before anyone buys
+func (rs *RoundSimulator) decideBuyTypes(match *models.Match, state *models.MatchState, roundNum int) {
+	for i := range match.Teams {
+		if state.TeamEconomies[match.Teams[i].Name] != nil {
+			rs.updateTeamEconomyAfterBuy(&match.Teams[i], state)
+		}
+	}
+	for _, team := range match.Teams {
+		if teamEconomy := state.TeamEconomies[team.Name]; teamEconomy != nil {
+			teamEconomy.BuyType = rs.determineBuyStrategy(match, teamEconomy, rs.opponentEconomy(match, state, team.Name), roundNum)
+		}
+	}
+}
+
+// opponentEconomy returns the economy of the team playing against the given one, or nil
+func (rs *RoundSimulator) opponentEconomy(match *models.Match, state *models.MatchState, teamName string) *models.TeamEconomy {
+	for _, team := range match.Teams {
+		if team.Name != teamName {
+			return state.TeamEconomies[team.Name]
+		}
+	}
+	return nil
+}
+
+// determineBuyStrategy decides a team's buy type from its money. A team that can
+// afford SMGs but not a full buy anti-ecos an opponent that can't buy either and
+// carries little equipment into the round; opponent may be nil when unknown.
+func (rs *RoundSimulator) determineBuyStrategy(match *models.Match, economy, opponent *models.TeamEconomy, roundNum int) string {
+	if rs.config.ForceBuyType != "" {
+		return rs.config.ForceBuyType
+	}
+	
 	avgMoney := economy.AverageMoney
 	
+	// Everyone is on pistols in a pistol round, so that's not an eco to punish
+	opponentSaving := opponent != nil && opponent.AverageMoney < 2500 && opponent.EquipmentValue < rs.config.GetAntiEcoThreshold()
+	if opponentSaving && !match.IsPistolRound(roundNum) && avgMoney >= 2000 && avgMoney < 5000 {
+		return "anti_eco"
+	}
+	
 	if avgMoney >= 5000 {
 		return "full_buy"
 	} else if avgMoney >= 2500 {
@@ -472,6 +1045,59 @@ func (rs *RoundSimulator) getItemCost(item string) int {
 	return rs.economyManager.GetWeaponPrice(item) + rs.economyManager.GetUtilityPrice(item)
 }
 
+// buyWindowTicks returns how long after the round starts players can still buy
+func (rs *RoundSimulator) buyWindowTicks() int64 {
+	return int64(rs.serverConfig.BuyTime * rs.config.TickRate)
+}
+
+// buyItem attempts a purchase at a round-relative tick. Purchases inside the buy
+// window charge the player and equip the item; later ones are rejected and only
+// logged with verbose logging.
+func (rs *RoundSimulator) buyItem(player *models.Player, playerState *models.PlayerState, item string, cost int, tick int64, roundNum int) models.GameEvent {
+	if tick > rs.buyWindowTicks() {
+		if !rs.config.VerboseLogging || rs.config.FastMode {
+			return nil
+		}
+		return &models.PurchaseRejectedEvent{
+			BaseEvent: models.NewBaseEvent("purchase_rejected", tick, roundNum),
+			Player:    player,
+			Item:      item,
+			Reason:    "not in buyzone",
+		}
+	}
+	
+	playerState.Money -= cost
+	rs.applyPurchaseToPlayer(playerState, item)
+	
+	// Fast mode keeps the economy effect but skips the event
+	if rs.config.FastMode {
+		return nil
+	}
+	return &models.ItemPurchaseEvent{
+		BaseEvent: models.NewBaseEvent("item_purchase", tick, roundNum),
+		Player:    player,
+		Item:      item,
+		Cost:      cost,
+	}
+}
+
+// allowedOnPistolRound reports whether an item can be bought on a pistol round:
+// pistols, armor and utility, but no primary weapons
+func (rs *RoundSimulator) allowedOnPistolRound(item string) bool {
+	if info, exists := rs.economyManager.GetWeaponInfo()[item]; exists {
+		return info.Type == "pistol"
+	}
+	return true
+}
+
+// canCarryItem checks whether a purchased item fits in the player's inventory
+func (rs *RoundSimulator) canCarryItem(state *models.PlayerState, item string) bool {
+	if info, exists := rs.economyManager.GetUtilityInfo()[item]; exists && info.Type == "grenade" {
+		return models.CanCarryGrenade(state.Grenades, item)
+	}
+	return true
+}
+
 func (rs *RoundSimulator) applyPurchaseToPlayer(state *models.PlayerState, item string) {
 	// Apply purchased item to player state
 	weaponInfo := rs.economyManager.GetWeaponInfo()
@@ -494,21 +1120,16 @@ func (rs *RoundSimulator) applyPurchaseToPlayer(state *models.PlayerState, item
 		switch info.Type {
 		case "armor":
 			if item == "vesthelm" {
-				state.Armor = 100
-				state.HasHelmet = true
+				state.EquipArmor(true)
 			} else if item == "vest" {
-				state.Armor = 100
+				state.EquipArmor(false)
 			}
 		case "utility":
 			if item == "defuser" {
 				state.HasDefuseKit = true
 			}
 		case "grenade":
-			grenade := models.Grenade{
-				Type:  info.Name,
-				Price: info.Price,
-			}
-			state.Grenades = append(state.Grenades, grenade)
+			state.AddGrenade(models.Grenade{Type: info.Name, Price: info.Price})
 		}
 	}
 }
@@ -521,19 +1142,36 @@ func (rs *RoundSimulator) generateKillEvent(match *models.Match, state *models.M
 		return nil
 	}
 	
-	// Select attacker and victim
+	// Select attacker and victim, aggressive players taking more of the duels
 	var attacker, victim *models.Player
-	if rs.rng.Float64() < 0.5 {
-		attacker = ctPlayers[rs.rng.Intn(len(ctPlayers))]
-		victim = tPlayers[rs.rng.Intn(len(tPlayers))]
+	if rs.rng.Float64() < rs.ctDuelProbability(match, state) {
+		attacker = rs.selectDuelist(ctPlayers)
+		victim = rs.selectDuelist(tPlayers)
 	} else {
-		attacker = tPlayers[rs.rng.Intn(len(tPlayers))]
-		victim = ctPlayers[rs.rng.Intn(len(ctPlayers))]
+		attacker = rs.selectDuelist(tPlayers)
+		victim = rs.selectDuelist(ctPlayers)
+	}
+	
+	// The last player of a steered round's winning side takes the duel instead
+	if rs.roundWinner != "" {
+		winners := ctPlayers
+		if rs.roundWinner == "TERRORIST" {
+			winners = tPlayers
+		}
+		if len(winners) == 1 && victim == winners[0] {
+			attacker, victim = victim, attacker
+		}
 	}
 	
 	// Select weapon
 	weapon := rs.selectWeaponForKill(attacker, state)
 	headshot := rs.rng.Float64() < rs.getHeadshotProbability(attacker, weapon)
+	hitgroup := 1
+	if !headshot {
+		hitgroup = bodyHitgroups[rs.rng.Intn(len(bodyHitgroups))]
+	}
+	noScope := noScopeWeapons[weapon] && rs.rng.Float64() < rs.simConfig.NoScopeProbability
+	inAir := rs.rng.Float64() < rs.simConfig.InAirKillProbability
 	
 	// Create kill event
 	killEvent := &models.KillEvent{
@@ -542,17 +1180,26 @@ func (rs *RoundSimulator) generateKillEvent(match *models.Match, state *models.M
 		Victim:        victim,
 		Weapon:        weapon,
 		Headshot:      headshot,
+		Hitgroup:      hitgroup,
 		Penetrated:    0,
-		NoScope:       false,
+		NoScope:       noScope,
 		AttackerBlind: false,
+		InAir:         inAir,
 		Distance:      float64(5 + rs.rng.Intn(30)), // 5-35 meters
 		AttackerPos:   state.PlayerStates[attacker.Name].Position,
 		VictimPos:     state.PlayerStates[victim.Name].Position,
 	}
+	if rs.skins != nil {
+		rs.skins.RecordKill(killEvent, state.PlayerStates[attacker.Name])
+	}
 	
 	// Update player states
 	state.PlayerStates[victim.Name].IsAlive = false
 	state.PlayerStates[victim.Name].Health = 0
+	if state.PlayerStates[victim.Name].HasBomb {
+		rs.dropBomb(state, victim, tick, roundNum)
+	}
+	rs.pickUpWeapon(state, attacker, victim, tick, roundNum)
 	
 	// Update statistics
 	attacker.Stats.Kills++
@@ -570,7 +1217,7 @@ func (rs *RoundSimulator) selectMVP(match *models.Match, winner string, events [
 	
 	for _, event := range events {
 		if killEvent, ok := event.(*models.KillEvent); ok {
-			if killEvent.Attacker.Side == winner || (winner == "CT" && killEvent.Attacker.Side == "COUNTER-TERRORIST") {
+			if models.NormalizeSide(killEvent.Attacker.Side) == models.NormalizeSide(winner) {
 				killCounts[killEvent.Attacker.Name]++
 			}
 		}
@@ -581,10 +1228,14 @@ func (rs *RoundSimulator) selectMVP(match *models.Match, winner string, events [
 	maxKills := -1
 	
 	winningTeam := rs.getTeamBySide(match, winner)
-	for _, player := range winningTeam.Players {
+	if winningTeam == nil {
+		return nil
+	}
+	for i := range winningTeam.Players {
+		player := &winningTeam.Players[i]
 		if kills, exists := killCounts[player.Name]; exists && kills > maxKills {
 			maxKills = kills
-			mvp = &player
+			mvp = player
 		}
 	}
 	
@@ -600,7 +1251,7 @@ func (rs *RoundSimulator) selectMVP(match *models.Match, winner string, events [
 
 func (rs *RoundSimulator) getTeamBySide(match *models.Match, side string) *models.Team {
 	for i := range match.Teams {
-		if match.Teams[i].Side == side {
+		if models.NormalizeSide(match.Teams[i].Side) == models.NormalizeSide(side) {
 			return &match.Teams[i]
 		}
 	}
@@ -616,6 +1267,55 @@ func (rs *RoundSimulator) getTeamIndex(match *models.Match, teamName string) int
 	return 0
 }
 
+// aggressionWeight returns how likely a player is to take a duel relative to an
+// average one, from 1-effect for the most passive to 1+effect for the most aggressive
+func (rs *RoundSimulator) aggressionWeight(player *models.Player) float64 {
+	return 1 + rs.simConfig.AggressionEffect*(2*player.Profile.Aggression-1)
+}
+
+// selectDuelist picks the player of a side that takes the next duel, weighted by aggression
+func (rs *RoundSimulator) selectDuelist(players []*models.Player) *models.Player {
+	total := 0.0
+	for _, player := range players {
+		total += math.Max(rs.aggressionWeight(player), minDuelWeight)
+	}
+	
+	roll := rs.rng.Float64() * total
+	for _, player := range players {
+		roll -= math.Max(rs.aggressionWeight(player), minDuelWeight)
+		if roll < 0 {
+			return player
+		}
+	}
+	return players[len(players)-1]
+}
+
+// openingContactTicks returns when the teams first meet, 3-10 seconds into the
+// round at an average pace
+func (rs *RoundSimulator) openingContactTicks(match *models.Match, state *models.MatchState) int64 {
+	return rs.pacedTicks(match, state, float64(3+rs.rng.Intn(8)))
+}
+
+// minPace is the shortest a gap between engagements can be paced, as a share of its length
+const minPace = 0.25
+
+// pacedTicks converts a gap between engagements to ticks, shortened when the players
+// still alive are aggressive and stretched when they are passive
+func (rs *RoundSimulator) pacedTicks(match *models.Match, state *models.MatchState, seconds float64) int64 {
+	alive := append(rs.getAlivePlayers(match, state, "CT"), rs.getAlivePlayers(match, state, "TERRORIST")...)
+	pace := 1.0
+	if len(alive) > 0 {
+		aggression := 0.0
+		for _, player := range alive {
+			aggression += player.Profile.Aggression
+		}
+		pace -= rs.simConfig.AggressionEffect * (aggression/float64(len(alive)) - 0.5)
+	}
+	// Keep time moving forward however aggressive the players are
+	pace = math.Max(pace, minPace)
+	return int64(seconds * pace * float64(rs.config.TickRate))
+}
+
 func (rs *RoundSimulator) getAliveCount(match *models.Match, state *models.MatchState, side string) int {
 	count := 0
 	team := rs.getTeamBySide(match, side)
@@ -646,16 +1346,23 @@ func (rs *RoundSimulator) updateTeamEconomyAfterBuy(team *models.Team, state *mo
 	economy := state.TeamEconomies[team.Name]
 	totalMoney := 0
 	equipmentValue := 0
+	pickedUpValue := 0
+	players := 0
 	
 	for _, player := range team.Players {
 		playerState := state.PlayerStates[player.Name]
+		if playerState == nil {
+			continue
+		}
+		players++
 		totalMoney += playerState.Money
 		equipmentValue += rs.calculateEquipmentValue(playerState)
+		pickedUpValue += playerState.PickedUpValue()
 	}
 	
-	economy.TotalMoney = totalMoney
-	economy.AverageMoney = totalMoney / len(team.Players)
+	economy.SetMoney(totalMoney, players)
 	economy.EquipmentValue = equipmentValue
+	economy.PickedUpValue = pickedUpValue
 }
 
 func (rs *RoundSimulator) calculateEquipmentValue(state *models.PlayerState) int {
@@ -698,7 +1405,7 @@ func (rs *RoundSimulator) selectWeaponForKill(attacker *models.Player, state *mo
 	}
 	
 	// Default weapons based on side
-	if attacker.Side == "CT" {
+	if models.NormalizeSide(attacker.Side) == "CT" {
 		return "usp_silencer"
 	}
 	return "glock"
@@ -732,12 +1439,18 @@ func (rs *RoundSimulator) getHeadshotProbability(attacker *models.Player, weapon
 
 func (rs *RoundSimulator) getSpawnPosition(side string, playerIndex int) models.Vector3 {
 	baseX := float64(playerIndex * 100)
-	if side == "CT" {
+	if models.NormalizeSide(side) == "CT" {
 		return models.Vector3{X: baseX, Y: 0, Z: 0}
 	}
 	return models.Vector3{X: baseX, Y: 1000, Z: 0}
 }
 
+// selectBombSite picks one of the bomb sites available on the map
+func (rs *RoundSimulator) selectBombSite(mapName string) string {
+	sites := models.GetBombSites(mapName)
+	return sites[rs.rng.Intn(len(sites))]
+}
+
 func (rs *RoundSimulator) getBombSitePosition(site string) models.Vector3 {
 	if site == "A" {
 		return models.Vector3{X: 500, Y: 500, Z: 0}
@@ -0,0 +1,841 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/noueii/nocs-log-generator/backend/pkg/models"
+)
+
+// newTestEngine creates a match engine with two full teams for testing
+func newTestEngine(t testing.TB, seed int64) *MatchEngine {
+	t.Helper()
+
+	config := models.DefaultMatchConfig()
+	config.Seed = seed
+
+	teams := make([]models.Team, 2)
+	sides := []string{"CT", "TERRORIST"}
+	for i := range teams {
+		teams[i] = models.Team{
+			Name: fmt.Sprintf("Team%d", i+1),
+			Side: sides[i],
+		}
+		for j := 0; j < 5; j++ {
+			teams[i].Players = append(teams[i].Players, models.Player{
+				Name:    fmt.Sprintf("Player%d_%d", i+1, j+1),
+				SteamID: fmt.Sprintf("STEAM_1:0:%d", (i*5)+j+1),
+				UserID:  (i * 5) + j + 1,
+				Team:    teams[i].Name,
+				Side:    sides[i],
+				Profile: models.DefaultPlayerProfile(),
+			})
+		}
+	}
+
+	match := models.NewMatch(config, teams)
+	return NewMatchEngine(&match.Config, match)
+}
+
+// lastEventTick returns the highest tick among the given events
+func lastEventTick(events []models.GameEvent) int64 {
+	var last int64
+	for _, event := range events {
+		if event.GetTick() > last {
+			last = event.GetTick()
+		}
+	}
+	return last
+}
+
+func TestRoundSimulator_RoundDurations(t *testing.T) {
+	roundTime := 115 * time.Second
+	maxDuration := roundTime + 40*time.Second + 10*time.Second
+
+	simulators := map[string]func(rs *RoundSimulator, e *MatchEngine) (*RoundResult, []models.GameEvent, error){
+		"bomb_scenario": func(rs *RoundSimulator, e *MatchEngine) (*RoundResult, []models.GameEvent, error) {
+			return rs.simulateBombRound(e.match, e.state, 1, &RoundStrategy{Intensity: 0.5})
+		},
+		"elimination": func(rs *RoundSimulator, e *MatchEngine) (*RoundResult, []models.GameEvent, error) {
+			return rs.simulateEliminationRound(e.match, e.state, 1, &RoundStrategy{Intensity: 0.5})
+		},
+		"timeout": func(rs *RoundSimulator, e *MatchEngine) (*RoundResult, []models.GameEvent, error) {
+			return rs.simulateTimeoutRound(e.match, e.state, 1, &RoundStrategy{Intensity: 0.5})
+		},
+	}
+
+	for roundType, simulate := range simulators {
+		t.Run(roundType, func(t *testing.T) {
+			for seed := int64(1); seed <= 20; seed++ {
+				engine := newTestEngine(t, seed)
+				rs := engine.roundSimulator
+
+				result, events, err := simulate(rs, engine)
+				if err != nil {
+					t.Fatalf("seed %d: unexpected error: %v", seed, err)
+				}
+
+				if result.Duration <= 0 || result.Duration > maxDuration {
+					t.Errorf("seed %d: duration %v out of range (0, %v]", seed, result.Duration, maxDuration)
+				}
+
+				if result.Reason == "time" && result.Duration != roundTime {
+					t.Errorf("seed %d: expected timeout duration %v, got %v", seed, roundTime, result.Duration)
+				}
+
+				// The round cannot end before its last event
+				lastEvent := models.TicksToDuration(lastEventTick(events), rs.config.TickRate)
+				if result.Duration < lastEvent {
+					t.Errorf("seed %d: duration %v is shorter than last event at %v", seed, result.Duration, lastEvent)
+				}
+
+				// An elimination ends the round on its last kill
+				var lastKill int64
+				for _, event := range events {
+					if _, ok := event.(*models.KillEvent); ok && event.GetTick() > lastKill {
+						lastKill = event.GetTick()
+					}
+				}
+				if killed := models.TicksToDuration(lastKill, rs.config.TickRate); result.Reason == "elimination" && result.Duration != killed {
+					t.Errorf("seed %d: elimination duration %v, last kill at %v", seed, result.Duration, killed)
+				}
+			}
+		})
+	}
+}
+
+func TestRoundSimulator_PredictRoundOutcome(t *testing.T) {
+	testCases := []struct {
+		name     string
+		round    int
+		ctMoney  int
+		tMoney   int
+		ctSkill  float64
+		tSkill   float64
+		expected float64
+	}{
+		{"even matchup", 1, 4000, 4000, 0.5, 0.5, 0.55},
+		{"CT economy advantage", 1, 6000, 1000, 0.5, 0.5, 0.9110},
+		{"T economy advantage", 1, 1000, 6000, 0.5, 0.5, 0.1890},
+		{"CT skill advantage", 1, 4000, 4000, 0.9, 0.1, 0.8602},
+		{"second half timeout bias", 16, 4000, 4000, 0.5, 0.5, 0.575},
+		{"clamped duel odds", 1, 16000, 0, 1.0, 0.0, 0.9992},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			engine := newTestEngine(t, 1)
+			ctTeam := engine.roundSimulator.getTeamBySide(engine.match, "CT")
+			tTeam := engine.roundSimulator.getTeamBySide(engine.match, "TERRORIST")
+
+			for i := range ctTeam.Players {
+				ctTeam.Players[i].Profile.AimSkill = tc.ctSkill
+			}
+			for i := range tTeam.Players {
+				tTeam.Players[i].Profile.AimSkill = tc.tSkill
+			}
+			engine.state.CurrentRound = tc.round
+			engine.state.TeamEconomies[ctTeam.Name].AverageMoney = tc.ctMoney
+			engine.state.TeamEconomies[tTeam.Name].AverageMoney = tc.tMoney
+
+			ctWinProb, details := engine.roundSimulator.PredictRoundOutcome(engine.match, engine.state)
+			if math.Abs(ctWinProb-tc.expected) > 1e-4 {
+				t.Errorf("Expected CT win probability %.4f, got %.4f", tc.expected, ctWinProb)
+			}
+			if details["ct_win_probability"] != ctWinProb {
+				t.Errorf("Expected details to report CT win probability %.4f, got %.4f", ctWinProb, details["ct_win_probability"])
+			}
+		})
+	}
+}
+
+func TestEliminationProbability(t *testing.T) {
+	testCases := []struct {
+		p              float64
+		own, opponents int
+		expected       float64
+	}{
+		{0.5, 5, 5, 0.5},
+		{0.7, 5, 5, 0.9012},
+		{0.5, 1, 1, 0.5},
+		{0.5, 5, 1, 0.96875},
+		{0.5, 1, 5, 0.03125},
+		{0.6, 0, 5, 0},
+	}
+
+	for _, tc := range testCases {
+		got := eliminationProbability(tc.p, tc.own, tc.opponents)
+		if math.Abs(got-tc.expected) > 1e-4 {
+			t.Errorf("%.1f with %d against %d: expected %.4f, got %.4f", tc.p, tc.own, tc.opponents, tc.expected, got)
+		}
+		// One of the sides is always eliminated first
+		if other := eliminationProbability(1-tc.p, tc.opponents, tc.own); tc.own > 0 && tc.opponents > 0 && math.Abs(got+other-1) > 1e-9 {
+			t.Errorf("%.1f with %d against %d: expected both sides' chances to add up to 1, got %.4f and %.4f", tc.p, tc.own, tc.opponents, got, other)
+		}
+	}
+}
+
+// TestRoundSimulator_PredictionMatchesSimulatedRounds plays many rounds from the same
+// state and compares how often the CTs win to the prediction for that state
+func TestRoundSimulator_PredictionMatchesSimulatedRounds(t *testing.T) {
+	testCases := []struct {
+		name            string
+		round           int
+		ctMoney, tMoney int
+		ctSkill, tSkill float64
+	}{
+		{"even matchup", 4, 4000, 4000, 0.5, 0.5},
+		{"CT full buy against an eco", 4, 6000, 1000, 0.5, 0.5},
+		{"T skill advantage", 4, 4000, 4000, 0.2, 0.8},
+		{"second half", 16, 3000, 5000, 0.6, 0.4},
+	}
+	const rounds = 1000
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			predicted := -1.0
+			ctWins := 0
+			for seed := int64(1); seed <= rounds; seed++ {
+				engine := newTestEngine(t, seed)
+				rs := engine.roundSimulator
+				for _, team := range engine.match.Teams {
+					money, skill := tc.ctMoney, tc.ctSkill
+					if team.Side == "TERRORIST" {
+						money, skill = tc.tMoney, tc.tSkill
+					}
+					for i := range team.Players {
+						team.Players[i].Profile.AimSkill = skill
+						engine.state.PlayerStates[team.Players[i].Name].Money = money
+					}
+				}
+				engine.state.CurrentRound = tc.round
+				rs.decideBuyTypes(engine.match, engine.state, tc.round)
+
+				if predicted < 0 {
+					predicted, _ = rs.PredictRoundOutcome(engine.match, engine.state)
+				}
+				result, _, err := rs.SimulateRound(engine.match, engine.state, tc.round)
+				if err != nil {
+					t.Fatalf("SimulateRound failed: %v", err)
+				}
+				if result.Winner == "CT" {
+					ctWins++
+				}
+			}
+
+			// Allow about three standard deviations of the observed rate
+			observed := float64(ctWins) / rounds
+			if tolerance := 3 * math.Sqrt(predicted*(1-predicted)/rounds); math.Abs(observed-predicted) > tolerance {
+				t.Errorf("Predicted a CT win probability of %.3f, CTs won %.3f of %d rounds", predicted, observed, rounds)
+			}
+		})
+	}
+}
+
+func TestRoundSimulator_CanDefuse(t *testing.T) {
+	engine := newTestEngine(t, 1)
+	rs := engine.roundSimulator
+	tickRate := int64(rs.config.TickRate)
+
+	testCases := []struct {
+		name          string
+		secondsLeft   int64
+		hasKit        bool
+		expectDefused bool
+	}{
+		{"kit with 6 seconds left", 6, true, true},
+		{"no kit with 6 seconds left", 6, false, false},
+		{"kit with exactly 5 seconds left", 5, true, true},
+		{"no kit with 10 seconds left", 10, false, true},
+		{"kit with 4 seconds left", 4, true, false},
+		{"no time left", 0, true, false},
+	}
+
+	for _, tc := range testCases {
+		if result := rs.canDefuse(tc.secondsLeft*tickRate, tc.hasKit); result != tc.expectDefused {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expectDefused, result)
+		}
+	}
+}
+
+func TestRoundSimulator_PostPlantUsesServerConfig(t *testing.T) {
+	serverConfig := models.DefaultServerConfig()
+	serverConfig.BombTimer = 30
+	serverConfig.DefuseTime = 3
+	serverConfig.DefuseTimeNoKit = 6
+
+	for seed := int64(1); seed <= 20; seed++ {
+		engine := newTestEngine(t, seed)
+		engine.SetServerConfig(&serverConfig)
+		rs := engine.roundSimulator
+		tickRate := int64(rs.config.TickRate)
+
+		plantTick := int64(20) * tickRate
+		result, events, err := rs.simulatePostPlant(engine.match, engine.state, 1, plantTick, "A", nil, &RoundStrategy{Intensity: 0.5})
+		if err != nil {
+			t.Fatalf("seed %d: unexpected error: %v", seed, err)
+		}
+
+		explodeTick := plantTick + int64(serverConfig.BombTimer)*tickRate
+		for _, event := range events {
+			switch e := event.(type) {
+			case *models.BombExplodeEvent:
+				if e.Tick != explodeTick {
+					t.Errorf("seed %d: expected explosion at tick %d, got %d", seed, explodeTick, e.Tick)
+				}
+			case *models.BombDefuseEvent:
+				if e.Tick > explodeTick {
+					t.Errorf("seed %d: defuse at tick %d finished after explosion at %d", seed, e.Tick, explodeTick)
+				}
+			}
+		}
+
+		if result.Reason == "bomb_exploded" && result.Duration != models.TicksToDuration(explodeTick, rs.config.TickRate) {
+			t.Errorf("seed %d: expected explosion duration %v, got %v", seed, models.TicksToDuration(explodeTick, rs.config.TickRate), result.Duration)
+		}
+	}
+}
+
+func TestRoundSimulator_TimeExpiredResult(t *testing.T) {
+	rs := newTestEngine(t, 1).roundSimulator
+	tickRate := rs.config.TickRate
+	roundEndTick := rs.roundTimeTicks()
+
+	testCases := []struct {
+		name       string
+		bomb       *plantedBomb
+		winner     string
+		reason     string
+		endTick    int64
+		explosions int
+	}{
+		{name: "no plant", bomb: nil, winner: "CT", reason: "time", endTick: roundEndTick},
+		{name: "planted", bomb: &plantedBomb{site: "B", explodeTick: roundEndTick + 640}, winner: "TERRORIST", reason: "bomb_exploded", endTick: roundEndTick + 640, explosions: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, events := rs.timeExpiredResult(tc.bomb, roundEndTick, 3, nil)
+			if result.Winner != tc.winner || result.Reason != tc.reason {
+				t.Errorf("Expected %s win by %s, got %s win by %s", tc.winner, tc.reason, result.Winner, result.Reason)
+			}
+			if result.Duration != models.TicksToDuration(tc.endTick, tickRate) {
+				t.Errorf("Expected duration %v, got %v", models.TicksToDuration(tc.endTick, tickRate), result.Duration)
+			}
+			if len(events) != tc.explosions {
+				t.Fatalf("Expected %d explosion events, got %d", tc.explosions, len(events))
+			}
+			if tc.explosions > 0 {
+				explode, ok := events[0].(*models.BombExplodeEvent)
+				if !ok || explode.Tick != tc.endTick || explode.Site != tc.bomb.site {
+					t.Errorf("Expected explosion at site %s tick %d, got %+v", tc.bomb.site, tc.endTick, events[0])
+				}
+			}
+		})
+	}
+}
+
+func TestRoundSimulator_PostPlantWithoutDefuseReachesExplosion(t *testing.T) {
+	testCases := []struct {
+		name  string
+		setup func(engine *MatchEngine, serverConfig *models.ServerConfig)
+	}{
+		{
+			name: "no CTs left",
+			setup: func(engine *MatchEngine, serverConfig *models.ServerConfig) {
+				for _, player := range engine.getTeamBySide("CT").Players {
+					engine.state.PlayerStates[player.Name].IsAlive = false
+				}
+			},
+		},
+		{
+			name: "defuse cannot finish",
+			setup: func(engine *MatchEngine, serverConfig *models.ServerConfig) {
+				serverConfig.DefuseTime = serverConfig.BombTimer + 1
+				serverConfig.DefuseTimeNoKit = serverConfig.BombTimer + 1
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for seed := int64(1); seed <= 10; seed++ {
+				engine := newTestEngine(t, seed)
+				serverConfig := models.DefaultServerConfig()
+				tc.setup(engine, &serverConfig)
+				engine.SetServerConfig(&serverConfig)
+				rs := engine.roundSimulator
+
+				plantTick := int64(30 * rs.config.TickRate)
+				result, events, err := rs.simulatePostPlant(engine.match, engine.state, 1, plantTick, "A", nil, &RoundStrategy{Intensity: 0.5})
+				if err != nil {
+					t.Fatalf("seed %d: unexpected error: %v", seed, err)
+				}
+
+				if result.Winner != "TERRORIST" || result.Reason != "bomb_exploded" {
+					t.Fatalf("seed %d: expected the bomb to explode, got %s win by %s", seed, result.Winner, result.Reason)
+				}
+				explodeTick := plantTick + int64(serverConfig.BombTimer*rs.config.TickRate)
+				if result.Duration != models.TicksToDuration(explodeTick, rs.config.TickRate) {
+					t.Errorf("seed %d: expected round to end at %v, got %v", seed, models.TicksToDuration(explodeTick, rs.config.TickRate), result.Duration)
+				}
+				last, ok := events[len(events)-1].(*models.BombExplodeEvent)
+				if !ok || last.Tick != explodeTick {
+					t.Errorf("seed %d: expected the round to end with an explosion at tick %d", seed, explodeTick)
+				}
+			}
+		})
+	}
+}
+
+func TestRoundSimulator_RejectsPurchasesAfterBuyWindow(t *testing.T) {
+	engine := newTestEngine(t, 1)
+	rs := engine.roundSimulator
+	player := &engine.match.Teams[0].Players[0]
+	window := rs.buyWindowTicks()
+
+	testCases := []struct {
+		name     string
+		tick     int64
+		verbose  bool
+		accepted bool
+		logged   bool
+	}{
+		{name: "inside window", tick: window, accepted: true},
+		{name: "after window", tick: window + 1},
+		{name: "after window verbose", tick: window + 1, verbose: true, logged: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rs.config.VerboseLogging = tc.verbose
+			playerState := &models.PlayerState{Money: 5000}
+
+			event := rs.buyItem(player, playerState, "ak47", 2700, tc.tick, 2)
+
+			_, purchased := event.(*models.ItemPurchaseEvent)
+			if purchased != tc.accepted {
+				t.Errorf("Expected purchase recorded = %v, got event %T", tc.accepted, event)
+			}
+			if got := playerState.PrimaryWeapon != nil; got != tc.accepted {
+				t.Errorf("Expected weapon equipped = %v", tc.accepted)
+			}
+			wantMoney := 5000
+			if tc.accepted {
+				wantMoney -= 2700
+			}
+			if playerState.Money != wantMoney {
+				t.Errorf("Expected %d money left, got %d", wantMoney, playerState.Money)
+			}
+
+			rejected, logged := event.(*models.PurchaseRejectedEvent)
+			if logged != tc.logged {
+				t.Errorf("Expected rejection logged = %v, got event %T", tc.logged, event)
+			}
+			if logged && !strings.Contains(rejected.ToLogLine(), `cannot purchase "ak47": not in buyzone`) {
+				t.Errorf("Unexpected rejection line %q", rejected.ToLogLine())
+			}
+		})
+	}
+}
+
+func TestRoundSimulator_KillModifierProbabilities(t *testing.T) {
+	for _, probability := range []float64{0, 1} {
+		simConfig := models.DefaultSimulationConfig()
+		simConfig.NoScopeProbability = probability
+		simConfig.InAirKillProbability = probability
+
+		engine := newTestEngine(t, 42)
+		engine.SetSimulationConfig(&simConfig)
+		for _, team := range engine.match.Teams {
+			for i, player := range team.Players {
+				weapon := "ak47"
+				if i%2 == 0 {
+					weapon = "awp"
+				}
+				engine.state.PlayerStates[player.Name].PrimaryWeapon = &models.Weapon{Name: weapon}
+			}
+		}
+
+		for i := 0; i < 50; i++ {
+			for _, state := range engine.state.PlayerStates {
+				state.IsAlive, state.Health = true, 100
+			}
+			kill := engine.roundSimulator.generateKillEvent(engine.match, engine.state, 100, 1).(*models.KillEvent)
+
+			expectNoScope := probability == 1 && kill.Weapon == "awp"
+			if kill.NoScope != expectNoScope {
+				t.Fatalf("p=%v: expected noscope=%v for a %s kill, got %v", probability, expectNoScope, kill.Weapon, kill.NoScope)
+			}
+			if kill.InAir != (probability == 1) {
+				t.Fatalf("p=%v: expected in air=%v, got %v", probability, probability == 1, kill.InAir)
+			}
+		}
+	}
+}
+
+func TestRoundSimulator_KillStreaks(t *testing.T) {
+	engine := newTestEngine(t, 42)
+	engine.config.KillStreaks = true
+	rs := engine.roundSimulator
+	ct := engine.match.Teams[0].Players
+	terrorists := engine.match.Teams[1].Players
+
+	kill := func(tick int64, attacker, victim *models.Player) models.GameEvent {
+		return &models.KillEvent{BaseEvent: models.NewBaseEvent("player_death", tick, 1), Attacker: attacker, Victim: victim}
+	}
+
+	// Four uninterrupted kills by one player
+	var events []models.GameEvent
+	for i := 0; i < 4; i++ {
+		events = append(events, kill(int64(i*64), &ct[0], &terrorists[i]))
+	}
+	highlights := rs.recordKillStreaks(events, 1)
+	if ct[0].Stats.LongestKillStreak != 4 {
+		t.Errorf("Expected a streak of 4, got %d", ct[0].Stats.LongestKillStreak)
+	}
+	if len(highlights) != 2 {
+		t.Fatalf("Expected highlights for the 3rd and 4th kill, got %d", len(highlights))
+	}
+	if line := highlights[1].ToLogLine(); !strings.Contains(line, "Player1_1 is on a 4 kill streak") || highlights[1].GetTick() != 192 {
+		t.Errorf("Unexpected highlight %q at tick %d", line, highlights[1].GetTick())
+	}
+
+	// A death in between resets the streak
+	events = []models.GameEvent{
+		kill(0, &terrorists[0], &ct[0]),
+		kill(64, &terrorists[0], &ct[1]),
+		kill(128, &ct[2], &terrorists[0]),
+		kill(192, &terrorists[0], &ct[3]),
+		kill(256, &terrorists[0], &ct[4]),
+	}
+	if highlights := rs.recordKillStreaks(events, 2); len(highlights) != 0 {
+		t.Errorf("Expected no highlights after a reset streak, got %d", len(highlights))
+	}
+	if terrorists[0].Stats.LongestKillStreak != 2 {
+		t.Errorf("Expected the streak to reset on death, longest is %d", terrorists[0].Stats.LongestKillStreak)
+	}
+
+	// Streaks do not carry over into the next round
+	events = []models.GameEvent{kill(0, &ct[2], &terrorists[1]), kill(64, &ct[2], &terrorists[2])}
+	if highlights := rs.recordKillStreaks(events, 3); len(highlights) != 0 || ct[2].Stats.LongestKillStreak != 2 {
+		t.Errorf("Expected a fresh streak of 2 in a new round, got %d with %d highlights", ct[2].Stats.LongestKillStreak, len(highlights))
+	}
+}
+
+func TestRoundSimulator_BombDropAndPickup(t *testing.T) {
+	replanted := 0
+	for seed := int64(1); seed <= 10; seed++ {
+		engine := newTestEngine(t, seed)
+		if err := engine.GenerateMatch(); err != nil {
+			t.Fatalf("GenerateMatch failed: %v", err)
+		}
+
+		carriers := make(map[int]string)
+		dropped := make(map[int]bool)
+		dead := make(map[int]map[string]bool)
+		for _, event := range engine.match.Events {
+			round := event.GetRound()
+			if dead[round] == nil {
+				dead[round] = make(map[string]bool)
+			}
+			switch e := event.(type) {
+			case *models.KillEvent:
+				dead[round][e.Victim.Name] = true
+			case *models.BombPickupEvent:
+				if _, spawned := carriers[round]; spawned && !dropped[round] {
+					t.Fatalf("Seed %d round %d: %s picked up a bomb that was never dropped", seed, round, e.Player.Name)
+				}
+				if dead[round][e.Player.Name] {
+					t.Fatalf("Seed %d round %d: dead player %s picked up the bomb", seed, round, e.Player.Name)
+				}
+				carriers[round] = e.Player.Name
+				dropped[round] = false
+			case *models.BombDropEvent:
+				if e.Player.Name != carriers[round] || !dead[round][e.Player.Name] {
+					t.Fatalf("Seed %d round %d: %s dropped the bomb, expected the killed carrier %s", seed, round, e.Player.Name, carriers[round])
+				}
+				dropped[round] = true
+			case *models.BombPlantEvent:
+				if dropped[round] || e.Player.Name != carriers[round] {
+					t.Fatalf("Seed %d round %d: %s planted without carrying the bomb (carrier %s)", seed, round, e.Player.Name, carriers[round])
+				}
+			}
+		}
+
+		// Rounds where the spawn carrier died and a teammate planted
+		for _, event := range engine.match.Events {
+			if plant, ok := event.(*models.BombPlantEvent); ok {
+				for _, other := range engine.match.Events {
+					if drop, ok := other.(*models.BombDropEvent); ok && drop.Round == plant.Round {
+						replanted++
+						break
+					}
+				}
+			}
+		}
+	}
+
+	if replanted == 0 {
+		t.Error("Expected some round where a teammate picked up the dropped bomb and planted")
+	}
+}
+
+func TestRoundSimulator_PickUpWeapon(t *testing.T) {
+	engine := newTestEngine(t, 42)
+	rs, state := engine.roundSimulator, engine.state
+	picker, victim := &engine.match.Teams[0].Players[0], &engine.match.Teams[1].Players[0]
+
+	pickerState, victimState := state.PlayerStates[picker.Name], state.PlayerStates[victim.Name]
+	pickerState.PrimaryWeapon = nil
+	rs.applyPurchaseToPlayer(victimState, "awp")
+	money := pickerState.Money
+	valueBefore := rs.calculateEquipmentValue(pickerState)
+
+	rs.itemEvents = nil
+	rs.pickUpWeapon(state, picker, victim, 640, 1)
+
+	if pickerState.PrimaryWeapon == nil || pickerState.PrimaryWeapon.Name != "awp" || !pickerState.PrimaryWeapon.PickedUp {
+		t.Fatalf("Expected %s to hold a picked up AWP, got %+v", picker.Name, pickerState.PrimaryWeapon)
+	}
+	if victimState.PrimaryWeapon != nil {
+		t.Error("Expected the AWP to leave the victim's loadout")
+	}
+	if pickerState.Money != money {
+		t.Errorf("Expected no money to be spent, went from %d to %d", money, pickerState.Money)
+	}
+	if gained := rs.calculateEquipmentValue(pickerState) - valueBefore; gained != 4750 {
+		t.Errorf("Expected the AWP to add 4750 equipment value, got %d", gained)
+	}
+
+	rs.updateTeamEconomyAfterBuy(&engine.match.Teams[0], state)
+	if economy := state.TeamEconomies["Team1"]; economy.PickedUpValue != 4750 || economy.EquipmentValue < 4750 {
+		t.Errorf("Expected the team economy to count a picked up 4750, got %d of %d", economy.PickedUpValue, economy.EquipmentValue)
+	}
+
+	if len(rs.itemEvents) != 1 {
+		t.Fatalf("Expected one pickup event, got %d", len(rs.itemEvents))
+	}
+	pickup, ok := rs.itemEvents[0].(*models.WeaponPickupEvent)
+	if !ok || pickup.Player != picker || pickup.From != victim || pickup.Weapon != "awp" {
+		t.Errorf("Unexpected pickup event %+v", rs.itemEvents[0])
+	}
+
+	// A cheaper gun is not worth swapping the AWP for
+	rs.applyPurchaseToPlayer(victimState, "mp9")
+	rs.pickUpWeapon(state, picker, victim, 700, 1)
+	if pickerState.PrimaryWeapon.Name != "awp" || len(rs.itemEvents) != 1 {
+		t.Error("Expected the AWP to be kept over an MP9")
+	}
+}
+
+func TestRoundSimulator_PositionSampleRate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rate     int
+		expected int
+	}{
+		// A 10 second round at 64 tick: eight players live through it, one dies at
+		// tick 100 and one at tick 320, and only ticks before a death are sampled
+		{"one per second", 1, 8*10 + 2 + 5},
+		{"default rate", 0, 8*40 + 7 + 20},
+		{"four per second", 4, 8*40 + 7 + 20},
+		{"sixteen per second", 16, 8*160 + 25 + 80},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			engine := newTestEngine(t, 42)
+			engine.config.EmitPositions = true
+			engine.config.PositionSampleRate = tc.rate
+			ct, terrorists := engine.match.Teams[0].Players, engine.match.Teams[1].Players
+
+			kills := []models.GameEvent{
+				&models.KillEvent{BaseEvent: models.NewBaseEvent("player_death", 100, 1), Attacker: &terrorists[0], Victim: &ct[0]},
+				&models.KillEvent{BaseEvent: models.NewBaseEvent("player_death", 320, 1), Attacker: &ct[1], Victim: &terrorists[0]},
+			}
+			result := &RoundResult{Winner: "CT", Duration: 10 * time.Second}
+			samples := engine.roundSimulator.samplePositions(engine.match, kills, result, 1)
+
+			if len(samples) != tc.expected {
+				t.Fatalf("Expected %d position samples, got %d", tc.expected, len(samples))
+			}
+			for _, event := range samples {
+				sample, ok := event.(*models.PlayerPositionEvent)
+				if !ok || sample.GetType() != "player_position" {
+					t.Fatalf("Unexpected event %T", event)
+				}
+				if sample.Player.Name == ct[0].Name && sample.Tick >= 100 {
+					t.Errorf("Expected no samples of %s after their death, got tick %d", ct[0].Name, sample.Tick)
+				}
+			}
+		})
+	}
+
+	// Sampling draws no random numbers, so it leaves the match outcome unchanged
+	plain, sampled := newTestEngine(t, 7), newTestEngine(t, 7)
+	sampled.config.EmitPositions = true
+	for _, engine := range []*MatchEngine{plain, sampled} {
+		if err := engine.GenerateMatch(); err != nil {
+			t.Fatalf("GenerateMatch failed: %v", err)
+		}
+	}
+	if len(plain.match.Rounds) != len(sampled.match.Rounds) {
+		t.Fatalf("Expected %d rounds with positions, got %d", len(plain.match.Rounds), len(sampled.match.Rounds))
+	}
+	for i, round := range plain.match.Rounds {
+		if sampled.match.Rounds[i].Winner != round.Winner {
+			t.Errorf("Round %d: expected winner %s with positions, got %s", round.RoundNumber, round.Winner, sampled.match.Rounds[i].Winner)
+		}
+	}
+}
+
+func TestRoundSimulator_AggressionPacing(t *testing.T) {
+	const rounds = 200
+
+	// firstKills simulates rounds with the CTs at the given aggression against
+	// average terrorists, returning the mean first kill tick and how often the
+	// first CT took part in the first kill
+	firstKills := func(aggression float64) (float64, float64) {
+		var totalTick int64
+		entries := 0
+		for seed := int64(1); seed <= rounds; seed++ {
+			engine := newTestEngine(t, seed)
+			for _, team := range engine.match.Teams {
+				for i := range team.Players {
+					team.Players[i].Profile.Aggression = 0.5
+					if team.Side == "CT" {
+						team.Players[i].Profile.Aggression = aggression
+					}
+				}
+			}
+			engine.match.Teams[0].Players[0].Profile.Aggression = 1
+
+			_, events, err := engine.roundSimulator.simulateEliminationRound(engine.match, engine.state, 1, &RoundStrategy{Intensity: 0.5})
+			if err != nil {
+				t.Fatalf("seed %d: unexpected error: %v", seed, err)
+			}
+			for _, event := range events {
+				if kill, ok := event.(*models.KillEvent); ok {
+					totalTick += kill.Tick
+					if kill.Attacker.Name == "Player1_1" || kill.Victim.Name == "Player1_1" {
+						entries++
+					}
+					break
+				}
+			}
+		}
+		return float64(totalTick) / rounds, float64(entries) / rounds
+	}
+
+	aggressiveTick, _ := firstKills(0.9)
+	passiveTick, entryRate := firstKills(0.1)
+	if aggressiveTick >= passiveTick {
+		t.Errorf("Expected aggressive CTs to draw first blood earlier, got mean tick %.0f vs %.0f for passive CTs", aggressiveTick, passiveTick)
+	}
+
+	// Among passive teammates, the aggressive player takes the opening duel more
+	// often than the one in five an even split would give
+	if entryRate < 0.3 {
+		t.Errorf("Expected the aggressive player in most opening duels, got %.2f", entryRate)
+	}
+}
+
+func TestRoundSimulator_PacedTicksStayPositive(t *testing.T) {
+	engine := newTestEngine(t, 42)
+	engine.roundSimulator.simConfig.AggressionEffect = 1
+	// Out of range profiles skipping validation must not turn time backwards
+	for _, team := range engine.match.Teams {
+		for i := range team.Players {
+			team.Players[i].Profile.Aggression = 5
+		}
+	}
+
+	ticks := engine.roundSimulator.pacedTicks(engine.match, engine.state, 2)
+	if minimum := int64(2 * minPace * float64(engine.config.TickRate)); ticks < minimum || ticks <= 0 {
+		t.Errorf("Expected at least %d ticks for a 2 second gap, got %d", minimum, ticks)
+	}
+}
+
+func TestRoundSimulator_LastPlayerTrades(t *testing.T) {
+	// lastDuel leaves one player alive on each side
+	lastDuel := func(seed int64) *MatchEngine {
+		engine := newTestEngine(t, seed)
+		for _, team := range engine.match.Teams {
+			for _, player := range team.Players[1:] {
+				engine.state.PlayerStates[player.Name].IsAlive = false
+			}
+		}
+		return engine
+	}
+
+	t.Run("without a planted bomb", func(t *testing.T) {
+		trades := 0
+		for seed := int64(1); seed <= 100; seed++ {
+			engine := lastDuel(seed)
+			rs := engine.roundSimulator
+			kills, winner := rs.duel(engine.match, engine.state, 640, 1, false)
+			if len(kills) != 2 {
+				continue
+			}
+			trades++
+
+			first, second := kills[0].(*models.KillEvent), kills[1].(*models.KillEvent)
+			if second.Attacker != first.Victim || second.Victim != first.Attacker {
+				t.Fatalf("seed %d: expected the victim to trade their killer, got %s killing %s", seed, second.Attacker.Name, second.Victim.Name)
+			}
+			if second.Tick < first.Tick || second.Tick > first.Tick+rs.tradeWindowTicks() {
+				t.Errorf("seed %d: trade at tick %d outside the window after the kill at %d", seed, second.Tick, first.Tick)
+			}
+			if expected := first.Attacker.Side; winner != expected {
+				t.Errorf("seed %d: expected %s to win by eliminating the other side first, got %q", seed, expected, winner)
+			}
+			if rs.getAliveCount(engine.match, engine.state, "CT") != 0 || rs.getAliveCount(engine.match, engine.state, "TERRORIST") != 0 {
+				t.Errorf("seed %d: expected both players dead after a trade", seed)
+			}
+			if result := rs.eliminationResult(winner, kills); result.Duration != models.TicksToDuration(second.Tick, rs.config.TickRate) {
+				t.Errorf("seed %d: expected the round to end after the trade, got %v", seed, result.Duration)
+			}
+		}
+		if trades == 0 {
+			t.Fatal("Expected some of the last duels to end in a trade")
+		}
+	})
+
+	t.Run("with a planted bomb", func(t *testing.T) {
+		tradedAfterCT := 0
+		for seed := int64(1); seed <= 200; seed++ {
+			engine := lastDuel(seed)
+			rs := engine.roundSimulator
+			plantTick := int64(30 * rs.config.TickRate)
+			result, events, err := rs.simulatePostPlant(engine.match, engine.state, 1, plantTick, "A", nil, &RoundStrategy{Intensity: 0.5})
+			if err != nil {
+				t.Fatalf("seed %d: unexpected error: %v", seed, err)
+			}
+
+			var kills []*models.KillEvent
+			for _, event := range events {
+				if kill, ok := event.(*models.KillEvent); ok {
+					kills = append(kills, kill)
+				}
+			}
+			if len(kills) != 2 {
+				continue
+			}
+
+			// Whoever fell first, nobody is left to defuse
+			if result.Winner != "TERRORIST" || result.Reason != "bomb_exploded" {
+				t.Fatalf("seed %d: expected the bomb to explode after a trade, got %s win by %s", seed, result.Winner, result.Reason)
+			}
+			if kills[1].Victim.Side == "TERRORIST" {
+				tradedAfterCT++
+			}
+		}
+		if tradedAfterCT == 0 {
+			t.Fatal("Expected a last terrorist to die right after the last CT")
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/noueii/nocs-log-generator/backend/pkg/models"
+)
+
+// vetoSeedOffset keeps the veto's bans independent from the map seeds
+const vetoSeedOffset = 0x7e70
+
+// seriesMapBreak is the pause between the end of one map of a series and the start of the next
+const seriesMapBreak = 15 * time.Minute
+
+// GenerateSeries plays a best-of-N series map by map, stopping once a team has won
+// enough maps to clinch it. Each map is generated like a single match, with its own
+// seed derived from the series seed, and starts after the previous one ends. The
+// matches played so far are returned along with any error.
+func (g *MatchGenerator) GenerateSeries(req *models.SeriesRequest) (*models.SeriesResult, []*models.Match, error) {
+	if req == nil {
+		return nil, nil, fmt.Errorf("series request cannot be nil")
+	}
+
+	seed := req.Options.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	// Fill rosters once, so every map is played by the same players
+	if req.Options.AutoRoster && len(req.Maps) > 0 {
+		rosterReq := req.MapRequest(0)
+		rosterReq.Options.Seed = seed
+		FillRosters(&rosterReq)
+	}
+
+	if err := req.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	result := models.NewSeriesResult(req.BestOf, seed, req.Teams)
+	result.Veto = buildVeto(req, seed)
+	pickedBy := make(map[string]string, len(result.Veto))
+	for _, step := range result.Veto {
+		if step.Action == "pick" {
+			pickedBy[step.Map] = step.Team
+		}
+	}
+	mapsToWin := models.MapsToWin(req.BestOf)
+
+	var matches []*models.Match
+	startTime := req.Options.StartTime
+	for i := range req.Maps {
+		mapReq := req.MapRequest(i)
+		mapReq.Teams = cloneTeams(req.Teams)
+		mapReq.Options.Seed = seriesMapSeed(seed, i+1)
+		mapReq.Options.StartTime = startTime
+		mapReq.Options.Veto = result.Veto
+
+		match, err := g.Generate(&mapReq)
+		if err != nil {
+			return result, matches, fmt.Errorf("map %d (%s): %w", i+1, mapReq.Map, err)
+		}
+		matches = append(matches, match)
+
+		winner := match.GetWinningTeam()
+		scores := make(map[string]int, len(match.Scores))
+		for team, score := range match.Scores {
+			scores[team] = score
+		}
+		result.Maps = append(result.Maps, models.SeriesMap{
+			Map:      match.Map,
+			MatchID:  match.ID,
+			PickedBy: pickedBy[match.Map],
+			Scores:   scores,
+			Winner:   winner,
+		})
+
+		// A drawn map counts for neither team
+		if winner != "" {
+			result.Score[winner]++
+			if result.Score[winner] >= mapsToWin {
+				result.Winner = winner
+				break
+			}
+		}
+
+		next := match.EndTime.Add(seriesMapBreak)
+		startTime = &next
+	}
+
+	return result, matches, nil
+}
+
+// buildVeto models the map veto that led to the series' maps. Teams alternate from
+// the first one. Picks are the maps in play order with the last one left over as
+// the decider, and bans are drawn from the rest of a seven map pool made of the
+// series' maps topped up with the active duty maps.
+func buildVeto(req *models.SeriesRequest, seed int64) []models.VetoStep {
+	played := make(map[string]bool, len(req.Maps))
+	for _, mapName := range req.Maps {
+		played[mapName] = true
+	}
+	var bannable []string
+	for _, mapName := range models.ActiveDutyMaps {
+		if len(bannable)+len(req.Maps) == len(models.ActiveDutyMaps) {
+			break
+		}
+		if !played[mapName] {
+			bannable = append(bannable, mapName)
+		}
+	}
+	rng := rand.New(rand.NewSource(seed + vetoSeedOffset))
+	rng.Shuffle(len(bannable), func(i, j int) { bannable[i], bannable[j] = bannable[j], bannable[i] })
+
+	sequence := models.VetoSequence(req.BestOf)
+	veto := make([]models.VetoStep, 0, len(sequence))
+	picks := 0
+	for i, action := range sequence {
+		step := models.VetoStep{Action: action, Team: req.Teams[i%2].Name}
+		switch action {
+		case "ban":
+			step.Map, bannable = bannable[0], bannable[1:]
+		case "pick":
+			step.Map = req.Maps[picks]
+			picks++
+		case "decider":
+			step.Team = ""
+			step.Map = req.Maps[len(req.Maps)-1]
+		}
+		veto = append(veto, step)
+	}
+	return veto
+}
+
+// seriesMapSeed derives the positive seed a map of a series is generated with,
+// hashing the series seed with the map number like round seeds
+func seriesMapSeed(seriesSeed int64, mapNumber int) int64 {
+	seed := roundSeed(seriesSeed, mapNumber) & math.MaxInt64
+	if seed == 0 {
+		return 1
+	}
+	return seed
+}
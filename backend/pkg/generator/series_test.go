@@ -0,0 +1,174 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/noueii/nocs-log-generator/backend/pkg/models"
+)
+
+func newTestSeriesRequest(seed int64) *models.SeriesRequest {
+	return &models.SeriesRequest{
+		Teams: []models.Team{
+			{Name: "Alpha", Players: GenerateRoster("Alpha", 5, 1)},
+			{Name: "Bravo", Players: GenerateRoster("Bravo", 5, 2)},
+		},
+		Maps:    []string{"de_mirage", "de_inferno", "de_nuke"},
+		BestOf:  3,
+		Format:  "mr12",
+		Options: models.MatchOptions{Seed: seed, MaxRounds: 6},
+	}
+}
+
+func TestMatchGenerator_GenerateSeriesStopsWhenClinched(t *testing.T) {
+	sweeps := 0
+	for seed := int64(1); seed <= 10; seed++ {
+		result, matches, err := NewMatchGenerator().GenerateSeries(newTestSeriesRequest(seed))
+		if err != nil {
+			t.Fatalf("Seed %d: GenerateSeries failed: %v", seed, err)
+		}
+		if len(matches) != len(result.Maps) {
+			t.Fatalf("Seed %d: expected a match per map played, got %d matches for %d maps", seed, len(matches), len(result.Maps))
+		}
+
+		wins := map[string]int{}
+		for i, played := range result.Maps {
+			if played.MatchID != matches[i].ID || played.Map != newTestSeriesRequest(seed).Maps[i] {
+				t.Errorf("Seed %d: map %d doesn't match its match", seed, i+1)
+			}
+			if i > 0 && !matches[i].StartTime.After(matches[i-1].EndTime) {
+				t.Errorf("Seed %d: map %d starts before map %d ends", seed, i+1, i)
+			}
+			for _, count := range wins {
+				if count >= 2 {
+					t.Fatalf("Seed %d: map %d played after the series was clinched", seed, i+1)
+				}
+			}
+			if played.Winner != "" {
+				wins[played.Winner]++
+			}
+		}
+
+		if result.Winner == "" {
+			continue
+		}
+		if wins[result.Winner] != 2 || result.Score[result.Winner] != 2 {
+			t.Errorf("Seed %d: expected winner %s to have won 2 maps, got %d", seed, result.Winner, wins[result.Winner])
+		}
+		if last := result.Maps[len(result.Maps)-1]; last.Winner != result.Winner {
+			t.Errorf("Seed %d: expected the last map to clinch the series, won by %q", seed, last.Winner)
+		}
+		if len(result.Maps) == 2 {
+			sweeps++
+		}
+	}
+
+	if sweeps == 0 {
+		t.Error("Expected some 2-0 series to skip the third map")
+	}
+}
+
+func TestMatchGenerator_GenerateSeriesIsReproducible(t *testing.T) {
+	first, _, err := NewMatchGenerator().GenerateSeries(newTestSeriesRequest(7))
+	if err != nil {
+		t.Fatalf("GenerateSeries failed: %v", err)
+	}
+	second, _, err := NewMatchGenerator().GenerateSeries(newTestSeriesRequest(7))
+	if err != nil {
+		t.Fatalf("GenerateSeries failed: %v", err)
+	}
+
+	if len(first.Maps) != len(second.Maps) || first.Winner != second.Winner {
+		t.Fatalf("Expected the same series for the same seed, got %+v and %+v", first, second)
+	}
+	for i := range first.Maps {
+		if first.Maps[i].Winner != second.Maps[i].Winner {
+			t.Errorf("Map %d: expected winner %q, got %q", i+1, first.Maps[i].Winner, second.Maps[i].Winner)
+		}
+	}
+}
+
+func TestMatchGenerator_GenerateSeriesValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*models.SeriesRequest)
+		want   string
+	}{
+		{"unsupported format", func(r *models.SeriesRequest) { r.BestOf = 2; r.Maps = r.Maps[:2] }, "invalid series format"},
+		{"too few maps", func(r *models.SeriesRequest) { r.Maps = r.Maps[:2] }, "needs 3 maps"},
+		{"unknown map", func(r *models.SeriesRequest) { r.Maps[2] = "de_nowhere" }, "unknown map"},
+		{"repeated map", func(r *models.SeriesRequest) { r.Maps[2] = "de_mirage" }, "more than once"},
+		{"invalid options", func(r *models.SeriesRequest) { r.Options.TickRate = 32 }, "tick rate"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := newTestSeriesRequest(1)
+			tc.modify(req)
+			result, matches, err := NewMatchGenerator().GenerateSeries(req)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("Expected an error containing %q, got %v", tc.want, err)
+			}
+			if result != nil || len(matches) != 0 {
+				t.Errorf("Expected no maps to be played for an invalid request")
+			}
+		})
+	}
+}
+
+func TestMatchGenerator_GenerateSeriesVeto(t *testing.T) {
+	tests := []struct {
+		bestOf int
+		maps   []string
+		bans   int
+		picks  int
+	}{
+		{1, []string{"de_dust2"}, 6, 0},
+		{3, []string{"de_mirage", "de_inferno", "de_cache"}, 4, 2},
+		{5, []string{"de_mirage", "de_inferno", "de_nuke", "de_ancient", "de_anubis"}, 2, 4},
+	}
+
+	for _, tc := range tests {
+		req := newTestSeriesRequest(5)
+		req.BestOf, req.Maps = tc.bestOf, tc.maps
+		result, matches, err := NewMatchGenerator().GenerateSeries(req)
+		if err != nil {
+			t.Fatalf("Bo%d: GenerateSeries failed: %v", tc.bestOf, err)
+		}
+
+		if len(result.Veto) != len(models.VetoSequence(tc.bestOf)) {
+			t.Fatalf("Bo%d: expected %d veto steps, got %d", tc.bestOf, len(models.VetoSequence(tc.bestOf)), len(result.Veto))
+		}
+		counts := map[string]int{}
+		var picked []string
+		for i, step := range result.Veto {
+			counts[step.Action]++
+			if step.Action == "pick" {
+				picked = append(picked, step.Map)
+			}
+			if step.Action != "decider" && step.Team != req.Teams[i%2].Name {
+				t.Errorf("Bo%d: expected step %d by %s, got %s", tc.bestOf, i+1, req.Teams[i%2].Name, step.Team)
+			}
+		}
+		if counts["ban"] != tc.bans || counts["pick"] != tc.picks || counts["decider"] != 1 {
+			t.Errorf("Bo%d: expected %d bans, %d picks and a decider, got %v", tc.bestOf, tc.bans, tc.picks, counts)
+		}
+		if last := result.Veto[len(result.Veto)-1]; last.Action != "decider" || last.Map != tc.maps[len(tc.maps)-1] {
+			t.Errorf("Bo%d: expected %s as the decider, got %+v", tc.bestOf, tc.maps[len(tc.maps)-1], last)
+		}
+		for i, mapName := range picked {
+			if mapName != tc.maps[i] {
+				t.Errorf("Bo%d: expected pick %d to be %s, got %s", tc.bestOf, i+1, tc.maps[i], mapName)
+			}
+		}
+		if err := models.ValidateVeto(result.Veto); err != nil {
+			t.Errorf("Bo%d: invalid veto: %v", tc.bestOf, err)
+		}
+
+		for _, match := range matches {
+			if len(match.Config.Veto) != len(result.Veto) {
+				t.Errorf("Bo%d: expected the veto in the config of %s", tc.bestOf, match.Map)
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/noueii/nocs-log-generator/backend/pkg/models"
+)
+
+// statTrakProbability is the chance that a skinned weapon is a StatTrak variant
+const statTrakProbability = 0.25
+
+// skinSeedOffset keeps the skin sequence independent from the simulation RNG
+const skinSeedOffset = 0x5eed5c1
+
+// weaponSkins lists finishes per weapon; other weapons draw from genericSkins
+var weaponSkins = map[string][]string{
+	"ak47":          {"Redline", "Vulcan", "Case Hardened", "Fire Serpent", "Asiimov", "Neon Rider"},
+	"m4a1":          {"Howl", "Asiimov", "Desolate Space", "Buzz Kill", "The Emperor"},
+	"m4a1_silencer": {"Hyper Beast", "Printstream", "Golden Coil", "Decimator", "Nightmare"},
+	"awp":           {"Dragon Lore", "Asiimov", "Hyper Beast", "Lightning Strike", "Wildfire"},
+	"deagle":        {"Blaze", "Code Red", "Printstream", "Kumicho Dragon"},
+	"usp_silencer":  {"Kill Confirmed", "Orion", "Neo-Noir", "Cortex"},
+	"glock":         {"Fade", "Water Elemental", "Vogue", "Bullet Queen"},
+	"ump45":         {"Primal Saber", "Momentum", "Arctic Wolf"},
+}
+
+// genericSkins are finishes available on every weapon
+var genericSkins = []string{"Safari Mesh", "Forest DDPAT", "Boreal Forest", "Urban Masked", "Night Stripe"}
+
+// SkinAssigner gives weapons seeded cosmetic skins and tracks StatTrak kill counts.
+// It uses its own RNG so enabling skins never changes match outcomes.
+type SkinAssigner struct {
+	rng *rand.Rand
+}
+
+// NewSkinAssigner creates a skin assigner seeded from the match seed
+func NewSkinAssigner(seed int64) *SkinAssigner {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &SkinAssigner{rng: rand.New(rand.NewSource(seed + skinSeedOffset))}
+}
+
+// AssignSkins skins every weapon held by the players that does not have a skin yet.
+// Players are visited in roster order so the same seed always yields the same skins.
+func (sa *SkinAssigner) AssignSkins(match *models.Match, state *models.MatchState) {
+	for _, team := range match.Teams {
+		for _, player := range team.Players {
+			playerState, ok := state.PlayerStates[player.Name]
+			if !ok {
+				continue
+			}
+			sa.assignSkin(playerState.PrimaryWeapon)
+			sa.assignSkin(playerState.SecondaryWeapon)
+		}
+	}
+}
+
+// RecordKill annotates a kill with the attacker's weapon skin and counts it on StatTrak weapons
+func (sa *SkinAssigner) RecordKill(kill *models.KillEvent, attackerState *models.PlayerState) {
+	weapon := heldWeapon(attackerState, kill.Weapon)
+	if weapon == nil || weapon.Skin == "" {
+		return
+	}
+
+	kill.WeaponSkin = weapon.Skin
+	if weapon.StatTrak {
+		weapon.StatTrakKills++
+		kill.StatTrakKills = weapon.StatTrakKills
+	}
+}
+
+// assignSkin picks a finish for a weapon that is not skinned yet
+func (sa *SkinAssigner) assignSkin(weapon *models.Weapon) {
+	if weapon == nil || weapon.Skin != "" {
+		return
+	}
+
+	skins := weaponSkins[weapon.Name]
+	if len(skins) == 0 {
+		skins = genericSkins
+	}
+	weapon.Skin = skins[sa.rng.Intn(len(skins))]
+	weapon.StatTrak = sa.rng.Float64() < statTrakProbability
+}
+
+// heldWeapon returns the player's weapon with the given name, if they hold it
+func heldWeapon(state *models.PlayerState, name string) *models.Weapon {
+	if state == nil {
+		return nil
+	}
+	if state.PrimaryWeapon != nil && state.PrimaryWeapon.Name == name {
+		return state.PrimaryWeapon
+	}
+	if state.SecondaryWeapon != nil && state.SecondaryWeapon.Name == name {
+		return state.SecondaryWeapon
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/noueii/nocs-log-generator/backend/pkg/models"
+)
+
+func TestSkinAssigner_CosmeticOnly(t *testing.T) {
+	plain := newTestEngine(t, 42)
+	skinned := newTestEngine(t, 42)
+	skinned.roundSimulator.skins = NewSkinAssigner(42)
+
+	for _, engine := range []*MatchEngine{plain, skinned} {
+		if err := engine.GenerateMatch(); err != nil {
+			t.Fatalf("GenerateMatch failed: %v", err)
+		}
+	}
+
+	if len(plain.match.Rounds) != len(skinned.match.Rounds) {
+		t.Fatalf("Expected %d rounds with skins, got %d", len(plain.match.Rounds), len(skinned.match.Rounds))
+	}
+	for i := range plain.match.Rounds {
+		if plain.match.Rounds[i].Winner != skinned.match.Rounds[i].Winner {
+			t.Fatalf("Round %d winner changed when skins were enabled", i+1)
+		}
+	}
+
+	skinnedKills, statTrakKills := 0, 0
+	for _, event := range plain.match.Events {
+		if kill, ok := event.(*models.KillEvent); ok && (kill.WeaponSkin != "" || kill.StatTrakKills != 0) {
+			t.Fatal("Expected no skin annotations when skins are disabled")
+		}
+	}
+	for _, event := range skinned.match.Events {
+		kill, ok := event.(*models.KillEvent)
+		if !ok || kill.WeaponSkin == "" {
+			continue
+		}
+		skinnedKills++
+		if kill.StatTrakKills > 0 {
+			statTrakKills++
+		}
+	}
+	if skinnedKills == 0 {
+		t.Error("Expected some kills to carry a weapon skin")
+	}
+	if statTrakKills == 0 {
+		t.Error("Expected some kills to increment a StatTrak counter")
+	}
+}
+
+func TestSkinAssigner_RecordKill(t *testing.T) {
+	weapon := &models.Weapon{Name: "ak47", Skin: "Redline", StatTrak: true}
+	state := &models.PlayerState{PrimaryWeapon: weapon}
+	assigner := NewSkinAssigner(1)
+
+	for i := 1; i <= 3; i++ {
+		kill := &models.KillEvent{Weapon: "ak47"}
+		assigner.RecordKill(kill, state)
+		if kill.WeaponSkin != "Redline" || kill.StatTrakKills != i {
+			t.Fatalf("Kill %d: expected Redline with %d StatTrak kills, got %q with %d", i, i, kill.WeaponSkin, kill.StatTrakKills)
+		}
+	}
+
+	// Kills with a weapon the player does not hold are not annotated
+	kill := &models.KillEvent{Weapon: "knife"}
+	assigner.RecordKill(kill, state)
+	if kill.WeaponSkin != "" || kill.StatTrakKills != 0 {
+		t.Errorf("Expected no annotation for an unheld weapon, got %q with %d", kill.WeaponSkin, kill.StatTrakKills)
+	}
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+)
+
+// weaponStat is the per-weapon aggregate both benchmarks compute
+type weaponStat struct {
+	kills, headshots, damage int
+}
+
+// columnarEvents builds n events cycling through kills, hurts and events without a weapon
+func columnarEvents(n int) []GameEvent {
+	weapons := []string{"ak47", "m4a1_silencer", "awp", "glock", "deagle"}
+	players := make([]*Player, 10)
+	for i := range players {
+		players[i] = &Player{Name: fmt.Sprintf("Player%d", i+1), UserID: i + 1}
+	}
+
+	events := make([]GameEvent, n)
+	for i := range events {
+		base := BaseEvent{Tick: int64(i), Round: i/2000 + 1}
+		attacker, victim := players[i%10], players[(i+5)%10]
+		switch i % 4 {
+		case 0:
+			base.Type = "player_death"
+			events[i] = &KillEvent{BaseEvent: base, Attacker: attacker, Victim: victim, Weapon: weapons[i%5], Headshot: i%3 == 0, Hitgroup: 1}
+		case 1, 2:
+			base.Type = "player_hurt"
+			events[i] = &PlayerHurtEvent{BaseEvent: base, Attacker: attacker, Victim: victim, Weapon: weapons[i%5], Damage: 20 + i%30, Hitgroup: 2}
+		default:
+			base.Type = "round_start"
+			events[i] = &RoundStartEvent{BaseEvent: base}
+		}
+	}
+	return events
+}
+
+// weaponStatsInterface aggregates weapon stats by type-switching over events
+func weaponStatsInterface(events []GameEvent) map[string]weaponStat {
+	stats := make(map[string]weaponStat)
+	for _, event := range events {
+		switch e := event.(type) {
+		case *KillEvent:
+			stat := stats[e.Weapon]
+			stat.kills++
+			if e.Headshot {
+				stat.headshots++
+			}
+			stats[e.Weapon] = stat
+		case *PlayerHurtEvent:
+			stat := stats[e.Weapon]
+			stat.damage += e.Damage
+			stats[e.Weapon] = stat
+		}
+	}
+	return stats
+}
+
+// weaponStatsColumnar aggregates the same stats over the weapon columns
+func weaponStatsColumnar(c *ColumnarMatch) map[string]weaponStat {
+	byCode := make([]weaponStat, len(c.WeaponNames))
+	for i, weapon := range c.Weapons {
+		if weapon == 0 {
+			continue
+		}
+		if c.Kills[i] {
+			byCode[weapon].kills++
+			if c.Headshots[i] {
+				byCode[weapon].headshots++
+			}
+		}
+		byCode[weapon].damage += int(c.Damage[i])
+	}
+
+	stats := make(map[string]weaponStat, len(byCode))
+	for code, stat := range byCode[1:] {
+		stats[c.WeaponNames[code+1]] = stat
+	}
+	return stats
+}
+
+func TestNewColumnarMatch(t *testing.T) {
+	events := columnarEvents(400)
+	c := NewColumnarMatch(events)
+
+	if c.Len() != len(events) {
+		t.Fatalf("Expected %d rows, got %d", len(events), c.Len())
+	}
+	for i, event := range events {
+		if c.Ticks[i] != event.GetTick() || int(c.Rounds[i]) != event.GetRound() || c.Type(i) != event.GetType() {
+			t.Fatalf("Row %d: expected tick %d round %d type %s, got %d %d %s",
+				i, event.GetTick(), event.GetRound(), event.GetType(), c.Ticks[i], c.Rounds[i], c.Type(i))
+		}
+		switch e := event.(type) {
+		case *KillEvent:
+			if !c.Kills[i] || c.Weapon(i) != e.Weapon || c.Attackers[i] != int32(e.Attacker.UserID) || c.Victims[i] != int32(e.Victim.UserID) {
+				t.Errorf("Row %d: kill columns don't match the event", i)
+			}
+		case *PlayerHurtEvent:
+			if c.Kills[i] || c.Weapon(i) != e.Weapon || int(c.Damage[i]) != e.Damage {
+				t.Errorf("Row %d: hurt columns don't match the event", i)
+			}
+		default:
+			if c.Weapon(i) != "" || c.Attackers[i] != 0 {
+				t.Errorf("Row %d: expected no combat columns for %s", i, event.GetType())
+			}
+		}
+	}
+
+	interfaceStats, columnarStats := weaponStatsInterface(events), weaponStatsColumnar(c)
+	if fmt.Sprint(interfaceStats) != fmt.Sprint(columnarStats) {
+		t.Errorf("Expected the same weapon stats from both forms, got %v and %v", interfaceStats, columnarStats)
+	}
+}
+
+func BenchmarkWeaponStats_Interface(b *testing.B) {
+	events := columnarEvents(200000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		weaponStatsInterface(events)
+	}
+}
+
+func BenchmarkWeaponStats_Columnar(b *testing.B) {
+	c := NewColumnarMatch(columnarEvents(200000))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		weaponStatsColumnar(c)
+	}
+}
@@ -1,10 +1,16 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 // MatchConfig represents the configuration for a match
@@ -18,10 +24,21 @@ type MatchConfig struct {
 	// Server settings
 	TickRate     int    `json:"tick_rate"`
 	ServerName   string `json:"server_name,omitempty"`
+	ExtraCvars   map[string]string `json:"extra_cvars,omitempty"` // Additional server_cvar lines in the log header, e.g. sv_cheats
+	
+	// Tournament metadata written to the log header
+	TournamentName string `json:"tournament_name,omitempty"`
+	MatchTitle     string `json:"match_title,omitempty"`
+	Veto           []VetoStep `json:"veto,omitempty"` // Map veto the match was picked in, logged before the map loads
 	
 	// Simulation settings
 	Seed         int64  `json:"seed,omitempty"`
 	Duration     time.Duration `json:"duration,omitempty"`
+	StartTime    *time.Time `json:"start_time,omitempty"` // Fixed match start for reproducible timestamps, default: generation time
+	AutoAssignedSides bool `json:"auto_assigned_sides,omitempty"` // Starting sides were picked by a seeded coin flip
+	SeedPerRound bool   `json:"seed_per_round,omitempty"` // Reseed the RNG from the match seed and round number every round
+	MinRoundDuration int `json:"min_round_duration,omitempty"` // Seconds an elimination round lasts at least, 0 disables it
+	ServerTimers // Round, freeze, buy, bomb and defuse timers, defaults from the server configuration
 	
 	// Rollback settings
 	RollbackEnabled     bool    `json:"rollback_enabled"`
@@ -33,21 +50,45 @@ type MatchConfig struct {
 	StartMoney          int  `json:"start_money"`
 	MaxMoney            int  `json:"max_money"`
 	RealisticEconomy    bool `json:"realistic_economy"`
+	ForceBuyType        string `json:"force_buy_type,omitempty"` // Debug override: "eco", "force_buy" or "full_buy" every round
+	LossBonusLadder     []int  `json:"loss_bonus_ladder,omitempty"` // Loss bonus per consecutive loss, defaults to CS2 values
+	AntiEcoThreshold    int    `json:"anti_eco_threshold,omitempty"` // Opponent team equipment value under which a saving opponent is anti-ecoed, default: 5000
+	KnifeKillReward     int    `json:"knife_kill_reward,omitempty"` // Reward for a knife kill, default: 1500
+	GrenadeKillReward   int    `json:"grenade_kill_reward,omitempty"` // Reward for an HE or fire kill, default: 300
 	
 	// Advanced settings
 	NetworkIssues       bool    `json:"network_issues"`
 	AntiCheatEvents     bool    `json:"anti_cheat_events"`
 	ChatMessages        bool    `json:"chat_messages"`
+	CasterRecaps        bool    `json:"caster_recaps,omitempty"` // Console say recap from a caster after every round
+	KillStreaks         bool    `json:"kill_streaks,omitempty"` // Server say highlight for every 3+ kill streak
+	FirstBlood          bool    `json:"first_blood,omitempty"` // Server say notice for the first kill of every round
+	ServerCvars         bool    `json:"server_cvars,omitempty"` // server_cvar lines at warmup end, restart and halftime
+	StatusInterval      int     `json:"status_interval,omitempty"` // Rounds between server status snapshots, 0 disables them
+	MVPEvents           bool    `json:"mvp_events,omitempty"` // Round MVP as a separate round_mvp event instead of part of round_end
+	Scoreline           []int   `json:"scoreline,omitempty"` // Final score per team, in team order, that round outcomes are steered towards
+	WeaponSkins         bool    `json:"weapon_skins"` // Cosmetic skins and StatTrak counts in raw event data
 	SkillVariance       float64 `json:"skill_variance"`
 	
 	// Output settings
 	LogFormat           string `json:"log_format"`      // "standard", "json", "custom"
 	TimestampFormat     string `json:"timestamp_format"`
+	LineEnding          string `json:"line_ending,omitempty"` // "lf" (default) or "crlf" between log lines
+	MaxNameLength       int    `json:"max_name_length,omitempty"` // Longest player name in the log in characters, default: 31
+	NamePolicy          string `json:"name_policy,omitempty"` // "truncate" (default) cuts longer names, "reject" fails validation
 	OutputVerbosity     string `json:"output_verbosity"` // "minimal", "standard", "verbose"
 	IncludePositions    bool   `json:"include_positions"`
+	EmitPositions       bool   `json:"emit_positions,omitempty"` // Sampled player_position events for radar playback
+	PositionSampleRate  int    `json:"position_sample_rate,omitempty"` // Position samples per second, default: 4
 	IncludeWeaponFire   bool   `json:"include_weapon_fire"`
+	DamagePacing        string `json:"damage_pacing,omitempty"` // "engagements" (default) clusters non-lethal hits in fights, "uniform" spreads them over the round
 	VerboseLogging      bool   `json:"verbose_logging"`
 	DetailedEvents      bool   `json:"detailed_events"`
+	IncludeRoundStats   bool   `json:"include_round_stats"` // Player stat deltas in round_end broadcasts
+	
+	// FastMode only emits the events that decide round outcomes (round start/end,
+	// kills and bomb events). Purchases still affect the economy but are not logged.
+	FastMode            bool   `json:"fast_mode"`
 }
 
 // SimulationConfig represents configuration for match simulation
@@ -56,6 +97,7 @@ type SimulationConfig struct {
 	EventsPerSecond     int     `json:"events_per_second"`
 	MaxConcurrentMatches int    `json:"max_concurrent_matches"`
 	BufferSize          int     `json:"buffer_size"`
+	MaxEventsPerMatch   int64   `json:"max_events_per_match"` // Safety cap on generated events
 	
 	// Realism settings
 	PlayerBehaviorRealism float64 `json:"player_behavior_realism"` // 0.0 to 1.0
@@ -66,11 +108,17 @@ type SimulationConfig struct {
 	RandomSeed          int64   `json:"random_seed"`
 	SkillVariation      float64 `json:"skill_variation"`
 	WeaponAccuracy      float64 `json:"weapon_accuracy"`
+	DamageVariance      float64 `json:"damage_variance"` // Hits deal the weapon's base damage give or take this share of it
+	LethalityBias       float64 `json:"lethality_bias"`  // -1 to 1, skews hits towards the low (grindier) or high (bloodier) end of the variance
+	AggressionEffect    float64 `json:"aggression_effect"` // 0 to 1, how strongly player aggression speeds up rounds and draws players into duels
 	
 	// Event probabilities
 	TeamKillProbability float64 `json:"team_kill_probability"`
 	FlashAssistProbability float64 `json:"flash_assist_probability"`
+	AssistDamageThreshold float64 `json:"assist_damage_threshold"` // Share of the victim's health a teammate must deal for an assist
 	WallBangProbability float64 `json:"wallbang_probability"`
+	NoScopeProbability  float64 `json:"noscope_probability"`     // Chance an AWP or scout kill is a no-scope
+	InAirKillProbability float64 `json:"in_air_kill_probability"` // Chance a kill is made while airborne
 	
 	// Chat and communication
 	ChatFrequency       float64 `json:"chat_frequency"`
@@ -106,6 +154,7 @@ type ServerConfig struct {
 	// Economy settings
 	StartMoney          int    `json:"start_money"`
 	MaxMoney            int    `json:"max_money"`
+	LossBonusLadder     []int  `json:"loss_bonus_ladder"` // Loss bonus per consecutive loss
 	
 	// Gameplay settings
 	FriendlyFire        bool   `json:"friendly_fire"`
@@ -131,6 +180,17 @@ type ServerConfig struct {
 	LogBans             bool   `json:"log_bans"`
 }
 
+// ServerTimers are the round timers a match can override, in seconds. Timers that
+// are not set keep the defaults of the server configuration.
+type ServerTimers struct {
+	RoundTime       int `json:"round_time,omitempty"`         // Round timer, default: 115
+	FreezeTime      int `json:"freeze_time,omitempty"`        // Freeze time before each round, default: 15
+	BuyTime         int `json:"buy_time,omitempty"`           // Buy window from the start of the freeze time, default: 20
+	BombTimer       int `json:"bomb_timer,omitempty"`         // Time from plant to explosion, default: 40
+	DefuseTime      int `json:"defuse_time,omitempty"`        // Defuse with a kit, default: 5
+	DefuseTimeNoKit int `json:"defuse_time_no_kit,omitempty"` // Defuse without a kit, default: 10
+}
+
 // ParserConfig represents configuration for demo parsing
 type ParserConfig struct {
 	// Input settings
@@ -201,14 +261,20 @@ func DefaultSimulationConfig() SimulationConfig {
 		EventsPerSecond:          1000,
 		MaxConcurrentMatches:     10,
 		BufferSize:              10000,
+		MaxEventsPerMatch:        1000000,
 		PlayerBehaviorRealism:    0.8,
 		EconomicRealism:          0.9,
 		PositionalRealism:        0.7,
 		SkillVariation:           0.2,
 		WeaponAccuracy:           0.8,
+		DamageVariance:           0.2,
+		AggressionEffect:         0.5,
 		TeamKillProbability:      0.001,
 		FlashAssistProbability:   0.1,
+		AssistDamageThreshold:    0.4,
 		WallBangProbability:      0.05,
+		NoScopeProbability:       0.05,
+		InAirKillProbability:     0.01,
 		ChatFrequency:            0.1,
 		RadioCommandFreq:         0.05,
 		DeathCamComments:         true,
@@ -234,6 +300,7 @@ func DefaultServerConfig() ServerConfig {
 		DefuseTimeNoKit:     10,
 		StartMoney:          800,
 		MaxMoney:            16000,
+		LossBonusLadder:     DefaultLossBonusLadder(),
 		FriendlyFire:        true,
 		AutoBalance:         false,
 		VACEnabled:          true,
@@ -274,6 +341,13 @@ func (c *MatchConfig) Validate() error {
 	if strings.TrimSpace(c.Map) == "" {
 		return errors.New("map is required")
 	}
+	if !IsKnownMap(c.Map) {
+		return fmt.Errorf("unknown map: %s", c.Map)
+	}
+	
+	if err := c.ServerTimers.Validate(); err != nil {
+		return err
+	}
 	
 	if c.TickRate != 0 && (c.TickRate < 64 || c.TickRate > 128) {
 		return errors.New("tick rate must be between 64 and 128")
@@ -291,9 +365,92 @@ func (c *MatchConfig) Validate() error {
 		return errors.New("start money must be between 0 and max money")
 	}
 	
+	if err := ValidateMaxRounds(c.MaxRounds); err != nil {
+		return err
+	}
+	
+	if c.ForceBuyType != "" && !IsValidBuyType(c.ForceBuyType) {
+		return fmt.Errorf("invalid force buy type: %s (must be one of %s)", c.ForceBuyType, strings.Join(BuyTypes, ", "))
+	}
+	
+	if c.LossBonusLadder != nil {
+		if err := ValidateLossBonusLadder(c.LossBonusLadder); err != nil {
+			return err
+		}
+	}
+	
+	if err := ValidateAntiEcoThreshold(c.AntiEcoThreshold); err != nil {
+		return err
+	}
+	
+	if c.LineEnding != "" && !IsValidLineEnding(c.LineEnding) {
+		return fmt.Errorf("invalid line ending: %s (must be one of %s)", c.LineEnding, strings.Join(LineEndings, ", "))
+	}
+	
+	if err := ValidateMaxNameLength(c.MaxNameLength); err != nil {
+		return err
+	}
+	
+	if c.NamePolicy != "" && !IsValidNamePolicy(c.NamePolicy) {
+		return fmt.Errorf("invalid name policy: %s (must be one of %s)", c.NamePolicy, strings.Join(NamePolicies, ", "))
+	}
+	
+	if c.DamagePacing != "" && !IsValidDamagePacing(c.DamagePacing) {
+		return fmt.Errorf("invalid damage pacing: %s (must be one of %s)", c.DamagePacing, strings.Join(DamagePacings, ", "))
+	}
+	
+	if err := ValidatePositionSampleRate(c.PositionSampleRate); err != nil {
+		return err
+	}
+	
+	if err := ValidateExtraCvars(c.ExtraCvars); err != nil {
+		return err
+	}
+	
+	if err := ValidateVeto(c.Veto); err != nil {
+		return err
+	}
+	
+	if c.StatusInterval < 0 {
+		return errors.New("status interval cannot be negative")
+	}
+	
+	if c.KnifeKillReward < 0 || c.GrenadeKillReward < 0 {
+		return errors.New("kill rewards cannot be negative")
+	}
+	
+	if err := ValidateMinRoundDuration(c.MinRoundDuration); err != nil {
+		return err
+	}
+	
+	if c.Scoreline != nil {
+		if err := ValidateScoreline(c.Scoreline, c.GetMaxRounds()); err != nil {
+			return err
+		}
+	}
+	
 	return nil
 }
 
+// ApplyDefaults fills the rates and limits a partially specified simulation
+// configuration leaves at zero with their defaults. Probabilities are left alone,
+// as zero is a meaningful value for them.
+func (c *SimulationConfig) ApplyDefaults() {
+	defaults := DefaultSimulationConfig()
+	if c.EventsPerSecond == 0 {
+		c.EventsPerSecond = defaults.EventsPerSecond
+	}
+	if c.MaxConcurrentMatches == 0 {
+		c.MaxConcurrentMatches = defaults.MaxConcurrentMatches
+	}
+	if c.BufferSize == 0 {
+		c.BufferSize = defaults.BufferSize
+	}
+	if c.MaxEventsPerMatch == 0 {
+		c.MaxEventsPerMatch = defaults.MaxEventsPerMatch
+	}
+}
+
 // Validate validates the simulation configuration
 func (c *SimulationConfig) Validate() error {
 	if c.EventsPerSecond <= 0 {
@@ -308,6 +465,10 @@ func (c *SimulationConfig) Validate() error {
 		return errors.New("buffer size must be positive")
 	}
 	
+	if c.MaxEventsPerMatch <= 0 {
+		return errors.New("max events per match must be positive")
+	}
+	
 	// Validate realism values (0.0 to 1.0)
 	realism := []struct {
 		name  string
@@ -318,6 +479,8 @@ func (c *SimulationConfig) Validate() error {
 		{"positional realism", c.PositionalRealism},
 		{"skill variation", c.SkillVariation},
 		{"weapon accuracy", c.WeaponAccuracy},
+		{"damage variance", c.DamageVariance},
+		{"aggression effect", c.AggressionEffect},
 	}
 	
 	for _, r := range realism {
@@ -326,6 +489,10 @@ func (c *SimulationConfig) Validate() error {
 		}
 	}
 	
+	if c.LethalityBias < -1 || c.LethalityBias > 1 {
+		return errors.New("lethality bias must be between -1.0 and 1.0")
+	}
+	
 	// Validate probabilities
 	probabilities := []struct {
 		name  string
@@ -334,6 +501,9 @@ func (c *SimulationConfig) Validate() error {
 		{"team kill probability", c.TeamKillProbability},
 		{"flash assist probability", c.FlashAssistProbability},
 		{"wallbang probability", c.WallBangProbability},
+		{"assist damage threshold", c.AssistDamageThreshold},
+		{"noscope probability", c.NoScopeProbability},
+		{"in air kill probability", c.InAirKillProbability},
 		{"chat frequency", c.ChatFrequency},
 		{"radio command frequency", c.RadioCommandFreq},
 		{"packet loss", c.PacketLoss},
@@ -374,6 +544,10 @@ func (c *ServerConfig) Validate() error {
 		return errors.New("buy time must be positive and not exceed freezetime")
 	}
 	
+	if err := ValidateLossBonusLadder(c.LossBonusLadder); err != nil {
+		return err
+	}
+	
 	return nil
 }
 
@@ -427,43 +601,301 @@ func (c *ParserConfig) Validate() error {
 	return nil
 }
 
-// GetMaxRounds returns the maximum number of rounds for the format
+// GetMaxRounds returns the regulation rounds of the match: MaxRounds when set, like
+// mp_maxrounds on a server, otherwise the format's default
 func (c *MatchConfig) GetMaxRounds() int {
 	if c.MaxRounds > 0 {
 		return c.MaxRounds
 	}
-	
-	switch c.Format {
-	case "mr12":
-		return 24
-	case "mr15":
+	return MaxRoundsForFormat(c.Format)
+}
+
+// MaxRoundsForFormat returns the regulation rounds of a format: 24 for mr12, 30 for mr15.
+// Unknown formats are treated as mr12.
+func MaxRoundsForFormat(format string) int {
+	if format == "mr15" {
 		return 30
-	default:
-		return 24
 	}
+	return 24
 }
 
-// GetWinThreshold returns the number of rounds needed to win
-func (c *MatchConfig) GetWinThreshold() int {
-	return (c.GetMaxRounds() / 2) + 1
+// ValidateMaxRounds checks an mp_maxrounds override, where 0 means the format default.
+// Halves need the same number of rounds, so the value must be even.
+func ValidateMaxRounds(maxRounds int) error {
+	if maxRounds < 0 || maxRounds%2 != 0 {
+		return fmt.Errorf("max rounds must be 0 (format default) or a positive even number, got %d", maxRounds)
+	}
+	return nil
 }
 
-// IsValidMap checks if a map name is valid
-func (c *MatchConfig) IsValidMap() bool {
-	validMaps := []string{
-		"de_mirage", "de_dust2", "de_inferno", "de_cache", "de_overpass",
-		"de_train", "de_nuke", "de_cbble", "de_vertigo", "de_ancient",
+// ValidateScoreline checks that a requested final scoreline, one score per team, can
+// be reached in regulation: the winner on the win threshold and the loser below half,
+// or a draw at half each
+func ValidateScoreline(scoreline []int, maxRounds int) error {
+	if len(scoreline) != 2 {
+		return fmt.Errorf("scoreline must have exactly 2 scores, one per team")
+	}
+	for _, score := range scoreline {
+		if score < 0 {
+			return fmt.Errorf("scoreline scores cannot be negative: %d", score)
+		}
 	}
 	
-	for _, validMap := range validMaps {
-		if strings.EqualFold(c.Map, validMap) {
+	high, low := scoreline[0], scoreline[1]
+	if low > high {
+		high, low = low, high
+	}
+	half := maxRounds / 2
+	if (high == half+1 && low < half) || (high == half && low == half && maxRounds%2 == 0) {
+		return nil
+	}
+	return fmt.Errorf("scoreline %d-%d is impossible in a %d round match", scoreline[0], scoreline[1], maxRounds)
+}
+
+// LineEndings lists the supported log line terminators
+var LineEndings = []string{"lf", "crlf"}
+
+// IsValidLineEnding checks if a line ending is known
+func IsValidLineEnding(lineEnding string) bool {
+	for _, known := range LineEndings {
+		if lineEnding == known {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultMaxNameLength is the longest player name written to the log when no limit is set,
+// the length CS2 itself allows
+const DefaultMaxNameLength = 31
+
+// MaxPlayerNameLength caps the configurable player name length
+const MaxPlayerNameLength = 128
+
+// ValidateMaxNameLength checks that a max name length is unset or within range
+func ValidateMaxNameLength(length int) error {
+	if length < 0 || length > MaxPlayerNameLength {
+		return fmt.Errorf("max name length must be between 0 (default) and %d characters", MaxPlayerNameLength)
+	}
+	return nil
+}
+
+// NamePolicies lists how player names over the max name length are handled
+var NamePolicies = []string{"truncate", "reject"}
+
+// IsValidNamePolicy checks if a name policy is known
+func IsValidNamePolicy(policy string) bool {
+	for _, known := range NamePolicies {
+		if policy == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePlayerNameLengths rejects player names longer than the max name length under
+// the reject policy, and accepts any name otherwise. A length of 0 is the default
+// limit. Quotes and backslashes count twice, as they are escaped in the log.
+func ValidatePlayerNameLengths(teams []Team, maxLength int, policy string) error {
+	if policy != "reject" {
+		return nil
+	}
+	if maxLength == 0 {
+		maxLength = DefaultMaxNameLength
+	}
+	for _, team := range teams {
+		for _, player := range team.Players {
+			length := utf8.RuneCountInString(player.Name) + strings.Count(player.Name, `"`) + strings.Count(player.Name, `\`)
+			if length > maxLength {
+				return fmt.Errorf("player name %q of %s is %d characters, longer than the max name length of %d", player.Name, team.Name, length, maxLength)
+			}
+		}
+	}
+	return nil
+}
+
+// DamagePacings lists how the non-lethal hits of a round can be timed
+var DamagePacings = []string{"engagements", "uniform"}
+
+// IsValidDamagePacing checks if a damage pacing is known
+func IsValidDamagePacing(pacing string) bool {
+	for _, known := range DamagePacings {
+		if pacing == known {
 			return true
 		}
 	}
-	
 	return false
 }
 
+// LineTerminator returns the characters that end each log line, LF unless CRLF was requested
+func (c *MatchConfig) LineTerminator() string {
+	if c.LineEnding == "crlf" {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// GetMaxNameLength returns the longest player name written to the log, using the default when unset
+func (c *MatchConfig) GetMaxNameLength() int {
+	if c.MaxNameLength > 0 {
+		return c.MaxNameLength
+	}
+	return DefaultMaxNameLength
+}
+
+// DefaultPositionSampleRate is the number of position samples per second when none is set
+const DefaultPositionSampleRate = 4
+
+// MaxPositionSampleRate caps position samples per second to keep matches within the event limit
+const MaxPositionSampleRate = 16
+
+// ValidatePositionSampleRate checks that a position sample rate is unset or within range
+func ValidatePositionSampleRate(rate int) error {
+	if rate < 0 || rate > MaxPositionSampleRate {
+		return fmt.Errorf("position sample rate must be between 1 and %d samples per second", MaxPositionSampleRate)
+	}
+	return nil
+}
+
+// Caps on the configurable server timers in seconds
+const (
+	MaxRoundTime  = 300
+	MaxFreezeTime = 60
+	MaxBuyTime    = 60
+	MaxBombTimer  = 90
+	MaxDefuseTime = 30
+)
+
+// Apply overrides the timers of a server configuration with the ones that are set
+func (t ServerTimers) Apply(server *ServerConfig) {
+	if t.RoundTime > 0 {
+		server.RoundTime = t.RoundTime
+	}
+	if t.FreezeTime > 0 {
+		server.FreezetimeLength = t.FreezeTime
+	}
+	if t.BuyTime > 0 {
+		server.BuyTime = t.BuyTime
+	}
+	if t.BombTimer > 0 {
+		server.BombTimer = t.BombTimer
+	}
+	if t.DefuseTime > 0 {
+		server.DefuseTime = t.DefuseTime
+	}
+	if t.DefuseTimeNoKit > 0 {
+		server.DefuseTimeNoKit = t.DefuseTimeNoKit
+	}
+}
+
+// Validate checks that every timer is unset or within range, and that a defuse
+// without a kit still fits in the bomb timer
+func (t ServerTimers) Validate() error {
+	limits := []struct {
+		name  string
+		value int
+		max   int
+	}{
+		{"round time", t.RoundTime, MaxRoundTime},
+		{"freeze time", t.FreezeTime, MaxFreezeTime},
+		{"buy time", t.BuyTime, MaxBuyTime},
+		{"bomb timer", t.BombTimer, MaxBombTimer},
+		{"defuse time", t.DefuseTime, MaxDefuseTime},
+		{"defuse time without a kit", t.DefuseTimeNoKit, MaxDefuseTime},
+	}
+	for _, limit := range limits {
+		if limit.value < 0 || limit.value > limit.max {
+			return fmt.Errorf("%s must be between 0 (default) and %d seconds", limit.name, limit.max)
+		}
+	}
+	
+	server := DefaultServerConfig()
+	t.Apply(&server)
+	if server.DefuseTime > server.DefuseTimeNoKit {
+		return errors.New("defuse time with a kit cannot be longer than without one")
+	}
+	if server.DefuseTimeNoKit >= server.BombTimer {
+		return errors.New("defuse time without a kit must be shorter than the bomb timer")
+	}
+	return nil
+}
+
+// MaxMinRoundDuration caps the minimum round duration in seconds. Rounds with a planted
+// bomb always last longer, so only elimination rounds without a plant need stretching.
+const MaxMinRoundDuration = 20
+
+// ValidateMinRoundDuration checks that a minimum round duration is unset or within range
+func ValidateMinRoundDuration(seconds int) error {
+	if seconds < 0 || seconds > MaxMinRoundDuration {
+		return fmt.Errorf("min round duration must be between 0 and %d seconds", MaxMinRoundDuration)
+	}
+	return nil
+}
+
+// DefaultAntiEcoThreshold is the opponent team equipment value under which a saving
+// opponent is anti-ecoed when none is set: pistols and a little armor, well short of rifles
+const DefaultAntiEcoThreshold = 5000
+
+// MaxAntiEcoThreshold caps the anti-eco threshold at more than a full buy team carries
+const MaxAntiEcoThreshold = 50000
+
+// ValidateAntiEcoThreshold checks that an anti-eco threshold is unset or within range
+func ValidateAntiEcoThreshold(threshold int) error {
+	if threshold < 0 || threshold > MaxAntiEcoThreshold {
+		return fmt.Errorf("anti-eco threshold must be between 0 (default) and %d", MaxAntiEcoThreshold)
+	}
+	return nil
+}
+
+// GetAntiEcoThreshold returns the anti-eco threshold, using the default when unset
+func (c *MatchConfig) GetAntiEcoThreshold() int {
+	if c.AntiEcoThreshold > 0 {
+		return c.AntiEcoThreshold
+	}
+	return DefaultAntiEcoThreshold
+}
+
+// GetPositionSampleRate returns the position samples per second, using the default when unset
+func (c *MatchConfig) GetPositionSampleRate() int {
+	if c.PositionSampleRate > 0 {
+		return c.PositionSampleRate
+	}
+	return DefaultPositionSampleRate
+}
+
+// cvarNamePattern matches console variable names such as sv_cheats or mp_overtime_enable
+var cvarNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateExtraCvars checks that every extra cvar key looks like a cvar name
+func ValidateExtraCvars(cvars map[string]string) error {
+	for _, name := range SortedCvarNames(cvars) {
+		if !cvarNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid cvar name %q: must start with a letter or underscore and contain only letters, digits and underscores", name)
+		}
+	}
+	return nil
+}
+
+// SortedCvarNames returns the names of the given cvars in a stable, sorted order
+func SortedCvarNames(cvars map[string]string) []string {
+	names := make([]string, 0, len(cvars))
+	for name := range cvars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetWinThreshold returns the number of rounds needed to win
+func (c *MatchConfig) GetWinThreshold() int {
+	return (c.GetMaxRounds() / 2) + 1
+}
+
+// IsValidMap checks if a map name is valid
+func (c *MatchConfig) IsValidMap() bool {
+	return IsKnownMap(c.Map)
+}
+
 // ApplyProfile applies a predefined configuration profile
 func (c *MatchConfig) ApplyProfile(profileName string) {
 	switch strings.ToLower(profileName) {
@@ -500,6 +932,19 @@ func (c *MatchConfig) ApplyProfile(profileName string) {
 // Clone creates a deep copy of the match configuration
 func (c *MatchConfig) Clone() *MatchConfig {
 	clone := *c
+	if c.ExtraCvars != nil {
+		clone.ExtraCvars = make(map[string]string, len(c.ExtraCvars))
+		for name, value := range c.ExtraCvars {
+			clone.ExtraCvars[name] = value
+		}
+	}
+	if c.StartTime != nil {
+		startTime := *c.StartTime
+		clone.StartTime = &startTime
+	}
+	clone.Veto = append([]VetoStep(nil), c.Veto...)
+	clone.LossBonusLadder = append([]int(nil), c.LossBonusLadder...)
+	clone.Scoreline = append([]int(nil), c.Scoreline...)
 	return &clone
 }
 
@@ -518,4 +963,33 @@ func (c *MatchConfig) Merge(other *MatchConfig) {
 		c.Seed = other.Seed
 	}
 	// Add more fields as needed...
-}
\ No newline at end of file
+}
+// Digest returns a hash of the normalized configuration, excluding the seed
+func (c *MatchConfig) Digest() string {
+	return c.digest(false)
+}
+
+// DigestWithSeed returns a hash of the normalized configuration including the seed
+func (c *MatchConfig) DigestWithSeed() string {
+	return c.digest(true)
+}
+
+// digest hashes a normalized copy of the configuration
+func (c *MatchConfig) digest(includeSeed bool) string {
+	normalized := *c
+	normalized.Format = strings.ToLower(strings.TrimSpace(c.Format))
+	normalized.Map = strings.ToLower(strings.TrimSpace(c.Map))
+	normalized.ServerName = strings.TrimSpace(c.ServerName)
+	normalized.MaxRounds = c.GetMaxRounds()
+	if !includeSeed {
+		normalized.Seed = 0
+	}
+
+	data, err := json.Marshal(normalized)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,284 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMatchConfig_Digest(t *testing.T) {
+	config1 := DefaultMatchConfig()
+	config1.Seed = 12345
+
+	// Equivalent config with different casing, explicit max rounds and another seed
+	config2 := DefaultMatchConfig()
+	config2.Map = "DE_MIRAGE"
+	config2.Format = "MR12"
+	config2.MaxRounds = 24
+	config2.Seed = 67890
+
+	if config1.Digest() != config2.Digest() {
+		t.Errorf("Expected equivalent configs to have the same digest, got %s and %s", config1.Digest(), config2.Digest())
+	}
+
+	if config1.DigestWithSeed() == config2.DigestWithSeed() {
+		t.Error("Expected different seeds to produce different digests when the seed is included")
+	}
+
+	config3 := DefaultMatchConfig()
+	config3.Map = "de_dust2"
+
+	if config1.Digest() == config3.Digest() {
+		t.Error("Expected a different map to produce a different digest")
+	}
+}
+
+func TestMatchConfig_ValidateForceBuyType(t *testing.T) {
+	testCases := []struct {
+		buyType string
+		valid   bool
+	}{
+		{"", true},
+		{"eco", true},
+		{"force_buy", true},
+		{"full_buy", true},
+		{"semi_eco", false},
+		{"all_awps", false},
+	}
+
+	for _, tc := range testCases {
+		config := DefaultMatchConfig()
+		config.ForceBuyType = tc.buyType
+
+		err := config.Validate()
+		if tc.valid && err != nil {
+			t.Errorf("ForceBuyType %q: unexpected error: %v", tc.buyType, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("ForceBuyType %q: expected validation error", tc.buyType)
+		}
+	}
+}
+
+func TestMatchConfig_ValidateMap(t *testing.T) {
+	for _, mapName := range []string{"de_mirage", "DE_NUKE", "de_shortdust"} {
+		config := DefaultMatchConfig()
+		config.Map = mapName
+		if err := config.Validate(); err != nil {
+			t.Errorf("Map %q: unexpected error: %v", mapName, err)
+		}
+	}
+
+	// Unknown maps are rejected before any round picks a bomb site on them
+	config := DefaultMatchConfig()
+	config.Map = "de_nowhere"
+	if err := config.Validate(); err == nil || !strings.Contains(err.Error(), "unknown map") {
+		t.Errorf("Expected an unknown map error, got %v", err)
+	}
+	req := GenerateRequest{Map: "de_nowhere", Format: "mr12", Teams: make([]Team, 2)}
+	if err := req.Validate(); err == nil || !strings.Contains(err.Error(), "unknown map") {
+		t.Errorf("Expected the request to be rejected for an unknown map, got %v", err)
+	}
+}
+
+func TestMatchConfig_ValidateAntiEcoThreshold(t *testing.T) {
+	for _, threshold := range []int{0, 1, DefaultAntiEcoThreshold, MaxAntiEcoThreshold} {
+		config := DefaultMatchConfig()
+		config.AntiEcoThreshold = threshold
+		if err := config.Validate(); err != nil {
+			t.Errorf("Threshold %d: unexpected error: %v", threshold, err)
+		}
+	}
+	for _, threshold := range []int{-1, MaxAntiEcoThreshold + 1} {
+		config := DefaultMatchConfig()
+		config.AntiEcoThreshold = threshold
+		if err := config.Validate(); err == nil || !strings.Contains(err.Error(), "anti-eco threshold") {
+			t.Errorf("Threshold %d: expected a range error, got %v", threshold, err)
+		}
+	}
+
+	config := DefaultMatchConfig()
+	if got := config.GetAntiEcoThreshold(); got != DefaultAntiEcoThreshold {
+		t.Errorf("Expected an unset threshold to default to %d, got %d", DefaultAntiEcoThreshold, got)
+	}
+}
+
+func TestMatchConfig_ValidateExtraCvars(t *testing.T) {
+	testCases := []struct {
+		name  string
+		cvars map[string]string
+		valid bool
+	}{
+		{"none", nil, true},
+		{"cvar names", map[string]string{"sv_cheats": "1", "mp_overtime_enable": "1", "_internal2": ""}, true},
+		{"space", map[string]string{"sv cheats": "1"}, false},
+		{"quote", map[string]string{`sv_cheats" "1`: "1"}, false},
+		{"leading digit", map[string]string{"1sv_cheats": "1"}, false},
+		{"empty name", map[string]string{"": "1"}, false},
+	}
+
+	for _, tc := range testCases {
+		config := DefaultMatchConfig()
+		config.ExtraCvars = tc.cvars
+
+		err := config.Validate()
+		if tc.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("%s: expected validation error", tc.name)
+		}
+	}
+}
+
+func TestValidateLossBonusLadder(t *testing.T) {
+	testCases := []struct {
+		name   string
+		ladder []int
+		valid  bool
+	}{
+		{"default", DefaultLossBonusLadder(), true},
+		{"single step", []int{1000}, true},
+		{"flat", []int{1400, 1400, 1400}, true},
+		{"empty", []int{}, false},
+		{"decreasing", []int{1400, 1900, 1500}, false},
+		{"negative", []int{-100, 1900}, false},
+	}
+
+	for _, tc := range testCases {
+		err := ValidateLossBonusLadder(tc.ladder)
+		if tc.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("%s: expected validation error", tc.name)
+		}
+
+		config := DefaultMatchConfig()
+		config.LossBonusLadder = tc.ladder
+		if err := config.Validate(); (err == nil) != tc.valid {
+			t.Errorf("%s: MatchConfig.Validate returned %v", tc.name, err)
+		}
+	}
+}
+
+func TestLossBonusForStreak(t *testing.T) {
+	ladder := []int{100, 200, 300}
+	testCases := []struct {
+		losses int
+		want   int
+	}{
+		{0, 100},
+		{1, 100},
+		{2, 200},
+		{3, 300},
+		{7, 300},
+	}
+
+	for _, tc := range testCases {
+		if got := LossBonusForStreak(ladder, tc.losses); got != tc.want {
+			t.Errorf("LossBonusForStreak(%d) = %d, want %d", tc.losses, got, tc.want)
+		}
+	}
+}
+
+func TestValidateScoreline(t *testing.T) {
+	testCases := []struct {
+		name      string
+		scoreline []int
+		maxRounds int
+		valid     bool
+	}{
+		{"shutout", []int{13, 0}, 24, true},
+		{"closest win", []int{11, 13}, 24, true},
+		{"draw", []int{12, 12}, 24, true},
+		{"mr15 win", []int{16, 14}, 30, true},
+		{"overtime score", []int{13, 12}, 24, false},
+		{"past the win threshold", []int{14, 0}, 24, false},
+		{"nobody wins", []int{10, 8}, 24, false},
+		{"mr12 score in mr15", []int{13, 0}, 30, false},
+		{"negative", []int{13, -1}, 24, false},
+		{"one score", []int{13}, 24, false},
+		{"three scores", []int{13, 0, 0}, 24, false},
+	}
+
+	for _, tc := range testCases {
+		err := ValidateScoreline(tc.scoreline, tc.maxRounds)
+		if tc.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("%s: expected validation error", tc.name)
+		}
+	}
+}
+
+func TestGenerateRequest_ValidateNamePolicy(t *testing.T) {
+	req := GenerateRequest{Map: "de_mirage", Format: "mr12"}
+	for _, side := range []string{"CT", "TERRORIST"} {
+		team := Team{Name: side, Side: side}
+		for i := 0; i < 5; i++ {
+			team.Players = append(team.Players, Player{Name: fmt.Sprintf("%s_Player%d", side, i), SteamID: "STEAM_1:0:1"})
+		}
+		req.Teams = append(req.Teams, team)
+	}
+	req.Teams[1].Players[0].Name = "AnOverlyLongName"
+
+	req.Options.MaxNameLength = 15
+	if err := req.Validate(); err != nil {
+		t.Errorf("Expected long names to be truncated by default, got %v", err)
+	}
+
+	req.Options.NamePolicy = "reject"
+	if err := req.Validate(); err == nil || !strings.Contains(err.Error(), "AnOverlyLongName") {
+		t.Errorf("Expected the 16 character name to be rejected, got %v", err)
+	}
+
+	req.Options.MaxNameLength = 0
+	if err := req.Validate(); err != nil {
+		t.Errorf("Expected the name to fit the default limit, got %v", err)
+	}
+
+	req.Options.NamePolicy = "shorten"
+	if err := req.Validate(); err == nil || !strings.Contains(err.Error(), "name policy") {
+		t.Errorf("Expected an unknown name policy to be rejected, got %v", err)
+	}
+
+	req.Options.NamePolicy = ""
+	req.Options.MaxNameLength = MaxPlayerNameLength + 1
+	if err := req.Validate(); err == nil || !strings.Contains(err.Error(), "between 0 (default) and") {
+		t.Errorf("Expected a max name length over the cap to be rejected with its range, got %v", err)
+	}
+}
+
+func TestGenerateRequest_ValidateScoreline(t *testing.T) {
+	req := GenerateRequest{Map: "de_mirage", Format: "mr12"}
+	for _, side := range []string{"CT", "TERRORIST"} {
+		team := Team{Name: side, Side: side}
+		for i := 0; i < 5; i++ {
+			team.Players = append(team.Players, Player{Name: fmt.Sprintf("%s%d", side, i), SteamID: "STEAM_1:0:1"})
+		}
+		req.Teams = append(req.Teams, team)
+	}
+
+	req.Options.Scoreline = []int{13, 0}
+	if err := req.Validate(); err != nil {
+		t.Errorf("Expected 13-0 to be valid in mr12, got %v", err)
+	}
+
+	req.Options.Scoreline = []int{16, 0}
+	if err := req.Validate(); err == nil || !strings.Contains(err.Error(), "impossible") {
+		t.Errorf("Expected 16-0 to be impossible in mr12, got %v", err)
+	}
+
+	// A max_rounds override changes which scorelines are reachable
+	req.Options.MaxRounds = 6
+	req.Options.Scoreline = []int{4, 2}
+	if err := req.Validate(); err != nil {
+		t.Errorf("Expected 4-2 to be valid over 6 rounds, got %v", err)
+	}
+	req.Options.Scoreline = []int{13, 0}
+	if err := req.Validate(); err == nil {
+		t.Error("Expected 13-0 to be impossible over 6 rounds")
+	}
+}
@@ -12,6 +12,7 @@ type EconomyManager struct {
 	RoundWinBonus   map[string]int
 	KillRewards     map[string]int
 	ObjectiveRewards map[string]int
+	LossBonusLadder []int // Loss bonus for the 1st, 2nd, ... consecutive loss
 }
 
 // WeaponInfo represents weapon information and pricing
@@ -56,6 +57,7 @@ func NewEconomyManager() *EconomyManager {
 		RoundWinBonus:    getRoundWinBonuses(),
 		KillRewards:      getKillRewards(),
 		ObjectiveRewards: getObjectiveRewards(),
+		LossBonusLadder:  DefaultLossBonusLadder(),
 	}
 }
 
@@ -128,16 +130,22 @@ func getCS2UtilityPrices() map[string]int {
 	}
 }
 
-// getRoundWinBonuses returns round win bonus amounts
+// getRoundWinBonuses returns round win bonus amounts keyed by round end reason
 func getRoundWinBonuses() map[string]int {
 	return map[string]int{
 		"elimination":   3250,
 		"bomb_defused":  3500,
 		"bomb_exploded": 3500,
-		"time_expired":  3250,
+		"time":          3250,
 	}
 }
 
+// sideWinReasons lists the ways each side can win a round
+var sideWinReasons = map[string][]string{
+	"CT":        {"elimination", "bomb_defused", "time"},
+	"TERRORIST": {"elimination", "bomb_exploded"},
+}
+
 // getKillRewards returns kill reward amounts by weapon type
 func getKillRewards() map[string]int {
 	return map[string]int{
@@ -146,6 +154,7 @@ func getKillRewards() map[string]int {
 		"rifle":     300,
 		"sniper":    100, // AWP, auto-snipers
 		"shotgun":   900,
+		"machinegun": 300,
 		"knife":     1500,
 		"grenade":   300,
 		"zeus":      300,
@@ -248,6 +257,356 @@ func (em *EconomyManager) GetWeaponInfo() map[string]WeaponInfo {
 			MovementSpeed: 240,
 			Team:          "ct",
 		},
+		"p250": {
+			Name:          "p250",
+			DisplayName:   "P250",
+			Type:          "pistol",
+			Price:         300,
+			KillReward:    300,
+			Damage:        38,
+			Accuracy:      0.65,
+			ArmorPen:      0.64,
+			RangeModifier: 0.85,
+			Firerate:      400,
+			MovementSpeed: 240,
+			Team:          "both",
+		},
+		"tec9": {
+			Name:          "tec9",
+			DisplayName:   "Tec-9",
+			Type:          "pistol",
+			Price:         500,
+			KillReward:    300,
+			Damage:        33,
+			Accuracy:      0.60,
+			ArmorPen:      0.905,
+			RangeModifier: 0.83,
+			Firerate:      500,
+			MovementSpeed: 240,
+			Team:          "t",
+		},
+		"fiveseven": {
+			Name:          "fiveseven",
+			DisplayName:   "Five-SeveN",
+			Type:          "pistol",
+			Price:         500,
+			KillReward:    300,
+			Damage:        32,
+			Accuracy:      0.68,
+			ArmorPen:      0.911,
+			RangeModifier: 0.81,
+			Firerate:      400,
+			MovementSpeed: 240,
+			Team:          "ct",
+		},
+		"cz75a": {
+			Name:          "cz75a",
+			DisplayName:   "CZ75-Auto",
+			Type:          "pistol",
+			Price:         500,
+			KillReward:    100,
+			Damage:        31,
+			Accuracy:      0.55,
+			ArmorPen:      0.776,
+			RangeModifier: 0.85,
+			Firerate:      600,
+			MovementSpeed: 240,
+			Team:          "both",
+		},
+		"deagle": {
+			Name:          "deagle",
+			DisplayName:   "Desert Eagle",
+			Type:          "pistol",
+			Price:         700,
+			KillReward:    300,
+			Damage:        53,
+			Accuracy:      0.62,
+			ArmorPen:      0.932,
+			RangeModifier: 0.81,
+			Firerate:      267,
+			MovementSpeed: 230,
+			Team:          "both",
+		},
+		"revolver": {
+			Name:          "revolver",
+			DisplayName:   "R8 Revolver",
+			Type:          "pistol",
+			Price:         600,
+			KillReward:    300,
+			Damage:        86,
+			Accuracy:      0.70,
+			ArmorPen:      0.932,
+			RangeModifier: 0.94,
+			Firerate:      120,
+			MovementSpeed: 220,
+			Team:          "both",
+		},
+		"mac10": {
+			Name:          "mac10",
+			DisplayName:   "MAC-10",
+			Type:          "smg",
+			Price:         1050,
+			KillReward:    600,
+			Damage:        29,
+			Accuracy:      0.55,
+			ArmorPen:      0.575,
+			RangeModifier: 0.80,
+			Firerate:      800,
+			MovementSpeed: 240,
+			Team:          "t",
+		},
+		"mp9": {
+			Name:          "mp9",
+			DisplayName:   "MP9",
+			Type:          "smg",
+			Price:         1250,
+			KillReward:    600,
+			Damage:        26,
+			Accuracy:      0.60,
+			ArmorPen:      0.60,
+			RangeModifier: 0.87,
+			Firerate:      857,
+			MovementSpeed: 240,
+			Team:          "ct",
+		},
+		"mp7": {
+			Name:          "mp7",
+			DisplayName:   "MP7",
+			Type:          "smg",
+			Price:         1500,
+			KillReward:    600,
+			Damage:        29,
+			Accuracy:      0.63,
+			ArmorPen:      0.625,
+			RangeModifier: 0.85,
+			Firerate:      750,
+			MovementSpeed: 220,
+			Team:          "both",
+		},
+		"ump45": {
+			Name:          "ump45",
+			DisplayName:   "UMP-45",
+			Type:          "smg",
+			Price:         1200,
+			KillReward:    600,
+			Damage:        35,
+			Accuracy:      0.60,
+			ArmorPen:      0.65,
+			RangeModifier: 0.75,
+			Firerate:      666,
+			MovementSpeed: 230,
+			Team:          "both",
+		},
+		"p90": {
+			Name:          "p90",
+			DisplayName:   "P90",
+			Type:          "smg",
+			Price:         2350,
+			KillReward:    300,
+			Damage:        26,
+			Accuracy:      0.58,
+			ArmorPen:      0.69,
+			RangeModifier: 0.86,
+			Firerate:      857,
+			MovementSpeed: 230,
+			Team:          "both",
+		},
+		"bizon": {
+			Name:          "bizon",
+			DisplayName:   "PP-Bizon",
+			Type:          "smg",
+			Price:         1400,
+			KillReward:    600,
+			Damage:        27,
+			Accuracy:      0.55,
+			ArmorPen:      0.575,
+			RangeModifier: 0.80,
+			Firerate:      750,
+			MovementSpeed: 240,
+			Team:          "both",
+		},
+		"famas": {
+			Name:          "famas",
+			DisplayName:   "FAMAS",
+			Type:          "rifle",
+			Price:         2050,
+			KillReward:    300,
+			Damage:        30,
+			Accuracy:      0.70,
+			ArmorPen:      0.70,
+			RangeModifier: 0.96,
+			Firerate:      666,
+			MovementSpeed: 220,
+			Team:          "ct",
+		},
+		"galil": {
+			Name:          "galil",
+			DisplayName:   "Galil AR",
+			Type:          "rifle",
+			Price:         1800,
+			KillReward:    300,
+			Damage:        30,
+			Accuracy:      0.68,
+			ArmorPen:      0.775,
+			RangeModifier: 0.98,
+			Firerate:      666,
+			MovementSpeed: 215,
+			Team:          "t",
+		},
+		"sg556": {
+			Name:          "sg556",
+			DisplayName:   "SG 553",
+			Type:          "rifle",
+			Price:         3000,
+			KillReward:    300,
+			Damage:        30,
+			Accuracy:      0.80,
+			ArmorPen:      1.00,
+			RangeModifier: 0.98,
+			Firerate:      545,
+			MovementSpeed: 210,
+			Team:          "t",
+		},
+		"aug": {
+			Name:          "aug",
+			DisplayName:   "AUG",
+			Type:          "rifle",
+			Price:         3300,
+			KillReward:    300,
+			Damage:        28,
+			Accuracy:      0.82,
+			ArmorPen:      0.90,
+			RangeModifier: 0.98,
+			Firerate:      600,
+			MovementSpeed: 220,
+			Team:          "ct",
+		},
+		"ssg08": {
+			Name:          "ssg08",
+			DisplayName:   "SSG 08",
+			Type:          "sniper",
+			Price:         1700,
+			KillReward:    300,
+			Damage:        88,
+			Accuracy:      0.95,
+			ArmorPen:      0.85,
+			RangeModifier: 0.98,
+			Firerate:      48,
+			MovementSpeed: 230,
+			Team:          "both",
+		},
+		"g3sg1": {
+			Name:          "g3sg1",
+			DisplayName:   "G3SG1",
+			Type:          "sniper",
+			Price:         5000,
+			KillReward:    300,
+			Damage:        80,
+			Accuracy:      0.90,
+			ArmorPen:      0.825,
+			RangeModifier: 0.98,
+			Firerate:      240,
+			MovementSpeed: 215,
+			Team:          "t",
+		},
+		"scar20": {
+			Name:          "scar20",
+			DisplayName:   "SCAR-20",
+			Type:          "sniper",
+			Price:         5000,
+			KillReward:    300,
+			Damage:        80,
+			Accuracy:      0.90,
+			ArmorPen:      0.825,
+			RangeModifier: 0.98,
+			Firerate:      240,
+			MovementSpeed: 215,
+			Team:          "ct",
+		},
+		"nova": {
+			Name:          "nova",
+			DisplayName:   "Nova",
+			Type:          "shotgun",
+			Price:         1050,
+			KillReward:    900,
+			Damage:        26,
+			Accuracy:      0.40,
+			ArmorPen:      0.50,
+			RangeModifier: 0.70,
+			Firerate:      68,
+			MovementSpeed: 220,
+			Team:          "both",
+		},
+		"xm1014": {
+			Name:          "xm1014",
+			DisplayName:   "XM1014",
+			Type:          "shotgun",
+			Price:         2000,
+			KillReward:    900,
+			Damage:        20,
+			Accuracy:      0.40,
+			ArmorPen:      0.80,
+			RangeModifier: 0.70,
+			Firerate:      171,
+			MovementSpeed: 215,
+			Team:          "both",
+		},
+		"sawedoff": {
+			Name:          "sawedoff",
+			DisplayName:   "Sawed-Off",
+			Type:          "shotgun",
+			Price:         1100,
+			KillReward:    900,
+			Damage:        32,
+			Accuracy:      0.35,
+			ArmorPen:      0.75,
+			RangeModifier: 0.45,
+			Firerate:      71,
+			MovementSpeed: 210,
+			Team:          "t",
+		},
+		"mag7": {
+			Name:          "mag7",
+			DisplayName:   "MAG-7",
+			Type:          "shotgun",
+			Price:         1300,
+			KillReward:    900,
+			Damage:        30,
+			Accuracy:      0.40,
+			ArmorPen:      0.75,
+			RangeModifier: 0.45,
+			Firerate:      71,
+			MovementSpeed: 225,
+			Team:          "ct",
+		},
+		"negev": {
+			Name:          "negev",
+			DisplayName:   "Negev",
+			Type:          "machinegun",
+			Price:         1700,
+			KillReward:    300,
+			Damage:        35,
+			Accuracy:      0.45,
+			ArmorPen:      0.71,
+			RangeModifier: 0.97,
+			Firerate:      800,
+			MovementSpeed: 150,
+			Team:          "both",
+		},
+		"m249": {
+			Name:          "m249",
+			DisplayName:   "M249",
+			Type:          "machinegun",
+			Price:         5200,
+			KillReward:    300,
+			Damage:        32,
+			Accuracy:      0.50,
+			ArmorPen:      0.80,
+			RangeModifier: 0.97,
+			Firerate:      750,
+			MovementSpeed: 195,
+			Team:          "both",
+		},
 	}
 }
 
@@ -313,18 +672,33 @@ func (em *EconomyManager) GetUtilityInfo() map[string]UtilityInfo {
 	}
 }
 
+// itemAliases maps the log names of armor to their utility info entries
+var itemAliases = map[string]string{
+	"assaultsuit": "vesthelm",
+	"kevlar":      "vest",
+}
+
+// ItemCategory classifies a purchasable item as "weapon", "armor", "grenade" or
+// "utility", accepting log names like "weapon_ak47" and "item_defuser".
+// Unknown items return an empty string.
+func (em *EconomyManager) ItemCategory(item string) string {
+	name := strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(item), "weapon_"), "item_")
+	if alias, ok := itemAliases[name]; ok {
+		name = alias
+	}
+
+	if _, ok := em.GetWeaponInfo()[name]; ok {
+		return "weapon"
+	}
+	if info, ok := em.GetUtilityInfo()[name]; ok {
+		return info.Type
+	}
+	return ""
+}
+
 // CalculateLossBonus calculates the loss bonus for a team
 func (em *EconomyManager) CalculateLossBonus(consecutiveLosses int) int {
-	baseLossBonus := 1400
-	bonusIncrement := 500
-	maxLossBonus := 3400
-	
-	lossBonus := baseLossBonus + (consecutiveLosses-1)*bonusIncrement
-	if lossBonus > maxLossBonus {
-		lossBonus = maxLossBonus
-	}
-	
-	return lossBonus
+	return LossBonusForStreak(em.LossBonusLadder, consecutiveLosses)
 }
 
 // CalculateWinBonus calculates the win bonus for a team
@@ -335,16 +709,55 @@ func (em *EconomyManager) CalculateWinBonus(winReason string) int {
 	return em.RoundWinBonus["elimination"] // Default bonus
 }
 
+// CalculateRoundWinBonus returns the win bonus for a side winning a round by reason,
+// or an error if that side cannot win a round that way
+func (em *EconomyManager) CalculateRoundWinBonus(side, winReason string) (int, error) {
+	normalized := NormalizeSide(side)
+	reasons, ok := sideWinReasons[normalized]
+	if !ok {
+		return 0, fmt.Errorf("unknown winning side %q", side)
+	}
+	for _, reason := range reasons {
+		if reason == winReason {
+			return em.CalculateWinBonus(winReason), nil
+		}
+	}
+	return 0, fmt.Errorf("%s cannot win a round by %q", normalized, winReason)
+}
+
+// killWeaponTypes maps the log names of knife and grenade kills to their kill reward type
+var killWeaponTypes = map[string]string{
+	"bayonet": "knife",
+	"inferno": "grenade", // Fire from a molotov or incendiary
+	"taser":   "zeus",
+}
+
 // CalculateKillReward calculates the kill reward for a weapon
 func (em *EconomyManager) CalculateKillReward(weaponName string) int {
+	weaponName = strings.TrimPrefix(strings.ToLower(weaponName), "weapon_")
+	
 	// First try to get exact weapon reward
 	if reward, exists := em.KillRewards[weaponName]; exists {
 		return reward
 	}
 	
-	// Try to get reward by weapon type
+	// Knife and grenade kills pay their type's reward, e.g. knife_t or hegrenade
+	weaponType := killWeaponTypes[weaponName]
+	if strings.HasPrefix(weaponName, "knife") {
+		weaponType = "knife"
+	} else if info, exists := em.GetUtilityInfo()[weaponName]; exists && info.Type == "grenade" {
+		weaponType = "grenade"
+	}
+	if reward, exists := em.KillRewards[weaponType]; exists {
+		return reward
+	}
+	
+	// Try to get reward by weapon, then by weapon type
 	weaponInfo := em.GetWeaponInfo()
 	if info, exists := weaponInfo[weaponName]; exists {
+		if info.KillReward > 0 {
+			return info.KillReward
+		}
 		if reward, exists := em.KillRewards[info.Type]; exists {
 			return reward
 		}
@@ -379,14 +792,79 @@ func (em *EconomyManager) CanAfford(playerMoney int, itemName string) bool {
 	return playerMoney >= price
 }
 
+// DefaultLossBonusLadder returns the CS2 loss bonus for each consecutive loss
+func DefaultLossBonusLadder() []int {
+	return []int{1400, 1900, 2400, 2900, 3400}
+}
+
+// LossBonusForStreak returns the ladder step for a loss streak. Streaks longer than
+// the ladder keep paying the last step and an empty ladder falls back to the default.
+func LossBonusForStreak(ladder []int, consecutiveLosses int) int {
+	if len(ladder) == 0 {
+		ladder = DefaultLossBonusLadder()
+	}
+	
+	index := consecutiveLosses - 1
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(ladder) {
+		index = len(ladder) - 1
+	}
+	return ladder[index]
+}
+
+// ValidateLossBonusLadder checks that a loss bonus ladder is non-empty, non-negative
+// and never decreases as the loss streak grows
+func ValidateLossBonusLadder(ladder []int) error {
+	if len(ladder) == 0 {
+		return fmt.Errorf("loss bonus ladder must not be empty")
+	}
+	
+	for i, bonus := range ladder {
+		if bonus < 0 {
+			return fmt.Errorf("loss bonus ladder step %d is negative: %d", i+1, bonus)
+		}
+		if i > 0 && bonus < ladder[i-1] {
+			return fmt.Errorf("loss bonus ladder must not decrease: step %d (%d) is below step %d (%d)", i+1, bonus, i, ladder[i-1])
+		}
+	}
+	return nil
+}
+
+// BuyTypes lists the team buy types a round can be forced into; anti-eco rounds
+// depend on the opponent, so they are only ever decided by the simulation
+var BuyTypes = []string{"eco", "force_buy", "full_buy"}
+
+// IsValidBuyType checks if a buy type is known
+func IsValidBuyType(buyType string) bool {
+	for _, known := range BuyTypes {
+		if buyType == known {
+			return true
+		}
+	}
+	return false
+}
+
 // GetOptimalBuy suggests an optimal buy for a player
 func (em *EconomyManager) GetOptimalBuy(player *Player, teamEconomy *TeamEconomy, roundType string) []string {
 	money := player.Economy.Money
 	var buy []string
 	
-	// Determine buy type based on money and team economy
+	// Use the requested buy type, otherwise determine it from team economy
 	avgMoney := teamEconomy.AverageMoney
 	
+	switch roundType {
+	case "full_buy":
+		return em.getFullBuy(player, money)
+	case "force_buy":
+		return em.getForceBuy(player, money)
+	case "anti_eco":
+		return em.getAntiEcoBuy(player, money)
+	case "eco":
+		return em.getEcoBuy(player, money)
+	}
+	
 	if avgMoney >= 5000 {
 		// Full buy round
 		buy = em.getFullBuy(player, money)
@@ -408,7 +886,7 @@ func (em *EconomyManager) getFullBuy(player *Player, money int) []string {
 	
 	// Primary weapon based on side and role
 	var primary string
-	if strings.EqualFold(player.Side, "CT") {
+	if NormalizeSide(player.Side) == "CT" {
 		if player.Role == "awp" && remaining >= 4750 {
 			primary = "awp"
 		} else if remaining >= 3100 {
@@ -447,13 +925,18 @@ func (em *EconomyManager) getFullBuy(player *Player, money int) []string {
 		buy = append(buy, "flashbang")
 		remaining -= 200
 	}
+	fire := FireGrenadeForSide(player.Side)
+	if price := em.GetUtilityPrice(fire); remaining >= price {
+		buy = append(buy, fire)
+		remaining -= price
+	}
 	if remaining >= 300 {
 		buy = append(buy, "hegrenade")
 		remaining -= 300
 	}
 	
 	// Defuse kit for CT
-	if strings.EqualFold(player.Side, "CT") && remaining >= 400 {
+	if NormalizeSide(player.Side) == "CT" && remaining >= 400 {
 		buy = append(buy, "defuser")
 		remaining -= 400
 	}
@@ -461,6 +944,15 @@ func (em *EconomyManager) getFullBuy(player *Player, money int) []string {
 	return buy
 }
 
+// FireGrenadeForSide returns the fire grenade a side can buy: incendiaries for CTs,
+// molotovs for terrorists
+func FireGrenadeForSide(side string) string {
+	if NormalizeSide(side) == "CT" {
+		return "incgrenade"
+	}
+	return "molotov"
+}
+
 // getForceBuy returns a force buy recommendation
 func (em *EconomyManager) getForceBuy(player *Player, money int) []string {
 	var buy []string
@@ -468,7 +960,7 @@ func (em *EconomyManager) getForceBuy(player *Player, money int) []string {
 	
 	// Cheaper primary weapons
 	var primary string
-	if strings.EqualFold(player.Side, "CT") {
+	if NormalizeSide(player.Side) == "CT" {
 		if remaining >= 2050 {
 			primary = "famas"
 		} else if remaining >= 1250 {
@@ -502,6 +994,41 @@ func (em *EconomyManager) getForceBuy(player *Player, money int) []string {
 	return buy
 }
 
+// getAntiEcoBuy returns a buy against a saving opponent: a cheap SMG for its kill
+// reward, armor and grenades
+func (em *EconomyManager) getAntiEcoBuy(player *Player, money int) []string {
+	var buy []string
+	remaining := money
+	
+	primary := "mac10"
+	if NormalizeSide(player.Side) == "CT" {
+		primary = "mp9"
+	}
+	if price := em.GetWeaponPrice(primary); remaining >= price {
+		buy = append(buy, primary)
+		remaining -= price
+	}
+	
+	if remaining >= 1000 {
+		buy = append(buy, "vesthelm")
+		remaining -= 1000
+	} else if remaining >= 650 {
+		buy = append(buy, "vest")
+		remaining -= 650
+	}
+	
+	if remaining >= 300 {
+		buy = append(buy, "hegrenade")
+		remaining -= 300
+	}
+	if remaining >= 200 {
+		buy = append(buy, "flashbang")
+		remaining -= 200
+	}
+	
+	return buy
+}
+
 // getEcoBuy returns an eco round recommendation
 func (em *EconomyManager) getEcoBuy(player *Player, money int) []string {
 	var buy []string
@@ -512,7 +1039,7 @@ func (em *EconomyManager) getEcoBuy(player *Player, money int) []string {
 		buy = append(buy, "deagle")
 		remaining -= 700
 	} else if remaining >= 500 {
-		if strings.EqualFold(player.Side, "CT") {
+		if NormalizeSide(player.Side) == "CT" {
 			buy = append(buy, "fiveseven")
 		} else {
 			buy = append(buy, "tec9")
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestEconomyManager_CalculateKillReward(t *testing.T) {
+	em := NewEconomyManager()
+
+	expected := map[string]int{
+		// Pistols
+		"glock":        300,
+		"usp_silencer": 300,
+		"p250":         300,
+		"tec9":         300,
+		"fiveseven":    300,
+		"cz75a":        100,
+		"deagle":       300,
+		"revolver":     300,
+
+		// SMGs
+		"mac10": 600,
+		"mp9":   600,
+		"mp7":   600,
+		"ump45": 600,
+		"p90":   300,
+		"bizon": 600,
+
+		// Rifles
+		"famas":         300,
+		"galil":         300,
+		"m4a4":          300,
+		"m4a1_silencer": 300,
+		"ak47":          300,
+		"sg556":         300,
+		"aug":           300,
+
+		// Sniper Rifles
+		"ssg08":  300,
+		"awp":    100,
+		"g3sg1":  300,
+		"scar20": 300,
+
+		// Shotguns
+		"nova":     900,
+		"xm1014":   900,
+		"sawedoff": 900,
+		"mag7":     900,
+
+		// Machine Guns
+		"negev": 300,
+		"m249":  300,
+	}
+
+	// Every weapon in the price table must resolve to a known reward
+	weaponInfo := em.GetWeaponInfo()
+	for weapon := range em.WeaponPrices {
+		if _, exists := weaponInfo[weapon]; !exists {
+			t.Errorf("Weapon %s is missing from GetWeaponInfo", weapon)
+		}
+		if _, exists := expected[weapon]; !exists {
+			t.Errorf("Weapon %s has no expected kill reward", weapon)
+		}
+	}
+
+	for weapon, reward := range expected {
+		t.Run(weapon, func(t *testing.T) {
+			if got := em.CalculateKillReward(weapon); got != reward {
+				t.Errorf("CalculateKillReward(%s) = %d, expected %d", weapon, got, reward)
+			}
+
+			info := weaponInfo[weapon]
+			if info.Price != em.GetWeaponPrice(weapon) {
+				t.Errorf("Weapon %s info price %d does not match price table %d", weapon, info.Price, em.GetWeaponPrice(weapon))
+			}
+		})
+	}
+}
+
+func TestEconomyManager_CalculateKnifeAndGrenadeKillRewards(t *testing.T) {
+	em := NewEconomyManager()
+
+	expected := map[string]int{
+		"knife":          1500,
+		"knife_t":        1500,
+		"knife_karambit": 1500,
+		"bayonet":        1500,
+		"weapon_knife":   1500,
+		"hegrenade":      300,
+		"molotov":        300,
+		"incgrenade":     300,
+		"inferno":        300,
+		"taser":          300,
+	}
+	for weapon, reward := range expected {
+		if got := em.CalculateKillReward(weapon); got != reward {
+			t.Errorf("CalculateKillReward(%s) = %d, expected %d", weapon, got, reward)
+		}
+	}
+}
+
+func TestEconomyManager_CalculateRoundWinBonus(t *testing.T) {
+	em := NewEconomyManager()
+
+	testCases := []struct {
+		side     string
+		reason   string
+		expected int
+		valid    bool
+	}{
+		{"CT", "elimination", 3250, true},
+		{"CT", "bomb_defused", 3500, true},
+		{"CT", "time", 3250, true},
+		{"CT", "bomb_exploded", 0, false},
+		{"TERRORIST", "elimination", 3250, true},
+		{"TERRORIST", "bomb_exploded", 3500, true},
+		{"TERRORIST", "bomb_defused", 0, false},
+		{"TERRORIST", "time", 0, false},
+		{"T", "bomb_exploded", 3500, true},
+		{"SPECTATOR", "elimination", 0, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.side+"/"+tc.reason, func(t *testing.T) {
+			bonus, err := em.CalculateRoundWinBonus(tc.side, tc.reason)
+			if tc.valid != (err == nil) {
+				t.Fatalf("Expected valid=%v, got error %v", tc.valid, err)
+			}
+			if bonus != tc.expected {
+				t.Errorf("Expected bonus %d, got %d", tc.expected, bonus)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package models
+
+import "sort"
+
+// EventIndex keeps a match's events ordered by tick so that tick ranges can be
+// looked up with a binary search instead of scanning every event
+type EventIndex struct {
+	events []GameEvent
+}
+
+// NewEventIndex indexes events by tick. Events sharing a tick keep their original order.
+func NewEventIndex(events []GameEvent) *EventIndex {
+	sorted := make([]GameEvent, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].GetTick() < sorted[j].GetTick()
+	})
+	return &EventIndex{events: sorted}
+}
+
+// Len returns the number of indexed events
+func (ix *EventIndex) Len() int {
+	return len(ix.events)
+}
+
+// EventsInTickRange returns the events with start <= tick <= end in tick order.
+// The returned slice shares the index's backing array and must not be modified.
+func (ix *EventIndex) EventsInTickRange(start, end int64) []GameEvent {
+	if end < start {
+		return nil
+	}
+	from := sort.Search(len(ix.events), func(i int) bool {
+		return ix.events[i].GetTick() >= start
+	})
+	to := sort.Search(len(ix.events), func(i int) bool {
+		return ix.events[i].GetTick() > end
+	})
+	return ix.events[from:to:to]
+}
@@ -0,0 +1,82 @@
+package models
+
+import "testing"
+
+func newTickEvent(tick int64, round int) GameEvent {
+	return &RoundStartEvent{BaseEvent: BaseEvent{Type: "round_start", Tick: tick, Round: round}}
+}
+
+func TestEventIndex_EventsInTickRange(t *testing.T) {
+	// Out of order input, with two events sharing tick 64
+	events := []GameEvent{newTickEvent(128, 1), newTickEvent(64, 2), newTickEvent(0, 3), newTickEvent(64, 4), newTickEvent(256, 5)}
+	index := NewEventIndex(events)
+
+	testCases := []struct {
+		name       string
+		start, end int64
+		expected   []int // rounds of the returned events, in order
+	}{
+		{"everything", 0, 256, []int{3, 2, 4, 1, 5}},
+		{"inclusive bounds", 64, 128, []int{2, 4, 1}},
+		{"single tick", 64, 64, []int{2, 4}},
+		{"between events", 65, 127, nil},
+		{"past the end", 257, 1000, nil},
+		{"reversed range", 128, 64, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			found := index.EventsInTickRange(tc.start, tc.end)
+			if len(found) != len(tc.expected) {
+				t.Fatalf("Expected %d events, got %d", len(tc.expected), len(found))
+			}
+			for i, event := range found {
+				if event.GetRound() != tc.expected[i] {
+					t.Errorf("Event %d: expected round %d, got %d", i, tc.expected[i], event.GetRound())
+				}
+			}
+		})
+	}
+
+	if events[0].GetTick() != 128 {
+		t.Error("Expected indexing to leave the original events untouched")
+	}
+}
+
+// benchmarkEvents builds a 50k-event match with a few events per tick
+func benchmarkEvents() []GameEvent {
+	events := make([]GameEvent, 50000)
+	for i := range events {
+		events[i] = newTickEvent(int64(i/3), i/2000+1)
+	}
+	return events
+}
+
+// eventsInTickRangeLinear is the unindexed scan the index replaces
+func eventsInTickRangeLinear(events []GameEvent, start, end int64) []GameEvent {
+	var found []GameEvent
+	for _, event := range events {
+		if tick := event.GetTick(); tick >= start && tick <= end {
+			found = append(found, event)
+		}
+	}
+	return found
+}
+
+func BenchmarkEventIndex_TickRange(b *testing.B) {
+	index := NewEventIndex(benchmarkEvents())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		start := int64(i % 16000)
+		index.EventsInTickRange(start, start+64)
+	}
+}
+
+func BenchmarkEventIndex_LinearScan(b *testing.B) {
+	events := benchmarkEvents()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		start := int64(i % 16000)
+		eventsInTickRangeLinear(events, start, start+64)
+	}
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// ValidateEventOrdering checks that event ticks and timestamps never go backwards
+func ValidateEventOrdering(events []GameEvent) error {
+	for i := 1; i < len(events); i++ {
+		prev, current := events[i-1], events[i]
+
+		if current.GetTick() < prev.GetTick() {
+			return fmt.Errorf("event %d (%s) at tick %d is before event %d (%s) at tick %d",
+				i, current.GetType(), current.GetTick(), i-1, prev.GetType(), prev.GetTick())
+		}
+		if current.GetTimestamp().Before(prev.GetTimestamp()) {
+			return fmt.Errorf("event %d (%s) at %s is before event %d (%s) at %s",
+				i, current.GetType(), current.GetTimestamp().Format(time.RFC3339Nano),
+				i-1, prev.GetType(), prev.GetTimestamp().Format(time.RFC3339Nano))
+		}
+	}
+	return nil
+}
+
+// RepairEventTimestamps recomputes event timestamps from their ticks relative to the match start
+func RepairEventTimestamps(events []GameEvent, start time.Time, tickRate int) {
+	if tickRate <= 0 {
+		return
+	}
+	for _, event := range events {
+		event.SetTimestamp(start.Add(TicksToDuration(event.GetTick(), tickRate)))
+	}
+}
+
+// TicksToDuration converts a tick count to game time at the given tick rate
+func TicksToDuration(ticks int64, tickRate int) time.Duration {
+	if tickRate <= 0 {
+		return 0
+	}
+	return time.Duration(ticks) * time.Second / time.Duration(tickRate)
+}
+
+// DurationToTicks converts game time to a tick count at the given tick rate
+func DurationToTicks(d time.Duration, tickRate int) int64 {
+	return int64(d) * int64(tickRate) / int64(time.Second)
+}
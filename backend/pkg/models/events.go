@@ -12,6 +12,9 @@ type GameEvent interface {
 	GetTimestamp() time.Time
 	GetType() string
 	GetTick() int64
+	GetRound() int
+	SetTick(tick int64)
+	SetTimestamp(timestamp time.Time)
 	ToLogLine() string
 	ToJSON() ([]byte, error)
 }
@@ -39,20 +42,43 @@ func (e *BaseEvent) GetTick() int64 {
 	return e.Tick
 }
 
+// GetRound returns the round the event belongs to
+func (e *BaseEvent) GetRound() int {
+	return e.Round
+}
+
+// SetTick sets the server tick
+func (e *BaseEvent) SetTick(tick int64) {
+	e.Tick = tick
+}
+
+// SetTimestamp sets the event timestamp
+func (e *BaseEvent) SetTimestamp(timestamp time.Time) {
+	e.Timestamp = timestamp
+}
+
 // KillEvent represents a player kill event
 type KillEvent struct {
 	BaseEvent
 	Attacker      *Player `json:"attacker"`
 	Victim        *Player `json:"victim"`
 	Assister      *Player `json:"assister,omitempty"`
+	FlashAssister *Player `json:"flash_assister,omitempty"` // Teammate of the attacker whose flash blinded the victim
 	Weapon        string  `json:"weapon"`
 	Headshot      bool    `json:"headshot"`
+	Hitgroup      int     `json:"hitgroup"` // Hitgroup of the fatal shot, same values as PlayerHurtEvent
 	Penetrated    int     `json:"penetrated"`
 	NoScope       bool    `json:"no_scope"`
 	AttackerBlind bool    `json:"attacker_blind"`
+	InAir         bool    `json:"in_air"` // Attacker was airborne, e.g. a jumpshot
 	Distance      float64 `json:"distance"`
 	AttackerPos   Vector3 `json:"attacker_pos"`
 	VictimPos     Vector3 `json:"victim_pos"`
+	FirstBlood    bool    `json:"first_blood,omitempty"` // First kill of the round
+	
+	// Cosmetic weapon details, only set when weapon skins are enabled
+	WeaponSkin    string  `json:"weapon_skin,omitempty"`
+	StatTrakKills int     `json:"stat_trak_kills,omitempty"`
 }
 
 // ToLogLine converts the kill event to CS2 log format
@@ -79,6 +105,18 @@ func (e *KillEvent) ToLogLine() string {
 	if e.AttackerBlind {
 		logLine += " (attackerblind)"
 	}
+	if e.InAir {
+		logLine += " (attackerinair)"
+	}
+	
+	if e.Assister != nil {
+		logLine += "\n" + fmt.Sprintf(`L %s: "%s<%d><%s><%s>" assisted killing %s`, 
+			timestamp, e.Assister.Name, e.Assister.UserID, e.Assister.SteamID, e.Assister.Side, victimInfo)
+	}
+	if e.FlashAssister != nil {
+		logLine += "\n" + fmt.Sprintf(`L %s: "%s<%d><%s><%s>" flash-assisted killing %s`, 
+			timestamp, e.FlashAssister.Name, e.FlashAssister.UserID, e.FlashAssister.SteamID, e.FlashAssister.Side, victimInfo)
+	}
 	
 	return logLine
 }
@@ -145,10 +183,10 @@ func (e *RoundEndEvent) ToLogLine() string {
 	}
 	
 	logLine := fmt.Sprintf(`L %s: Team "%s" triggered "%s" (CT "%d") (T "%d")`, 
-		timestamp, e.Winner, logReason, e.CTScore, e.TScore)
+		timestamp, LogSide(e.Winner), logReason, e.CTScore, e.TScore)
 	
 	if e.MVP != nil {
-		logLine += fmt.Sprintf(`\nL %s: "%s<%d><%s><%s>" triggered "MVP"`, 
+		logLine += "\n" + fmt.Sprintf(`L %s: "%s<%d><%s><%s>" triggered "MVP"`, 
 			timestamp, e.MVP.Name, e.MVP.UserID, e.MVP.SteamID, e.MVP.Side)
 	}
 	
@@ -184,6 +222,92 @@ func (e *BombPlantEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
 
+// BombDropEvent is logged when the bomb carrier drops the bomb, usually by dying
+type BombDropEvent struct {
+	BaseEvent
+	Player   *Player `json:"player"`
+	Position Vector3 `json:"position"` // Where the bomb lies
+}
+
+// ToLogLine converts the bomb drop event to CS2 log format
+func (e *BombDropEvent) ToLogLine() string {
+	timestamp := e.Timestamp.Format("01/02/2006 - 15:04:05")
+	
+	return fmt.Sprintf(`L %s: "%s<%d><%s><%s>" triggered "Dropped_The_Bomb"`, 
+		timestamp, e.Player.Name, e.Player.UserID, e.Player.SteamID, e.Player.Side)
+}
+
+// ToJSON converts the event to JSON
+func (e *BombDropEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// BombPickupEvent is logged when a terrorist gets the bomb, at spawn or from the ground
+type BombPickupEvent struct {
+	BaseEvent
+	Player   *Player `json:"player"`
+	Position Vector3 `json:"position"`
+}
+
+// ToLogLine converts the bomb pickup event to CS2 log format
+func (e *BombPickupEvent) ToLogLine() string {
+	timestamp := e.Timestamp.Format("01/02/2006 - 15:04:05")
+	
+	return fmt.Sprintf(`L %s: "%s<%d><%s><%s>" triggered "Got_The_Bomb"`, 
+		timestamp, e.Player.Name, e.Player.UserID, e.Player.SteamID, e.Player.Side)
+}
+
+// ToJSON converts the event to JSON
+func (e *BombPickupEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// WeaponPickupEvent is logged when a player picks up a weapon without buying it
+type WeaponPickupEvent struct {
+	BaseEvent
+	Player   *Player `json:"player"`
+	From     *Player `json:"from,omitempty"` // Dead player the weapon belonged to
+	Weapon   string  `json:"weapon"`
+	Position Vector3 `json:"position"`
+}
+
+// ToLogLine converts the weapon pickup event to CS2 log format
+func (e *WeaponPickupEvent) ToLogLine() string {
+	timestamp := e.Timestamp.Format("01/02/2006 - 15:04:05")
+	
+	return fmt.Sprintf(`L %s: "%s<%d><%s><%s>" picked up "%s"`, 
+		timestamp, e.Player.Name, e.Player.UserID, e.Player.SteamID, e.Player.Side, e.Weapon)
+}
+
+// ToJSON converts the event to JSON
+func (e *WeaponPickupEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// PlayerPositionEvent is a sampled position of an alive player, used for radar playback
+type PlayerPositionEvent struct {
+	BaseEvent
+	Player    *Player `json:"player"`
+	Position  Vector3 `json:"position"`
+	ViewAngle Vector3 `json:"view_angle"`
+}
+
+// ToLogLine converts the position sample to a log line in the style of getpos output, e.g.
+// "P<7><STEAM_1:0:1><CT>" position [100 250 0] angle [0 90 0]
+func (e *PlayerPositionEvent) ToLogLine() string {
+	timestamp := e.Timestamp.Format("01/02/2006 - 15:04:05")
+
+	return fmt.Sprintf(`L %s: "%s<%d><%s><%s>" position [%d %d %d] angle [%d %d %d]`,
+		timestamp, e.Player.Name, e.Player.UserID, e.Player.SteamID, e.Player.Side,
+		int(e.Position.X), int(e.Position.Y), int(e.Position.Z),
+		int(e.ViewAngle.X), int(e.ViewAngle.Y), int(e.ViewAngle.Z))
+}
+
+// ToJSON converts the event to JSON
+func (e *PlayerPositionEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
 // BombDefuseEvent represents a bomb defuse event
 type BombDefuseEvent struct {
 	BaseEvent
@@ -283,6 +407,46 @@ func (e *PlayerConnectEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
 
+// PlayerEnterEvent represents a player entering the game after connecting
+type PlayerEnterEvent struct {
+	BaseEvent
+	Player *Player `json:"player"`
+}
+
+// ToLogLine converts the player enter event to CS2 log format
+func (e *PlayerEnterEvent) ToLogLine() string {
+	timestamp := e.Timestamp.Format("01/02/2006 - 15:04:05")
+
+	return fmt.Sprintf(`L %s: "%s<%d><%s><>" entered the game`,
+		timestamp, e.Player.Name, e.Player.UserID, e.Player.SteamID)
+}
+
+// ToJSON converts the event to JSON
+func (e *PlayerEnterEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// NameChangeEvent represents a player changing their name
+type NameChangeEvent struct {
+	BaseEvent
+	Player  *Player `json:"player"`
+	OldName string  `json:"old_name"`
+	NewName string  `json:"new_name"`
+}
+
+// ToLogLine converts the name change event to CS2 log format
+func (e *NameChangeEvent) ToLogLine() string {
+	timestamp := e.Timestamp.Format("01/02/2006 - 15:04:05")
+	playerInfo := fmt.Sprintf(`"%s<%d><%s><%s>"`, e.OldName, e.Player.UserID, e.Player.SteamID, e.Player.Side)
+
+	return fmt.Sprintf(`L %s: %s changed name to "%s"`, timestamp, playerInfo, e.NewName)
+}
+
+// ToJSON converts the event to JSON
+func (e *NameChangeEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
 // PlayerDisconnectEvent represents a player disconnection event
 type PlayerDisconnectEvent struct {
 	BaseEvent
@@ -330,6 +494,31 @@ func (e *ItemPurchaseEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
 
+// PurchaseRejectedEvent represents a purchase the server refused, such as one
+// attempted outside the buy zone or after the buy time
+type PurchaseRejectedEvent struct {
+	BaseEvent
+	Player *Player `json:"player"`
+	Item   string  `json:"item"`
+	Reason string  `json:"reason"`
+}
+
+// ToLogLine converts the rejected purchase to a CS2 console line
+func (e *PurchaseRejectedEvent) ToLogLine() string {
+	timestamp := e.Timestamp.Format("01/02/2006 - 15:04:05")
+	
+	playerInfo := fmt.Sprintf(`"%s<%d><%s><%s>"`, 
+		e.Player.Name, e.Player.UserID, e.Player.SteamID, e.Player.Side)
+	
+	return fmt.Sprintf(`L %s: %s cannot purchase "%s": %s`, 
+		timestamp, playerInfo, e.Item, e.Reason)
+}
+
+// ToJSON converts the event to JSON
+func (e *PurchaseRejectedEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
 // GrenadeThrowEvent represents a grenade thrown event
 type GrenadeThrowEvent struct {
 	BaseEvent
@@ -337,17 +526,23 @@ type GrenadeThrowEvent struct {
 	GrenadeType string  `json:"grenade_type"`
 	Position    Vector3 `json:"position"`
 	Velocity    Vector3 `json:"velocity"`
+	EntityIndex int     `json:"entindex,omitempty"` // Entity of a flashbang, shared with its blind lines
 }
 
-// ToLogLine converts the grenade throw event to CS2 log format
+// ToLogLine converts the grenade throw event to CS2 log format, e.g.
+// "P<7><STEAM_1:0:1><TERRORIST>" threw flashbang [-416 1446 -59] flashbang entindex 347)
 func (e *GrenadeThrowEvent) ToLogLine() string {
 	timestamp := e.Timestamp.Format("01/02/2006 - 15:04:05")
 	
 	playerInfo := fmt.Sprintf(`"%s<%d><%s><%s>"`, 
 		e.Player.Name, e.Player.UserID, e.Player.SteamID, e.Player.Side)
 	
-	return fmt.Sprintf(`L %s: %s threw %s`, 
-		timestamp, playerInfo, e.GrenadeType)
+	logLine := fmt.Sprintf(`L %s: %s threw %s [%d %d %d]`, 
+		timestamp, playerInfo, e.GrenadeType, int(e.Position.X), int(e.Position.Y), int(e.Position.Z))
+	if e.GrenadeType == "flashbang" && e.EntityIndex > 0 {
+		logLine += fmt.Sprintf(` flashbang entindex %d)`, e.EntityIndex)
+	}
+	return logLine
 }
 
 // ToJSON converts the event to JSON
@@ -389,26 +584,33 @@ type FlashbangEvent struct {
 	Player    *Player   `json:"player"`
 	Position  Vector3   `json:"position"`
 	Flashed   []*Player `json:"flashed"`   // Players that were flashed
-	Duration  float64   `json:"duration"`  // Flash duration in seconds
+	Duration  float64   `json:"duration"`  // Flash duration in seconds, the longest blind
+	Durations []float64 `json:"durations,omitempty"` // Blind duration of each flashed player, in order
+	EntityIndex int     `json:"entindex,omitempty"` // Entity of the flashbang, shared with its throw line
 }
 
-// ToLogLine converts the flashbang event to CS2 log format
+// ToLogLine converts the flashbang detonation to CS2 log format: one blind line per
+// flashed player, e.g. "V<3><STEAM_1:0:2><CT>" blinded for 2.53 by "P<7><STEAM_1:0:1><TERRORIST>"
+// from flashbang entindex 347. CS2 logs the throw separately, after the blind lines.
 func (e *FlashbangEvent) ToLogLine() string {
 	timestamp := e.Timestamp.Format("01/02/2006 - 15:04:05")
 	
 	playerInfo := fmt.Sprintf(`"%s<%d><%s><%s>"`, 
 		e.Player.Name, e.Player.UserID, e.Player.SteamID, e.Player.Side)
 	
-	logLine := fmt.Sprintf(`L %s: %s threw flashbang`, timestamp, playerInfo)
-	
-	for _, flashed := range e.Flashed {
+	lines := make([]string, 0, len(e.Flashed))
+	for i, flashed := range e.Flashed {
 		flashedInfo := fmt.Sprintf(`"%s<%d><%s><%s>"`, 
 			flashed.Name, flashed.UserID, flashed.SteamID, flashed.Side)
-		logLine += fmt.Sprintf(`\nL %s: %s blinded %s with flashbang for %.1f`, 
-			timestamp, playerInfo, flashedInfo, e.Duration)
+		duration := e.Duration
+		if i < len(e.Durations) {
+			duration = e.Durations[i]
+		}
+		lines = append(lines, fmt.Sprintf(`L %s: %s blinded for %.2f by %s from flashbang entindex %d `, 
+			timestamp, flashedInfo, duration, playerInfo, e.EntityIndex))
 	}
 	
-	return logLine
+	return strings.Join(lines, "\n")
 }
 
 // ToJSON converts the event to JSON
@@ -454,6 +656,41 @@ func (e *ChatEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
 
+// Observer message sources
+const (
+	ObserverSourceConsole = "console" // Typed into the server console, e.g. by a caster
+	ObserverSourceServer  = "server"  // Broadcast by the server itself
+)
+
+// ObserverChatEvent represents a message from a caster, observer or admin
+// rather than from a player in the match
+type ObserverChatEvent struct {
+	BaseEvent
+	Source  string `json:"source"`            // ObserverSourceConsole or ObserverSourceServer
+	Speaker string `json:"speaker,omitempty"` // Caster or observer the message is attributed to
+	Message string `json:"message"`
+}
+
+// ToLogLine converts the observer message to a Console or Server say line
+func (e *ObserverChatEvent) ToLogLine() string {
+	timestamp := e.Timestamp.Format("01/02/2006 - 15:04:05")
+	
+	message := e.Message
+	if e.Speaker != "" {
+		message = fmt.Sprintf("[%s] %s", e.Speaker, message)
+	}
+	
+	if e.Source == ObserverSourceConsole {
+		return fmt.Sprintf(`L %s: "Console<0><Console><Console>" say "%s"`, timestamp, message)
+	}
+	return fmt.Sprintf(`L %s: Server say "%s"`, timestamp, message)
+}
+
+// ToJSON converts the event to JSON
+func (e *ObserverChatEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
 // TeamSwitchEvent represents a player switching teams
 type TeamSwitchEvent struct {
 	BaseEvent
@@ -490,7 +727,7 @@ type ServerCommandEvent struct {
 func (e *ServerCommandEvent) ToLogLine() string {
 	timestamp := e.Timestamp.Format("01/02/2006 - 15:04:05")
 	
-	return fmt.Sprintf(`L %s: Server cvar "%s" = "%s"`, 
+	return fmt.Sprintf(`L %s: server_cvar: "%s" "%s"`, 
 		timestamp, e.Command, e.Args)
 }
 
@@ -499,6 +736,69 @@ func (e *ServerCommandEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
 
+// ServerStatusEvent is a periodic snapshot of the server, like the output of the status command
+type ServerStatusEvent struct {
+	BaseEvent
+	Hostname string `json:"hostname"`
+	Map      string `json:"map"`
+	Players  int    `json:"players"` // Connected players
+	Uptime   int64  `json:"uptime"`  // Seconds since the log started
+}
+
+// ToLogLine converts the server status event to a log line
+func (e *ServerStatusEvent) ToLogLine() string {
+	timestamp := e.Timestamp.Format("01/02/2006 - 15:04:05")
+	
+	return fmt.Sprintf(`L %s: server_status: hostname "%s" map "%s" players "%d" uptime "%d"`, 
+		timestamp, e.Hostname, e.Map, e.Players, e.Uptime)
+}
+
+// ToJSON converts the event to JSON
+func (e *ServerStatusEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// VetoEvent is one step of the map veto before a match, logged ahead of the map loading
+type VetoEvent struct {
+	BaseEvent
+	Team   string `json:"team,omitempty"` // Team that banned or picked, empty for the decider
+	Action string `json:"action"`         // "ban", "pick" or "decider"
+	Map    string `json:"map"`
+}
+
+// ToLogLine converts the veto event to a log line
+func (e *VetoEvent) ToLogLine() string {
+	timestamp := e.Timestamp.Format("01/02/2006 - 15:04:05")
+	
+	return fmt.Sprintf(`L %s: map_veto: team "%s" action "%s" map "%s"`, 
+		timestamp, e.Team, e.Action, e.Map)
+}
+
+// ToJSON converts the event to JSON
+func (e *VetoEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// MVPEvent names the MVP of a round, logged right after its round_end
+type MVPEvent struct {
+	BaseEvent
+	Player *Player `json:"player"`
+	Reason string  `json:"reason"` // "kills" or "objective" for the planter of an exploded bomb or the defuser
+}
+
+// ToLogLine converts the MVP event to CS2 log format
+func (e *MVPEvent) ToLogLine() string {
+	timestamp := e.Timestamp.Format("01/02/2006 - 15:04:05")
+	
+	return fmt.Sprintf(`L %s: "%s<%d><%s><%s>" triggered "MVP"`, 
+		timestamp, e.Player.Name, e.Player.UserID, e.Player.SteamID, e.Player.Side)
+}
+
+// ToJSON converts the event to JSON
+func (e *MVPEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
 // NewBaseEvent creates a new base event with current timestamp
 func NewBaseEvent(eventType string, tick int64, round int) BaseEvent {
 	return BaseEvent{
@@ -544,6 +844,62 @@ func (f *EventFactory) CreateKillEvent(attacker, victim *Player, weapon string,
 	}
 }
 
+// CreatePlayerConnectEvent creates a new player connect event
+func (f *EventFactory) CreatePlayerConnectEvent(player *Player, address string) *PlayerConnectEvent {
+	return &PlayerConnectEvent{
+		BaseEvent: NewBaseEvent("player_connect", f.currentTick, f.currentRound),
+		Player:    player,
+		Address:   address,
+	}
+}
+
+// CreatePlayerEnterEvent creates a new player enter event
+func (f *EventFactory) CreatePlayerEnterEvent(player *Player) *PlayerEnterEvent {
+	return &PlayerEnterEvent{
+		BaseEvent: NewBaseEvent("player_enter", f.currentTick, f.currentRound),
+		Player:    player,
+	}
+}
+
+// CreateNameChangeEvent creates a new name change event
+func (f *EventFactory) CreateNameChangeEvent(player *Player, oldName, newName string) *NameChangeEvent {
+	return &NameChangeEvent{
+		BaseEvent: NewBaseEvent("player_name_change", f.currentTick, f.currentRound),
+		Player:    player,
+		OldName:   oldName,
+		NewName:   newName,
+	}
+}
+
+// CreateTeamSwitchEvent creates a new team switch event
+func (f *EventFactory) CreateTeamSwitchEvent(player *Player, fromTeam, toTeam string) *TeamSwitchEvent {
+	return &TeamSwitchEvent{
+		BaseEvent: NewBaseEvent("team_switch", f.currentTick, f.currentRound),
+		Player:    player,
+		FromTeam:  fromTeam,
+		ToTeam:    toTeam,
+	}
+}
+
+// CreateObserverChatEvent creates a new caster, observer or admin message event
+func (f *EventFactory) CreateObserverChatEvent(source, speaker, message string) *ObserverChatEvent {
+	return &ObserverChatEvent{
+		BaseEvent: NewBaseEvent("observer_chat", f.currentTick, f.currentRound),
+		Source:    source,
+		Speaker:   speaker,
+		Message:   message,
+	}
+}
+
+// CreateServerCommandEvent creates a new server cvar event
+func (f *EventFactory) CreateServerCommandEvent(command, args string) *ServerCommandEvent {
+	return &ServerCommandEvent{
+		BaseEvent: NewBaseEvent("server_cvar", f.currentTick, f.currentRound),
+		Command:   command,
+		Args:      args,
+	}
+}
+
 // CreateRoundStartEvent creates a new round start event
 func (f *EventFactory) CreateRoundStartEvent(ctScore, tScore, ctPlayers, tPlayers int) *RoundStartEvent {
 	return &RoundStartEvent{
@@ -0,0 +1,208 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPreambleEvents_ToLogLine(t *testing.T) {
+	timestamp := time.Date(2025, 3, 14, 18, 30, 5, 0, time.UTC)
+	player := &Player{Name: "s1mple", UserID: 3, SteamID: "STEAM_1:0:123", Side: "CT"}
+
+	enter := &PlayerEnterEvent{BaseEvent: BaseEvent{Timestamp: timestamp}, Player: player}
+	nameChange := &NameChangeEvent{BaseEvent: BaseEvent{Timestamp: timestamp}, Player: player, OldName: "s1mple_old", NewName: "s1mple"}
+
+	testCases := []struct {
+		name     string
+		event    GameEvent
+		expected string
+	}{
+		{"enter", enter, `L 03/14/2025 - 18:30:05: "s1mple<3><STEAM_1:0:123><>" entered the game`},
+		{"name change", nameChange, `L 03/14/2025 - 18:30:05: "s1mple_old<3><STEAM_1:0:123><CT>" changed name to "s1mple"`},
+	}
+
+	for _, tc := range testCases {
+		if line := tc.event.ToLogLine(); line != tc.expected {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.expected, line)
+		}
+	}
+}
+
+func TestObserverChatEvent_ToLogLine(t *testing.T) {
+	timestamp := time.Date(2025, 3, 14, 18, 30, 5, 0, time.UTC)
+	player := &Player{Name: "s1mple", UserID: 3, SteamID: "STEAM_1:0:123", Side: "CT"}
+
+	testCases := []struct {
+		name     string
+		event    GameEvent
+		expected string
+	}{
+		{"player chat", &ChatEvent{BaseEvent: BaseEvent{Timestamp: timestamp}, Player: player, Message: "nice"},
+			`L 03/14/2025 - 18:30:05: "s1mple<3><STEAM_1:0:123><CT>" say "nice"`},
+		{"caster", &ObserverChatEvent{BaseEvent: BaseEvent{Timestamp: timestamp}, Source: ObserverSourceConsole, Speaker: "Caster", Message: "what a round"},
+			`L 03/14/2025 - 18:30:05: "Console<0><Console><Console>" say "[Caster] what a round"`},
+		{"admin", &ObserverChatEvent{BaseEvent: BaseEvent{Timestamp: timestamp}, Source: ObserverSourceServer, Message: "match restarts in 10s"},
+			`L 03/14/2025 - 18:30:05: Server say "match restarts in 10s"`},
+	}
+
+	for _, tc := range testCases {
+		if line := tc.event.ToLogLine(); line != tc.expected {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.expected, line)
+		}
+	}
+}
+
+func TestFlashbangEvent_ToLogLine(t *testing.T) {
+	timestamp := time.Date(2025, 3, 14, 18, 30, 5, 0, time.UTC)
+	thrower := &Player{Name: "electronic", UserID: 5, SteamID: "STEAM_1:0:456", Side: "TERRORIST"}
+	victims := []*Player{
+		{Name: "s1mple", UserID: 3, SteamID: "STEAM_1:0:123", Side: "CT"},
+		{Name: "b1t", UserID: 4, SteamID: "STEAM_1:0:789", Side: "CT"},
+	}
+
+	// Captured from a CS2 server log, with the players swapped for the ones above
+	expected := `L 03/14/2025 - 18:30:05: "s1mple<3><STEAM_1:0:123><CT>" blinded for 2.53 by "electronic<5><STEAM_1:0:456><TERRORIST>" from flashbang entindex 347 ` + "\n" +
+		`L 03/14/2025 - 18:30:05: "b1t<4><STEAM_1:0:789><CT>" blinded for 2.53 by "electronic<5><STEAM_1:0:456><TERRORIST>" from flashbang entindex 347 `
+	flash := &FlashbangEvent{BaseEvent: BaseEvent{Timestamp: timestamp}, Player: thrower, Flashed: victims, Duration: 2.53, EntityIndex: 347}
+	if line := flash.ToLogLine(); line != expected {
+		t.Errorf("Expected blind lines\n%s\ngot\n%s", expected, line)
+	}
+
+	expected = `L 03/14/2025 - 18:30:05: "electronic<5><STEAM_1:0:456><TERRORIST>" threw flashbang [-416 1446 -59] flashbang entindex 347)`
+	throw := &GrenadeThrowEvent{BaseEvent: BaseEvent{Timestamp: timestamp}, Player: thrower, GrenadeType: "flashbang", Position: Vector3{X: -416.4, Y: 1446.8, Z: -59}, EntityIndex: 347}
+	if line := throw.ToLogLine(); line != expected {
+		t.Errorf("Expected throw line %q, got %q", expected, line)
+	}
+
+	expected = `L 03/14/2025 - 18:30:05: "electronic<5><STEAM_1:0:456><TERRORIST>" threw hegrenade [-416 1446 -59]`
+	throw.GrenadeType = "hegrenade"
+	if line := throw.ToLogLine(); line != expected {
+		t.Errorf("Expected throw line %q, got %q", expected, line)
+	}
+}
+
+func TestRoundEndEvent_ToLogLineNormalizesWinner(t *testing.T) {
+	timestamp := time.Date(2025, 3, 14, 18, 30, 5, 0, time.UTC)
+
+	testCases := []struct {
+		winner   string
+		expected string
+	}{
+		{"COUNTER-TERRORIST", "CT"},
+		{"CT", "CT"},
+		{"T", "TERRORIST"},
+		{"TERRORIST", "TERRORIST"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.winner, func(t *testing.T) {
+			event := &RoundEndEvent{BaseEvent: BaseEvent{Timestamp: timestamp}, Winner: tc.winner, Reason: "bomb_defused", CTScore: 4, TScore: 2}
+			expected := `L 03/14/2025 - 18:30:05: Team "` + tc.expected + `" triggered "Bomb_Defused" (CT "4") (T "2")`
+			if line := event.ToLogLine(); line != expected {
+				t.Errorf("Expected %q, got %q", expected, line)
+			}
+		})
+	}
+}
+
+func TestBombDropAndPickupEvents_ToLogLine(t *testing.T) {
+	timestamp := time.Date(2025, 3, 14, 18, 30, 5, 0, time.UTC)
+	player := &Player{Name: "electronic", UserID: 5, SteamID: "STEAM_1:0:456", Side: "TERRORIST"}
+
+	drop := &BombDropEvent{BaseEvent: BaseEvent{Timestamp: timestamp}, Player: player}
+	if expected := `L 03/14/2025 - 18:30:05: "electronic<5><STEAM_1:0:456><TERRORIST>" triggered "Dropped_The_Bomb"`; drop.ToLogLine() != expected {
+		t.Errorf("Expected %q, got %q", expected, drop.ToLogLine())
+	}
+	pickup := &BombPickupEvent{BaseEvent: BaseEvent{Timestamp: timestamp}, Player: player}
+	if expected := `L 03/14/2025 - 18:30:05: "electronic<5><STEAM_1:0:456><TERRORIST>" triggered "Got_The_Bomb"`; pickup.ToLogLine() != expected {
+		t.Errorf("Expected %q, got %q", expected, pickup.ToLogLine())
+	}
+}
+
+func TestKillEvent_ModifierStrings(t *testing.T) {
+	timestamp := time.Date(2025, 3, 14, 18, 30, 5, 0, time.UTC)
+	attacker := &Player{Name: "s1mple", UserID: 3, SteamID: "STEAM_1:0:123", Side: "CT"}
+	victim := &Player{Name: "ZywOo", UserID: 7, SteamID: "STEAM_1:0:456", Side: "TERRORIST"}
+	prefix := `L 03/14/2025 - 18:30:05: "s1mple<3><STEAM_1:0:123><CT>" killed "ZywOo<7><STEAM_1:0:456><TERRORIST>" with "awp"`
+
+	testCases := []struct {
+		name     string
+		kill     KillEvent
+		expected string
+	}{
+		{"plain", KillEvent{}, ""},
+		{"noscope", KillEvent{NoScope: true}, " (noscope)"},
+		{"in air", KillEvent{InAir: true}, " (attackerinair)"},
+		{"noscope jumpshot headshot", KillEvent{Headshot: true, NoScope: true, InAir: true}, " (headshot) (noscope) (attackerinair)"},
+	}
+
+	for _, tc := range testCases {
+		kill := tc.kill
+		kill.BaseEvent = BaseEvent{Timestamp: timestamp}
+		kill.Attacker, kill.Victim, kill.Weapon = attacker, victim, "awp"
+		if line := kill.ToLogLine(); line != prefix+tc.expected {
+			t.Errorf("%s: expected %q, got %q", tc.name, prefix+tc.expected, line)
+		}
+	}
+}
+
+func TestValidateEventOrdering(t *testing.T) {
+	start := time.Date(2025, 3, 14, 18, 0, 0, 0, time.UTC)
+	newEvent := func(tick int64, timestamp time.Time) GameEvent {
+		return &RoundStartEvent{BaseEvent: BaseEvent{Type: "round_start", Tick: tick, Timestamp: timestamp}}
+	}
+
+	ordered := []GameEvent{newEvent(0, start), newEvent(64, start.Add(time.Second)), newEvent(64, start.Add(time.Second))}
+	if err := ValidateEventOrdering(ordered); err != nil {
+		t.Errorf("Unexpected error for ordered events: %v", err)
+	}
+
+	backwardsTick := []GameEvent{newEvent(128, start), newEvent(64, start.Add(time.Second))}
+	if err := ValidateEventOrdering(backwardsTick); err == nil {
+		t.Error("Expected error for decreasing ticks")
+	}
+
+	// Timestamps taken from the wall clock can disagree with tick order
+	backwardsTime := []GameEvent{newEvent(0, start.Add(time.Minute)), newEvent(64, start)}
+	if err := ValidateEventOrdering(backwardsTime); err == nil {
+		t.Error("Expected error for decreasing timestamps")
+	}
+
+	RepairEventTimestamps(backwardsTime, start, 64)
+	if err := ValidateEventOrdering(backwardsTime); err != nil {
+		t.Errorf("Unexpected error after repair: %v", err)
+	}
+	if !backwardsTime[1].GetTimestamp().Equal(start.Add(time.Second)) {
+		t.Errorf("Expected repaired timestamp %v, got %v", start.Add(time.Second), backwardsTime[1].GetTimestamp())
+	}
+}
+
+func TestTicksToDuration(t *testing.T) {
+	testCases := []struct {
+		ticks    int64
+		tickRate int
+		expected time.Duration
+	}{
+		{0, 64, 0},
+		{64, 64, time.Second},
+		{32, 64, 500 * time.Millisecond},
+		{115 * 64, 64, 115 * time.Second},
+		{0, 128, 0},
+		{128, 128, time.Second},
+		{64, 128, 500 * time.Millisecond},
+		{115 * 128, 128, 115 * time.Second},
+		{128, 0, 0},
+	}
+
+	for _, tc := range testCases {
+		result := TicksToDuration(tc.ticks, tc.tickRate)
+		if result != tc.expected {
+			t.Errorf("TicksToDuration(%d, %d) = %v, expected %v", tc.ticks, tc.tickRate, result, tc.expected)
+		}
+		if tc.tickRate > 0 {
+			if ticks := DurationToTicks(tc.expected, tc.tickRate); ticks != tc.ticks {
+				t.Errorf("DurationToTicks(%v, %d) = %d, expected %d", tc.expected, tc.tickRate, ticks, tc.ticks)
+			}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"strings"
+)
+
+// MapInfo describes a playable map and its bomb sites
+type MapInfo struct {
+	Name        string   `json:"name"`
+	DisplayName string   `json:"display_name"`
+	Type        string   `json:"type"` // "defusal", "wingman"
+	BombSites   []string `json:"bomb_sites"`
+}
+
+// defaultBombSites is used for maps that are not in the map table
+var defaultBombSites = []string{"A", "B"}
+
+// mapTable lists all supported maps in display order
+var mapTable = []MapInfo{
+	{Name: "de_mirage", DisplayName: "Mirage", Type: "defusal", BombSites: []string{"A", "B"}},
+	{Name: "de_dust2", DisplayName: "Dust II", Type: "defusal", BombSites: []string{"A", "B"}},
+	{Name: "de_inferno", DisplayName: "Inferno", Type: "defusal", BombSites: []string{"A", "B"}},
+	{Name: "de_cache", DisplayName: "Cache", Type: "defusal", BombSites: []string{"A", "B"}},
+	{Name: "de_overpass", DisplayName: "Overpass", Type: "defusal", BombSites: []string{"A", "B"}},
+	{Name: "de_train", DisplayName: "Train", Type: "defusal", BombSites: []string{"A", "B"}},
+	{Name: "de_nuke", DisplayName: "Nuke", Type: "defusal", BombSites: []string{"A", "B"}},
+	{Name: "de_cbble", DisplayName: "Cobblestone", Type: "defusal", BombSites: []string{"A", "B"}},
+	{Name: "de_vertigo", DisplayName: "Vertigo", Type: "defusal", BombSites: []string{"A", "B"}},
+	{Name: "de_ancient", DisplayName: "Ancient", Type: "defusal", BombSites: []string{"A", "B"}},
+	{Name: "de_anubis", DisplayName: "Anubis", Type: "defusal", BombSites: []string{"A", "B"}},
+	{Name: "de_shortdust", DisplayName: "Shortdust", Type: "wingman", BombSites: []string{"A"}},
+	{Name: "de_lake", DisplayName: "Lake", Type: "wingman", BombSites: []string{"B"}},
+}
+
+// ActiveDutyMaps is the competitive map pool series vetoes fill up from
+var ActiveDutyMaps = []string{"de_mirage", "de_dust2", "de_inferno", "de_nuke", "de_overpass", "de_ancient", "de_anubis"}
+
+// GetMaps returns all supported maps
+func GetMaps() []MapInfo {
+	maps := make([]MapInfo, len(mapTable))
+	copy(maps, mapTable)
+	return maps
+}
+
+// GetMapInfo returns the map table entry for a map name
+func GetMapInfo(mapName string) (MapInfo, bool) {
+	for _, info := range mapTable {
+		if strings.EqualFold(info.Name, mapName) {
+			return info, true
+		}
+	}
+	return MapInfo{}, false
+}
+
+// IsKnownMap checks if a map is in the map table
+func IsKnownMap(mapName string) bool {
+	_, ok := GetMapInfo(mapName)
+	return ok
+}
+
+// GetBombSites returns the bomb sites available on a map
+func GetBombSites(mapName string) []string {
+	if info, ok := GetMapInfo(mapName); ok && len(info.BombSites) > 0 {
+		return info.BombSites
+	}
+	return defaultBombSites
+}
+
+// IsValidBombSite checks if a bomb site exists on a map
+func IsValidBombSite(mapName, site string) bool {
+	for _, s := range GetBombSites(mapName) {
+		if strings.EqualFold(s, site) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -40,6 +41,9 @@ type Match struct {
 	TotalEvents  int64     `json:"total_events"`
 	FileSize     int64     `json:"file_size,omitempty"`
 	Duration     time.Duration `json:"duration,omitempty"`
+	
+	// Request the match was generated from, with the seed it used, for regenerating it
+	Request      *GenerateRequest `json:"-"`
 }
 
 // RoundData represents the state and events of a single round
@@ -47,12 +51,24 @@ type RoundData struct {
 	RoundNumber  int         `json:"round_number"`
 	StartTime    time.Time   `json:"start_time"`
 	EndTime      time.Time   `json:"end_time"`
+	StartTick    int64       `json:"start_tick"`  // Tick the round went live, after freeze time
+	EndTick      int64       `json:"end_tick"`    // Tick of the round end event
 	Winner       string      `json:"winner"`      // "CT", "TERRORIST"
 	Reason       string      `json:"reason"`      // "elimination", "bomb_defused", "bomb_exploded", "time"
 	MVP          string      `json:"mvp"`         // Player name
 	Events       []GameEvent `json:"events"`
 	Economy      map[string]TeamEconomy `json:"economy"`
 	Scores       map[string]int `json:"scores"`
+	PlayerStates map[string]PlayerState `json:"player_states,omitempty"` // Player states at the end of the round
+	RNGState     RNGState    `json:"rng_state"`   // Position of the random stream when the round started
+}
+
+// RNGState is a position in the generator's random stream: the seed it was last
+// seeded with and the number of values drawn since. Restoring it replays a round
+// exactly, e.g. to debug an anomalous one.
+type RNGState struct {
+	Seed  int64  `json:"seed"`
+	Draws uint64 `json:"draws"`
 }
 
 // MatchState represents the current state during match generation
@@ -79,24 +95,98 @@ type GenerateRequest struct {
 // MatchOptions contains additional configuration for match generation
 type MatchOptions struct {
 	Seed       int64 `json:"seed,omitempty"`       // Random seed for reproducible generation
+	StartTime  *time.Time `json:"start_time,omitempty"` // Match start (RFC 3339) for reproducible timestamps and log filenames
 	TickRate   int   `json:"tick_rate,omitempty"`  // Default: 64
 	Overtime   bool  `json:"overtime,omitempty"`   // Allow overtime
 	MaxRounds  int   `json:"max_rounds,omitempty"` // Override default based on format
+	RoundStats bool  `json:"round_stats,omitempty"` // Include player stat deltas in round_end broadcasts
+	FastMode   bool  `json:"fast_mode,omitempty"`   // Only generate outcome-relevant events
+	VerboseLogging bool `json:"verbose_logging,omitempty"` // Log the shots fired before every hit and rejected purchases
+	WeaponSkins bool `json:"weapon_skins,omitempty"` // Annotate kills with cosmetic skins and StatTrak counts
+	CasterRecaps bool `json:"caster_recaps,omitempty"` // Add a caster's round recap as a Console say line after every round
+	KillStreaks bool `json:"kill_streaks,omitempty"` // Announce 3+ kill streaks as Server say highlight lines
+	FirstBlood bool  `json:"first_blood,omitempty"`  // Announce the first kill of every round as a Server say line
+	ServerCvars bool `json:"server_cvars,omitempty"` // Log server_cvar lines at match phase transitions like warmup end and halftime
+	StatusInterval int `json:"status_interval,omitempty"` // Log a server status snapshot every this many rounds
+	MVPEvents  bool  `json:"mvp_events,omitempty"`  // Emit the round MVP as its own round_mvp event after round_end
+	AutoRoster bool  `json:"auto_roster,omitempty"` // Fill teams with fewer than 5 players with generated players
+	ForceBuyType string `json:"force_buy_type,omitempty"` // Force every team into this buy type each round
+	LossBonusLadder []int `json:"loss_bonus_ladder,omitempty"` // Custom loss bonus per consecutive loss
+	AntiEcoThreshold int `json:"anti_eco_threshold,omitempty"` // Opponent equipment value under which a saving opponent is anti-ecoed, default: 5000
+	MaxMoney   int   `json:"max_money,omitempty"`   // Most money a player can hold, default: 16000
+	KnifeKillReward   int `json:"knife_kill_reward,omitempty"`   // Reward for a knife kill, default: 1500
+	GrenadeKillReward int `json:"grenade_kill_reward,omitempty"` // Reward for an HE or fire kill, default: 300
+	SeedPerRound bool `json:"seed_per_round,omitempty"` // Give each round its own child seed so rounds reproduce independently
+	MinRoundDuration int `json:"min_round_duration,omitempty"` // Shortest an elimination round may last in seconds, at most 20
+	ServerTimers     // Round, freeze, buy, bomb and defuse timers in seconds, e.g. "bomb_timer": 35
+	Scoreline  []int `json:"scoreline,omitempty"`  // Final score per team, in team order, to steer round outcomes towards
+	LineEnding string `json:"line_ending,omitempty"` // "lf" (default) or "crlf" between log lines
+	MaxNameLength int `json:"max_name_length,omitempty"` // Longest player name in the log in characters, default: 31
+	NamePolicy string `json:"name_policy,omitempty"` // "truncate" (default) cuts longer names, "reject" fails validation
+	ExtraCvars map[string]string `json:"extra_cvars,omitempty"` // Additional server_cvar lines for the log header
+	EmitPositions bool `json:"emit_positions,omitempty"` // Emit sampled player_position events for radar playback
+	PositionSampleRate int `json:"position_sample_rate,omitempty"` // Position samples per second, default: 4
+	
+	TournamentName string `json:"tournament_name,omitempty"` // Fake tournament the match belongs to
+	MatchTitle     string `json:"match_title,omitempty"`     // Overrides the default "Team1 vs Team2" title
+	Veto           []VetoStep `json:"veto,omitempty"`         // Map veto the match was picked in, logged before the map loads
+}
+
+// SimulateRoundRequest represents a request to predict a single round outcome
+type SimulateRoundRequest struct {
+	Map   string         `json:"map,omitempty"`
+	Round int            `json:"round,omitempty"` // Round number, affects round type probabilities
+	CT    RoundTeamInput `json:"ct" binding:"required"`
+	T     RoundTeamInput `json:"t" binding:"required"`
+}
+
+// SimulateEngagementRequest sets up a single fight between two groups of players
+type SimulateEngagementRequest struct {
+	Attackers []Player `json:"attackers" binding:"required,min=1"` // Side defaults to TERRORIST
+	Defenders []Player `json:"defenders"`                          // Side defaults to CT
+	Seed      int64    `json:"seed,omitempty"`                     // Random seed, default: random
+}
+
+// FormatRequest re-renders raw events exported with their __type discriminator
+type FormatRequest struct {
+	Format string          `json:"format,omitempty"` // "standard" (default), "json" or "csv"
+	Events json.RawMessage `json:"events" binding:"required"`
+}
+
+// RegenerateRequest overrides parts of a stored match's configuration for regenerating it
+type RegenerateRequest struct {
+	Config json.RawMessage `json:"config,omitempty"` // MatchConfig fields to change, the seed is always kept
+}
+
+// CompareRequest names two stored matches to compare
+type CompareRequest struct {
+	MatchIDs []string `json:"match_ids" binding:"required,len=2"` // Baseline first, then the match compared against it
+}
+
+// RoundTeamInput describes one side's economy and skill for round prediction
+type RoundTeamInput struct {
+	Name         string  `json:"name,omitempty"`
+	AverageMoney int     `json:"average_money"`
+	Skill        float64 `json:"skill"` // Average aim skill, 0.0 to 1.0
 }
 
 // GenerateResponse represents the response from match generation
 type GenerateResponse struct {
-	MatchID string `json:"match_id"`
-	Status  string `json:"status"`
-	LogURL  string `json:"log_url,omitempty"`
-	Error   string `json:"error,omitempty"`
+	MatchID      string      `json:"match_id"`
+	Status       string      `json:"status"`
+	LogURL       string      `json:"log_url,omitempty"`
+	Log          interface{} `json:"log,omitempty"` // Inline log when requested
+	ConfigDigest string      `json:"config_digest,omitempty"`
+	StartTime    time.Time   `json:"start_time"` // Start the log timestamps and filename are derived from
+	Cached       bool        `json:"cached,omitempty"`
+	Error        string      `json:"error,omitempty"`
 }
 
 // NewMatch creates a new match with the given configuration
 func NewMatch(config MatchConfig, teams []Team) *Match {
 	match := &Match{
 		ID:           generateMatchID(),
-		Title:        fmt.Sprintf("%s vs %s", teams[0].Name, teams[1].Name),
+		Title:        config.MatchTitle,
 		Map:          config.Map,
 		Format:       config.Format,
 		Status:       "pending",
@@ -108,16 +198,13 @@ func NewMatch(config MatchConfig, teams []Team) *Match {
 		Events:       make([]GameEvent, 0),
 	}
 	
-	// Set max rounds based on format
-	switch config.Format {
-	case "mr12":
-		match.MaxRounds = 24
-	case "mr15":
-		match.MaxRounds = 30
-	default:
-		match.MaxRounds = 24
+	if match.Title == "" {
+		match.Title = fmt.Sprintf("%s vs %s", teams[0].Name, teams[1].Name)
 	}
 	
+	// Set max rounds from the mp_maxrounds override, or the format
+	match.MaxRounds = config.GetMaxRounds()
+	
 	// Initialize scores
 	for _, team := range teams {
 		match.Scores[team.Name] = 0
@@ -126,6 +213,23 @@ func NewMatch(config MatchConfig, teams []Team) *Match {
 	return match
 }
 
+// HalftimeRound returns the last round of the first half; sides switch after it
+// (round 12 in mr12, round 15 in mr15)
+func (m *Match) HalftimeRound() int {
+	return m.MaxRounds / 2
+}
+
+// WinThreshold returns the number of rounds a team needs to win the match
+func (m *Match) WinThreshold() int {
+	return m.MaxRounds/2 + 1
+}
+
+// IsPistolRound reports whether a round opens a half, when only pistols, armor and
+// utility can be bought
+func (m *Match) IsPistolRound(roundNum int) bool {
+	return roundNum == 1 || roundNum == m.HalftimeRound()+1
+}
+
 // IsFinished returns true if the match is complete
 func (m *Match) IsFinished() bool {
 	if m.Status == "completed" {
@@ -133,7 +237,7 @@ func (m *Match) IsFinished() bool {
 	}
 	
 	// Check if any team has won
-	winThreshold := (m.MaxRounds / 2) + 1
+	winThreshold := m.WinThreshold()
 	for _, score := range m.Scores {
 		if score >= winThreshold {
 			return true
@@ -150,7 +254,7 @@ func (m *Match) IsFinished() bool {
 
 // GetWinningTeam returns the name of the winning team, or empty string if no winner
 func (m *Match) GetWinningTeam() string {
-	winThreshold := (m.MaxRounds / 2) + 1
+	winThreshold := m.WinThreshold()
 	highestScore := 0
 	winningTeam := ""
 	
@@ -207,6 +311,9 @@ func (r *GenerateRequest) Validate() error {
 	if r.Map == "" {
 		return errors.New("map is required")
 	}
+	if !IsKnownMap(r.Map) {
+		return fmt.Errorf("unknown map: %s", r.Map)
+	}
 	
 	if r.Format != "mr12" && r.Format != "mr15" {
 		return errors.New("format must be 'mr12' or 'mr15'")
@@ -219,24 +326,139 @@ func (r *GenerateRequest) Validate() error {
 		}
 	}
 	
+	if err := ValidateTeamNames(r.Teams); err != nil {
+		return err
+	}
+	
+	if err := ValidateTeamSides(r.Teams); err != nil {
+		return err
+	}
+	
 	// Validate options
 	if r.Options.TickRate != 0 && (r.Options.TickRate < 64 || r.Options.TickRate > 128) {
 		return errors.New("tick rate must be between 64 and 128")
 	}
 	
+	if err := ValidateMaxRounds(r.Options.MaxRounds); err != nil {
+		return err
+	}
+	
+	if r.Options.ForceBuyType != "" && !IsValidBuyType(r.Options.ForceBuyType) {
+		return fmt.Errorf("invalid force buy type: %s (must be one of %s)", r.Options.ForceBuyType, strings.Join(BuyTypes, ", "))
+	}
+	
+	if r.Options.LossBonusLadder != nil {
+		if err := ValidateLossBonusLadder(r.Options.LossBonusLadder); err != nil {
+			return err
+		}
+	}
+	
+	if err := ValidateAntiEcoThreshold(r.Options.AntiEcoThreshold); err != nil {
+		return err
+	}
+	
+	if r.Options.LineEnding != "" && !IsValidLineEnding(r.Options.LineEnding) {
+		return fmt.Errorf("invalid line ending: %s (must be one of %s)", r.Options.LineEnding, strings.Join(LineEndings, ", "))
+	}
+	
+	if err := ValidateMaxNameLength(r.Options.MaxNameLength); err != nil {
+		return err
+	}
+	
+	if r.Options.NamePolicy != "" && !IsValidNamePolicy(r.Options.NamePolicy) {
+		return fmt.Errorf("invalid name policy: %s (must be one of %s)", r.Options.NamePolicy, strings.Join(NamePolicies, ", "))
+	}
+	
+	if err := ValidatePlayerNameLengths(r.Teams, r.Options.MaxNameLength, r.Options.NamePolicy); err != nil {
+		return err
+	}
+	
+	if err := ValidatePositionSampleRate(r.Options.PositionSampleRate); err != nil {
+		return err
+	}
+	
+	if err := r.Options.ServerTimers.Validate(); err != nil {
+		return err
+	}
+	
+	if err := ValidateExtraCvars(r.Options.ExtraCvars); err != nil {
+		return err
+	}
+	
+	if err := ValidateVeto(r.Options.Veto); err != nil {
+		return err
+	}
+	for i, step := range r.Options.Veto {
+		if step.Team != "" && step.Team != r.Teams[0].Name && step.Team != r.Teams[1].Name {
+			return fmt.Errorf("veto step %d: unknown team: %s", i+1, step.Team)
+		}
+	}
+	
+	if r.Options.StatusInterval < 0 {
+		return errors.New("status interval cannot be negative")
+	}
+	
+	if r.Options.KnifeKillReward < 0 || r.Options.GrenadeKillReward < 0 {
+		return errors.New("kill rewards cannot be negative")
+	}
+	
+	if err := ValidateMinRoundDuration(r.Options.MinRoundDuration); err != nil {
+		return err
+	}
+	
+	if r.Options.MaxMoney != 0 && r.Options.MaxMoney < DefaultMatchConfig().StartMoney {
+		return fmt.Errorf("max money must be at least the start money of %d", DefaultMatchConfig().StartMoney)
+	}
+	
+	if r.Options.Scoreline != nil {
+		if err := ValidateScoreline(r.Options.Scoreline, r.MaxRounds()); err != nil {
+			return err
+		}
+	}
+	
+	return r.validateStartingEconomy()
+}
+
+// MaxRounds returns the rounds the requested match is played over: the max_rounds
+// option when set, otherwise the format's regulation length
+func (r *GenerateRequest) MaxRounds() int {
+	if r.Options.MaxRounds > 0 {
+		return r.Options.MaxRounds
+	}
+	return MaxRoundsForFormat(r.Format)
+}
+
+// validateStartingEconomy checks the loss streaks and player money a request seeds
+// the starting economy with
+func (r *GenerateRequest) validateStartingEconomy() error {
+	maxMoney := r.Options.MaxMoney
+	if maxMoney == 0 {
+		maxMoney = DefaultMatchConfig().MaxMoney
+	}
+	
+	for _, team := range r.Teams {
+		if team.Economy.ConsecutiveLosses < 0 {
+			return fmt.Errorf("team %s: consecutive losses cannot be negative", team.Name)
+		}
+		for _, player := range team.Players {
+			if player.Economy.Money < 0 || player.Economy.Money > maxMoney {
+				return fmt.Errorf("player %s: starting money %d must be between 0 and %d", player.Name, player.Economy.Money, maxMoney)
+			}
+		}
+	}
 	return nil
 }
 
 // generateMatchID generates a unique match ID
 func generateMatchID() string {
 	// Simple timestamp-based ID for MVP
-	return fmt.Sprintf("match_%d", time.Now().Unix())
+	return fmt.Sprintf("match_%d", time.Now().UnixNano())
 }
 
 // GetTeamBySide returns the team playing on the specified side
 func (m *Match) GetTeamBySide(side string) *Team {
 	for i := range m.Teams {
-		if strings.EqualFold(m.Teams[i].Side, side) {
+		if NormalizeSide(m.Teams[i].Side) == NormalizeSide(side) {
 			return &m.Teams[i]
 		}
 	}
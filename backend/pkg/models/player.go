@@ -61,6 +61,7 @@ type PlayerStats struct {
 	Multikills3      int `json:"3k_rounds"`
 	Multikills4      int `json:"4k_rounds"`
 	Multikills5      int `json:"5k_rounds"`
+	LongestKillStreak int `json:"longest_kill_streak"` // Most kills in a round without dying in between
 	
 	// Objective participation
 	BombPlants       int `json:"bomb_plants"`
@@ -162,6 +163,9 @@ type Weapon struct {
 	// Weapon attachments/skins (optional)
 	Skin         string  `json:"skin,omitempty"`
 	StatTrak     bool    `json:"stat_trak"`
+	StatTrakKills int    `json:"stat_trak_kills,omitempty"`
+	
+	PickedUp     bool    `json:"picked_up,omitempty"` // Taken from a dead player rather than bought
 }
 
 // Grenade represents a grenade with its properties
@@ -241,6 +245,41 @@ func (p *Player) Validate() error {
 		return fmt.Errorf("invalid side: %s", p.Side)
 	}
 	
+	if err := p.Profile.Validate(); err != nil {
+		return fmt.Errorf("invalid profile: %w", err)
+	}
+	
+	return nil
+}
+
+// Validate checks that every profile rating is within 0.0 to 1.0
+func (p *PlayerProfile) Validate() error {
+	ratings := []struct {
+		name  string
+		value float64
+	}{
+		{"aim_skill", p.AimSkill},
+		{"reflex_speed", p.ReflexSpeed},
+		{"game_sense", p.GameSense},
+		{"positioning", p.Positioning},
+		{"teamwork", p.Teamwork},
+		{"utility_usage", p.UtilityUsage},
+		{"aggression", p.Aggression},
+		{"economy_discipline", p.EconomyDiscipline},
+		{"clutch_factor", p.ClutchFactor},
+		{"rifle_skill", p.RifleSkill},
+		{"awp_skill", p.AWPSkill},
+		{"pistol_skill", p.PistolSkill},
+		{"entry_fragging", p.EntryFragging},
+		{"support_play", p.SupportPlay},
+		{"igl_skill", p.IGLSkill},
+		{"consistency_factor", p.ConsistencyFactor},
+	}
+	for _, rating := range ratings {
+		if rating.value < 0 || rating.value > 1 {
+			return fmt.Errorf("%s must be between 0.0 and 1.0, got %g", rating.name, rating.value)
+		}
+	}
 	return nil
 }
 
@@ -325,12 +364,62 @@ func (p *Player) AddWeapon(weapon Weapon) {
 	}
 }
 
-// AddGrenade gives a grenade to the player
-func (p *Player) AddGrenade(grenade Grenade) {
-	// Check if player can carry more grenades (max 4 in CS2)
-	if len(p.State.Grenades) < 4 {
-		p.State.Grenades = append(p.State.Grenades, grenade)
+// maxGrenades is the total number of grenades a player can carry in CS2
+const maxGrenades = 4
+
+// grenadeAliases maps short grenade names to their item names
+var grenadeAliases = map[string]string{
+	"he":         "hegrenade",
+	"flash":      "flashbang",
+	"smoke":      "smokegrenade",
+	"incendiary": "incgrenade",
+}
+
+// normalizeGrenadeType returns the item name for a grenade type
+func normalizeGrenadeType(grenadeType string) string {
+	grenadeType = strings.ToLower(grenadeType)
+	if name, exists := grenadeAliases[grenadeType]; exists {
+		return name
+	}
+	return grenadeType
+}
+
+// grenadeSlot returns the inventory slot a grenade occupies; molotovs and incendiaries share one
+func grenadeSlot(grenadeType string) string {
+	grenadeType = normalizeGrenadeType(grenadeType)
+	if grenadeType == "molotov" || grenadeType == "incgrenade" {
+		return "fire"
 	}
+	return grenadeType
+}
+
+// GrenadeLimit returns how many grenades of a type a player can carry
+func GrenadeLimit(grenadeType string) int {
+	if grenadeSlot(grenadeType) == "flashbang" {
+		return 2
+	}
+	return 1
+}
+
+// CanCarryGrenade checks the total and per-type grenade limits for an inventory
+func CanCarryGrenade(grenades []Grenade, grenadeType string) bool {
+	if len(grenades) >= maxGrenades {
+		return false
+	}
+	
+	slot := grenadeSlot(grenadeType)
+	count := 0
+	for _, grenade := range grenades {
+		if grenadeSlot(grenade.Type) == slot {
+			count++
+		}
+	}
+	return count < GrenadeLimit(grenadeType)
+}
+
+// AddGrenade gives a grenade to the player if the inventory limits allow it
+func (p *Player) AddGrenade(grenade Grenade) bool {
+	return p.State.AddGrenade(grenade)
 }
 
 // RemoveGrenade removes a grenade of the specified type
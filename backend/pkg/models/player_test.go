@@ -0,0 +1,89 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPlayer_AddGrenadeLimits(t *testing.T) {
+	player := NewPlayer("TestPlayer", "STEAM_1:0:123456")
+
+	// Only one smoke can be carried
+	kept := 0
+	for i := 0; i < 3; i++ {
+		if player.AddGrenade(Grenade{Type: "smokegrenade", Price: 300}) {
+			kept++
+		}
+	}
+	if kept != 1 {
+		t.Errorf("Expected 1 smoke to be kept, got %d", kept)
+	}
+
+	// Two flashbangs are allowed, a third is not
+	if !player.AddGrenade(Grenade{Type: "flashbang", Price: 200}) {
+		t.Error("Expected first flashbang to be added")
+	}
+	if !player.AddGrenade(Grenade{Type: "flash", Price: 200}) {
+		t.Error("Expected second flashbang to be added")
+	}
+	if player.AddGrenade(Grenade{Type: "flashbang", Price: 200}) {
+		t.Error("Expected third flashbang to be rejected")
+	}
+
+	// Molotov and incendiary share a slot
+	if !player.AddGrenade(Grenade{Type: "molotov", Price: 400}) {
+		t.Error("Expected molotov to be added")
+	}
+
+	// Inventory is now full (smoke, 2 flashes, molotov)
+	if player.AddGrenade(Grenade{Type: "hegrenade", Price: 300}) {
+		t.Error("Expected HE grenade to be rejected with a full inventory")
+	}
+
+	if len(player.State.Grenades) != 4 {
+		t.Errorf("Expected 4 grenades, got %d", len(player.State.Grenades))
+	}
+}
+
+func TestCanCarryGrenade_FireSlot(t *testing.T) {
+	grenades := []Grenade{{Type: "incgrenade"}}
+	if CanCarryGrenade(grenades, "molotov") {
+		t.Error("Expected molotov to be rejected when carrying an incendiary")
+	}
+	if !CanCarryGrenade(grenades, "hegrenade") {
+		t.Error("Expected HE grenade to be allowed alongside an incendiary")
+	}
+}
+
+func TestPlayer_ValidateProfileRange(t *testing.T) {
+	player := NewPlayer("Ranged", "STEAM_1:0:1")
+	if err := player.Validate(); err != nil {
+		t.Fatalf("Expected the default profile to be valid, got %v", err)
+	}
+
+	player.Profile.Aggression = 5
+	if err := player.Validate(); err == nil || !strings.Contains(err.Error(), "aggression") {
+		t.Errorf("Expected aggression 5 to be rejected, got %v", err)
+	}
+
+	player.Profile.Aggression = 1
+	player.Profile.AimSkill = -0.1
+	if err := player.Validate(); err == nil || !strings.Contains(err.Error(), "aim_skill") {
+		t.Errorf("Expected a negative aim skill to be rejected, got %v", err)
+	}
+
+	// The request validation rejects the player as part of its team
+	req := GenerateRequest{Map: "de_mirage", Format: "mr12"}
+	for _, side := range []string{"CT", "TERRORIST"} {
+		team := Team{Name: side, Side: side}
+		for i := 0; i < 5; i++ {
+			team.Players = append(team.Players, Player{Name: fmt.Sprintf("%s_%d", side, i)})
+		}
+		req.Teams = append(req.Teams, team)
+	}
+	req.Teams[0].Players[2].Profile.Aggression = 5
+	if err := req.Validate(); err == nil || !strings.Contains(err.Error(), "aggression") {
+		t.Errorf("Expected the request to be rejected for aggression 5, got %v", err)
+	}
+}
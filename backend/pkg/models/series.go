@@ -0,0 +1,153 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SeriesFormats lists the supported best-of series lengths
+var SeriesFormats = []int{1, 3, 5}
+
+// SeriesRequest asks for a best-of-N series between two teams, played over the
+// maps in order until a team clinches it
+type SeriesRequest struct {
+	Teams   []Team       `json:"teams" binding:"required,len=2"`
+	Maps    []string     `json:"maps" binding:"required,min=1"`
+	BestOf  int          `json:"best_of" binding:"required,oneof=1 3 5"`
+	Format  string       `json:"format" binding:"required,oneof=mr12 mr15"`
+	Options MatchOptions `json:"options"` // Applied to every map, the seed seeds the whole series
+}
+
+// VetoActions lists the steps of a map veto
+var VetoActions = []string{"ban", "pick", "decider"}
+
+// VetoStep is one ban or pick of a map veto, or the map left over as the decider
+type VetoStep struct {
+	Team   string `json:"team,omitempty"` // Empty for the decider
+	Action string `json:"action"`
+	Map    string `json:"map"`
+}
+
+// VetoSequence returns the actions of a best-of-N veto over a seven map pool:
+// bans and picks alternating between the teams, then the decider
+func VetoSequence(bestOf int) []string {
+	switch bestOf {
+	case 1:
+		return []string{"ban", "ban", "ban", "ban", "ban", "ban", "decider"}
+	case 3:
+		return []string{"ban", "ban", "pick", "pick", "ban", "ban", "decider"}
+	case 5:
+		return []string{"ban", "ban", "pick", "pick", "pick", "pick", "decider"}
+	}
+	return nil
+}
+
+// ValidateVeto checks that veto steps use known actions and maps, that only the
+// decider has no team and that no map comes up twice
+func ValidateVeto(veto []VetoStep) error {
+	seen := make(map[string]bool, len(veto))
+	for i, step := range veto {
+		known := false
+		for _, action := range VetoActions {
+			known = known || step.Action == action
+		}
+		if !known {
+			return fmt.Errorf("veto step %d: invalid action %q (must be one of %s)", i+1, step.Action, strings.Join(VetoActions, ", "))
+		}
+		if (step.Action == "decider") != (step.Team == "") {
+			return fmt.Errorf("veto step %d: only the decider has no team", i+1)
+		}
+		if !IsKnownMap(step.Map) {
+			return fmt.Errorf("veto step %d: unknown map: %s", i+1, step.Map)
+		}
+		if seen[strings.ToLower(step.Map)] {
+			return fmt.Errorf("veto step %d: map %s is vetoed more than once", i+1, step.Map)
+		}
+		seen[strings.ToLower(step.Map)] = true
+	}
+	return nil
+}
+
+// SeriesResult is the outcome of a series with the match played on each map
+type SeriesResult struct {
+	ID     string         `json:"id"`
+	BestOf int            `json:"best_of"`
+	Seed   int64          `json:"seed"`
+	Veto   []VetoStep     `json:"veto"`
+	Maps   []SeriesMap    `json:"maps"`
+	Score  map[string]int `json:"score"`            // Maps won per team
+	Winner string         `json:"winner,omitempty"` // Empty when no team clinched, e.g. after drawn maps
+}
+
+// SeriesMap is one map of a series and the match played on it
+type SeriesMap struct {
+	Map      string         `json:"map"`
+	MatchID  string         `json:"match_id"`
+	PickedBy string         `json:"picked_by,omitempty"` // Empty for the decider
+	Scores   map[string]int `json:"scores"`
+	Winner   string         `json:"winner,omitempty"` // Empty for a drawn map
+}
+
+// MapsToWin returns how many maps a team must win to clinch a best-of-N series
+func MapsToWin(bestOf int) int {
+	return bestOf/2 + 1
+}
+
+// Validate checks the series format and maps, and the match settings they are played with
+func (r *SeriesRequest) Validate() error {
+	known := false
+	for _, bestOf := range SeriesFormats {
+		known = known || r.BestOf == bestOf
+	}
+	if !known {
+		return fmt.Errorf("invalid series format: best of %d (must be 1, 3 or 5)", r.BestOf)
+	}
+
+	if len(r.Maps) != r.BestOf {
+		return fmt.Errorf("a best of %d series needs %d maps, got %d", r.BestOf, r.BestOf, len(r.Maps))
+	}
+	seen := make(map[string]bool, len(r.Maps))
+	for _, mapName := range r.Maps {
+		if !IsKnownMap(mapName) {
+			return fmt.Errorf("unknown map: %s", mapName)
+		}
+		if seen[strings.ToLower(mapName)] {
+			return fmt.Errorf("map %s is played more than once", mapName)
+		}
+		seen[strings.ToLower(mapName)] = true
+	}
+
+	req := r.MapRequest(0)
+	return req.Validate()
+}
+
+// MapRequest returns the generate request for the series' map at the given index
+func (r *SeriesRequest) MapRequest(index int) GenerateRequest {
+	return GenerateRequest{
+		Teams:   r.Teams,
+		Map:     r.Maps[index],
+		Format:  r.Format,
+		Options: r.Options,
+	}
+}
+
+// generateSeriesID creates an ID for a series
+func generateSeriesID() string {
+	return fmt.Sprintf("series_%d", time.Now().UnixNano())
+}
+
+// NewSeriesResult creates an empty result for a series
+func NewSeriesResult(bestOf int, seed int64, teams []Team) *SeriesResult {
+	result := &SeriesResult{
+		ID:     generateSeriesID(),
+		BestOf: bestOf,
+		Seed:   seed,
+		Maps:   []SeriesMap{},
+		Score:  make(map[string]int, len(teams)),
+	}
+	for _, team := range teams {
+		result.Score[team.Name] = 0
+	}
+	return result
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"errors"
 	"fmt"
+	"math"
 	"strings"
 )
 
@@ -31,15 +32,18 @@ type Team struct {
 
 // TeamEconomy represents the team's economic state
 type TeamEconomy struct {
-	TotalMoney     int `json:"total_money"`
-	AverageMoney   int `json:"average_money"`
-	EquipmentValue int `json:"equipment_value"`
+	TotalMoney        int     `json:"total_money"`
+	AverageMoney      int     `json:"average_money"` // Rounded to the nearest dollar
+	AverageMoneyExact float64 `json:"average_money_exact"`
+	EquipmentValue    int     `json:"equipment_value"`
+	PickedUpValue     int     `json:"picked_up_value"` // Part of the equipment value picked up from dead players rather than bought
 	
 	// Loss bonus tracking
 	ConsecutiveLosses int `json:"consecutive_losses"`
 	LossBonus        int `json:"loss_bonus"`
 	
 	// Round economy
+	BuyType          string `json:"buy_type,omitempty"` // "eco", "anti_eco", "force_buy" or "full_buy" for the current round
 	MoneySpent       int `json:"money_spent"`
 	MoneyEarned      int `json:"money_earned"`
 	
@@ -56,6 +60,18 @@ type TeamEconomy struct {
 	DefuseKits       int `json:"defuse_kits"`
 }
 
+// SetMoney records the money of a team's players and their average, both exact and
+// rounded to the nearest dollar. Every team economy update goes through it, so the
+// reported average always matches the players whose money was counted.
+func (e *TeamEconomy) SetMoney(totalMoney, players int) {
+	e.TotalMoney = totalMoney
+	e.AverageMoney, e.AverageMoneyExact = 0, 0
+	if players > 0 {
+		e.AverageMoneyExact = float64(totalMoney) / float64(players)
+		e.AverageMoney = int(math.Round(e.AverageMoneyExact))
+	}
+}
+
 // TeamStats represents aggregate team statistics
 type TeamStats struct {
 	// Basic stats
@@ -90,6 +106,7 @@ type PlayerState struct {
 	IsAlive      bool    `json:"is_alive"`
 	Health       int     `json:"health"`
 	Armor        int     `json:"armor"`
+	ArmorBroken  bool    `json:"armor_broken,omitempty"` // Armor was shot away; hits do full damage until armor is bought again
 	HasHelmet    bool    `json:"has_helmet"`
 	HasDefuseKit bool    `json:"has_defuse_kit"`
 	
@@ -118,6 +135,51 @@ type PlayerState struct {
 	IsLastAlive  bool    `json:"is_last_alive"`
 }
 
+// EquipArmor gives the player full armor, with a helmet if requested
+func (ps *PlayerState) EquipArmor(helmet bool) {
+	ps.Armor = 100
+	ps.ArmorBroken = false
+	if helmet {
+		ps.HasHelmet = true
+	}
+}
+
+// AddGrenade gives the player a grenade if the inventory limits allow it
+func (ps *PlayerState) AddGrenade(grenade Grenade) bool {
+	if !CanCarryGrenade(ps.Grenades, grenade.Type) {
+		return false
+	}
+	ps.Grenades = append(ps.Grenades, grenade)
+	return true
+}
+
+// PickedUpValue returns the value of the weapons the player picked up instead of buying
+func (ps *PlayerState) PickedUpValue() int {
+	value := 0
+	for _, weapon := range []*Weapon{ps.PrimaryWeapon, ps.SecondaryWeapon} {
+		if weapon != nil && weapon.PickedUp {
+			value += weapon.Price
+		}
+	}
+	return value
+}
+
+// Clone returns a deep copy of the player state
+func (ps *PlayerState) Clone() *PlayerState {
+	clone := *ps
+	if ps.PrimaryWeapon != nil {
+		primary := *ps.PrimaryWeapon
+		clone.PrimaryWeapon = &primary
+	}
+	if ps.SecondaryWeapon != nil {
+		secondary := *ps.SecondaryWeapon
+		clone.SecondaryWeapon = &secondary
+	}
+	clone.Grenades = make([]Grenade, len(ps.Grenades))
+	copy(clone.Grenades, ps.Grenades)
+	return &clone
+}
+
 // Vector3 represents a 3D position or direction
 type Vector3 struct {
 	X float64 `json:"x"`
@@ -180,11 +242,106 @@ func (t *Team) Validate() error {
 	return nil
 }
 
+// NormalizeSide maps side aliases to the canonical "CT" or "TERRORIST", or "" if unknown
+func NormalizeSide(side string) string {
+	switch strings.ToUpper(strings.TrimSpace(side)) {
+	case "CT", "COUNTER-TERRORIST", "COUNTER_TERRORIST", "COUNTERTERRORIST", "COUNTER-TERRORISTS", "3":
+		return "CT"
+	case "T", "TERRORIST", "TERRORISTS", "2":
+		return "TERRORIST"
+	default:
+		return ""
+	}
+}
+
+// LogSide returns the side token used in log lines ("CT" or "TERRORIST"), leaving
+// unrecognized values untouched
+func LogSide(side string) string {
+	if normalized := NormalizeSide(side); normalized != "" {
+		return normalized
+	}
+	return side
+}
+
 // IsValidSide checks if the side is valid
 func IsValidSide(side string) bool {
-	return strings.EqualFold(side, "CT") || 
-		   strings.EqualFold(side, "TERRORIST") ||
-		   strings.EqualFold(side, "COUNTER-TERRORIST")
+	return NormalizeSide(side) != ""
+}
+
+// ValidateTeamSides checks that two teams start on opposite sides, or that both
+// sides are blank so they can be assigned automatically
+func ValidateTeamSides(teams []Team) error {
+	if len(teams) != 2 {
+		return errors.New("exactly 2 teams are required")
+	}
+	
+	first, second := NormalizeSide(teams[0].Side), NormalizeSide(teams[1].Side)
+	if first == "" && second == "" {
+		return nil
+	}
+	if first == "" || second == "" {
+		return errors.New("team sides must either both be set or both be left blank")
+	}
+	if first == second {
+		return fmt.Errorf("both teams are assigned to side %s; one team must be CT and the other TERRORIST", first)
+	}
+	return nil
+}
+
+// ValidateAssignedSides checks that exactly one team plays CT and one plays TERRORIST,
+// as generation expects once starting sides have been assigned
+func ValidateAssignedSides(teams []Team) error {
+	counts := make(map[string]int)
+	for _, team := range teams {
+		counts[NormalizeSide(team.Side)]++
+	}
+	for _, side := range []string{"CT", "TERRORIST"} {
+		if counts[side] != 1 {
+			return fmt.Errorf("expected exactly one team on side %s, got %d", side, counts[side])
+		}
+	}
+	return nil
+}
+
+// ValidateTeamNames checks that no two teams share a name. Scores, economies and
+// log lines identify teams by name, so names differing only in case or surrounding
+// spaces are rejected too.
+func ValidateTeamNames(teams []Team) error {
+	seen := make(map[string]bool)
+	for _, team := range teams {
+		key := strings.ToLower(strings.TrimSpace(team.Name))
+		if seen[key] {
+			return fmt.Errorf("duplicate team name: %s", team.Name)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// AssignTeamSides puts the teams' canonical starting sides on the teams and their
+// players. When neither team has a side, firstOnCT decides which team starts as CT
+// and true is returned to report that the sides were assigned automatically.
+func AssignTeamSides(teams []Team, firstOnCT bool) bool {
+	if len(teams) != 2 {
+		return false
+	}
+	
+	assigned := false
+	if NormalizeSide(teams[0].Side) == "" && NormalizeSide(teams[1].Side) == "" {
+		teams[0].Side, teams[1].Side = "CT", "TERRORIST"
+		if !firstOnCT {
+			teams[0].Side, teams[1].Side = "TERRORIST", "CT"
+		}
+		assigned = true
+	}
+	
+	for i := range teams {
+		teams[i].Side = NormalizeSide(teams[i].Side)
+		for j := range teams[i].Players {
+			teams[i].Players[j].Side = teams[i].Side
+		}
+	}
+	return assigned
 }
 
 // GetAlivePlayers returns all living players on the team
@@ -266,8 +423,7 @@ func (t *Team) UpdateEconomy() {
 	}
 	
 	// Update team economy
-	t.Economy.TotalMoney = totalMoney
-	t.Economy.AverageMoney = totalMoney / len(t.Players)
+	t.Economy.SetMoney(totalMoney, len(t.Players))
 	t.Economy.EquipmentValue = equipmentValue
 	t.Economy.Rifles = rifles
 	t.Economy.SMGs = smgs
@@ -306,10 +462,7 @@ func (t *Team) HandleRoundLoss() {
 	t.Economy.ConsecutiveLosses++
 	
 	// Calculate loss bonus
-	lossBonus := 1400 + (t.Economy.ConsecutiveLosses-1)*500
-	if lossBonus > 3400 {
-		lossBonus = 3400 // Max loss bonus
-	}
+	lossBonus := LossBonusForStreak(DefaultLossBonusLadder(), t.Economy.ConsecutiveLosses)
 	t.Economy.LossBonus = lossBonus
 	
 	// Award loss bonus to players
@@ -323,7 +476,7 @@ func (t *Team) HandleRoundLoss() {
 
 // SwitchSides switches the team to the opposite side
 func (t *Team) SwitchSides() {
-	if strings.EqualFold(t.Side, "CT") {
+	if NormalizeSide(t.Side) == "CT" {
 		t.Side = "TERRORIST"
 	} else {
 		t.Side = "CT"
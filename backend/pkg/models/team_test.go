@@ -0,0 +1,256 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNormalizeSide(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"CT", "CT"},
+		{"ct", "CT"},
+		{"Counter-Terrorist", "CT"},
+		{"COUNTER-TERRORIST", "CT"},
+		{" counter_terrorist ", "CT"},
+		{"TERRORIST", "TERRORIST"},
+		{"terrorist", "TERRORIST"},
+		{"T", "TERRORIST"},
+		{"Terrorists", "TERRORIST"},
+		{"", ""},
+		{"spectator", ""},
+	}
+
+	for _, tc := range testCases {
+		if result := NormalizeSide(tc.input); result != tc.expected {
+			t.Errorf("NormalizeSide(%q) = %q, expected %q", tc.input, result, tc.expected)
+		}
+		if IsValidSide(tc.input) != (tc.expected != "") {
+			t.Errorf("IsValidSide(%q) = %v, expected %v", tc.input, IsValidSide(tc.input), tc.expected != "")
+		}
+	}
+}
+
+func TestMatch_GetTeamBySideAliases(t *testing.T) {
+	match := &Match{
+		Teams: []Team{
+			{Name: "Alpha", Side: "counter-terrorist"},
+			{Name: "Bravo", Side: "Terrorist"},
+		},
+	}
+
+	if team := match.GetTeamBySide("CT"); team == nil || team.Name != "Alpha" {
+		t.Errorf("Expected Alpha for CT, got %v", team)
+	}
+	if team := match.GetTeamBySide("t"); team == nil || team.Name != "Bravo" {
+		t.Errorf("Expected Bravo for T, got %v", team)
+	}
+}
+
+func TestValidateTeamSides(t *testing.T) {
+	testCases := []struct {
+		name   string
+		first  string
+		second string
+		valid  bool
+	}{
+		{"opposite sides", "CT", "TERRORIST", true},
+		{"aliases", "t", "counter-terrorist", true},
+		{"both blank", "", "", true},
+		{"both CT", "CT", "CT", false},
+		{"both T via aliases", "T", "terrorist", false},
+		{"one blank", "CT", "", false},
+	}
+
+	for _, tc := range testCases {
+		teams := []Team{{Name: "Alpha", Side: tc.first}, {Name: "Bravo", Side: tc.second}}
+		err := ValidateTeamSides(teams)
+		if tc.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("%s: expected validation error", tc.name)
+		}
+	}
+}
+
+func TestGenerateRequest_ValidateRejectsSameSide(t *testing.T) {
+	newTeam := func(name, side string) Team {
+		team := Team{Name: name, Side: side}
+		for i := 0; i < 5; i++ {
+			team.Players = append(team.Players, Player{Name: name + string(rune('a'+i)), SteamID: "STEAM_1:0:1"})
+		}
+		return team
+	}
+
+	req := GenerateRequest{
+		Teams:  []Team{newTeam("Alpha", "CT"), newTeam("Bravo", "CT")},
+		Map:    "de_mirage",
+		Format: "mr12",
+	}
+	if err := req.Validate(); err == nil {
+		t.Error("Expected a request with two CT teams to be rejected")
+	}
+
+	req.Teams[0].Side = "TERRORIST"
+	if err := req.Validate(); err != nil {
+		t.Errorf("Expected opposite sides to be valid, got %v", err)
+	}
+}
+
+func TestGenerateRequest_ValidateRejectsDuplicateTeamNames(t *testing.T) {
+	newTeam := func(name, side string) Team {
+		team := Team{Name: name, Side: side}
+		for i := 0; i < 5; i++ {
+			team.Players = append(team.Players, Player{Name: side + string(rune('a'+i)), SteamID: "STEAM_1:0:1"})
+		}
+		return team
+	}
+
+	testCases := []struct {
+		name   string
+		first  string
+		second string
+		valid  bool
+	}{
+		{"distinct", "Alpha", "Bravo", true},
+		{"identical", "Team", "Team", false},
+		{"case only", "Team", "team", false},
+		{"surrounding spaces", "Team", " Team ", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := GenerateRequest{
+				Teams:  []Team{newTeam(tc.first, "CT"), newTeam(tc.second, "TERRORIST")},
+				Map:    "de_mirage",
+				Format: "mr12",
+			}
+			err := req.Validate()
+			if tc.valid && err != nil {
+				t.Errorf("Expected teams %q and %q to be valid, got %v", tc.first, tc.second, err)
+			}
+			if !tc.valid && err == nil {
+				t.Errorf("Expected teams %q and %q to be rejected", tc.first, tc.second)
+			}
+		})
+	}
+}
+
+func TestAssignTeamSides(t *testing.T) {
+	teams := []Team{
+		{Name: "Alpha", Players: []Player{{Name: "a1"}}},
+		{Name: "Bravo", Players: []Player{{Name: "b1"}}},
+	}
+	if !AssignTeamSides(teams, false) {
+		t.Error("Expected blank sides to be reported as auto-assigned")
+	}
+	if teams[0].Side != "TERRORIST" || teams[1].Side != "CT" {
+		t.Errorf("Expected the coin flip to put the second team on CT, got %s/%s", teams[0].Side, teams[1].Side)
+	}
+
+	teams[0].Side, teams[1].Side = "", ""
+	AssignTeamSides(teams, true)
+	if teams[0].Side != "CT" || teams[1].Side != "TERRORIST" {
+		t.Errorf("Expected the coin flip to put the first team on CT, got %s/%s", teams[0].Side, teams[1].Side)
+	}
+
+	teams[0].Side, teams[1].Side = "t", "ct"
+	if AssignTeamSides(teams, true) {
+		t.Error("Expected requested sides not to be reported as auto-assigned")
+	}
+	if teams[0].Side != "TERRORIST" || teams[1].Side != "CT" {
+		t.Errorf("Expected requested sides to be kept, got %s/%s", teams[0].Side, teams[1].Side)
+	}
+	if teams[0].Players[0].Side != "TERRORIST" || teams[1].Players[0].Side != "CT" {
+		t.Error("Expected player sides to follow their team")
+	}
+}
+
+func TestMatch_HalftimeAndPistolRounds(t *testing.T) {
+	testCases := []struct {
+		format        string
+		maxRounds     int
+		halftimeRound int
+		pistolRounds  []int
+	}{
+		{"mr12", 24, 12, []int{1, 13}},
+		{"mr15", 30, 15, []int{1, 16}},
+	}
+
+	for _, tc := range testCases {
+		config := DefaultMatchConfig()
+		config.Format = tc.format
+		match := NewMatch(config, []Team{{Name: "Alpha"}, {Name: "Bravo"}})
+
+		if match.MaxRounds != tc.maxRounds || config.GetMaxRounds() != tc.maxRounds {
+			t.Errorf("%s: expected %d rounds, got %d (config %d)", tc.format, tc.maxRounds, match.MaxRounds, config.GetMaxRounds())
+		}
+		if match.HalftimeRound() != tc.halftimeRound {
+			t.Errorf("%s: expected halftime after round %d, got %d", tc.format, tc.halftimeRound, match.HalftimeRound())
+		}
+
+		pistols := 0
+		for round := 1; round <= match.MaxRounds; round++ {
+			if match.IsPistolRound(round) {
+				pistols++
+			}
+		}
+		for _, round := range tc.pistolRounds {
+			if !match.IsPistolRound(round) {
+				t.Errorf("%s: expected round %d to be a pistol round", tc.format, round)
+			}
+		}
+		if pistols != len(tc.pistolRounds) {
+			t.Errorf("%s: expected %d pistol rounds, got %d", tc.format, len(tc.pistolRounds), pistols)
+		}
+	}
+}
+
+func TestGenerateRequest_ValidateStartingEconomy(t *testing.T) {
+	newRequest := func() GenerateRequest {
+		req := GenerateRequest{Map: "de_mirage", Format: "mr12"}
+		for i, side := range []string{"CT", "TERRORIST"} {
+			team := Team{Name: side, Side: side}
+			for j := 0; j < 5; j++ {
+				team.Players = append(team.Players, Player{Name: fmt.Sprintf("%s%d", side, j), SteamID: "STEAM_1:0:1"})
+			}
+			req.Teams = append(req.Teams, team)
+			req.Teams[i].Economy.ConsecutiveLosses = i
+		}
+		return req
+	}
+
+	testCases := []struct {
+		name   string
+		modify func(req *GenerateRequest)
+		valid  bool
+	}{
+		{"defaults", func(req *GenerateRequest) {}, true},
+		{"loss streak", func(req *GenerateRequest) { req.Teams[0].Economy.ConsecutiveLosses = 3 }, true},
+		{"negative loss streak", func(req *GenerateRequest) { req.Teams[0].Economy.ConsecutiveLosses = -1 }, false},
+		{"money at the cap", func(req *GenerateRequest) { req.Teams[1].Players[2].Economy.Money = 16000 }, true},
+		{"money over the cap", func(req *GenerateRequest) { req.Teams[1].Players[2].Economy.Money = 16001 }, false},
+		{"money under a raised cap", func(req *GenerateRequest) {
+			req.Options.MaxMoney = 30000
+			req.Teams[1].Players[2].Economy.Money = 16001
+		}, true},
+		{"negative money", func(req *GenerateRequest) { req.Teams[0].Players[0].Economy.Money = -800 }, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := newRequest()
+			tc.modify(&req)
+			err := req.Validate()
+			if tc.valid && err != nil {
+				t.Errorf("Expected request to be valid, got %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Error("Expected request to be rejected")
+			}
+		})
+	}
+}
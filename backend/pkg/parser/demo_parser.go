@@ -1,9 +1,14 @@
 package parser
 
 import (
+	"errors"
+
 	"github.com/noueii/nocs-log-generator/backend/pkg/models"
 )
 
+// ErrDemoParsingUnavailable is returned while no demo decoder is built in
+var ErrDemoParsingUnavailable = errors.New("demo parsing is not available in this build")
+
 // DemoParser handles CS2 demo file parsing using demoinfocs-golang
 type DemoParser struct {
 	// TODO: Add demoinfocs-golang dependencies
@@ -14,9 +19,9 @@ func NewDemoParser() *DemoParser {
 	return &DemoParser{}
 }
 
-// ParseDemo parses a CS2 demo file and converts it to HTTP log format
+// ParseDemo parses a CS2 demo file into a match whose events can be re-emitted
+// by any of the formatters
 func (p *DemoParser) ParseDemo(demoPath string) (*models.Match, error) {
 	// TODO: Implement demo parsing using demoinfocs-golang
-	// This will be implemented in a future task
-	return nil, nil
-}
\ No newline at end of file
+	return nil, ErrDemoParsingUnavailable
+}
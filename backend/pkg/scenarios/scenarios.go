@@ -0,0 +1,191 @@
+package scenarios
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/noueii/nocs-log-generator/backend/pkg/models"
+)
+
+// Scenario is a named generate request that produces a known match shape. The shape
+// comes from the request's scoreline, economy and player profiles rather than from a
+// lucky seed; the seed only makes the exact log reproducible.
+type Scenario struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Seed        int64                  `json:"seed"`
+	Request     models.GenerateRequest `json:"request"`
+
+	// Check reports whether a generated match has the documented shape
+	Check func(match *models.Match) error `json:"-"`
+}
+
+// registry maps scenario names to their builders
+var registry = map[string]func() Scenario{
+	"eco_vs_full_buy": ScenarioEcoVsFullBuy,
+	"awp_clutch":      ScenarioAWPClutch,
+}
+
+// List returns all scenarios ordered by name
+func List() []Scenario {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	scenarios := make([]Scenario, 0, len(names))
+	for _, name := range names {
+		scenarios = append(scenarios, registry[name]())
+	}
+	return scenarios
+}
+
+// Get builds the scenario with the given name
+func Get(name string) (Scenario, bool) {
+	build, ok := registry[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return Scenario{}, false
+	}
+	return build(), true
+}
+
+// ScenarioEcoVsFullBuy is a four round match in which one team starts rich and wins
+// every round, so the second round is its full buy against the other team's eco
+func ScenarioEcoVsFullBuy() Scenario {
+	const seed = 2
+	request := newRequest("de_mirage", seed, models.MatchOptions{
+		MaxRounds: 4,
+		Scoreline: []int{3, 0},
+		// Losing earns nothing, so the pistol round loser can't afford more than an eco
+		LossBonusLadder: []int{0},
+	})
+	for i := range request.Teams[0].Players {
+		request.Teams[0].Players[i].Economy.Money = 16000
+	}
+
+	return Scenario{
+		Name:        "eco_vs_full_buy",
+		Description: "Four round match won 3-0 by a team that starts on max money: round 2 is its full buy against the loser's eco",
+		Seed:        seed,
+		Request:     request,
+		Check:       checkEcoVsFullBuy,
+	}
+}
+
+// ScenarioAWPClutch is a match one team wins 13-0 with a passive AWPer behind four
+// aggressive teammates. The AWPer is left alone to win about a quarter of the rounds,
+// so nearly every seed produces at least one clutch.
+func ScenarioAWPClutch() Scenario {
+	const seed = 9
+	request := newRequest("de_dust2", seed, models.MatchOptions{Scoreline: []int{13, 0}})
+	for i := range request.Teams[0].Players {
+		player := &request.Teams[0].Players[i]
+		player.Profile.Aggression = 1
+		if player.Role == "awp" {
+			player.Profile.Aggression = 0
+		}
+	}
+
+	return Scenario{
+		Name:        "awp_clutch",
+		Description: "MR12 match won 13-0 containing a round won by the winning team's AWPer as the last player alive on their team",
+		Seed:        seed,
+		Request:     request,
+		Check:       checkAWPClutch,
+	}
+}
+
+// newRequest builds an MR12 request between two fixed rosters
+func newRequest(mapName string, seed int64, options models.MatchOptions) models.GenerateRequest {
+	options.Seed = seed
+	options.TickRate = 64
+
+	return models.GenerateRequest{
+		Teams: []models.Team{
+			newTeam(0, "Scenario Alpha", "ALP", "alpha"),
+			newTeam(1, "Scenario Bravo", "BRV", "bravo"),
+		},
+		Map:     mapName,
+		Format:  "mr12",
+		Options: options,
+	}
+}
+
+// newTeam builds a five player roster with one player per role
+func newTeam(index int, name, tag, prefix string) models.Team {
+	roles := []string{"awp", "entry", "support", "igl", "lurker"}
+
+	team := models.Team{
+		Name:    name,
+		Tag:     tag,
+		Players: make([]models.Player, 0, len(roles)),
+	}
+	for i, role := range roles {
+		team.Players = append(team.Players, models.Player{
+			Name:    prefix + "_" + role,
+			SteamID: fmt.Sprintf("STEAM_1:%d:%d", i%2, 700000+index*100+i),
+			Role:    role,
+			Profile: models.DefaultPlayerProfile(),
+		})
+	}
+	return team
+}
+
+// checkEcoVsFullBuy verifies the second round was a full buy against an eco, won by
+// the full buy team
+func checkEcoVsFullBuy(match *models.Match) error {
+	if len(match.Rounds) < 2 {
+		return fmt.Errorf("expected at least 2 rounds, got %d", len(match.Rounds))
+	}
+
+	previous, round := match.Rounds[0], match.Rounds[1]
+	for _, team := range match.Teams {
+		buyType := round.Economy[team.Name].BuyType
+		won := round.Scores[team.Name] > previous.Scores[team.Name]
+		if won && buyType != "full_buy" {
+			return fmt.Errorf("round 2 winner %s played a %q round, expected full_buy", team.Name, buyType)
+		}
+		if !won && buyType != "eco" {
+			return fmt.Errorf("round 2 loser %s played a %q round, expected eco", team.Name, buyType)
+		}
+	}
+	return nil
+}
+
+// checkAWPClutch verifies some round was won by a team whose AWPer survived it as the
+// last player alive, after all four teammates died
+func checkAWPClutch(match *models.Match) error {
+	deaths := make(map[int]map[string]bool)
+	for _, event := range match.Events {
+		if kill, ok := event.(*models.KillEvent); ok {
+			if deaths[kill.Round] == nil {
+				deaths[kill.Round] = make(map[string]bool)
+			}
+			deaths[kill.Round][kill.Victim.Name] = true
+		}
+	}
+
+	previous := make(map[string]int)
+	for _, round := range match.Rounds {
+		for _, team := range match.Teams {
+			won := round.Scores[team.Name] > previous[team.Name]
+			previous[team.Name] = round.Scores[team.Name]
+			if !won {
+				continue
+			}
+
+			clutch := true
+			for _, player := range team.Players {
+				if deaths[round.RoundNumber][player.Name] == (player.Role == "awp") {
+					clutch = false
+				}
+			}
+			if clutch {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("no round was won by a lone AWP player")
+}
@@ -0,0 +1,67 @@
+package scenarios
+
+import (
+	"testing"
+
+	"github.com/noueii/nocs-log-generator/backend/pkg/generator"
+)
+
+func TestScenarios_ProduceDocumentedShape(t *testing.T) {
+	for _, scenario := range List() {
+		t.Run(scenario.Name, func(t *testing.T) {
+			if scenario.Request.Options.Seed != scenario.Seed {
+				t.Fatalf("Expected request seed %d, got %d", scenario.Seed, scenario.Request.Options.Seed)
+			}
+
+			match, err := generator.NewMatchGenerator().Generate(&scenario.Request)
+			if err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+
+			if err := scenario.Check(match); err != nil {
+				t.Errorf("Scenario %s did not produce its documented shape: %v", scenario.Name, err)
+			}
+		})
+	}
+}
+
+func TestScenarios_ShapeDoesNotDependOnSeed(t *testing.T) {
+	// Scenarios whose shape is likely rather than certain, with the share of seeds
+	// that must produce it; every other scenario must produce it for every seed
+	minShare := map[string]float64{
+		"awp_clutch": 0.95,
+	}
+
+	const seeds = 100
+	for _, scenario := range List() {
+		produced := 0
+		for seed := int64(1); seed <= seeds; seed++ {
+			req := scenario.Request
+			req.Options.Seed = seed
+			match, err := generator.NewMatchGenerator().Generate(&req)
+			if err != nil {
+				t.Fatalf("%s with seed %d: Generate failed: %v", scenario.Name, seed, err)
+			}
+			if scenario.Check(match) == nil {
+				produced++
+			}
+		}
+
+		want, ok := minShare[scenario.Name]
+		if !ok {
+			want = 1
+		}
+		if share := float64(produced) / seeds; share < want {
+			t.Errorf("%s: expected at least %.0f%% of seeds to produce its shape, got %.0f%%", scenario.Name, want*100, share*100)
+		}
+	}
+}
+
+func TestScenarios_Get(t *testing.T) {
+	if _, ok := Get(" AWP_Clutch "); !ok {
+		t.Error("Expected scenario lookup to ignore case and whitespace")
+	}
+	if _, ok := Get("unknown"); ok {
+		t.Error("Expected unknown scenario to be missing")
+	}
+}
@@ -0,0 +1,238 @@
+package store
+
+import (
+	"container/list"
+	"sort"
+	"sync"
+
+	"github.com/noueii/nocs-log-generator/backend/pkg/models"
+)
+
+// shardCount is the number of independently locked shards; a power of two so
+// shard selection is a mask
+const shardCount = 32
+
+// DefaultCapacity is how many matches a store keeps before evicting the oldest
+const DefaultCapacity = 1000
+
+// MatchStore keeps generated matches in memory. Matches are spread over shards by
+// ID and digests by their own hash, so concurrent requests rarely share a lock.
+// Once the store is full, storing a new match evicts the one stored longest ago.
+type MatchStore struct {
+	shards [shardCount]*storeShard
+
+	capacity int
+	orderMu  sync.Mutex
+	order    *list.List               // match IDs, oldest first
+	elements map[string]*list.Element // match ID -> its place in order
+}
+
+// storeShard holds one slice of the store behind its own lock
+type storeShard struct {
+	mu           sync.RWMutex
+	matches      map[string]*models.Match
+	matchDigests map[string][]string           // match ID -> digests indexed for it
+	digests      map[string]string             // digest -> match ID
+	indexes      map[string]*models.EventIndex // match ID -> tick index, built on first use
+}
+
+// NewMatchStore creates a new in-memory match store holding up to DefaultCapacity matches
+func NewMatchStore() *MatchStore {
+	return NewMatchStoreWithCapacity(DefaultCapacity)
+}
+
+// NewMatchStoreWithCapacity creates a new in-memory match store holding up to capacity
+// matches, or any number of them when capacity is 0
+func NewMatchStoreWithCapacity(capacity int) *MatchStore {
+	s := &MatchStore{
+		capacity: capacity,
+		order:    list.New(),
+		elements: make(map[string]*list.Element),
+	}
+	for i := range s.shards {
+		s.shards[i] = &storeShard{
+			matches:      make(map[string]*models.Match),
+			matchDigests: make(map[string][]string),
+			digests:      make(map[string]string),
+			indexes:      make(map[string]*models.EventIndex),
+		}
+	}
+	return s
+}
+
+// shardFor returns the shard responsible for a key using 32-bit FNV-1a
+func (s *MatchStore) shardFor(key string) *storeShard {
+	hash := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		hash ^= uint32(key[i])
+		hash *= 16777619
+	}
+	return s.shards[hash&(shardCount-1)]
+}
+
+// Put stores a match, replacing any match with the same ID
+func (s *MatchStore) Put(match *models.Match) {
+	if match == nil {
+		return
+	}
+
+	shard := s.shardFor(match.ID)
+	shard.mu.Lock()
+	shard.matches[match.ID] = match
+	delete(shard.indexes, match.ID)
+	shard.mu.Unlock()
+
+	s.track(match.ID)
+}
+
+// PutWithDigest stores a match and indexes it by a request digest
+func (s *MatchStore) PutWithDigest(match *models.Match, digest string) {
+	if match == nil {
+		return
+	}
+
+	shard := s.shardFor(match.ID)
+	shard.mu.Lock()
+	shard.matches[match.ID] = match
+	delete(shard.indexes, match.ID)
+	if digest != "" {
+		shard.matchDigests[match.ID] = append(shard.matchDigests[match.ID], digest)
+	}
+	shard.mu.Unlock()
+
+	if digest != "" {
+		digestShard := s.shardFor(digest)
+		digestShard.mu.Lock()
+		digestShard.digests[digest] = match.ID
+		digestShard.mu.Unlock()
+	}
+
+	s.track(match.ID)
+}
+
+// track records a stored match in the eviction order and evicts the oldest matches
+// while the store is over capacity. Replacing a match keeps its place.
+func (s *MatchStore) track(id string) {
+	var evicted []string
+	s.orderMu.Lock()
+	if _, ok := s.elements[id]; !ok {
+		s.elements[id] = s.order.PushBack(id)
+	}
+	for s.capacity > 0 && s.order.Len() > s.capacity {
+		oldest := s.order.Remove(s.order.Front()).(string)
+		delete(s.elements, oldest)
+		evicted = append(evicted, oldest)
+	}
+	s.orderMu.Unlock()
+
+	for _, oldest := range evicted {
+		s.remove(oldest)
+	}
+}
+
+// Get returns the match with the given ID
+func (s *MatchStore) Get(id string) (*models.Match, bool) {
+	shard := s.shardFor(id)
+	shard.mu.RLock()
+	defer shard.mu.RUnlock()
+
+	match, ok := shard.matches[id]
+	return match, ok
+}
+
+// GetByDigest returns a previously stored match generated from an identical request
+func (s *MatchStore) GetByDigest(digest string) (*models.Match, bool) {
+	digestShard := s.shardFor(digest)
+	digestShard.mu.RLock()
+	id, ok := digestShard.digests[digest]
+	digestShard.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+
+	return s.Get(id)
+}
+
+// EventIndex returns the tick index of a stored match's events, building it on first use
+func (s *MatchStore) EventIndex(id string) (*models.EventIndex, bool) {
+	shard := s.shardFor(id)
+	shard.mu.RLock()
+	match, ok := shard.matches[id]
+	index := shard.indexes[id]
+	shard.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	if index != nil {
+		return index, true
+	}
+
+	// Build outside the lock; only keep the index if the match was not replaced meanwhile
+	index = models.NewEventIndex(match.Events)
+	shard.mu.Lock()
+	if shard.matches[id] == match {
+		shard.indexes[id] = index
+	}
+	shard.mu.Unlock()
+	return index, true
+}
+
+// Delete removes a match and any digests pointing to it
+func (s *MatchStore) Delete(id string) {
+	s.orderMu.Lock()
+	if element, ok := s.elements[id]; ok {
+		s.order.Remove(element)
+		delete(s.elements, id)
+	}
+	s.orderMu.Unlock()
+
+	s.remove(id)
+}
+
+// remove drops a match, its index and the digests pointing to it from the shards
+func (s *MatchStore) remove(id string) {
+	shard := s.shardFor(id)
+	shard.mu.Lock()
+	delete(shard.matches, id)
+	delete(shard.indexes, id)
+	digests := shard.matchDigests[id]
+	delete(shard.matchDigests, id)
+	shard.mu.Unlock()
+
+	for _, digest := range digests {
+		digestShard := s.shardFor(digest)
+		digestShard.mu.Lock()
+		if digestShard.digests[digest] == id {
+			delete(digestShard.digests, digest)
+		}
+		digestShard.mu.Unlock()
+	}
+}
+
+// List returns all stored matches ordered by ID
+func (s *MatchStore) List() []*models.Match {
+	matches := make([]*models.Match, 0)
+	for _, shard := range s.shards {
+		shard.mu.RLock()
+		for _, match := range shard.matches {
+			matches = append(matches, match)
+		}
+		shard.mu.RUnlock()
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].ID < matches[j].ID
+	})
+	return matches
+}
+
+// Len returns the number of stored matches
+func (s *MatchStore) Len() int {
+	total := 0
+	for _, shard := range s.shards {
+		shard.mu.RLock()
+		total += len(shard.matches)
+		shard.mu.RUnlock()
+	}
+	return total
+}
@@ -0,0 +1,195 @@
+package store
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/noueii/nocs-log-generator/backend/pkg/models"
+)
+
+func TestMatchStore_PutGetDelete(t *testing.T) {
+	s := NewMatchStore()
+	for i := 0; i < 100; i++ {
+		s.PutWithDigest(&models.Match{ID: fmt.Sprintf("match-%03d", i)}, fmt.Sprintf("digest-%03d", i))
+	}
+
+	if s.Len() != 100 {
+		t.Fatalf("Expected 100 matches, got %d", s.Len())
+	}
+	if match, ok := s.GetByDigest("digest-042"); !ok || match.ID != "match-042" {
+		t.Fatalf("Expected digest lookup to return match-042, got %v", match)
+	}
+
+	list := s.List()
+	for i := 1; i < len(list); i++ {
+		if list[i-1].ID >= list[i].ID {
+			t.Fatalf("Expected List to be ordered by ID, got %s before %s", list[i-1].ID, list[i].ID)
+		}
+	}
+
+	s.Delete("match-042")
+	if _, ok := s.Get("match-042"); ok {
+		t.Error("Expected deleted match to be gone")
+	}
+	if _, ok := s.GetByDigest("digest-042"); ok {
+		t.Error("Expected the deleted match's digest to be removed")
+	}
+	if s.Len() != 99 {
+		t.Errorf("Expected 99 matches after delete, got %d", s.Len())
+	}
+}
+
+func TestMatchStore_EvictsOldestOverCapacity(t *testing.T) {
+	s := NewMatchStoreWithCapacity(3)
+	for i := 0; i < 3; i++ {
+		s.PutWithDigest(&models.Match{ID: fmt.Sprintf("match-%d", i)}, fmt.Sprintf("digest-%d", i))
+	}
+	// Replacing a match keeps its place, and deleting one frees a slot
+	s.Put(&models.Match{ID: "match-0"})
+	s.Delete("match-2")
+	s.Put(&models.Match{ID: "match-3"})
+	s.Put(&models.Match{ID: "match-4"})
+
+	if s.Len() != 3 {
+		t.Fatalf("Expected the store to hold 3 matches, got %d", s.Len())
+	}
+	if _, ok := s.Get("match-0"); ok {
+		t.Error("Expected the oldest match to be evicted")
+	}
+	if _, ok := s.GetByDigest("digest-0"); ok {
+		t.Error("Expected the evicted match's digest to be removed")
+	}
+	for _, id := range []string{"match-1", "match-3", "match-4"} {
+		if _, ok := s.Get(id); !ok {
+			t.Errorf("Expected %s to be kept", id)
+		}
+	}
+}
+
+func TestMatchStore_DeleteKeepsReassignedDigest(t *testing.T) {
+	s := NewMatchStore()
+	s.PutWithDigest(&models.Match{ID: "old"}, "shared")
+	s.PutWithDigest(&models.Match{ID: "new"}, "shared")
+
+	s.Delete("old")
+	if match, ok := s.GetByDigest("shared"); !ok || match.ID != "new" {
+		t.Errorf("Expected digest to still point at the newer match, got %v", match)
+	}
+}
+
+func TestMatchStore_EventIndex(t *testing.T) {
+	s := NewMatchStore()
+	if _, ok := s.EventIndex("missing"); ok {
+		t.Error("Expected no index for an unknown match")
+	}
+
+	newEvent := func(tick int64) models.GameEvent {
+		return &models.RoundStartEvent{BaseEvent: models.BaseEvent{Type: "round_start", Tick: tick}}
+	}
+	s.Put(&models.Match{ID: "match", Events: []models.GameEvent{newEvent(0), newEvent(64)}})
+	index, ok := s.EventIndex("match")
+	if !ok || index.Len() != 2 {
+		t.Fatalf("Expected an index over 2 events, got %v", index)
+	}
+	if again, _ := s.EventIndex("match"); again != index {
+		t.Error("Expected the index to be reused")
+	}
+
+	// Replacing the match drops its stale index
+	s.Put(&models.Match{ID: "match", Events: []models.GameEvent{newEvent(0), newEvent(64), newEvent(128)}})
+	if index, _ := s.EventIndex("match"); index.Len() != 3 {
+		t.Errorf("Expected the index to be rebuilt for the replaced match, got %d events", index.Len())
+	}
+
+	s.Delete("match")
+	if _, ok := s.EventIndex("match"); ok {
+		t.Error("Expected no index after delete")
+	}
+}
+
+func TestMatchStore_Concurrent(t *testing.T) {
+	s := NewMatchStore()
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				id := fmt.Sprintf("w%d-%d", worker, i)
+				s.PutWithDigest(&models.Match{ID: id}, "d-"+id)
+				if _, ok := s.Get(id); !ok {
+					t.Errorf("Expected %s to be readable after Put", id)
+				}
+				s.List()
+				if i%2 == 0 {
+					s.Delete(id)
+				}
+			}
+		}(worker)
+	}
+	wg.Wait()
+
+	if s.Len() != 8*100 {
+		t.Errorf("Expected %d matches, got %d", 8*100, s.Len())
+	}
+}
+
+// mutexMatchStore is the single-mutex baseline the sharded store is benchmarked against
+type mutexMatchStore struct {
+	mu      sync.RWMutex
+	matches map[string]*models.Match
+}
+
+func (s *mutexMatchStore) Put(match *models.Match) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.matches[match.ID] = match
+}
+
+func (s *mutexMatchStore) Get(id string) (*models.Match, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	match, ok := s.matches[id]
+	return match, ok
+}
+
+type benchmarkStore interface {
+	Put(match *models.Match)
+	Get(id string) (*models.Match, bool)
+}
+
+// benchmarkReadWrite runs a parallel mix of nine reads per write
+func benchmarkReadWrite(b *testing.B, s benchmarkStore) {
+	const preloaded = 1024
+	matches := make([]*models.Match, preloaded)
+	for i := range matches {
+		matches[i] = &models.Match{ID: fmt.Sprintf("match-%04d", i)}
+		s.Put(matches[i])
+	}
+
+	// Each goroutine starts at a different match so they do not walk in lockstep
+	var offset atomic.Int64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := int(offset.Add(97))
+		for pb.Next() {
+			match := matches[i%preloaded]
+			if i%10 == 0 {
+				s.Put(match)
+			} else {
+				s.Get(match.ID)
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkMatchStore_Sharded(b *testing.B) {
+	benchmarkReadWrite(b, NewMatchStore())
+}
+
+func BenchmarkMatchStore_SingleMutex(b *testing.B) {
+	benchmarkReadWrite(b, &mutexMatchStore{matches: make(map[string]*models.Match)})
+}
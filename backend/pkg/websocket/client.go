@@ -52,6 +52,9 @@ type Client struct {
 
 	// Map of subscribed match IDs
 	subscribedMatches map[string]bool
+
+	// Map of match IDs the client also receives player position samples for
+	positionMatches map[string]bool
 }
 
 // Message types for WebSocket communication
@@ -74,6 +77,21 @@ type IncomingMessage struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
+// SubscriptionFilter holds the opt-in streams of a match subscription, sent as
+// the data of a subscribe message
+type SubscriptionFilter struct {
+	Positions bool `json:"positions"` // Receive high-frequency player_position events
+}
+
+// parseSubscriptionFilter reads the filter from the data of a subscribe message
+func parseSubscriptionFilter(data interface{}) SubscriptionFilter {
+	var filter SubscriptionFilter
+	if fields, ok := data.(map[string]interface{}); ok {
+		filter.Positions, _ = fields["positions"].(bool)
+	}
+	return filter
+}
+
 // OutgoingMessage represents messages sent to clients
 type OutgoingMessage struct {
 	Type      MessageType `json:"type"`
@@ -90,6 +108,7 @@ func NewClient(conn *websocket.Conn, hub *Hub, clientID string) *Client {
 		hub:               hub,
 		send:              make(chan []byte, 256),
 		subscribedMatches: make(map[string]bool),
+		positionMatches:   make(map[string]bool),
 	}
 }
 
@@ -191,8 +210,9 @@ func (c *Client) handleMessage(message []byte) {
 	switch inMsg.Type {
 	case MessageTypeSubscribe:
 		if inMsg.MatchID != "" {
-			c.hub.SubscribeToMatch(c, inMsg.MatchID)
-			c.sendStatus("subscribed", map[string]string{"match_id": inMsg.MatchID})
+			filter := parseSubscriptionFilter(inMsg.Data)
+			c.hub.SubscribeToMatch(c, inMsg.MatchID, filter)
+			c.sendStatus("subscribed", map[string]interface{}{"match_id": inMsg.MatchID, "positions": filter.Positions})
 		} else {
 			c.sendError("Missing match_id for subscription")
 		}
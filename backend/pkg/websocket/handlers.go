@@ -73,6 +73,11 @@ func (m *Manager) BroadcastMatchEvent(matchID string, eventType string, data int
 		return fmt.Errorf("failed to marshal match event: %w", err)
 	}
 	
+	// Position samples are frequent, so only subscribers that asked for them get them
+	if eventType == EventTypePlayerPosition {
+		m.hub.BroadcastPositionsToMatch(matchID, message)
+		return nil
+	}
 	m.hub.BroadcastToMatch(matchID, message)
 	return nil
 }
@@ -196,6 +201,7 @@ const (
 	EventTypeRoundStart      = "round_start"
 	EventTypeRoundEnd        = "round_end"
 	EventTypePlayerEvent     = "player_event"
+	EventTypePlayerPosition  = "player_position"
 	EventTypeEconomyUpdate   = "economy_update"
 	EventTypeMatchProgress   = "match_progress"
 	EventTypeMatchComplete   = "match_complete"
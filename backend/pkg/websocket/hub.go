@@ -34,8 +34,9 @@ type Hub struct {
 
 // MatchMessage represents a message targeted at specific match subscribers
 type MatchMessage struct {
-	MatchID string
-	Data    []byte
+	MatchID   string
+	Data      []byte
+	Positions bool // Only sent to subscribers that opted in to position samples
 }
 
 // NewHub creates a new WebSocket hub instance
@@ -104,8 +105,19 @@ func (h *Hub) BroadcastToMatch(matchID string, message []byte) {
 	}
 }
 
-// SubscribeToMatch subscribes a client to match-specific messages
-func (h *Hub) SubscribeToMatch(client *Client, matchID string) {
+// BroadcastPositionsToMatch sends a position sample to the subscribers of a match
+// that opted in to position samples
+func (h *Hub) BroadcastPositionsToMatch(matchID string, message []byte) {
+	h.matchBroadcast <- &MatchMessage{
+		MatchID:   matchID,
+		Data:      message,
+		Positions: true,
+	}
+}
+
+// SubscribeToMatch subscribes a client to match-specific messages, including the
+// opt-in streams enabled by the filter
+func (h *Hub) SubscribeToMatch(client *Client, matchID string, filter SubscriptionFilter) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
@@ -115,6 +127,11 @@ func (h *Hub) SubscribeToMatch(client *Client, matchID string) {
 	
 	h.matchClients[matchID][client] = true
 	client.subscribedMatches[matchID] = true
+	if filter.Positions {
+		client.positionMatches[matchID] = true
+	} else {
+		delete(client.positionMatches, matchID)
+	}
 	
 	log.Printf("Client %s subscribed to match %s", client.id, matchID)
 }
@@ -134,6 +151,7 @@ func (h *Hub) UnsubscribeFromMatch(client *Client, matchID string) {
 	}
 	
 	delete(client.subscribedMatches, matchID)
+	delete(client.positionMatches, matchID)
 	
 	log.Printf("Client %s unsubscribed from match %s", client.id, matchID)
 }
@@ -223,6 +241,9 @@ func (h *Hub) broadcastToMatch(matchMsg *MatchMessage) {
 	}
 	
 	for client := range matchClients {
+		if matchMsg.Positions && !client.positionMatches[matchMsg.MatchID] {
+			continue
+		}
 		select {
 		case client.send <- matchMsg.Data:
 		default:
@@ -230,6 +251,7 @@ func (h *Hub) broadcastToMatch(matchMsg *MatchMessage) {
 			// Remove client from match subscription
 			delete(matchClients, client)
 			delete(client.subscribedMatches, matchMsg.MatchID)
+			delete(client.positionMatches, matchMsg.MatchID)
 			
 			// Clean up empty match subscription map
 			if len(matchClients) == 0 {